@@ -49,6 +49,7 @@ type Configuration struct {
 	USER_CACHE_LIFE_MINUTES  int
 	USER_CACHE_CLEAN_MINUTES int
 	USER_CACHE_MAX_SIZE_MB   int
+	SCHEMA_REGISTRY_API      bool
 }
 
 func GetConfig() Configuration {
@@ -222,17 +222,19 @@ func createTables(MetadataDbClient MetadataStorage) error {
 		ALTER TABLE schemas DROP CONSTRAINT IF EXISTS name;
 		ALTER TABLE schemas DROP CONSTRAINT IF EXISTS schemas_name_tenant_name_key;
 		ALTER TABLE schemas ADD CONSTRAINT schemas_name_tenant_name_key UNIQUE(name, tenant_name);
+		ALTER TABLE schemas ADD COLUMN IF NOT EXISTS compatibility_mode VARCHAR NOT NULL DEFAULT 'none';
 		END IF;
 	END $$;`
 
 	schemasTable := `
-	CREATE TYPE enum_type AS ENUM ('json', 'graphql', 'protobuf');
+	CREATE TYPE enum_type AS ENUM ('json', 'graphql', 'protobuf', 'avro');
 	CREATE TABLE IF NOT EXISTS schemas(
 		id SERIAL NOT NULL,
 		name VARCHAR NOT NULL,
 		type enum_type NOT NULL DEFAULT 'protobuf',
 		created_by_username VARCHAR NOT NULL,
 		tenant_name VARCHAR NOT NULL DEFAULT '$memphis',
+		compatibility_mode VARCHAR NOT NULL DEFAULT 'none',
 		PRIMARY KEY (id),
 		CONSTRAINT fk_tenant_name_schemas
 			FOREIGN KEY(tenant_name)
@@ -241,6 +243,8 @@ func createTables(MetadataDbClient MetadataStorage) error {
 		);
 		CREATE INDEX IF NOT EXISTS name ON schemas (name);`
 
+	alterSchemasTypeEnum := `ALTER TYPE enum_type ADD VALUE IF NOT EXISTS 'avro';`
+
 	alterTagsTable := `
 	DO $$
 	BEGIN
@@ -341,6 +345,13 @@ func createTables(MetadataDbClient MetadataStorage) error {
 		) THEN
 		ALTER TABLE stations ADD COLUMN IF NOT EXISTS tenant_name VARCHAR NOT NULL DEFAULT '$memphis';
 		ALTER TABLE stations ADD COLUMN IF NOT EXISTS resend_disabled BOOL NOT NULL DEFAULT false;
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS schema_enforced BOOL NOT NULL DEFAULT false;
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS mirror JSONB;
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS sources JSONB NOT NULL DEFAULT '[]';
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS immutability JSONB NOT NULL DEFAULT '{}';
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS retention_policy JSONB NOT NULL DEFAULT '{}';
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS dls_station VARCHAR NOT NULL DEFAULT '';
+		ALTER TABLE stations ADD COLUMN IF NOT EXISTS dls_station_only BOOL NOT NULL DEFAULT false;
 		DROP INDEX IF EXISTS unique_station_name_deleted;
 		CREATE UNIQUE INDEX unique_station_name_deleted ON stations(name, is_deleted, tenant_name) WHERE is_deleted = false;
 		END IF;
@@ -370,6 +381,13 @@ func createTables(MetadataDbClient MetadataStorage) error {
 		tiered_storage_enabled BOOL NOT NULL,
 		tenant_name VARCHAR NOT NULL DEFAULT '$memphis',
 		resend_disabled BOOL NOT NULL DEFAULT false,
+		schema_enforced BOOL NOT NULL DEFAULT false,
+		mirror JSONB,
+		sources JSONB NOT NULL DEFAULT '[]',
+		immutability JSONB NOT NULL DEFAULT '{}',
+		retention_policy JSONB NOT NULL DEFAULT '{}',
+		dls_station VARCHAR NOT NULL DEFAULT '',
+		dls_station_only BOOL NOT NULL DEFAULT false,
 		PRIMARY KEY (id),
 		CONSTRAINT fk_tenant_name_stations
 			FOREIGN KEY(tenant_name)
@@ -384,6 +402,8 @@ func createTables(MetadataDbClient MetadataStorage) error {
 			SELECT 1 FROM information_schema.tables WHERE table_name = 'schema_versions' AND table_schema = 'public'
 		) THEN
 		ALTER TABLE schema_versions ADD COLUMN IF NOT EXISTS tenant_name VARCHAR NOT NULL DEFAULT '$memphis';
+		ALTER TABLE schema_versions ADD COLUMN IF NOT EXISTS schema_files JSONB NOT NULL DEFAULT '{}';
+		ALTER TABLE schema_versions ADD COLUMN IF NOT EXISTS entrypoint VARCHAR NOT NULL DEFAULT '';
 		END IF;
 	END $$;`
 
@@ -399,6 +419,8 @@ func createTables(MetadataDbClient MetadataStorage) error {
 		msg_struct_name VARCHAR DEFAULT '',
 		descriptor bytea,
 		tenant_name VARCHAR NOT NULL DEFAULT '$memphis',
+		schema_files JSONB NOT NULL DEFAULT '{}',
+		entrypoint VARCHAR NOT NULL DEFAULT '',
 		PRIMARY KEY (id),
 		UNIQUE(version_number, schema_id),
 		CONSTRAINT fk_schema_id
@@ -476,6 +498,9 @@ func createTables(MetadataDbClient MetadataStorage) error {
 		) THEN
 			ALTER TABLE dls_messages ADD COLUMN IF NOT EXISTS tenant_name VARCHAR NOT NULL DEFAULT '$memphis';
 			ALTER TABLE dls_messages ADD COLUMN IF NOT EXISTS producer_name VARCHAR NOT NULL DEFAULT '';
+			ALTER TABLE dls_messages ADD COLUMN IF NOT EXISTS retry_attempts JSONB NOT NULL DEFAULT '[]';
+			ALTER TABLE dls_messages ADD COLUMN IF NOT EXISTS poisoned_cgs_at JSONB NOT NULL DEFAULT '{}';
+			ALTER TABLE dls_messages ADD COLUMN IF NOT EXISTS retry_due_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP;
 			DROP INDEX IF EXISTS dls_producer_id;
 			IF EXISTS (
 				SELECT 1 FROM information_schema.columns WHERE table_name = 'dls_messages' AND column_name = 'producer_id'
@@ -507,6 +532,9 @@ func createTables(MetadataDbClient MetadataStorage) error {
 		validation_error VARCHAR DEFAULT '',
 		tenant_name VARCHAR NOT NULL DEFAULT '$memphis',
 		producer_name VARCHAR NOT NULL,
+		retry_attempts JSONB NOT NULL DEFAULT '[]',
+		poisoned_cgs_at JSONB NOT NULL DEFAULT '{}',
+		retry_due_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
 		PRIMARY KEY (id),
 		CONSTRAINT fk_station_id
 			FOREIGN KEY(station_id)
@@ -516,7 +544,9 @@ func createTables(MetadataDbClient MetadataStorage) error {
 			REFERENCES tenants(name)
 	);
 	CREATE INDEX IF NOT EXISTS dls_station_id
-		ON dls_messages(station_id);`
+		ON dls_messages(station_id);
+	CREATE INDEX IF NOT EXISTS dls_station_id_retry_due_at
+		ON dls_messages(station_id, retry_due_at);`
 
 	asyncTasksTable := `
         CREATE TABLE IF NOT EXISTS async_tasks(
@@ -531,10 +561,57 @@ func createTables(MetadataDbClient MetadataStorage) error {
 			UNIQUE(name, tenant_name, station_id)
         );`
 
+	alertRulesTable := `
+	CREATE TABLE IF NOT EXISTS alert_rules(
+		id SERIAL NOT NULL,
+		name VARCHAR NOT NULL,
+		tenant_name VARCHAR NOT NULL DEFAULT '$memphis',
+		station_name VARCHAR NOT NULL DEFAULT '',
+		tag_name VARCHAR NOT NULL DEFAULT '',
+		rule_type VARCHAR NOT NULL,
+		threshold BIGINT NOT NULL DEFAULT 0,
+		window_seconds INT NOT NULL DEFAULT 300,
+		severity VARCHAR NOT NULL DEFAULT 'warning',
+		dedup_window_seconds INT NOT NULL DEFAULT 600,
+		cooldown_seconds INT NOT NULL DEFAULT 300,
+		is_enabled BOOL NOT NULL DEFAULT true,
+		created_by VARCHAR NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (id),
+		CONSTRAINT fk_tenant_name_alert_rules
+			FOREIGN KEY(tenant_name)
+			REFERENCES tenants(name),
+		UNIQUE(name, tenant_name)
+	);
+	CREATE INDEX IF NOT EXISTS alert_rules_tenant_name ON alert_rules(tenant_name);`
+
+	dlsRetryPoliciesTable := `
+	CREATE TABLE IF NOT EXISTS dls_retry_policies(
+		id SERIAL NOT NULL,
+		station_id INT NOT NULL,
+		cg_name VARCHAR NOT NULL DEFAULT '',
+		max_attempts INT NOT NULL,
+		initial_backoff_ms BIGINT NOT NULL,
+		backoff_multiplier FLOAT NOT NULL DEFAULT 2,
+		max_backoff_ms BIGINT NOT NULL DEFAULT 0,
+		max_age_seconds INT NOT NULL DEFAULT 0,
+		tenant_name VARCHAR NOT NULL DEFAULT '$memphis',
+		updated_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (id),
+		CONSTRAINT fk_station_id_dls_retry_policies
+			FOREIGN KEY(station_id)
+			REFERENCES stations(id),
+		CONSTRAINT fk_tenant_name_dls_retry_policies
+			FOREIGN KEY(tenant_name)
+			REFERENCES tenants(name),
+		UNIQUE(station_id, cg_name)
+	);`
+
 	db := MetadataDbClient.Client
 	ctx := MetadataDbClient.Ctx
 
-	tables := []string{alterTenantsTable, tenantsTable, alterUsersTable, usersTable, alterAuditLogsTable, auditLogsTable, alterConfigurationsTable, configurationsTable, alterIntegrationsTable, integrationsTable, alterSchemasTable, schemasTable, alterTagsTable, tagsTable, alterStationsTable, stationsTable, alterDlsMsgsTable, dlsMessagesTable, alterConsumersTable, consumersTable, alterSchemaVerseTable, schemaVersionsTable, alterProducersTable, producersTable, alterConnectionsTable, asyncTasksTable}
+	tables := []string{alterTenantsTable, tenantsTable, alterUsersTable, usersTable, alterAuditLogsTable, auditLogsTable, alterConfigurationsTable, configurationsTable, alterIntegrationsTable, integrationsTable, alterSchemasTable, schemasTable, alterSchemasTypeEnum, alterTagsTable, tagsTable, alterStationsTable, stationsTable, alterDlsMsgsTable, dlsMessagesTable, alterConsumersTable, consumersTable, alterSchemaVerseTable, schemaVersionsTable, alterProducersTable, producersTable, alterConnectionsTable, asyncTasksTable, alertRulesTable, dlsRetryPoliciesTable}
 
 	for _, table := range tables {
 		_, err := db.Exec(ctx, table)
@@ -706,6 +783,37 @@ func GetAllConfigurations() (bool, []models.ConfigurationsValue, error) {
 	return true, configurations, nil
 }
 
+func GetConfiguration(key string, tenantName string) (bool, models.ConfigurationsValue, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return false, models.ConfigurationsValue{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM configurations WHERE key = $1 AND tenant_name = $2 LIMIT 1`
+	stmt, err := conn.Conn().Prepare(ctx, "get_configuration", query)
+	if err != nil {
+		return false, models.ConfigurationsValue{}, err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, key, tenantName)
+	if err != nil {
+		return false, models.ConfigurationsValue{}, err
+	}
+	defer rows.Close()
+	configurations, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.ConfigurationsValue])
+	if err != nil {
+		return false, models.ConfigurationsValue{}, err
+	}
+	if len(configurations) == 0 {
+		return false, models.ConfigurationsValue{}, nil
+	}
+	return true, configurations[0], nil
+}
+
 func InsertConfiguration(key string, value string, tenantName string) error {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -1080,6 +1188,34 @@ func GetActiveStations() ([]models.Station, error) {
 	return stations, nil
 }
 
+func GetSchemaEnforcedStations() ([]models.Station, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.Station{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM stations AS s WHERE s.is_deleted = false AND s.schema_enforced = true AND s.schema_name != ''`
+	stmt, err := conn.Conn().Prepare(ctx, "get_schema_enforced_stations", query)
+	if err != nil {
+		return []models.Station{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name)
+	if err != nil {
+		return []models.Station{}, err
+	}
+	defer rows.Close()
+	stations, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.Station])
+	if err != nil {
+		return []models.Station{}, err
+	}
+	if len(stations) == 0 {
+		return []models.Station{}, nil
+	}
+	return stations, nil
+}
+
 func GetStationByName(name string, tenantName string) (bool, models.Station, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -1156,6 +1292,10 @@ func InsertNewStation(
 	isNative bool,
 	dlsConfiguration models.DlsConfiguration,
 	tieredStorageEnabled bool,
+	mirror *models.StationSource,
+	sources []models.StationSource,
+	immutability models.StationImmutability,
+	retentionPolicy models.StationRetentionPolicy,
 	tenantName string) (models.Station, int64, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -1184,9 +1324,15 @@ func InsertNewStation(
 		dls_configuration_poison, 
 		dls_configuration_schemaverse,
 		tiered_storage_enabled,
-		tenant_name
+		tenant_name,
+		mirror,
+		sources,
+		immutability,
+		retention_policy,
+		dls_station,
+		dls_station_only
 		) 
-    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id`
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24) RETURNING id`
 
 	stmt, err := conn.Conn().Prepare(ctx, "insert_new_station", query)
 	if err != nil {
@@ -1199,9 +1345,12 @@ func InsertNewStation(
 	if tenantName != conf.GlobalAccount {
 		tenantName = strings.ToLower(tenantName)
 	}
+	if sources == nil {
+		sources = []models.StationSource{}
+	}
 	rows, err := conn.Conn().Query(ctx, stmt.Name,
 		stationName, retentionType, retentionValue, storageType, replicas, userId, username, createAt, updatedAt,
-		false, schemaName, schemaVersionUpdate, idempotencyWindow, isNative, dlsConfiguration.Poison, dlsConfiguration.Schemaverse, tieredStorageEnabled, tenantName)
+		false, schemaName, schemaVersionUpdate, idempotencyWindow, isNative, dlsConfiguration.Poison, dlsConfiguration.Schemaverse, tieredStorageEnabled, tenantName, mirror, sources, immutability, retentionPolicy, dlsConfiguration.Station, dlsConfiguration.StationOnly)
 	if err != nil {
 		return models.Station{}, 0, err
 	}
@@ -1253,6 +1402,12 @@ func InsertNewStation(
 		DlsConfigurationSchemaverse: dlsConfiguration.Schemaverse,
 		TieredStorageEnabled:        tieredStorageEnabled,
 		TenantName:                  tenantName,
+		Mirror:                      mirror,
+		Sources:                     sources,
+		Immutability:                immutability,
+		RetentionPolicy:             retentionPolicy,
+		DlsStation:                  dlsConfiguration.Station,
+		DlsStationOnly:              dlsConfiguration.StationOnly,
 	}
 
 	rowsAffected := rows.CommandTag().RowsAffected()
@@ -1361,6 +1516,13 @@ func GetAllStationsDetailsPerTenant(tenantName string) ([]models.ExtendedStation
 			&stationRes.TieredStorageEnabled,
 			&stationRes.TenantName,
 			&stationRes.ResendDisabled,
+			&stationRes.SchemaEnforced,
+			&stationRes.Mirror,
+			&stationRes.Sources,
+			&stationRes.Immutability,
+			&stationRes.RetentionPolicy,
+			&stationRes.DlsStation,
+			&stationRes.DlsStationOnly,
 			&producer.ID,
 			&producer.Name,
 			&producer.StationId,
@@ -1486,6 +1648,13 @@ func GetAllStationsDetailsLight(tenantName string) ([]models.ExtendedStationLigh
 			&stationRes.TieredStorageEnabled,
 			&stationRes.TenantName,
 			&stationRes.ResendDisabled,
+			&stationRes.SchemaEnforced,
+			&stationRes.Mirror,
+			&stationRes.Sources,
+			&stationRes.Immutability,
+			&stationRes.RetentionPolicy,
+			&stationRes.DlsStation,
+			&stationRes.DlsStationOnly,
 			&stationRes.Activity,
 		); err != nil {
 			return []models.ExtendedStationLight{}, err
@@ -1664,6 +1833,14 @@ func GetAllStationsDetails() ([]models.ExtendedStation, error) {
 			&stationRes.DlsConfigurationSchemaverse,
 			&stationRes.TieredStorageEnabled,
 			&stationRes.TenantName,
+			&stationRes.ResendDisabled,
+			&stationRes.SchemaEnforced,
+			&stationRes.Mirror,
+			&stationRes.Sources,
+			&stationRes.Immutability,
+			&stationRes.RetentionPolicy,
+			&stationRes.DlsStation,
+			&stationRes.DlsStationOnly,
 			&producer.ID,
 			&producer.Name,
 			&producer.StationId,
@@ -1917,6 +2094,102 @@ func UpdateStationDlsConfig(stationName string, poison bool, schemaverse bool, t
 	return nil
 }
 
+func UpdateStationDlsStation(stationName string, dlsStation string, dlsStationOnly bool, tenantName string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE stations SET dls_station = $2, dls_station_only = $3
+	WHERE name = $1 AND is_deleted = false AND tenant_name=$4`
+	stmt, err := conn.Conn().Prepare(ctx, "update_station_dls_station", query)
+	if err != nil {
+		return err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	_, err = conn.Conn().Query(ctx, stmt.Name, stationName, dlsStation, dlsStationOnly, tenantName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func UpdateStationSchemaEnforcement(stationName string, schemaEnforced bool, tenantName string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE stations SET schema_enforced = $2
+	WHERE name = $1 AND is_deleted = false AND tenant_name=$3`
+	stmt, err := conn.Conn().Prepare(ctx, "update_station_schema_enforcement", query)
+	if err != nil {
+		return err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	_, err = conn.Conn().Query(ctx, stmt.Name, stationName, schemaEnforced, tenantName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func UpdateStationImmutability(stationName string, immutability models.StationImmutability, tenantName string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE stations SET immutability = $2
+	WHERE name = $1 AND is_deleted = false AND tenant_name=$3`
+	stmt, err := conn.Conn().Prepare(ctx, "update_station_immutability", query)
+	if err != nil {
+		return err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	_, err = conn.Conn().Query(ctx, stmt.Name, stationName, immutability, tenantName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func UpdateStationRetention(stationName string, retentionType string, retentionValue int, retentionPolicy models.StationRetentionPolicy, tenantName string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE stations SET retention_type = $2, retention_value = $3, retention_policy = $4, updated_at = $5
+	WHERE name = $1 AND is_deleted = false AND tenant_name=$6`
+	stmt, err := conn.Conn().Prepare(ctx, "update_station_retention", query)
+	if err != nil {
+		return err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	_, err = conn.Conn().Query(ctx, stmt.Name, stationName, retentionType, retentionValue, retentionPolicy, time.Now(), tenantName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func UpdateStationsOfDeletedUser(userId int, tenantName string) error {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -2418,6 +2691,39 @@ func GetNotDeletedProducersByStationID(stationId int) ([]models.Producer, error)
 	}
 	return producers, nil
 }
+func CountActiveProducersPerStation() (map[int]int, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return map[int]int{}, err
+	}
+	defer conn.Release()
+	query := `SELECT station_id, COUNT(*) FROM producers WHERE is_active = true GROUP BY station_id`
+	stmt, err := conn.Conn().Prepare(ctx, "count_active_producers_per_station", query)
+	if err != nil {
+		return map[int]int{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name)
+	if err != nil {
+		return map[int]int{}, err
+	}
+	defer rows.Close()
+	counts := make(map[int]int)
+	for rows.Next() {
+		var stationId, count int
+		err := rows.Scan(&stationId, &count)
+		if err != nil {
+			return map[int]int{}, err
+		}
+		counts[stationId] = count
+	}
+	if err := rows.Err(); err != nil {
+		return map[int]int{}, err
+	}
+	return counts, nil
+}
+
 func GetAllProducersByStationID(stationId int) ([]models.ExtendedProducer, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -3284,6 +3590,8 @@ func GetSchemaVersionsBySchemaID(id int) ([]models.SchemaVersion, error) {
 			MessageStructName: v.MessageStructName,
 			Descriptor:        string(v.Descriptor),
 			TenantName:        strings.ToLower(v.TenantName),
+			SchemaFiles:       v.SchemaFiles,
+			Entrypoint:        v.Entrypoint,
 		}
 
 		schemaVersions = append(schemaVersions, version)
@@ -3328,6 +3636,8 @@ func GetActiveVersionBySchemaID(id int) (models.SchemaVersion, error) {
 		MessageStructName: schemas[0].MessageStructName,
 		Descriptor:        string(schemas[0].Descriptor),
 		TenantName:        strings.ToLower(schemas[0].TenantName),
+		SchemaFiles:       schemas[0].SchemaFiles,
+		Entrypoint:        schemas[0].Entrypoint,
 	}
 
 	return schemaVersion, nil
@@ -3469,10 +3779,89 @@ func GetSchemaVersionByNumberAndID(version int, schemaId int) (bool, models.Sche
 		MessageStructName: schemas[0].MessageStructName,
 		Descriptor:        string(schemas[0].Descriptor),
 		TenantName:        strings.ToLower(schemas[0].TenantName),
+		SchemaFiles:       schemas[0].SchemaFiles,
+		Entrypoint:        schemas[0].Entrypoint,
+	}
+	return true, schemaVersion, nil
+}
+
+func GetSchemaVersionByID(id int, tenantName string) (bool, models.SchemaVersion, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return false, models.SchemaVersion{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM schema_versions WHERE id=$1 AND tenant_name=$2 LIMIT 1`
+	stmt, err := conn.Conn().Prepare(ctx, "get_schema_version_by_id", query)
+	if err != nil {
+		return false, models.SchemaVersion{}, err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, id, tenantName)
+	if err != nil {
+		return false, models.SchemaVersion{}, err
+	}
+	defer rows.Close()
+	schemas, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.SchemaVersionResponse])
+	if err != nil {
+		return false, models.SchemaVersion{}, err
+	}
+	if len(schemas) == 0 {
+		return false, models.SchemaVersion{}, nil
+	}
+	schemaVersion := models.SchemaVersion{
+		ID:                schemas[0].ID,
+		VersionNumber:     schemas[0].VersionNumber,
+		Active:            schemas[0].Active,
+		CreatedBy:         schemas[0].CreatedBy,
+		CreatedByUsername: schemas[0].CreatedByUsername,
+		CreatedAt:         schemas[0].CreatedAt,
+		SchemaContent:     schemas[0].SchemaContent,
+		SchemaId:          schemas[0].SchemaId,
+		MessageStructName: schemas[0].MessageStructName,
+		Descriptor:        string(schemas[0].Descriptor),
+		TenantName:        strings.ToLower(schemas[0].TenantName),
+		SchemaFiles:       schemas[0].SchemaFiles,
+		Entrypoint:        schemas[0].Entrypoint,
 	}
 	return true, schemaVersion, nil
 }
 
+func GetSchemaByID(id int, tenantName string) (bool, models.Schema, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return false, models.Schema{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM schemas WHERE id = $1 AND tenant_name = $2 LIMIT 1`
+	stmt, err := conn.Conn().Prepare(ctx, "get_schema_by_id", query)
+	if err != nil {
+		return false, models.Schema{}, err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, id, tenantName)
+	if err != nil {
+		return false, models.Schema{}, err
+	}
+	defer rows.Close()
+	schemas, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.Schema])
+	if err != nil {
+		return false, models.Schema{}, err
+	}
+	if len(schemas) == 0 {
+		return false, models.Schema{}, nil
+	}
+	return true, schemas[0], nil
+}
+
 func UpdateSchemaActiveVersion(schemaId int, versionNumber int) error {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -3499,6 +3888,26 @@ func UpdateSchemaActiveVersion(schemaId int, versionNumber int) error {
 	return nil
 }
 
+func UpdateSchemaCompatibilityMode(schemaId int, compatibilityMode string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE schemas SET compatibility_mode = $2 WHERE id = $1`
+	stmt, err := conn.Conn().Prepare(ctx, "update_schema_compatibility_mode", query)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn().Query(ctx, stmt.Name, schemaId, compatibilityMode)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func GetShcemaVersionsCount(schemaId int, tenantName string) (int, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -3606,7 +4015,7 @@ func FindAndDeleteSchema(schemaIds []int) error {
 	return nil
 }
 
-func InsertNewSchema(schemaName string, schemaType string, createdByUsername string, tenantName string) (models.Schema, int64, error) {
+func InsertNewSchema(schemaName string, schemaType string, createdByUsername string, compatibilityMode string, tenantName string) (models.Schema, int64, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
 
@@ -3620,8 +4029,9 @@ func InsertNewSchema(schemaName string, schemaType string, createdByUsername str
 		name, 
 		type,
 		created_by_username,
-		tenant_name) 
-    VALUES($1, $2, $3, $4) RETURNING id`
+		tenant_name,
+		compatibility_mode) 
+    VALUES($1, $2, $3, $4, $5) RETURNING id`
 
 	stmt, err := conn.Conn().Prepare(ctx, "insert_new_schema", query)
 	if err != nil {
@@ -3632,7 +4042,7 @@ func InsertNewSchema(schemaName string, schemaType string, createdByUsername str
 	if tenantName != conf.GlobalAccount {
 		tenantName = strings.ToLower(tenantName)
 	}
-	rows, err := conn.Conn().Query(ctx, stmt.Name, schemaName, schemaType, createdByUsername, tenantName)
+	rows, err := conn.Conn().Query(ctx, stmt.Name, schemaName, schemaType, createdByUsername, tenantName, compatibilityMode)
 	if err != nil {
 		return models.Schema{}, 0, err
 	}
@@ -3667,11 +4077,12 @@ func InsertNewSchema(schemaName string, schemaType string, createdByUsername str
 		Name:              schemaName,
 		Type:              schemaType,
 		CreatedByUsername: createdByUsername,
+		CompatibilityMode: compatibilityMode,
 	}
 	return newSchema, rowsAffected, nil
 }
 
-func InsertNewSchemaVersion(schemaVersionNumber int, userId int, username string, schemaContent string, schemaId int, messageStructName string, descriptor string, active bool, tenantName string) (models.SchemaVersion, int64, error) {
+func InsertNewSchemaVersion(schemaVersionNumber int, userId int, username string, schemaContent string, schemaId int, messageStructName string, descriptor string, active bool, tenantName string, schemaFiles map[string]string, entrypoint string) (models.SchemaVersion, int64, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
 
@@ -3691,8 +4102,10 @@ func InsertNewSchemaVersion(schemaVersionNumber int, userId int, username string
 		schema_id,
 		msg_struct_name,
 		descriptor,
-		tenant_name)
-    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+		tenant_name,
+		schema_files,
+		entrypoint)
+    VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id`
 
 	stmt, err := conn.Conn().Prepare(ctx, "insert_new_schema_version", query)
 	if err != nil {
@@ -3704,7 +4117,10 @@ func InsertNewSchemaVersion(schemaVersionNumber int, userId int, username string
 	if tenantName != conf.GlobalAccount {
 		tenantName = strings.ToLower(tenantName)
 	}
-	rows, err := conn.Conn().Query(ctx, stmt.Name, schemaVersionNumber, active, userId, username, createdAt, schemaContent, schemaId, messageStructName, []byte(descriptor), tenantName)
+	if schemaFiles == nil {
+		schemaFiles = map[string]string{}
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, schemaVersionNumber, active, userId, username, createdAt, schemaContent, schemaId, messageStructName, []byte(descriptor), tenantName, schemaFiles, entrypoint)
 	if err != nil {
 		return models.SchemaVersion{}, 0, err
 	}
@@ -3746,6 +4162,8 @@ func InsertNewSchemaVersion(schemaVersionNumber int, userId int, username string
 		SchemaId:          schemaId,
 		MessageStructName: messageStructName,
 		Descriptor:        descriptor,
+		SchemaFiles:       schemaFiles,
+		Entrypoint:        entrypoint,
 	}
 	return newSchemaVersion, rowsAffected, nil
 }
@@ -5313,9 +5731,10 @@ func StorePoisonMsg(stationId, messageSeq int, cgName string, producerName strin
 			updated_at,
 			message_type,
 			validation_error,
-			tenant_name
+			tenant_name,
+			poisoned_cgs_at
 			) 
-		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id`
 
 		stmt, err := tx.Prepare(ctx, "insert_dls_message", query)
@@ -5326,7 +5745,11 @@ func StorePoisonMsg(stationId, messageSeq int, cgName string, producerName strin
 		if tenantName != conf.GlobalAccount {
 			tenantName = strings.ToLower(tenantName)
 		}
-		rows, err := tx.Query(ctx, stmt.Name, stationId, messageSeq, producerName, poisonedCgs, messageDetails, updatedAt, "poison", "", tenantName)
+		poisonedCgsAt := make(map[string]time.Time, len(poisonedCgs))
+		for _, cgName := range poisonedCgs {
+			poisonedCgsAt[cgName] = updatedAt
+		}
+		rows, err := tx.Query(ctx, stmt.Name, stationId, messageSeq, producerName, poisonedCgs, messageDetails, updatedAt, "poison", "", tenantName, poisonedCgsAt)
 		if err != nil {
 			return 0, err
 		}
@@ -5355,7 +5778,8 @@ func StorePoisonMsg(stationId, messageSeq int, cgName string, producerName strin
 			}
 		}
 	} else { // then update
-		query = `UPDATE dls_messages SET poisoned_cgs = ARRAY_APPEND(poisoned_cgs, $1), updated_at = $4 WHERE station_id=$2 AND message_seq=$3 AND not($1 = ANY(poisoned_cgs)) AND tenant_name=$5 RETURNING id`
+		// every consumer group keeps its own poisoned time, the retry backoff of the other groups is not restarted
+		query = `UPDATE dls_messages SET poisoned_cgs = ARRAY_APPEND(poisoned_cgs, $1), updated_at = $4, poisoned_cgs_at = poisoned_cgs_at || jsonb_build_object($1::text, $4::timestamptz), retry_due_at = $4 WHERE station_id=$2 AND message_seq=$3 AND not($1 = ANY(poisoned_cgs)) AND tenant_name=$5 RETURNING id`
 		stmt, err := tx.Prepare(ctx, "update_poisoned_cgs", query)
 		if err != nil {
 			return 0, err
@@ -5394,6 +5818,46 @@ func StorePoisonMsg(stationId, messageSeq int, cgName string, producerName strin
 	return dlsMsgId, nil
 }
 
+func InsertImportedDlsMsgs(dlsMsgs []models.DlsMessage) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	query := `INSERT INTO dls_messages(
+			station_id,
+			message_seq,
+			poisoned_cgs,
+			message_details,
+			updated_at,
+			message_type,
+			validation_error,
+			tenant_name,
+			producer_name)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	batch := &pgx.Batch{}
+	for _, dlsMsg := range dlsMsgs {
+		tenantName := dlsMsg.TenantName
+		if tenantName != conf.GlobalAccount {
+			tenantName = strings.ToLower(tenantName)
+		}
+		batch.Queue(query, dlsMsg.StationId, dlsMsg.MessageSeq, dlsMsg.PoisonedCgs, dlsMsg.MessageDetails, dlsMsg.UpdatedAt, dlsMsg.MessageType, dlsMsg.ValidationError, tenantName, dlsMsg.ProducerName)
+	}
+
+	br := conn.SendBatch(ctx, batch)
+	defer br.Close()
+	for i := 0; i < len(dlsMsgs); i++ {
+		_, err = br.Exec()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func GetTotalPoisonMsgsPerCg(cgName string, stationId int) (int, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -5534,6 +5998,149 @@ func GetDlsMsgsByStationId(stationId int) ([]models.DlsMessage, error) {
 	return dlsMsgs, nil
 }
 
+func GetDlsMsgsByStationIdAndCg(stationId int, cgName string) ([]models.DlsMessage, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM dls_messages WHERE station_id = $1 AND $2 = ANY(poisoned_cgs) ORDER BY updated_at DESC LIMIT 1000`
+	stmt, err := conn.Conn().Prepare(ctx, "get_dls_msgs_by_station_and_cg", query)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, stationId, cgName)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	defer rows.Close()
+	dlsMsgs, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.DlsMessage])
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	if len(dlsMsgs) == 0 {
+		return []models.DlsMessage{}, nil
+	}
+	return dlsMsgs, nil
+}
+
+// RemoveCgFromStationDlsMsgs removes the consumer group from all the dls messages of the station,
+// messages which are left without any poisoned consumer group are deleted
+func RemoveCgFromStationDlsMsgs(stationId int, cgName string, tenantName string) (int, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Conn().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	query := `UPDATE dls_messages SET poisoned_cgs = ARRAY_REMOVE(poisoned_cgs, $1) WHERE station_id = $2 AND tenant_name = $3 AND $1 = ANY(poisoned_cgs) RETURNING id, COALESCE(ARRAY_LENGTH(poisoned_cgs, 1), 0)`
+	rows, err := tx.Query(ctx, query, cgName, stationId, tenantName)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	emptyIds := []int{}
+	for rows.Next() {
+		var id, cgsLeft int
+		err = rows.Scan(&id, &cgsLeft)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		removed++
+		if cgsLeft == 0 {
+			emptyIds = append(emptyIds, id)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(emptyIds) > 0 {
+		_, err = tx.Exec(ctx, `DELETE FROM dls_messages WHERE id = ANY($1)`, emptyIds)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// RemoveCgFromDlsMsgs removes the consumer group from the given dls messages and deletes the ones left without consumer groups
+func RemoveCgFromDlsMsgs(messageIds []int, cgName string, tenantName string) (int, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Conn().Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	query := `UPDATE dls_messages SET poisoned_cgs = ARRAY_REMOVE(poisoned_cgs, $1) WHERE id = ANY($2) AND tenant_name = $3 AND $1 = ANY(poisoned_cgs) RETURNING id, COALESCE(ARRAY_LENGTH(poisoned_cgs, 1), 0)`
+	rows, err := tx.Query(ctx, query, cgName, messageIds, tenantName)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	emptyIds := []int{}
+	for rows.Next() {
+		var id, cgsLeft int
+		err = rows.Scan(&id, &cgsLeft)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		removed++
+		if cgsLeft == 0 {
+			emptyIds = append(emptyIds, id)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(emptyIds) > 0 {
+		_, err = tx.Exec(ctx, `DELETE FROM dls_messages WHERE id = ANY($1)`, emptyIds)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
 func GetDlsMessageById(messageId int) (bool, models.DlsMessage, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -5587,6 +6194,56 @@ func GetTotalDlsMessages(tenantName string) (uint64, error) {
 	return count, nil
 }
 
+func CountDlsMsgsByStationIdSince(stationId int, since time.Time) (int64, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+	query := `SELECT COUNT(*) FROM dls_messages WHERE station_id = $1 AND updated_at > $2`
+	stmt, err := conn.Conn().Prepare(ctx, "count_dls_msgs_by_station_id_since", query)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = conn.Conn().QueryRow(ctx, stmt.Name, stationId, since).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func GetDlsMsgsCountPerStation() ([]models.StationDlsMsgsCount, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.StationDlsMsgsCount{}, err
+	}
+	defer conn.Release()
+	query := `SELECT s.tenant_name, s.name, d.message_type, COUNT(*)
+		FROM dls_messages AS d
+		INNER JOIN stations AS s ON s.id = d.station_id
+		WHERE s.is_deleted = false
+		GROUP BY s.tenant_name, s.name, d.message_type`
+	stmt, err := conn.Conn().Prepare(ctx, "get_dls_msgs_count_per_station", query)
+	if err != nil {
+		return []models.StationDlsMsgsCount{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name)
+	if err != nil {
+		return []models.StationDlsMsgsCount{}, err
+	}
+	defer rows.Close()
+	counts, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.StationDlsMsgsCount])
+	if err != nil {
+		return []models.StationDlsMsgsCount{}, err
+	}
+	return counts, nil
+}
+
 func GetStationIdsFromDlsMsgs(tenantName string) (map[int]string, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -5723,6 +6380,88 @@ func GetDlsMsgsBatch(tenantName string, min, max, stationId int) (bool, []models
 	return true, dlsMsgs, nil
 }
 
+const dlsMsgsFilterConditions = `tenant_name = $1 AND station_id = $2
+		AND ($3 = '' OR message_type = $3)
+		AND ($4::TIMESTAMPTZ IS NULL OR updated_at >= $4)
+		AND ($5::TIMESTAMPTZ IS NULL OR updated_at <= $5)
+		AND ($6 = '' OR $6 = ANY(poisoned_cgs))
+		AND ($7 = '' OR producer_name = $7)
+		AND ($8 = '' OR STRPOS(LOWER(validation_error), LOWER($8)) > 0)
+		AND (message_details::JSONB -> 'headers') @> $9::JSONB`
+
+func getDlsMsgsFilterArgs(tenantName string, stationId int, filter models.DlsMsgsFilter) ([]interface{}, error) {
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	headers := filter.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headersJson, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{tenantName, stationId, filter.MessageType, filter.From, filter.To, filter.CgName, filter.ProducerName, filter.ValidationError, string(headersJson)}, nil
+}
+
+func CountFilteredDlsMsgs(tenantName string, stationId int, filter models.DlsMsgsFilter) (int, int, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Release()
+	query := `SELECT COUNT(*), COALESCE(MAX(id), 0) FROM dls_messages WHERE ` + dlsMsgsFilterConditions
+	stmt, err := conn.Conn().Prepare(ctx, "count_filtered_dls_msgs", query)
+	if err != nil {
+		return 0, 0, err
+	}
+	args, err := getDlsMsgsFilterArgs(tenantName, stationId, filter)
+	if err != nil {
+		return 0, 0, err
+	}
+	var count, maxId int
+	err = conn.Conn().QueryRow(ctx, stmt.Name, args...).Scan(&count, &maxId)
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, maxId, nil
+}
+
+func GetFilteredDlsMsgsBatch(tenantName string, stationId int, filter models.DlsMsgsFilter, min, max int) ([]models.DlsMessage, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM dls_messages WHERE ` + dlsMsgsFilterConditions + ` AND id > $10 AND id <= $11 ORDER BY id ASC LIMIT 50`
+	stmt, err := conn.Conn().Prepare(ctx, "get_filtered_dls_msgs_batch", query)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	args, err := getDlsMsgsFilterArgs(tenantName, stationId, filter)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	args = append(args, min, max)
+	rows, err := conn.Conn().Query(ctx, stmt.Name, args...)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	defer rows.Close()
+	dlsMsgs, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.DlsMessage])
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	if len(dlsMsgs) == 0 {
+		return []models.DlsMessage{}, nil
+	}
+	return dlsMsgs, nil
+}
+
 // Tenants functions
 func UpsertTenant(name string, encryptrdInternalWSPass string) (models.Tenant, error) {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
@@ -6435,6 +7174,44 @@ func GetAsyncTaskByNameAndBrokerName(task, brokerName string) (bool, []models.As
 	return true, asyncTask, nil
 }
 
+// InsertAsyncTaskIfNotExists creates the task only when the station has no task with the same name,
+// it returns false when another task is already running
+func InsertAsyncTaskIfNotExists(task, brokerInCharge string, createdAt time.Time, tenantName string, stationId int) (bool, models.AsyncTask, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return false, models.AsyncTask{}, err
+	}
+	defer conn.Release()
+
+	query := `INSERT INTO async_tasks (name, broker_in_charge, created_at, updated_at, tenant_name, station_id) VALUES($1, $2, $3, $4, $5, $6) ON CONFLICT (name, tenant_name, station_id) DO NOTHING RETURNING *`
+	stmt, err := conn.Conn().Prepare(ctx, "insert_async_task_if_not_exists", query)
+	if err != nil {
+		return false, models.AsyncTask{}, err
+	}
+
+	var asyncTask models.AsyncTask
+	err = conn.Conn().QueryRow(ctx, stmt.Name, task, brokerInCharge, createdAt, createdAt, tenantName, stationId).Scan(
+		&asyncTask.ID,
+		&asyncTask.Name,
+		&asyncTask.BrokrInCharge,
+		&asyncTask.CreatedAt,
+		&asyncTask.UpdatedAt,
+		&asyncTask.Data,
+		&asyncTask.TenantName,
+		&asyncTask.StationId,
+	)
+	if err == pgx.ErrNoRows {
+		return false, models.AsyncTask{}, nil
+	}
+	if err != nil {
+		return false, models.AsyncTask{}, err
+	}
+	return true, asyncTask, nil
+}
+
 func UpdateAsyncTask(task, tenantName string, updatedAt time.Time, metaData interface{}, stationId int) error {
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -6478,7 +7255,96 @@ func RemoveAsyncTask(task, tenantName string, stationId int) error {
 	return nil
 }
 
-func RemoveAllAsyncTasks(duration time.Duration) ([]int, error) {
+func GetAsyncTaskByNameAndStationId(task, tenantName string, stationId int) (bool, models.AsyncTask, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return false, models.AsyncTask{}, err
+	}
+	defer conn.Release()
+
+	query := `SELECT * FROM async_tasks WHERE name = $1 AND tenant_name = $2 AND station_id = $3 LIMIT 1`
+	stmt, err := conn.Conn().Prepare(ctx, "get_async_task_by_name_and_station_id", query)
+	if err != nil {
+		return false, models.AsyncTask{}, err
+	}
+
+	rows, err := conn.Conn().Query(ctx, stmt.Name, task, tenantName, stationId)
+	if err != nil {
+		return false, models.AsyncTask{}, err
+	}
+	defer rows.Close()
+	asyncTasks, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AsyncTask])
+	if err != nil {
+		return false, models.AsyncTask{}, err
+	}
+	if len(asyncTasks) == 0 {
+		return false, models.AsyncTask{}, nil
+	}
+	return true, asyncTasks[0], nil
+}
+
+func GetAsyncTasksByStationId(stationId int, tenantName string) ([]models.AsyncTask, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+	defer conn.Release()
+
+	query := `SELECT * FROM async_tasks WHERE station_id = $1 AND tenant_name = $2 ORDER BY created_at`
+	stmt, err := conn.Conn().Prepare(ctx, "get_async_tasks_by_station_id", query)
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+
+	rows, err := conn.Conn().Query(ctx, stmt.Name, stationId, tenantName)
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+	defer rows.Close()
+	asyncTasks, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AsyncTask])
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+	if len(asyncTasks) == 0 {
+		return []models.AsyncTask{}, nil
+	}
+	return asyncTasks, nil
+}
+
+// ClaimInactiveAsyncTasksByNames moves the tasks which made no progress for the given duration to the broker so it can resume them
+func ClaimInactiveAsyncTasksByNames(names []string, duration time.Duration, brokerName string) ([]models.AsyncTask, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+	defer conn.Release()
+	query := `UPDATE async_tasks SET broker_in_charge = $1, updated_at = $2 WHERE name = ANY($3) AND updated_at <= $4 RETURNING *`
+	stmt, err := conn.Conn().Prepare(ctx, "claim_inactive_async_tasks_by_names", query)
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+	now := time.Now()
+	rows, err := conn.Conn().Query(ctx, stmt.Name, brokerName, now, names, now.Add(-duration))
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+	defer rows.Close()
+	asyncTasks, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AsyncTask])
+	if err != nil {
+		return []models.AsyncTask{}, err
+	}
+	return asyncTasks, nil
+}
+
+func RemoveAllAsyncTasks(duration time.Duration) ([]int, error) {
 	sub := time.Now().Add(-duration)
 	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
 	defer cancelfunc()
@@ -6514,3 +7380,485 @@ func RemoveAllAsyncTasks(duration time.Duration) ([]int, error) {
 	}
 	return stationIds, nil
 }
+
+// Alert Rules Functions
+func InsertNewAlertRule(rule models.AlertRule) (models.AlertRule, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	defer conn.Release()
+	query := `INSERT INTO alert_rules (
+		name,
+		tenant_name,
+		station_name,
+		tag_name,
+		rule_type,
+		threshold,
+		window_seconds,
+		severity,
+		dedup_window_seconds,
+		cooldown_seconds,
+		is_enabled,
+		created_by,
+		created_at,
+		updated_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13) RETURNING id`
+	stmt, err := conn.Conn().Prepare(ctx, "insert_new_alert_rule", query)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if rule.TenantName != conf.GlobalAccount {
+		rule.TenantName = strings.ToLower(rule.TenantName)
+	}
+	createdAt := time.Now()
+	err = conn.Conn().QueryRow(ctx, stmt.Name, rule.Name, rule.TenantName, rule.StationName, rule.TagName, rule.RuleType, rule.Threshold, rule.WindowSeconds, rule.Severity, rule.DedupWindowSeconds, rule.CooldownSeconds, rule.IsEnabled, rule.CreatedBy, createdAt).Scan(&rule.ID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && strings.Contains(pgErr.Detail, "already exists") {
+			return models.AlertRule{}, errors.New("alert rule " + rule.Name + " already exists")
+		}
+		return models.AlertRule{}, err
+	}
+	rule.CreatedAt = createdAt
+	rule.UpdatedAt = createdAt
+	return rule, nil
+}
+
+func UpdateAlertRule(rule models.AlertRule) (models.AlertRule, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	defer conn.Release()
+	query := `UPDATE alert_rules SET
+		station_name = $3,
+		tag_name = $4,
+		rule_type = $5,
+		threshold = $6,
+		window_seconds = $7,
+		severity = $8,
+		dedup_window_seconds = $9,
+		cooldown_seconds = $10,
+		is_enabled = $11,
+		updated_at = $12
+	WHERE name = $1 AND tenant_name = $2 RETURNING *`
+	stmt, err := conn.Conn().Prepare(ctx, "update_alert_rule", query)
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if rule.TenantName != conf.GlobalAccount {
+		rule.TenantName = strings.ToLower(rule.TenantName)
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, rule.Name, rule.TenantName, rule.StationName, rule.TagName, rule.RuleType, rule.Threshold, rule.WindowSeconds, rule.Severity, rule.DedupWindowSeconds, rule.CooldownSeconds, rule.IsEnabled, time.Now())
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	defer rows.Close()
+	rules, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AlertRule])
+	if err != nil {
+		return models.AlertRule{}, err
+	}
+	if len(rules) == 0 {
+		return models.AlertRule{}, errors.New("alert rule " + rule.Name + " does not exist")
+	}
+	return rules[0], nil
+}
+
+func GetAlertRuleByName(name string, tenantName string) (bool, models.AlertRule, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return false, models.AlertRule{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM alert_rules WHERE name = $1 AND tenant_name = $2 LIMIT 1`
+	stmt, err := conn.Conn().Prepare(ctx, "get_alert_rule_by_name", query)
+	if err != nil {
+		return false, models.AlertRule{}, err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, name, tenantName)
+	if err != nil {
+		return false, models.AlertRule{}, err
+	}
+	defer rows.Close()
+	rules, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AlertRule])
+	if err != nil {
+		return false, models.AlertRule{}, err
+	}
+	if len(rules) == 0 {
+		return false, models.AlertRule{}, nil
+	}
+	return true, rules[0], nil
+}
+
+func GetAllAlertRulesByTenant(tenantName string) ([]models.AlertRule, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM alert_rules WHERE tenant_name = $1 ORDER BY name`
+	stmt, err := conn.Conn().Prepare(ctx, "get_all_alert_rules_by_tenant", query)
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, tenantName)
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	defer rows.Close()
+	rules, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AlertRule])
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	if len(rules) == 0 {
+		return []models.AlertRule{}, nil
+	}
+	return rules, nil
+}
+
+func GetAllEnabledAlertRules() ([]models.AlertRule, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM alert_rules WHERE is_enabled = true`
+	stmt, err := conn.Conn().Prepare(ctx, "get_all_enabled_alert_rules", query)
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name)
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	defer rows.Close()
+	rules, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.AlertRule])
+	if err != nil {
+		return []models.AlertRule{}, err
+	}
+	if len(rules) == 0 {
+		return []models.AlertRule{}, nil
+	}
+	return rules, nil
+}
+
+func DeleteAlertRule(name string, tenantName string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `DELETE FROM alert_rules WHERE name = $1 AND tenant_name = $2`
+	stmt, err := conn.Conn().Prepare(ctx, "delete_alert_rule", query)
+	if err != nil {
+		return err
+	}
+	if tenantName != conf.GlobalAccount {
+		tenantName = strings.ToLower(tenantName)
+	}
+	_, err = conn.Conn().Exec(ctx, stmt.Name, name, tenantName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func RemoveAlertRulesByTenant(tenantName string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `DELETE FROM alert_rules WHERE tenant_name = $1`
+	stmt, err := conn.Conn().Prepare(ctx, "remove_alert_rules_by_tenant", query)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn().Exec(ctx, stmt.Name, tenantName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DLS Retry Policies Functions
+func UpsertDlsRetryPolicy(policy models.DlsRetryPolicy) (models.DlsRetryPolicy, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return models.DlsRetryPolicy{}, err
+	}
+	defer conn.Release()
+	query := `INSERT INTO dls_retry_policies (
+		station_id,
+		cg_name,
+		max_attempts,
+		initial_backoff_ms,
+		backoff_multiplier,
+		max_backoff_ms,
+		max_age_seconds,
+		tenant_name,
+		updated_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (station_id, cg_name) DO UPDATE SET
+		max_attempts = EXCLUDED.max_attempts,
+		initial_backoff_ms = EXCLUDED.initial_backoff_ms,
+		backoff_multiplier = EXCLUDED.backoff_multiplier,
+		max_backoff_ms = EXCLUDED.max_backoff_ms,
+		max_age_seconds = EXCLUDED.max_age_seconds,
+		updated_at = EXCLUDED.updated_at
+	RETURNING *`
+	stmt, err := conn.Conn().Prepare(ctx, "upsert_dls_retry_policy", query)
+	if err != nil {
+		return models.DlsRetryPolicy{}, err
+	}
+	if policy.TenantName != conf.GlobalAccount {
+		policy.TenantName = strings.ToLower(policy.TenantName)
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, policy.StationId, policy.CgName, policy.MaxAttempts, policy.InitialBackoffMs, policy.BackoffMultiplier, policy.MaxBackoffMs, policy.MaxAgeSeconds, policy.TenantName, time.Now())
+	if err != nil {
+		return models.DlsRetryPolicy{}, err
+	}
+	defer rows.Close()
+	policies, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.DlsRetryPolicy])
+	if err != nil {
+		return models.DlsRetryPolicy{}, err
+	}
+	if len(policies) == 0 {
+		return models.DlsRetryPolicy{}, errors.New("failed to upsert dls retry policy")
+	}
+	return policies[0], nil
+}
+
+func GetDlsRetryPoliciesByStationId(stationId int) ([]models.DlsRetryPolicy, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM dls_retry_policies WHERE station_id = $1 ORDER BY cg_name`
+	stmt, err := conn.Conn().Prepare(ctx, "get_dls_retry_policies_by_station_id", query)
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, stationId)
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	defer rows.Close()
+	policies, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.DlsRetryPolicy])
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	if len(policies) == 0 {
+		return []models.DlsRetryPolicy{}, nil
+	}
+	return policies, nil
+}
+
+func GetAllDlsRetryPolicies() ([]models.DlsRetryPolicy, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM dls_retry_policies`
+	stmt, err := conn.Conn().Prepare(ctx, "get_all_dls_retry_policies", query)
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name)
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	defer rows.Close()
+	policies, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.DlsRetryPolicy])
+	if err != nil {
+		return []models.DlsRetryPolicy{}, err
+	}
+	if len(policies) == 0 {
+		return []models.DlsRetryPolicy{}, nil
+	}
+	return policies, nil
+}
+
+func DeleteDlsRetryPolicy(stationId int, cgName string) (bool, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Release()
+	query := `DELETE FROM dls_retry_policies WHERE station_id = $1 AND cg_name = $2`
+	stmt, err := conn.Conn().Prepare(ctx, "delete_dls_retry_policy", query)
+	if err != nil {
+		return false, err
+	}
+	tag, err := conn.Conn().Exec(ctx, stmt.Name, stationId, cgName)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func DeleteDlsRetryPoliciesByStationId(stationId int) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `DELETE FROM dls_retry_policies WHERE station_id = $1`
+	stmt, err := conn.Conn().Prepare(ctx, "delete_dls_retry_policies_by_station_id", query)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn().Exec(ctx, stmt.Name, stationId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func RemoveDlsRetryPoliciesByTenant(tenantName string) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `DELETE FROM dls_retry_policies WHERE tenant_name = $1`
+	stmt, err := conn.Conn().Prepare(ctx, "remove_dls_retry_policies_by_tenant", query)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn().Exec(ctx, stmt.Name, tenantName)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetDueDlsMsgsByStationId returns the poison messages of the station which are due for evaluating their retry policies
+func GetDueDlsMsgsByStationId(stationId int, now time.Time) ([]models.DlsMessage, error) {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	defer conn.Release()
+	query := `SELECT * FROM dls_messages WHERE station_id = $1 AND message_type = 'poison' AND retry_due_at <= $2 ORDER BY retry_due_at ASC LIMIT 1000`
+	stmt, err := conn.Conn().Prepare(ctx, "get_due_dls_msgs_by_station_id", query)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	rows, err := conn.Conn().Query(ctx, stmt.Name, stationId, now)
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	defer rows.Close()
+	dlsMsgs, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.DlsMessage])
+	if err != nil {
+		return []models.DlsMessage{}, err
+	}
+	if len(dlsMsgs) == 0 {
+		return []models.DlsMessage{}, nil
+	}
+	return dlsMsgs, nil
+}
+
+// UpdateDlsMsgRetryDueAt sets when the message is evaluated again, nil means it has nothing left to retry
+func UpdateDlsMsgRetryDueAt(msgId int, retryDueAt *time.Time) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE dls_messages SET retry_due_at = $2 WHERE id = $1`
+	stmt, err := conn.Conn().Prepare(ctx, "update_dls_msg_retry_due_at", query)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn().Exec(ctx, stmt.Name, msgId, retryDueAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ResetDlsMsgsRetryDueAtByStationId makes all the poison messages of the station due so a changed policy is evaluated against them
+func ResetDlsMsgsRetryDueAtByStationId(stationId int) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE dls_messages SET retry_due_at = $2 WHERE station_id = $1 AND message_type = 'poison'`
+	stmt, err := conn.Conn().Prepare(ctx, "reset_dls_msgs_retry_due_at_by_station_id", query)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn().Exec(ctx, stmt.Name, stationId, time.Now())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func AppendDlsMsgRetryAttempt(msgId int, attempt models.DlsRetryAttempt) error {
+	ctx, cancelfunc := context.WithTimeout(context.Background(), DbOperationTimeout*time.Second)
+	defer cancelfunc()
+	conn, err := MetadataDbClient.Client.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	query := `UPDATE dls_messages SET retry_attempts = retry_attempts || $2::jsonb WHERE id = $1`
+	stmt, err := conn.Conn().Prepare(ctx, "append_dls_msg_retry_attempt", query)
+	if err != nil {
+		return err
+	}
+	attemptJson, err := json.Marshal([]models.DlsRetryAttempt{attempt})
+	if err != nil {
+		return err
+	}
+	_, err = conn.Conn().Exec(ctx, stmt.Name, msgId, string(attemptJson))
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -30,6 +30,8 @@ func InitializeHttpServer(s *server.Server) {
 		Integrations:   server.IntegrationsHandler{S: s},
 		Tenants:        server.TenantHandler{S: s},
 		Billing:        server.BillingHandler{S: s},
+		AlertRules:     server.AlertRulesHandler{S: s},
+		SchemaRegistry: server.SchemaRegistryHandler{S: s},
 	}
 
 	httpServer := routes.InitializeHttpRoutes(&handlers)
@@ -12,6 +12,7 @@
 package routes
 
 import (
+	"memphis/conf"
 	"memphis/middlewares"
 	"memphis/server"
 	ui "memphis/ui_static_files"
@@ -35,8 +36,12 @@ func InitializeHttpRoutes(handlers *server.Handlers) *gin.Engine {
 	InitializeSchemasRoutes(mainRouter, handlers)
 	InitializeIntegrationsRoutes(mainRouter, handlers)
 	InitializeConfigurationsRoutes(mainRouter, handlers)
+	InitializeAlertRulesRoutes(mainRouter, handlers)
 	server.InitializeTenantsRoutes(mainRouter, handlers)
 	server.InitializeBillingRoutes(mainRouter, handlers)
+	if conf.GetConfig().SCHEMA_REGISTRY_API {
+		InitializeSchemaRegistryRoutes(router, handlers)
+	}
 	ui.InitializeUIRoutes(router)
 
 	mainRouter.GET("/status", func(c *gin.Context) {
@@ -22,6 +22,7 @@ func InitializeStationsRoutes(router *gin.RouterGroup, h *server.Handlers) {
 	stationsRoutes := router.Group("/stations")
 	stationsRoutes.GET("/getStation", stationsHandler.GetStation)
 	stationsRoutes.GET("/getMessageDetails", stationsHandler.GetMessageDetails)
+	stationsRoutes.POST("/searchMessages", stationsHandler.SearchMessages)
 	stationsRoutes.GET("/getAllStations", stationsHandler.GetAllStations)
 	stationsRoutes.GET("/getStations", stationsHandler.GetStations)
 	stationsRoutes.GET("/getPoisonMessageJourney", stationsHandler.GetPoisonMessageJourney)
@@ -33,7 +34,19 @@ func InitializeStationsRoutes(router *gin.RouterGroup, h *server.Handlers) {
 	stationsRoutes.GET("/getUpdatesForSchemaByStation", stationsHandler.GetUpdatesForSchemaByStation)
 	stationsRoutes.GET("/tierdStorageClicked", stationsHandler.TierdStorageClicked) // TODO to be deleted
 	stationsRoutes.PUT("/updateDlsConfig", stationsHandler.UpdateDlsConfig)
+	stationsRoutes.PUT("/updateSchemaEnforcement", stationsHandler.UpdateSchemaEnforcement)
+	stationsRoutes.PUT("/updateStation", stationsHandler.UpdateStation)
+	stationsRoutes.PUT("/updateImmutability", stationsHandler.UpdateStationImmutability)
+	stationsRoutes.PUT("/setDlsRetryPolicy", stationsHandler.SetDlsRetryPolicy)
+	stationsRoutes.DELETE("/removeDlsRetryPolicy", stationsHandler.RemoveDlsRetryPolicy)
+	stationsRoutes.GET("/getDlsRetryPolicies", stationsHandler.GetDlsRetryPolicies)
 	stationsRoutes.POST("/dropDlsMessages", stationsHandler.DropDlsMessages)
+	stationsRoutes.POST("/dlsBulkOperation", stationsHandler.DlsBulkOperation)
+	stationsRoutes.GET("/exportDlsMessages", stationsHandler.ExportDlsMessages)
+	stationsRoutes.POST("/importDlsMessages", stationsHandler.ImportDlsMessages)
+	stationsRoutes.GET("/getCgDlsMessages", stationsHandler.GetCgDlsMessages)
+	stationsRoutes.DELETE("/purgeCgDlsMessages", stationsHandler.PurgeCgDlsMessages)
 	stationsRoutes.DELETE("/purgeStation", stationsHandler.PurgeStation)
 	stationsRoutes.DELETE("/removeMessages", stationsHandler.RemoveMessages)
+	stationsRoutes.POST("/rehydrateTieredStorage", stationsHandler.RehydrateTieredStorage)
 }
@@ -135,6 +135,11 @@ func runMemphis(s *server.Server) {
 		s.Errorf("failed setting existing tenants with dls retention opts: %v", err.Error())
 	}
 
+	err = s.RestoreStationSourcesGrants()
+	if err != nil {
+		s.Errorf("Failed restoring station sources grants: " + err.Error())
+	}
+
 	err = s.Force3ReplicationsForExistingStations()
 	if err != nil {
 		s.Errorf("Failed force 3 replications for existing stations: " + err.Error())
@@ -12,18 +12,22 @@
 package middlewares
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 
 	"memphis/conf"
+	"memphis/db"
 	"memphis/memphis_cache"
 	"memphis/models"
 
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var noNeedAuthRoutes = []string{
@@ -157,3 +161,63 @@ func Authenticate(c *gin.Context) {
 	c.Set("user", user)
 	c.Next()
 }
+
+// AuthenticateSchemaRegistry accepts the basic auth credentials schema registry clients are configured with, bearer tokens go through the regular authentication
+const schemaRegistryCredentialsTTL = 5 * time.Minute
+
+type verifiedCredentialsCache struct {
+	sync.Mutex
+	// hash of the username, the stored password hash and the given password -> expiration time
+	credentials map[[sha256.Size]byte]time.Time
+}
+
+var schemaRegistryCredentials = verifiedCredentialsCache{credentials: make(map[[sha256.Size]byte]time.Time)}
+
+// verify compares the password with the stored bcrypt hash once per ttl, registry clients authenticate every request with basic auth,
+// the stored hash is part of the key so a changed password is verified again
+func (vcc *verifiedCredentialsCache) verify(username, hashedPassword, password string, now time.Time) bool {
+	key := sha256.Sum256([]byte(username + "\x00" + hashedPassword + "\x00" + password))
+	vcc.Lock()
+	expiresAt, ok := vcc.credentials[key]
+	vcc.Unlock()
+	if ok && now.Before(expiresAt) {
+		return true
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) != nil {
+		return false
+	}
+
+	vcc.Lock()
+	defer vcc.Unlock()
+	for k, expiresAt := range vcc.credentials {
+		if !now.Before(expiresAt) {
+			delete(vcc.credentials, k)
+		}
+	}
+	vcc.credentials[key] = now.Add(schemaRegistryCredentialsTTL)
+	return true
+}
+
+func AuthenticateSchemaRegistry(c *gin.Context) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		Authenticate(c)
+		return
+	}
+
+	exist, user, err := db.GetUserForLogin(strings.ToLower(username))
+	if err != nil {
+		c.AbortWithStatusJSON(500, gin.H{"error_code": 50001, "message": "Server error"})
+		return
+	}
+	if !exist || !schemaRegistryCredentials.verify(user.Username, user.Password, password, time.Now()) {
+		c.AbortWithStatusJSON(401, gin.H{"error_code": 40101, "message": "Unauthorized"})
+		return
+	}
+	if user.TenantName != conf.GlobalAccount {
+		user.TenantName = strings.ToLower(user.TenantName)
+	}
+
+	c.Set("user", user)
+	c.Next()
+}
@@ -16,3 +16,27 @@ type AsyncTask struct {
 type MetaData struct {
 	Offset int `json:"offset"`
 }
+
+type DlsBulkTaskMetaData struct {
+	Operation string        `json:"operation"`
+	Filter    DlsMsgsFilter `json:"filter"`
+	Username  string        `json:"username"`
+	Offset    int           `json:"offset"`
+	MaxId     int           `json:"max_id"`
+	Total     int           `json:"total"`
+	Processed int           `json:"processed"`
+	Failed    int           `json:"failed"`
+}
+
+type DlsBulkTask struct {
+	ID             int           `json:"id"`
+	BrokerInCharge string        `json:"broker_in_charge"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Operation      string        `json:"operation"`
+	Filter         DlsMsgsFilter `json:"filter"`
+	Username       string        `json:"username"`
+	Total          int           `json:"total"`
+	Processed      int           `json:"processed"`
+	Failed         int           `json:"failed"`
+}
@@ -1,8 +1,11 @@
 package models
 
 type CacheUpdateRequest struct {
-	CacheType  string   `json:"type"`
-	Operation  string   `json:"operation"`
-	Usernames  []string `json:"users"`
-	TenantName string   `json:"tenant_name"`
+	CacheType    string   `json:"type"`
+	Operation    string   `json:"operation"`
+	Usernames    []string `json:"users"`
+	TenantName   string   `json:"tenant_name"`
+	Stations     []string `json:"stations,omitempty"`
+	TargetTenant string   `json:"target_tenant,omitempty"`
+	StationName  string   `json:"station_name,omitempty"`
 }
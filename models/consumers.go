@@ -101,6 +101,29 @@ type DelayedCgResp struct {
 	CGS         []DelayedCg `json:"cgs"`
 }
 
+type CgLag struct {
+	StationName         string     `json:"station_name"`
+	CGName              string     `json:"cg_name"`
+	IsActive            bool       `json:"is_active"`
+	NumPending          uint64     `json:"num_pending"`
+	NumAckPending       int        `json:"num_ack_pending"`
+	NumRedelivered      int        `json:"num_redelivered"`
+	OldestUnackedAgeSec int64      `json:"oldest_unacked_age_sec"`
+	LastDeliveredAt     *time.Time `json:"last_delivered_at"`
+	Tags                []string   `json:"tags"`
+	MaxAckTimeMs        int64      `json:"max_ack_time_ms"`
+	MaxMsgDeliveries    int        `json:"max_msg_deliveries"`
+	TotalLag            uint64     `json:"total_lag"`
+}
+
+type GetCgsLagSchema struct {
+	StationName string `form:"station_name" json:"station_name"`
+	TagName     string `form:"tag_name" json:"tag_name"`
+	SortBy      string `form:"sort_by" json:"sort_by"`
+	Order       string `form:"order" json:"order"`
+	OnlyLagging bool   `form:"only_lagging" json:"only_lagging"`
+}
+
 type LightCG struct {
 	CGName      string `json:"cg_name"`
 	StationName string `json:"station_name"`
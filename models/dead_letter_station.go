@@ -34,10 +34,12 @@ type MsgHeader struct {
 }
 
 type MessagePayload struct {
-	TimeSent time.Time         `json:"time_sent"`
-	Size     int               `json:"size"`
-	Data     string            `json:"data"`
-	Headers  map[string]string `json:"headers"`
+	TimeSent         time.Time                `json:"time_sent"`
+	Size             int                      `json:"size"`
+	Data             string                   `json:"data"`
+	Headers          map[string]string        `json:"headers"`
+	DecodedData      string                   `json:"decoded_data,omitempty"`
+	SchemaValidation *MessageSchemaValidation `json:"schema_validation,omitempty"`
 }
 
 type PoisonedCg struct {
@@ -64,16 +66,26 @@ type SchemaVerseDlsMessageSdk struct {
 }
 
 type DlsMessage struct {
-	ID              int            `json:"id"`
-	StationId       int            `json:"station_id"`
-	MessageSeq      int            `json:"message_seq"`
-	PoisonedCgs     []string       `json:"poisoned_cgs"`
-	MessageDetails  MessagePayload `json:"message_details"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	MessageType     string         `json:"message_type"`
-	ValidationError string         `json:"validation_error"`
-	TenantName      string         `json:"tenant_name"`
-	ProducerName    string         `json:"producer_name"`
+	ID              int                  `json:"id"`
+	StationId       int                  `json:"station_id"`
+	MessageSeq      int                  `json:"message_seq"`
+	PoisonedCgs     []string             `json:"poisoned_cgs"`
+	MessageDetails  MessagePayload       `json:"message_details"`
+	UpdatedAt       time.Time            `json:"updated_at"`
+	MessageType     string               `json:"message_type"`
+	ValidationError string               `json:"validation_error"`
+	TenantName      string               `json:"tenant_name"`
+	ProducerName    string               `json:"producer_name"`
+	RetryAttempts   []DlsRetryAttempt    `json:"retry_attempts"`
+	PoisonedCgsAt   map[string]time.Time `json:"poisoned_cgs_at"`
+	RetryDueAt      *time.Time           `json:"retry_due_at"`
+}
+
+type StationDlsMsgsCount struct {
+	TenantName  string `json:"tenant_name"`
+	StationName string `json:"station_name"`
+	MessageType string `json:"message_type"`
+	Count       int64  `json:"count"`
 }
 
 type DlsMsgResendAll struct {
@@ -91,6 +103,7 @@ type DlsMessageResponse struct {
 	Message         MessagePayload      `json:"message"`
 	UpdatedAt       time.Time           `json:"updated_at"`
 	ValidationError string              `json:"validation_error"`
+	RetryAttempts   []DlsRetryAttempt   `json:"retry_attempts"`
 }
 
 type PmAckMsg struct {
@@ -113,3 +126,114 @@ type LightDlsMessageResponse struct {
 type RetentionIntervalData struct {
 	Updated_at time.Time `json:"updated_at"`
 }
+
+type DlsRetryAttempt struct {
+	CgName      string    `json:"cg_name"`
+	Attempt     int       `json:"attempt"`
+	AttemptedAt time.Time `json:"attempted_at"`
+	Error       string    `json:"error,omitempty"`
+	Exhausted   bool      `json:"exhausted"`
+}
+
+type DlsRetryPolicy struct {
+	ID                int       `json:"id"`
+	StationId         int       `json:"station_id"`
+	CgName            string    `json:"cg_name"`
+	MaxAttempts       int       `json:"max_attempts"`
+	InitialBackoffMs  int64     `json:"initial_backoff_ms"`
+	BackoffMultiplier float64   `json:"backoff_multiplier"`
+	MaxBackoffMs      int64     `json:"max_backoff_ms"`
+	MaxAgeSeconds     int       `json:"max_age_seconds"`
+	TenantName        string    `json:"tenant_name"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type SetDlsRetryPolicySchema struct {
+	StationName       string  `json:"station_name" binding:"required"`
+	CgName            string  `json:"cg_name"`
+	MaxAttempts       int     `json:"max_attempts" binding:"required"`
+	InitialBackoffMs  int64   `json:"initial_backoff_ms" binding:"required"`
+	BackoffMultiplier float64 `json:"backoff_multiplier"`
+	MaxBackoffMs      int64   `json:"max_backoff_ms"`
+	MaxAgeSeconds     int     `json:"max_age_seconds"`
+}
+
+type RemoveDlsRetryPolicySchema struct {
+	StationName string `json:"station_name" binding:"required"`
+	CgName      string `json:"cg_name"`
+}
+
+type GetDlsRetryPoliciesSchema struct {
+	StationName string `form:"station_name" json:"station_name" binding:"required"`
+}
+
+type DlsMsgsFilter struct {
+	MessageType     string            `json:"message_type"`
+	From            *time.Time        `json:"from"`
+	To              *time.Time        `json:"to"`
+	CgName          string            `json:"cg_name"`
+	ProducerName    string            `json:"producer_name"`
+	ValidationError string            `json:"validation_error"`
+	Headers         map[string]string `json:"headers"`
+}
+
+type DlsBulkOperationSchema struct {
+	StationName string        `json:"station_name" binding:"required"`
+	Operation   string        `json:"operation" binding:"required"`
+	Filter      DlsMsgsFilter `json:"filter"`
+	DryRun      bool          `json:"dry_run"`
+}
+
+type DlsBulkOperationResponse struct {
+	Matched int  `json:"matched"`
+	TaskId  int  `json:"task_id"`
+	DryRun  bool `json:"dry_run"`
+}
+
+type DlsExportRecord struct {
+	MessageType     string            `json:"message_type"`
+	MessageSeq      int               `json:"message_seq"`
+	ProducerName    string            `json:"producer_name"`
+	PoisonedCgs     []string          `json:"poisoned_cgs"`
+	ValidationError string            `json:"validation_error"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	TimeSent        time.Time         `json:"time_sent"`
+	Headers         map[string]string `json:"headers"`
+	Data            []byte            `json:"data"`
+}
+
+type ExportDlsMessagesSchema struct {
+	StationName string `form:"station_name" json:"station_name" binding:"required"`
+	Format      string `form:"format" json:"format"`
+	MessageType string `form:"message_type" json:"message_type"`
+}
+
+type ImportDlsMessagesSchema struct {
+	StationName string `form:"station_name" json:"station_name" binding:"required"`
+	Target      string `form:"target" json:"target"`
+	CgName      string `form:"cg_name" json:"cg_name"`
+}
+
+type ImportDlsMessagesResponse struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	TaskId   int `json:"task_id,omitempty"`
+}
+
+type DlsImportTaskMetaData struct {
+	Username string `json:"username"`
+	Imported int    `json:"imported"`
+	Skipped  int    `json:"skipped"`
+	Failed   int    `json:"failed"`
+}
+
+type CgDlsMessagesSchema struct {
+	StationName string `form:"station_name" json:"station_name" binding:"required"`
+	CgName      string `form:"cg_name" json:"cg_name" binding:"required"`
+}
+
+type CgDlsMessagesResponse struct {
+	CgName         string                    `json:"cg_name"`
+	PoisonMessages []LightDlsMessageResponse `json:"poison_messages"`
+	Total          int                       `json:"total"`
+}
@@ -12,6 +12,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/slack-go/slack"
 )
 
@@ -63,3 +65,11 @@ type Notification struct {
 type RequestIntegrationSchema struct {
 	RequestContent string `json:"request_content"`
 }
+
+type WebhookPayload struct {
+	Type       string    `json:"type"`
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	TenantName string    `json:"tenant_name"`
+	Timestamp  time.Time `json:"timestamp"`
+}
@@ -21,42 +21,50 @@ type Schema struct {
 	Type              string `json:"type"`
 	CreatedByUsername string `json:"created_by_username"`
 	TenantName        string `json:"tenant_name"`
+	CompatibilityMode string `json:"compatibility_mode"`
 }
 
 type SchemaVersion struct {
-	ID                int       `json:"id" `
-	VersionNumber     int       `json:"version_number"`
-	Active            bool      `json:"active"`
-	CreatedBy         int       `json:"created_by"`
-	CreatedByUsername string    `json:"created_by_username"`
-	CreatedAt         time.Time `json:"created_at"`
-	SchemaContent     string    `json:"schema_content"`
-	SchemaId          int       `json:"schema_id"`
-	MessageStructName string    `json:"message_struct_name"`
-	Descriptor        string    `json:"descriptor"`
-	TenantName        string    `json:"tenant_name"`
+	ID                int               `json:"id" `
+	VersionNumber     int               `json:"version_number"`
+	Active            bool              `json:"active"`
+	CreatedBy         int               `json:"created_by"`
+	CreatedByUsername string            `json:"created_by_username"`
+	CreatedAt         time.Time         `json:"created_at"`
+	SchemaContent     string            `json:"schema_content"`
+	SchemaId          int               `json:"schema_id"`
+	MessageStructName string            `json:"message_struct_name"`
+	Descriptor        string            `json:"descriptor"`
+	TenantName        string            `json:"tenant_name"`
+	SchemaFiles       map[string]string `json:"schema_files"`
+	Entrypoint        string            `json:"entrypoint"`
 }
 
 type SchemaVersionResponse struct {
-	ID                int       `json:"id" `
-	VersionNumber     int       `json:"version_number"`
-	Active            bool      `json:"active"`
-	CreatedBy         int       `json:"created_by"`
-	CreatedByUsername string    `json:"created_by_username"`
-	CreatedAt         time.Time `json:"created_at"`
-	SchemaContent     string    `json:"schema_content"`
-	SchemaId          int       `json:"schema_id"`
-	MessageStructName string    `json:"message_struct_name"`
-	Descriptor        []byte    `json:"descriptor"`
-	TenantName        string    `json:"tenant_name"`
+	ID                int               `json:"id" `
+	VersionNumber     int               `json:"version_number"`
+	Active            bool              `json:"active"`
+	CreatedBy         int               `json:"created_by"`
+	CreatedByUsername string            `json:"created_by_username"`
+	CreatedAt         time.Time         `json:"created_at"`
+	SchemaContent     string            `json:"schema_content"`
+	SchemaId          int               `json:"schema_id"`
+	MessageStructName string            `json:"message_struct_name"`
+	Descriptor        []byte            `json:"descriptor"`
+	TenantName        string            `json:"tenant_name"`
+	SchemaFiles       map[string]string `json:"schema_files"`
+	Entrypoint        string            `json:"entrypoint"`
 }
 
 type CreateNewSchema struct {
-	Name              string      `json:"name" binding:"required,min=1,max=32"`
-	Type              string      `json:"type"`
-	SchemaContent     string      `json:"schema_content"`
-	Tags              []CreateTag `json:"tags"`
-	MessageStructName string      `json:"message_struct_name"`
+	Name              string            `json:"name" binding:"required,min=1,max=32"`
+	Type              string            `json:"type"`
+	SchemaContent     string            `json:"schema_content"`
+	Tags              []CreateTag       `json:"tags"`
+	MessageStructName string            `json:"message_struct_name"`
+	CompatibilityMode string            `json:"compatibility_mode"`
+	SchemaFiles       map[string]string `json:"schema_files"`
+	Entrypoint        string            `json:"entrypoint"`
 }
 
 type ExtendedSchema struct {
@@ -79,6 +87,7 @@ type ExtendedSchemaDetails struct {
 	UsedStations      []string        `json:"used_stations"`
 	Tags              []CreateTag     `json:"tags"`
 	CreatedByUsername string          `json:"created_by_username"`
+	CompatibilityMode string          `json:"compatibility_mode"`
 }
 
 type ProducerSchemaUpdateType int
@@ -115,9 +124,23 @@ type RemoveSchema struct {
 }
 
 type CreateNewVersion struct {
-	SchemaName        string `json:"schema_name"`
-	SchemaContent     string `json:"schema_content"`
-	MessageStructName string `json:"message_struct_name"`
+	SchemaName        string            `json:"schema_name"`
+	SchemaContent     string            `json:"schema_content"`
+	MessageStructName string            `json:"message_struct_name"`
+	SchemaFiles       map[string]string `json:"schema_files"`
+	Entrypoint        string            `json:"entrypoint"`
+}
+
+type UpdateCompatibilityMode struct {
+	SchemaName        string `json:"schema_name" binding:"required"`
+	CompatibilityMode string `json:"compatibility_mode" binding:"required"`
+}
+
+type SchemaCompatibilityViolation struct {
+	VersionNumber int    `json:"version_number"`
+	Direction     string `json:"direction"`
+	Path          string `json:"path"`
+	Message       string `json:"message"`
 }
 
 type RollBackVersion struct {
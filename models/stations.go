@@ -30,55 +30,96 @@ type MessageResponse struct {
 }
 
 type MessageDetails struct {
-	MessageSeq   int               `json:"message_seq"`
-	ProducedBy   string            `json:"produced_by"`
-	Data         string            `json:"data"`
-	TimeSent     time.Time         `json:"created_at"`
-	ConnectionId string            `json:"connection_id" `
-	Size         int               `json:"size"`
-	Headers      map[string]string `json:"headers"`
+	MessageSeq       int                      `json:"message_seq"`
+	ProducedBy       string                   `json:"produced_by"`
+	Data             string                   `json:"data"`
+	TimeSent         time.Time                `json:"created_at"`
+	ConnectionId     string                   `json:"connection_id" `
+	Size             int                      `json:"size"`
+	Headers          map[string]string        `json:"headers"`
+	DecodedData      string                   `json:"decoded_data,omitempty"`
+	SchemaValidation *MessageSchemaValidation `json:"schema_validation,omitempty"`
+}
+
+type MessageSchemaValidation struct {
+	SchemaName    string `json:"schema_name"`
+	SchemaType    string `json:"schema_type"`
+	VersionNumber int    `json:"version_number"`
+	IsValid       bool   `json:"is_valid"`
+	Error         string `json:"error,omitempty"`
 }
 
 type Station struct {
-	ID                          int       `json:"id"`
-	Name                        string    `json:"name"`
-	RetentionType               string    `json:"retention_type"`
-	RetentionValue              int       `json:"retention_value"`
-	StorageType                 string    `json:"storage_type"`
-	Replicas                    int       `json:"replicas"`
-	CreatedBy                   int       `json:"created_by,omitempty"`
-	CreatedByUsername           string    `json:"created_by_username"`
-	CreatedAt                   time.Time `json:"created_at"`
-	UpdatedAt                   time.Time `json:"updated_at,omitempty"`
-	IsDeleted                   bool      `json:"is_deleted,omitempty"`
-	SchemaName                  string    `json:"schema_name,omitempty"`
-	SchemaVersionNumber         int       `json:"schema_vesrion_number,omitempty"`
-	IdempotencyWindow           int64     `json:"idempotency_window_in_ms,omitempty"`
-	IsNative                    bool      `json:"is_native"`
-	DlsConfigurationPoison      bool      `json:"dls_configuration_poison,omitempty"`
-	DlsConfigurationSchemaverse bool      `json:"dls_configuration_schemaverse,omitempty"`
-	TieredStorageEnabled        bool      `json:"tiered_storage_enabled"`
-	TenantName                  string    `json:"tenant_name"`
-	ResendDisabled              bool      `json:"resend_disabled"`
+	ID                          int                    `json:"id"`
+	Name                        string                 `json:"name"`
+	RetentionType               string                 `json:"retention_type"`
+	RetentionValue              int                    `json:"retention_value"`
+	StorageType                 string                 `json:"storage_type"`
+	Replicas                    int                    `json:"replicas"`
+	CreatedBy                   int                    `json:"created_by,omitempty"`
+	CreatedByUsername           string                 `json:"created_by_username"`
+	CreatedAt                   time.Time              `json:"created_at"`
+	UpdatedAt                   time.Time              `json:"updated_at,omitempty"`
+	IsDeleted                   bool                   `json:"is_deleted,omitempty"`
+	SchemaName                  string                 `json:"schema_name,omitempty"`
+	SchemaVersionNumber         int                    `json:"schema_vesrion_number,omitempty"`
+	IdempotencyWindow           int64                  `json:"idempotency_window_in_ms,omitempty"`
+	IsNative                    bool                   `json:"is_native"`
+	DlsConfigurationPoison      bool                   `json:"dls_configuration_poison,omitempty"`
+	DlsConfigurationSchemaverse bool                   `json:"dls_configuration_schemaverse,omitempty"`
+	TieredStorageEnabled        bool                   `json:"tiered_storage_enabled"`
+	TenantName                  string                 `json:"tenant_name"`
+	ResendDisabled              bool                   `json:"resend_disabled"`
+	SchemaEnforced              bool                   `json:"schema_enforced"`
+	Mirror                      *StationSource         `json:"mirror,omitempty"`
+	Sources                     []StationSource        `json:"sources,omitempty"`
+	Immutability                StationImmutability    `json:"immutability"`
+	RetentionPolicy             StationRetentionPolicy `json:"retention_policy"`
+	DlsStation                  string                 `json:"dls_station"`
+	DlsStationOnly              bool                   `json:"dls_station_only"`
+}
+
+type StationSource struct {
+	StationName string `json:"station_name" binding:"required"`
+	TenantName  string `json:"tenant_name,omitempty"`
+}
+
+type StationRetentionPolicy struct {
+	MaxAgeSeconds         int    `json:"max_age_sec"`
+	MaxMessages           int64  `json:"max_messages"`
+	MaxBytes              int64  `json:"max_bytes"`
+	MaxMessagesPerSubject int64  `json:"max_messages_per_subject"`
+	MaxMessageSize        int32  `json:"max_message_size"`
+	DiscardPolicy         string `json:"discard_policy"`
+}
+
+type StationImmutability struct {
+	DenyDelete bool `json:"deny_delete"`
+	DenyPurge  bool `json:"deny_purge"`
+	LegalHold  bool `json:"legal_hold"`
+	Sealed     bool `json:"sealed"`
 }
 
 type GetStationResponseSchema struct {
-	ID                   int              `json:"id"`
-	Name                 string           `json:"name"`
-	RetentionType        string           `json:"retention_type"`
-	RetentionValue       int              `json:"retention_value"`
-	StorageType          string           `json:"storage_type"`
-	Replicas             int              `json:"replicas"`
-	CreatedBy            int              `json:"created_by"`
-	CreatedByUsername    string           `json:"created_by_username"`
-	CreatedAt            time.Time        `json:"created_at"`
-	LastUpdate           time.Time        `json:"last_update"`
-	IsDeleted            bool             `json:"is_deleted"`
-	Tags                 []CreateTag      `json:"tags"`
-	IdempotencyWindow    int64            `json:"idempotency_window_in_ms" `
-	IsNative             bool             `json:"is_native"`
-	DlsConfiguration     DlsConfiguration `json:"dls_configuration"`
-	TieredStorageEnabled bool             `json:"tiered_storage_enabled"`
+	ID                   int                    `json:"id"`
+	Name                 string                 `json:"name"`
+	RetentionType        string                 `json:"retention_type"`
+	RetentionValue       int                    `json:"retention_value"`
+	StorageType          string                 `json:"storage_type"`
+	Replicas             int                    `json:"replicas"`
+	CreatedBy            int                    `json:"created_by"`
+	CreatedByUsername    string                 `json:"created_by_username"`
+	CreatedAt            time.Time              `json:"created_at"`
+	LastUpdate           time.Time              `json:"last_update"`
+	IsDeleted            bool                   `json:"is_deleted"`
+	Tags                 []CreateTag            `json:"tags"`
+	IdempotencyWindow    int64                  `json:"idempotency_window_in_ms" `
+	IsNative             bool                   `json:"is_native"`
+	DlsConfiguration     DlsConfiguration       `json:"dls_configuration"`
+	TieredStorageEnabled bool                   `json:"tiered_storage_enabled"`
+	SchemaEnforced       bool                   `json:"schema_enforced"`
+	Immutability         StationImmutability    `json:"immutability"`
+	RetentionPolicy      StationRetentionPolicy `json:"retention_policy"`
 }
 
 type ExtendedStation struct {
@@ -108,30 +149,37 @@ type ExtendedStation struct {
 }
 
 type ExtendedStationLight struct {
-	ID                          int         `json:"id"`
-	Name                        string      `json:"name"`
-	RetentionType               string      `json:"retention_type,omitempty"`
-	RetentionValue              int         `json:"retention_value,omitempty"`
-	StorageType                 string      `json:"storage_type,omitempty"`
-	Replicas                    int         `json:"replicas,omitempty"`
-	CreatedBy                   int         `json:"created_by,omitempty"`
-	CreatedByUsername           string      `json:"created_by_username"`
-	CreatedAt                   time.Time   `json:"created_at"`
-	UpdatedAt                   time.Time   `json:"updated_at,omitempty"`
-	IsDeleted                   bool        `json:"is_deleted,omitempty"`
-	TotalMessages               int         `json:"total_messages"`
-	SchemaName                  string      `json:"schema_name,omitempty"`
-	SchemaVersionNumber         int         `json:"schema_vesrion_number,omitempty"`
-	Tags                        []CreateTag `json:"tags,omitempty"`
-	IdempotencyWindow           int64       `json:"idempotency_window_in_ms,omitempty"`
-	IsNative                    bool        `json:"is_native"`
-	DlsConfigurationPoison      bool        `json:"dls_configuration_poison,omitempty"`
-	DlsConfigurationSchemaverse bool        `json:"dls_configuration_schemaverse,omitempty"`
-	HasDlsMsgs                  bool        `json:"has_dls_messages"`
-	Activity                    bool        `json:"activity"`
-	TieredStorageEnabled        bool        `json:"tiered_storage_enabled,omitempty"`
-	TenantName                  string      `json:"tenant_name"`
-	ResendDisabled              bool        `json:"resend_disabled"`
+	ID                          int                    `json:"id"`
+	Name                        string                 `json:"name"`
+	RetentionType               string                 `json:"retention_type,omitempty"`
+	RetentionValue              int                    `json:"retention_value,omitempty"`
+	StorageType                 string                 `json:"storage_type,omitempty"`
+	Replicas                    int                    `json:"replicas,omitempty"`
+	CreatedBy                   int                    `json:"created_by,omitempty"`
+	CreatedByUsername           string                 `json:"created_by_username"`
+	CreatedAt                   time.Time              `json:"created_at"`
+	UpdatedAt                   time.Time              `json:"updated_at,omitempty"`
+	IsDeleted                   bool                   `json:"is_deleted,omitempty"`
+	TotalMessages               int                    `json:"total_messages"`
+	SchemaName                  string                 `json:"schema_name,omitempty"`
+	SchemaVersionNumber         int                    `json:"schema_vesrion_number,omitempty"`
+	Tags                        []CreateTag            `json:"tags,omitempty"`
+	IdempotencyWindow           int64                  `json:"idempotency_window_in_ms,omitempty"`
+	IsNative                    bool                   `json:"is_native"`
+	DlsConfigurationPoison      bool                   `json:"dls_configuration_poison,omitempty"`
+	DlsConfigurationSchemaverse bool                   `json:"dls_configuration_schemaverse,omitempty"`
+	HasDlsMsgs                  bool                   `json:"has_dls_messages"`
+	Activity                    bool                   `json:"activity"`
+	TieredStorageEnabled        bool                   `json:"tiered_storage_enabled,omitempty"`
+	TenantName                  string                 `json:"tenant_name"`
+	ResendDisabled              bool                   `json:"resend_disabled"`
+	SchemaEnforced              bool                   `json:"schema_enforced"`
+	Mirror                      *StationSource         `json:"mirror,omitempty"`
+	Sources                     []StationSource        `json:"sources,omitempty"`
+	Immutability                StationImmutability    `json:"immutability"`
+	RetentionPolicy             StationRetentionPolicy `json:"retention_policy"`
+	DlsStation                  string                 `json:"dls_station"`
+	DlsStationOnly              bool                   `json:"dls_station_only"`
 }
 
 type ActiveProducersConsumersDetails struct {
@@ -154,33 +202,72 @@ type GetStationSchema struct {
 }
 
 type CreateStationSchema struct {
-	Name                 string           `json:"name" binding:"required,min=1,max=128"`
-	RetentionType        string           `json:"retention_type"`
-	RetentionValue       int              `json:"retention_value"`
-	Replicas             int              `json:"replicas"`
-	StorageType          string           `json:"storage_type"`
-	Tags                 []CreateTag      `json:"tags"`
-	SchemaName           string           `json:"schema_name"`
-	IdempotencyWindow    int64            `json:"idempotency_window_in_ms"`
-	DlsConfiguration     DlsConfiguration `json:"dls_configuration"`
-	TieredStorageEnabled bool             `json:"tiered_storage_enabled"`
+	Name                 string                 `json:"name" binding:"required,min=1,max=128"`
+	RetentionType        string                 `json:"retention_type"`
+	RetentionValue       int                    `json:"retention_value"`
+	Replicas             int                    `json:"replicas"`
+	StorageType          string                 `json:"storage_type"`
+	Tags                 []CreateTag            `json:"tags"`
+	SchemaName           string                 `json:"schema_name"`
+	IdempotencyWindow    int64                  `json:"idempotency_window_in_ms"`
+	DlsConfiguration     DlsConfiguration       `json:"dls_configuration"`
+	TieredStorageEnabled bool                   `json:"tiered_storage_enabled"`
+	Mirror               *StationSource         `json:"mirror"`
+	Sources              []StationSource        `json:"sources"`
+	Immutability         StationImmutability    `json:"immutability"`
+	RetentionPolicy      StationRetentionPolicy `json:"retention_policy"`
 }
 
 type DlsConfiguration struct {
-	Poison      bool `json:"poison"`
-	Schemaverse bool `json:"schemaverse"`
+	Poison      bool   `json:"poison"`
+	Schemaverse bool   `json:"schemaverse"`
+	Station     string `json:"station"`
+	StationOnly bool   `json:"station_only"`
 }
 
 type UpdateDlsConfigSchema struct {
 	StationName string `json:"station_name" binding:"required"`
 	Poison      bool   `json:"poison"`
 	Schemaverse bool   `json:"schemaverse"`
+	Station     string `json:"station"`
+	StationOnly bool   `json:"station_only"`
+}
+
+type UpdateSchemaEnforcementSchema struct {
+	StationName    string `json:"station_name" binding:"required"`
+	SchemaEnforced bool   `json:"schema_enforced"`
+}
+
+type UpdateStationSchema struct {
+	StationName     string                       `json:"station_name" binding:"required"`
+	RetentionType   string                       `json:"retention_type"`
+	RetentionValue  int                          `json:"retention_value"`
+	RetentionPolicy UpdateStationRetentionPolicy `json:"retention_policy"`
+}
+
+// UpdateStationRetentionPolicy holds the limits to change, limits which are not supplied keep their current value
+type UpdateStationRetentionPolicy struct {
+	MaxAgeSeconds         *int    `json:"max_age_sec"`
+	MaxMessages           *int64  `json:"max_messages"`
+	MaxBytes              *int64  `json:"max_bytes"`
+	MaxMessagesPerSubject *int64  `json:"max_messages_per_subject"`
+	MaxMessageSize        *int32  `json:"max_message_size"`
+	DiscardPolicy         *string `json:"discard_policy"`
+}
+
+type UpdateStationImmutabilitySchema struct {
+	StationName string `json:"station_name" binding:"required"`
+	DenyDelete  bool   `json:"deny_delete"`
+	DenyPurge   bool   `json:"deny_purge"`
+	LegalHold   bool   `json:"legal_hold"`
+	Seal        bool   `json:"seal"`
 }
 
 type DropDlsMessagesSchema struct {
 	DlsMsgType    string `json:"dls_type" binding:"required"`
 	DlsMessageIds []int  `json:"dls_message_ids" binding:"required"`
 	StationName   string `json:"station_name" binding:"required"`
+	CgName        string `json:"cg_name"`
 }
 
 type PurgeStationSchema struct {
@@ -197,6 +284,7 @@ type RemoveMessagesSchema struct {
 type ResendPoisonMessagesSchema struct {
 	PoisonMessageIds []int  `json:"poison_message_ids" binding:"required"`
 	StationName      string `json:"station_name" binding:"required"`
+	CgName           string `json:"cg_name"`
 }
 
 type RemoveStationSchema struct {
@@ -239,3 +327,40 @@ type StationOverviewSchemaDetails struct {
 type GetUpdatesForSchema struct {
 	StationName string `form:"station_name" json:"station_name" binding:"required"`
 }
+
+type RehydrateTieredStorageSchema struct {
+	StationName       string    `json:"station_name" binding:"required"`
+	TargetStationName string    `json:"target_station_name"`
+	FromTime          time.Time `json:"from_time"`
+	ToTime            time.Time `json:"to_time"`
+	ObjectNames       []string  `json:"object_names"`
+}
+
+type RehydrateTieredStorageResponse struct {
+	TargetStationName   string `json:"target_station_name"`
+	ObjectsCount        int    `json:"objects_count"`
+	MessagesCount       int    `json:"messages_count"`
+	FailedMessagesCount int    `json:"failed_messages_count"`
+}
+
+type SearchMessagesSchema struct {
+	StationName     string    `json:"station_name" binding:"required"`
+	FromTime        time.Time `json:"from_time"`
+	ToTime          time.Time `json:"to_time"`
+	FromSeq         uint64    `json:"from_seq"`
+	ToSeq           uint64    `json:"to_seq"`
+	ProducerName    string    `json:"producer_name"`
+	HeaderKey       string    `json:"header_key"`
+	HeaderValue     string    `json:"header_value"`
+	PayloadContains string    `json:"payload_contains"`
+	JsonPath        string    `json:"json_path"`
+	JsonValue       string    `json:"json_value"`
+	Limit           int       `json:"limit"`
+	Cursor          string    `json:"cursor"`
+}
+
+type SearchMessagesResponse struct {
+	Messages        []MessageDetails `json:"messages"`
+	NextCursor      string           `json:"next_cursor"`
+	ScannedMessages int              `json:"scanned_messages"`
+}
@@ -0,0 +1,411 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	AlertRuleDlsCount     = "dls_count"
+	AlertRuleCgLag        = "cg_lag"
+	AlertRuleNoProducer   = "no_producer"
+	AlertRuleStorageUsage = "storage_usage"
+	AlertRuleAlert        = "alert_rule_alert"
+
+	alertRulesEvaluationInterval = 30 * time.Second
+)
+
+var alertRuleSeverities = []string{"info", "warning", "critical"}
+
+type alertRuleViolation struct {
+	key     string
+	message string
+}
+
+type alertRuleState struct {
+	lastSentAt time.Time
+	sentByKey  map[string]time.Time
+	suppressed int
+}
+
+type alertRulesEngine struct {
+	lock           sync.Mutex
+	rules          []models.AlertRule
+	ruleStations   map[int]map[int]bool
+	states         map[int]*alertRuleState
+	producerSeenAt map[int]time.Time
+	startedAt      time.Time
+}
+
+var alertRules = newAlertRulesEngine()
+
+func newAlertRulesEngine() *alertRulesEngine {
+	return &alertRulesEngine{
+		ruleStations:   make(map[int]map[int]bool),
+		states:         make(map[int]*alertRuleState),
+		producerSeenAt: make(map[int]time.Time),
+		startedAt:      time.Now(),
+	}
+}
+
+func (e *alertRulesEngine) setRules(rules []models.AlertRule, ruleStations map[int]map[int]bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.rules = rules
+	e.ruleStations = ruleStations
+	active := make(map[int]bool, len(rules))
+	for _, rule := range rules {
+		active[rule.ID] = true
+	}
+	for id := range e.states {
+		if !active[id] {
+			delete(e.states, id)
+		}
+	}
+}
+
+func (e *alertRulesEngine) getRules() []models.AlertRule {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.rules
+}
+
+func (e *alertRulesEngine) coversStation(rule models.AlertRule, stationId int) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.ruleCoversStation(rule, stationId)
+}
+
+func (e *alertRulesEngine) ruleCoversStation(rule models.AlertRule, stationId int) bool {
+	stations, ok := e.ruleStations[rule.ID]
+	if !ok {
+		return rule.StationName == "" && rule.TagName == ""
+	}
+	return stations[stationId]
+}
+
+// hasDlsCountRule reports whether poison messages of the station are already aggregated by a rule,
+// in which case the per message notification is skipped to avoid flooding the channels
+func (e *alertRulesEngine) hasDlsCountRule(tenantName string, stationId int) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	for _, rule := range e.rules {
+		if rule.RuleType == AlertRuleDlsCount && rule.TenantName == tenantName && e.ruleCoversStation(rule, stationId) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldFire applies the rule's cooldown (between any two notifications of the rule)
+// and dedup window (between notifications on the same key), returning the number of
+// violations suppressed since the last notification when it fires
+func (e *alertRulesEngine) shouldFire(ruleId int, key string, dedupWindow, cooldown time.Duration, now time.Time) (bool, int) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	state, ok := e.states[ruleId]
+	if !ok {
+		state = &alertRuleState{sentByKey: make(map[string]time.Time)}
+		e.states[ruleId] = state
+	}
+	if !state.lastSentAt.IsZero() && now.Sub(state.lastSentAt) < cooldown {
+		state.suppressed++
+		return false, 0
+	}
+	if sentAt, ok := state.sentByKey[key]; ok && now.Sub(sentAt) < dedupWindow {
+		state.suppressed++
+		return false, 0
+	}
+	for k, sentAt := range state.sentByKey {
+		if now.Sub(sentAt) >= dedupWindow {
+			delete(state.sentByKey, k)
+		}
+	}
+	suppressed := state.suppressed
+	state.suppressed = 0
+	state.lastSentAt = now
+	state.sentByKey[key] = now
+	return true, suppressed
+}
+
+func (e *alertRulesEngine) producerLastSeen(stationId int, active bool, now time.Time) time.Time {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if active {
+		e.producerSeenAt[stationId] = now
+	}
+	if seenAt, ok := e.producerSeenAt[stationId]; ok {
+		return seenAt
+	}
+	return e.startedAt
+}
+
+// alertRuleUsesWindow reports whether rules of the type are evaluated over window_seconds,
+// lag and storage usage rules check the current state only
+func alertRuleUsesWindow(ruleType string) bool {
+	return ruleType == AlertRuleDlsCount || ruleType == AlertRuleNoProducer
+}
+
+func validateAlertRule(rule models.AlertRule) error {
+	switch rule.RuleType {
+	case AlertRuleDlsCount, AlertRuleCgLag:
+		if rule.Threshold < 0 {
+			return fmt.Errorf("threshold of %v rules can not be negative", rule.RuleType)
+		}
+	case AlertRuleNoProducer:
+	case AlertRuleStorageUsage:
+		if rule.Threshold <= 0 || rule.Threshold > 100 {
+			return fmt.Errorf("threshold of %v rules is a percentage between 1 and 100", rule.RuleType)
+		}
+	default:
+		return fmt.Errorf("unsupported rule type %v, supported types are %v, %v, %v and %v", rule.RuleType, AlertRuleDlsCount, AlertRuleCgLag, AlertRuleNoProducer, AlertRuleStorageUsage)
+	}
+	if rule.StationName != "" && rule.TagName != "" {
+		return fmt.Errorf("an alert rule can target either a station or a tag")
+	}
+	if alertRuleUsesWindow(rule.RuleType) && rule.WindowSeconds <= 0 {
+		return fmt.Errorf("window_seconds has to be positive")
+	}
+	if !alertRuleUsesWindow(rule.RuleType) && rule.WindowSeconds != 0 {
+		return fmt.Errorf("window_seconds is not supported by %v rules", rule.RuleType)
+	}
+	if rule.DedupWindowSeconds < 0 || rule.CooldownSeconds < 0 {
+		return fmt.Errorf("dedup_window_seconds and cooldown_seconds can not be negative")
+	}
+	for _, severity := range alertRuleSeverities {
+		if rule.Severity == severity {
+			return nil
+		}
+	}
+	return fmt.Errorf("severity has to be one of %v", strings.Join(alertRuleSeverities, ", "))
+}
+
+func (s *Server) EvaluateAlertRules() {
+	ticker := time.NewTicker(alertRulesEvaluationInterval)
+	for range ticker.C {
+		err := s.refreshAlertRules()
+		if err != nil {
+			s.Errorf("EvaluateAlertRules at refreshAlertRules: %v", err.Error())
+			continue
+		}
+		// every broker keeps the rules for suppressing per message alerts, only the leader evaluates them
+		if s.JetStreamIsClustered() && !s.JetStreamIsLeader() {
+			continue
+		}
+		for _, rule := range alertRules.getRules() {
+			s.evaluateAlertRule(rule)
+		}
+	}
+}
+
+func (s *Server) refreshAlertRules() error {
+	rules, err := db.GetAllEnabledAlertRules()
+	if err != nil {
+		return err
+	}
+	ruleStations := make(map[int]map[int]bool)
+	for _, rule := range rules {
+		if rule.StationName != "" {
+			ruleStations[rule.ID] = make(map[int]bool)
+			exist, station, err := db.GetStationByName(rule.StationName, rule.TenantName)
+			if err != nil {
+				return err
+			}
+			if exist {
+				ruleStations[rule.ID][station.ID] = true
+			}
+		} else if rule.TagName != "" {
+			ruleStations[rule.ID] = make(map[int]bool)
+			exist, tag, err := db.GetTagByName(rule.TagName, rule.TenantName)
+			if err != nil {
+				return err
+			}
+			if exist {
+				for _, stationId := range tag.Stations {
+					ruleStations[rule.ID][stationId] = true
+				}
+			}
+		}
+	}
+	alertRules.setRules(rules, ruleStations)
+	return nil
+}
+
+func (s *Server) getAlertRuleStations(rule models.AlertRule) ([]models.Station, error) {
+	stations, err := db.GetActiveStationsPerTenant(rule.TenantName)
+	if err != nil {
+		return []models.Station{}, err
+	}
+	var ruleStations []models.Station
+	for _, station := range stations {
+		if alertRules.coversStation(rule, station.ID) {
+			ruleStations = append(ruleStations, station)
+		}
+	}
+	return ruleStations, nil
+}
+
+func (s *Server) evaluateAlertRule(rule models.AlertRule) {
+	var violations []alertRuleViolation
+	if rule.RuleType == AlertRuleStorageUsage && rule.StationName == "" && rule.TagName == "" {
+		brokerViolations, err := s.evaluateBrokerStorageRule(rule)
+		if err != nil {
+			s.Errorf("[tenant: %v]evaluateAlertRule at evaluateBrokerStorageRule: rule %v: %v", rule.TenantName, rule.Name, err.Error())
+		}
+		violations = brokerViolations
+	} else {
+		stations, err := s.getAlertRuleStations(rule)
+		if err != nil {
+			s.Errorf("[tenant: %v]evaluateAlertRule at getAlertRuleStations: rule %v: %v", rule.TenantName, rule.Name, err.Error())
+			return
+		}
+		for _, station := range stations {
+			stationViolations, err := s.evaluateAlertRuleOnStation(rule, station)
+			if err != nil {
+				s.Errorf("[tenant: %v]evaluateAlertRule at evaluateAlertRuleOnStation: rule %v, station %v: %v", rule.TenantName, rule.Name, station.Name, err.Error())
+				continue
+			}
+			violations = append(violations, stationViolations...)
+		}
+	}
+
+	now := time.Now()
+	for _, violation := range violations {
+		fire, suppressed := alertRules.shouldFire(rule.ID, violation.key, time.Duration(rule.DedupWindowSeconds)*time.Second, time.Duration(rule.CooldownSeconds)*time.Second, now)
+		if !fire {
+			continue
+		}
+		title := fmt.Sprintf("[%v] Alert rule %v triggered", strings.ToUpper(rule.Severity), rule.Name)
+		message := violation.message
+		if suppressed > 0 {
+			message = fmt.Sprintf("%v\n%v similar alerts were suppressed since the last notification", message, suppressed)
+		}
+		err := sendNotificationToChannels(rule.TenantName, title, message, AlertRuleAlert, true)
+		if err != nil {
+			s.Warnf("[tenant: %v]evaluateAlertRule at sendNotificationToChannels: rule %v: %v", rule.TenantName, rule.Name, err.Error())
+		}
+	}
+}
+
+func (s *Server) evaluateAlertRuleOnStation(rule models.AlertRule, station models.Station) ([]alertRuleViolation, error) {
+	window := time.Duration(rule.WindowSeconds) * time.Second
+	switch rule.RuleType {
+	case AlertRuleDlsCount:
+		count, err := db.CountDlsMsgsByStationIdSince(station.ID, time.Now().Add(-window))
+		if err != nil {
+			return []alertRuleViolation{}, err
+		}
+		if count > rule.Threshold {
+			return []alertRuleViolation{{
+				key:     station.Name,
+				message: fmt.Sprintf("Station %v has %v dead-letter messages in the last %v (threshold %v)", station.Name, count, window, rule.Threshold),
+			}}, nil
+		}
+	case AlertRuleCgLag:
+		stationName, err := StationNameFromStr(station.Name)
+		if err != nil {
+			return []alertRuleViolation{}, err
+		}
+		consumers, err := db.GetAllConsumersByStation(station.ID)
+		if err != nil {
+			return []alertRuleViolation{}, err
+		}
+		var violations []alertRuleViolation
+		checked := make(map[string]bool)
+		for _, consumer := range consumers {
+			if checked[consumer.ConsumersGroup] {
+				continue
+			}
+			checked[consumer.ConsumersGroup] = true
+			cgInfo, err := s.GetCgInfo(station.TenantName, stationName, consumer.ConsumersGroup)
+			if err != nil {
+				// the consumer group might have been removed in the meantime
+				continue
+			}
+			if int64(cgInfo.NumPending) > rule.Threshold {
+				violations = append(violations, alertRuleViolation{
+					key:     station.Name + "/" + consumer.ConsumersGroup,
+					message: fmt.Sprintf("Consumer group %v of station %v has %v unprocessed messages (threshold %v)", consumer.ConsumersGroup, station.Name, cgInfo.NumPending, rule.Threshold),
+				})
+			}
+		}
+		return violations, nil
+	case AlertRuleNoProducer:
+		producers, err := db.GetNotDeletedProducersByStationID(station.ID)
+		if err != nil {
+			return []alertRuleViolation{}, err
+		}
+		active := false
+		for _, producer := range producers {
+			if producer.IsActive {
+				active = true
+				break
+			}
+		}
+		now := time.Now()
+		lastSeen := alertRules.producerLastSeen(station.ID, active, now)
+		if station.CreatedAt.After(lastSeen) {
+			lastSeen = station.CreatedAt
+		}
+		if !active && now.Sub(lastSeen) > window {
+			return []alertRuleViolation{{
+				key:     station.Name,
+				message: fmt.Sprintf("Station %v has had no active producer for more than %v", station.Name, window),
+			}}, nil
+		}
+	case AlertRuleStorageUsage:
+		maxBytes := getStationMaxBytes(station)
+		if maxBytes <= 0 {
+			return []alertRuleViolation{}, nil
+		}
+		stationName, err := StationNameFromStr(station.Name)
+		if err != nil {
+			return []alertRuleViolation{}, err
+		}
+		info, err := s.memphisStreamInfo(station.TenantName, stationName.Intern())
+		if err != nil {
+			return []alertRuleViolation{}, err
+		}
+		percentage := int64(info.State.Bytes * 100 / uint64(maxBytes))
+		if percentage > rule.Threshold {
+			return []alertRuleViolation{{
+				key:     station.Name,
+				message: fmt.Sprintf("Station %v uses %v%% of its storage retention limit (threshold %v%%)", station.Name, percentage, rule.Threshold),
+			}}, nil
+		}
+	}
+	return []alertRuleViolation{}, nil
+}
+
+func (s *Server) evaluateBrokerStorageRule(rule models.AlertRule) ([]alertRuleViolation, error) {
+	v, err := s.Varz(nil)
+	if err != nil {
+		return []alertRuleViolation{}, err
+	}
+	if v.JetStream.Config == nil || v.JetStream.Config.MaxStore <= 0 || v.JetStream.Stats == nil {
+		return []alertRuleViolation{}, nil
+	}
+	percentage := int64(v.JetStream.Stats.Store * 100 / uint64(v.JetStream.Config.MaxStore))
+	if percentage > rule.Threshold {
+		return []alertRuleViolation{{
+			key:     "broker",
+			message: fmt.Sprintf("Broker storage usage is %v%% (threshold %v%%)", percentage, rule.Threshold),
+		}}, nil
+	}
+	return []alertRuleViolation{}, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+	"time"
+)
+
+func TestAlertRulesEngineShouldFire(t *testing.T) {
+	e := newAlertRulesEngine()
+	now := time.Now()
+	dedup, cooldown := 10*time.Minute, time.Minute
+
+	if fire, _ := e.shouldFire(1, "a", dedup, cooldown, now); !fire {
+		t.Fatalf("Expected the first violation to fire")
+	}
+	if fire, _ := e.shouldFire(1, "b", dedup, cooldown, now.Add(30*time.Second)); fire {
+		t.Fatalf("Expected the violation to be suppressed by the cooldown")
+	}
+	if fire, _ := e.shouldFire(1, "a", dedup, cooldown, now.Add(2*time.Minute)); fire {
+		t.Fatalf("Expected the violation to be suppressed by the dedup window")
+	}
+	fire, suppressed := e.shouldFire(1, "b", dedup, cooldown, now.Add(3*time.Minute))
+	if !fire || suppressed != 2 {
+		t.Fatalf("Expected a new key to fire after the cooldown with 2 suppressed, got %v, %v", fire, suppressed)
+	}
+	if fire, _ := e.shouldFire(1, "a", dedup, cooldown, now.Add(11*time.Minute)); !fire {
+		t.Fatalf("Expected the violation to fire after the dedup window")
+	}
+	if fire, _ := e.shouldFire(2, "a", dedup, cooldown, now.Add(11*time.Minute)); !fire {
+		t.Fatalf("Expected rules to be rate limited independently")
+	}
+}
+
+func TestValidateAlertRule(t *testing.T) {
+	for _, rule := range []models.AlertRule{
+		{RuleType: AlertRuleDlsCount, Threshold: 10, WindowSeconds: 300, Severity: "critical"},
+		{RuleType: AlertRuleCgLag, Threshold: 10, Severity: "warning"},
+	} {
+		if err := validateAlertRule(rule); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	invalid := []models.AlertRule{
+		{RuleType: "unknown", WindowSeconds: 300, Severity: "info"},
+		{RuleType: AlertRuleStorageUsage, Threshold: 120, Severity: "info"},
+		{RuleType: AlertRuleStorageUsage, Threshold: 80, WindowSeconds: 300, Severity: "info"},
+		{RuleType: AlertRuleCgLag, Threshold: 10, Severity: "urgent"},
+		{RuleType: AlertRuleNoProducer, WindowSeconds: 0, Severity: "info"},
+		{RuleType: AlertRuleNoProducer, WindowSeconds: 60, Severity: "info", StationName: "s", TagName: "t"},
+	}
+	for _, r := range invalid {
+		if err := validateAlertRule(r); err == nil {
+			t.Fatalf("Expected rule %+v to be invalid", r)
+		}
+	}
+}
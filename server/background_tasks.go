@@ -39,8 +39,8 @@ const CACHE_UDATES_SUBJ = "$memphis_cache_updates"
 
 var LastReadThroughputMap map[string]models.Throughput
 var LastWriteThroughputMap map[string]models.Throughput
-var tieredStorageMsgsMap *concurrentMap[map[string][]StoredMsg]
-var tieredStorageMapLock sync.Mutex
+var throughputLock sync.RWMutex
+var tieredStorageMsgsBuffer *tieredStorageBuffer
 
 func (s *Server) ListenForZombieConnCheckRequests() error {
 	_, err := s.subscribeOnAcc(s.MemphisGlobalAccount(), CONN_STATUS_SUBJ, CONN_STATUS_SUBJ+"_sid", func(_ *client, subject, reply string, msg []byte) {
@@ -90,6 +90,20 @@ func (s *Server) ListenForCacheUpdates() error {
 						return
 					}
 				}
+			case schemaEnforcementCacheType:
+				switch cache_req.Operation {
+				case "delete":
+					stationSchemaEnforcers.invalidate(cache_req.TenantName, cache_req.Stations)
+				case "reload":
+					s.reloadSchemaEnforcers(cache_req.TenantName, cache_req.Stations)
+				}
+			case stationSourcesCacheType:
+				switch cache_req.Operation {
+				case "grant":
+					s.handleStationSourcesGrant(cache_req)
+				case "revoke":
+					s.handleStationSourcesRevoke(cache_req)
+				}
 			}
 
 		}(copyBytes(msg))
@@ -117,6 +131,12 @@ func (s *Server) ListenForIntegrationsUpdateEvents() error {
 				CacheDetails("slack", integrationUpdate.Keys, integrationUpdate.Properties, integrationUpdate.TenantName)
 			case "s3":
 				CacheDetails("s3", integrationUpdate.Keys, integrationUpdate.Properties, integrationUpdate.TenantName)
+			case "local_fs":
+				CacheDetails("local_fs", integrationUpdate.Keys, integrationUpdate.Properties, integrationUpdate.TenantName)
+			case "webhook":
+				CacheDetails("webhook", integrationUpdate.Keys, integrationUpdate.Properties, integrationUpdate.TenantName)
+			case "email":
+				CacheDetails("email", integrationUpdate.Keys, integrationUpdate.Properties, integrationUpdate.TenantName)
 			default:
 				s.Warnf("[tenant: %v] ListenForIntegrationsUpdateEvents: %s %s", integrationUpdate.TenantName, strings.ToLower(integrationUpdate.Name), "unknown integration")
 				return
@@ -225,6 +245,7 @@ func getThroughputSubject(serverName string) string {
 }
 
 func (s *Server) InitializeThroughputSampling() {
+	throughputLock.Lock()
 	LastReadThroughputMap = map[string]models.Throughput{}
 	LastWriteThroughputMap = map[string]models.Throughput{}
 	for _, acc := range s.Opts().Accounts {
@@ -237,6 +258,7 @@ func (s *Server) InitializeThroughputSampling() {
 			BytesPerSec: 0,
 		}
 	}
+	throughputLock.Unlock()
 	go s.CalculateSelfThroughput()
 }
 
@@ -244,6 +266,7 @@ func (s *Server) CalculateSelfThroughput() {
 	for range time.Tick(time.Second * 1) {
 		readMap := map[string]int64{}
 		writeMap := map[string]int64{}
+		throughputLock.Lock()
 		s.accounts.Range(func(_, v interface{}) bool {
 			acc := v.(*Account)
 			accName := acc.GetName()
@@ -261,6 +284,7 @@ func (s *Server) CalculateSelfThroughput() {
 			writeMap[accName] = currentWrite
 			return true
 		})
+		throughputLock.Unlock()
 		serverName := s.opts.ServerName
 		subj := getThroughputSubject(serverName)
 		tpMsg := models.BrokerThroughput{
@@ -310,6 +334,7 @@ func (s *Server) StartBackgroundTasks() error {
 
 	go s.ConsumeSchemaverseDlsMessages()
 	go s.ConsumeUnackedMsgs()
+	tieredStorageMsgsBuffer = newTieredStorageBuffer()
 	go s.ConsumeTieredStorageMsgs()
 	go s.RemoveOldDlsMsgs()
 	go s.uploadMsgsToTier2Storage()
@@ -317,6 +342,10 @@ func (s *Server) StartBackgroundTasks() error {
 	go s.UploadTenantUsageToDB()
 	go s.RefreshFirebaseFunctionsKey()
 	go s.RemoveOldProducersAndConsumers()
+	go s.EvaluateAlertRules()
+	go s.RetryDlsMsgs()
+	go s.CollectMetrics()
+	go s.LoadSchemaEnforcers()
 
 	return nil
 }
@@ -325,20 +354,19 @@ func (s *Server) uploadMsgsToTier2Storage() {
 	currentTimeFrame := s.opts.TieredStorageUploadIntervalSec
 	ticker := time.NewTicker(time.Duration(currentTimeFrame) * time.Second)
 	defer ticker.Stop()
-	for range ticker.C {
+	for {
+		select {
+		case t := <-tieredStorageMsgsBuffer.flushCh:
+			// a batch reached its size limit, no need to wait for the next interval
+			go s.flushTenantToTier2Storage(t)
+			continue
+		case <-ticker.C:
+		}
 		if s.opts.TieredStorageUploadIntervalSec != currentTimeFrame {
 			currentTimeFrame = s.opts.TieredStorageUploadIntervalSec
 			ticker.Reset(time.Duration(currentTimeFrame) * time.Second)
 			// update consumer when TIERED_STORAGE_TIME_FRAME_SEC configuration was changed
-			cc := ConsumerConfig{
-				DeliverPolicy: DeliverAll,
-				AckPolicy:     AckExplicit,
-				Durable:       TIERED_STORAGE_CONSUMER,
-				FilterSubject: tieredStorageStream + ".>",
-				AckWait:       time.Duration(2) * time.Duration(currentTimeFrame) * time.Second,
-				MaxAckPending: -1,
-				MaxDeliver:    10,
-			}
+			cc := tieredStorageConsumerConfig(currentTimeFrame)
 			err := serv.memphisAddConsumer(s.MemphisGlobalAccountString(), tieredStorageStream, &cc)
 			if err != nil {
 				serv.Errorf("Failed add tiered storage consumer: %v", err.Error())
@@ -346,26 +374,9 @@ func (s *Server) uploadMsgsToTier2Storage() {
 			}
 			TIERED_STORAGE_CONSUMER_CREATED = true
 		}
-		tieredStorageMapLock.Lock()
-		err := flushMapToTier2Storage()
-		if err != nil {
-			serv.Errorf("Failed upload messages to tiered 2 storage: %v", err.Error())
-			tieredStorageMapLock.Unlock()
-			continue
-		}
-		// ack all messages uploaded to tiered 2 storage or when there is no s3 integaration to tenant
-		for t, tenant := range tieredStorageMsgsMap.m {
-			for i, msgs := range tenant {
-				for _, msg := range msgs {
-					reply := msg.ReplySubject
-					s.sendInternalAccountMsg(s.MemphisGlobalAccount(), reply, []byte(_EMPTY_))
-				}
-				delete(tenant, i)
-			}
-			tieredStorageMsgsMap.Delete(t)
+		for _, t := range tieredStorageMsgsBuffer.tenantsToFlush() {
+			go s.flushTenantToTier2Storage(t)
 		}
-
-		tieredStorageMapLock.Unlock()
 	}
 }
 
@@ -440,10 +451,14 @@ func (s *Server) ConsumeTieredStorageMsgs() {
 		ReplySubject string
 	}
 
-	tieredStorageMsgsMap = NewConcurrentMap[map[string][]StoredMsg]()
 	amount := 1000
 	req := []byte(strconv.FormatUint(uint64(amount), 10))
 	for {
+		if tieredStorageMsgsBuffer.isFull() {
+			// messages stay in the stream until there is room in the buffer
+			time.Sleep(2 * time.Second)
+			continue
+		}
 		if TIERED_STORAGE_CONSUMER_CREATED && TIERED_STORAGE_STREAM_CREATED {
 			resp := make(chan tsMsg)
 			replySubj := TIERED_STORAGE_CONSUMER + "_reply_" + s.memphis.nuid.Next()
@@ -51,6 +51,8 @@ const (
 	DEFAULT_CLIENTS_TOKEN                      = "memphis"
 	SHOWABLE_ERROR_STATUS_CODE                 = 666
 	DEFAULT_TIERED_STORAGE_UPLOAD_INTERVAL_SEC = 8
+	DEFAULT_TIERED_STORAGE_OBJECT_FORMAT       = "json"
+	DEFAULT_TIERED_STORAGE_LOCAL_FS_BASE_DIR   = "/data/tiered_storage"
 	DEFAULT_DLS_RETENTION_HOURS                = 3
 
 	// COMP_WITH_NATS_VERSION is the NATS version Memphis is compatible with
@@ -2777,7 +2777,7 @@ func (fs *fileStore) removeMsg(seq uint64, secure, viaLimits, needFSLock bool) (
 	// send the message to tiered 2 storage if needed
 	tieredStorageEnabled := fs.cfg.StreamConfig.TieredStorageEnabled
 	if !secure && !strings.HasPrefix(fs.cfg.StreamConfig.Name, "$memphis") && tieredStorageEnabled && serv != nil {
-		err = serv.sendToTier2Storage(fs, copyBytes(sm.buf), sm.seq, "s3")
+		err = serv.sendToTier2Storage(fs, copyBytes(sm.buf), sm.seq, sm.ts)
 		if err != nil {
 			return false, err
 		}
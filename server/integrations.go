@@ -18,7 +18,7 @@ import (
 
 var IntegrationsConcurrentCache *concurrentMap[map[string]interface{}]
 var NotificationFunctionsMap map[string]interface{}
-var StorageFunctionsMap map[string]interface{}
+var StorageFunctionsMap map[string]Tier2Storage
 
 const PoisonMAlert = "poison_message_alert"
 const SchemaVAlert = "schema_validation_fail_alert"
@@ -27,9 +27,12 @@ const DisconEAlert = "disconnection_events_alert"
 func InitializeIntegrations() error {
 	IntegrationsConcurrentCache = NewConcurrentMap[map[string]interface{}]()
 	NotificationFunctionsMap = make(map[string]interface{})
-	StorageFunctionsMap = make(map[string]interface{})
+	StorageFunctionsMap = make(map[string]Tier2Storage)
 	NotificationFunctionsMap["slack"] = sendMessageToSlackChannel
-	StorageFunctionsMap["s3"] = serv.uploadToS3Storage
+	NotificationFunctionsMap["webhook"] = sendMessageToWebhook
+	NotificationFunctionsMap["email"] = sendMessageToEmail
+	StorageFunctionsMap["s3"] = s3Storage{}
+	StorageFunctionsMap["local_fs"] = localFsStorage{}
 
 	err := InitializeConnections()
 	if err != nil {
@@ -60,6 +63,12 @@ func InitializeConnections() error {
 				return err
 			}
 			integration.Keys["auth_token"] = decryptedValue
+		} else if value, ok := integration.Keys["password"]; ok {
+			decryptedValue, err := DecryptAES(key, value)
+			if err != nil {
+				return err
+			}
+			integration.Keys["password"] = decryptedValue
 		}
 		CacheDetails(integration.Name, integration.Keys, integration.Properties, integration.TenantName)
 	}
@@ -72,6 +81,12 @@ func CacheDetails(integrationType string, keys map[string]string, properties map
 		cacheDetailsSlack(keys, properties, tenantName)
 	case "s3":
 		cacheDetailsS3(keys, properties, tenantName)
+	case "local_fs":
+		cacheDetailsLocalFs(keys, properties, tenantName)
+	case "webhook":
+		cacheDetailsWebhook(keys, properties, tenantName)
+	case "email":
+		cacheDetailsEmail(keys, properties, tenantName)
 
 	}
 }
@@ -0,0 +1,314 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"memphis/models"
+	"sort"
+	"time"
+)
+
+const (
+	dlsExportFormatNdjson   = "ndjson"
+	dlsExportFormatArchive  = "archive"
+	dlsArchiveMagic         = "MDLS"
+	dlsArchiveVersion       = 1
+	dlsArchiveMaxRecordSize = 64 * 1024 * 1024
+)
+
+var (
+	errDlsArchiveCorrupted = errors.New("the dls archive is corrupted")
+	errDlsArchiveTooLarge  = errors.New("the dls archive exceeds the maximum uncompressed size")
+)
+
+type dlsRecordWriter interface {
+	write(rec models.DlsExportRecord) error
+	close() error
+}
+
+type dlsRecordReader interface {
+	// next returns io.EOF once all the records have been read
+	next() (models.DlsExportRecord, error)
+}
+
+func newDlsRecordWriter(format string, w io.Writer) (dlsRecordWriter, error) {
+	switch format {
+	case dlsExportFormatNdjson:
+		return &dlsNdjsonWriter{enc: json.NewEncoder(w)}, nil
+	case dlsExportFormatArchive:
+		gz := gzip.NewWriter(w)
+		_, err := gz.Write(append([]byte(dlsArchiveMagic), dlsArchiveVersion))
+		if err != nil {
+			return nil, err
+		}
+		return &dlsArchiveWriter{gz: gz}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %v, supported formats are %v and %v", format, dlsExportFormatNdjson, dlsExportFormatArchive)
+	}
+}
+
+// newDlsRecordReader detects the format by the gzip magic bytes, anything else is read as NDJSON,
+// an archive is not decompressed beyond maxSize bytes
+func newDlsRecordReader(r io.Reader, maxSize int64) (dlsRecordReader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if len(head) < 2 || head[0] != 0x1f || head[1] != 0x8b {
+		return &dlsNdjsonReader{dec: json.NewDecoder(br)}, nil
+	}
+
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	ar := &dlsArchiveReader{r: bufio.NewReader(&dlsLimitedReader{r: gz, remaining: maxSize})}
+	header := make([]byte, len(dlsArchiveMagic)+1)
+	_, err = io.ReadFull(ar.r, header)
+	if errors.Is(err, errDlsArchiveTooLarge) {
+		return nil, err
+	}
+	if err != nil || string(header[:len(dlsArchiveMagic)]) != dlsArchiveMagic {
+		return nil, errDlsArchiveCorrupted
+	}
+	if header[len(dlsArchiveMagic)] != dlsArchiveVersion {
+		return nil, fmt.Errorf("unsupported dls archive version %v", header[len(dlsArchiveMagic)])
+	}
+	return ar, nil
+}
+
+// dlsLimitedReader fails once more than the remaining bytes are read, unlike io.LimitReader
+// which ends the stream silently and would let a truncated archive pass as a complete one
+type dlsLimitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (lr *dlsLimitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[:lr.remaining+1]
+	}
+	n, err := lr.r.Read(p)
+	if int64(n) > lr.remaining {
+		n = int(lr.remaining)
+		lr.remaining = 0
+		return n, errDlsArchiveTooLarge
+	}
+	lr.remaining -= int64(n)
+	return n, err
+}
+
+type dlsNdjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *dlsNdjsonWriter) write(rec models.DlsExportRecord) error {
+	return nw.enc.Encode(rec)
+}
+
+func (nw *dlsNdjsonWriter) close() error {
+	return nil
+}
+
+type dlsNdjsonReader struct {
+	dec *json.Decoder
+}
+
+func (nr *dlsNdjsonReader) next() (models.DlsExportRecord, error) {
+	var rec models.DlsExportRecord
+	if !nr.dec.More() {
+		return rec, io.EOF
+	}
+	err := nr.dec.Decode(&rec)
+	return rec, err
+}
+
+// the archive is a gzip stream of the magic and version followed by length prefixed records
+type dlsArchiveWriter struct {
+	gz  *gzip.Writer
+	buf []byte
+}
+
+func (aw *dlsArchiveWriter) write(rec models.DlsExportRecord) error {
+	aw.buf = encodeDlsArchiveRecord(aw.buf[:0], rec)
+	_, err := aw.gz.Write(binary.AppendUvarint(nil, uint64(len(aw.buf))))
+	if err != nil {
+		return err
+	}
+	_, err = aw.gz.Write(aw.buf)
+	return err
+}
+
+func (aw *dlsArchiveWriter) close() error {
+	return aw.gz.Close()
+}
+
+type dlsArchiveReader struct {
+	r   *bufio.Reader
+	buf []byte
+}
+
+func (ar *dlsArchiveReader) next() (models.DlsExportRecord, error) {
+	size, err := binary.ReadUvarint(ar.r)
+	if err == io.EOF || errors.Is(err, errDlsArchiveTooLarge) {
+		return models.DlsExportRecord{}, err
+	}
+	if err != nil || size > dlsArchiveMaxRecordSize {
+		return models.DlsExportRecord{}, errDlsArchiveCorrupted
+	}
+	if uint64(cap(ar.buf)) < size {
+		ar.buf = make([]byte, size)
+	}
+	ar.buf = ar.buf[:size]
+	_, err = io.ReadFull(ar.r, ar.buf)
+	if errors.Is(err, errDlsArchiveTooLarge) {
+		return models.DlsExportRecord{}, err
+	}
+	if err != nil {
+		return models.DlsExportRecord{}, errDlsArchiveCorrupted
+	}
+	return decodeDlsArchiveRecord(ar.buf)
+}
+
+func appendDlsArchiveBytes(b []byte, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func appendDlsArchiveTime(b []byte, t time.Time) []byte {
+	if t.IsZero() {
+		return binary.AppendVarint(b, 0)
+	}
+	return binary.AppendVarint(b, t.UnixNano())
+}
+
+func encodeDlsArchiveRecord(b []byte, rec models.DlsExportRecord) []byte {
+	b = appendDlsArchiveBytes(b, []byte(rec.MessageType))
+	b = binary.AppendUvarint(b, uint64(rec.MessageSeq))
+	b = appendDlsArchiveBytes(b, []byte(rec.ProducerName))
+	b = appendDlsArchiveBytes(b, []byte(rec.ValidationError))
+	b = appendDlsArchiveTime(b, rec.UpdatedAt)
+	b = appendDlsArchiveTime(b, rec.TimeSent)
+	b = binary.AppendUvarint(b, uint64(len(rec.PoisonedCgs)))
+	for _, cg := range rec.PoisonedCgs {
+		b = appendDlsArchiveBytes(b, []byte(cg))
+	}
+	keys := make([]string, 0, len(rec.Headers))
+	for k := range rec.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b = binary.AppendUvarint(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = appendDlsArchiveBytes(b, []byte(k))
+		b = appendDlsArchiveBytes(b, []byte(rec.Headers[k]))
+	}
+	return appendDlsArchiveBytes(b, rec.Data)
+}
+
+type dlsArchiveDecoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *dlsArchiveDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		d.err = errDlsArchiveCorrupted
+	}
+	return v
+}
+
+func (d *dlsArchiveDecoder) bytes() []byte {
+	size := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if size > uint64(d.r.Len()) {
+		d.err = errDlsArchiveCorrupted
+		return nil
+	}
+	v := make([]byte, size)
+	_, err := io.ReadFull(d.r, v)
+	if err != nil {
+		d.err = errDlsArchiveCorrupted
+	}
+	return v
+}
+
+func (d *dlsArchiveDecoder) time() time.Time {
+	if d.err != nil {
+		return time.Time{}
+	}
+	v, err := binary.ReadVarint(d.r)
+	if err != nil {
+		d.err = errDlsArchiveCorrupted
+		return time.Time{}
+	}
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, v).UTC()
+}
+
+func (d *dlsArchiveDecoder) count() int {
+	v := d.uvarint()
+	// every element takes at least a byte, a larger count can only come from a corrupted record
+	if v > uint64(d.r.Len()) {
+		d.err = errDlsArchiveCorrupted
+		return 0
+	}
+	return int(v)
+}
+
+func decodeDlsArchiveRecord(b []byte) (models.DlsExportRecord, error) {
+	d := &dlsArchiveDecoder{r: bytes.NewReader(b)}
+	rec := models.DlsExportRecord{
+		MessageType:     string(d.bytes()),
+		MessageSeq:      int(d.uvarint()),
+		ProducerName:    string(d.bytes()),
+		ValidationError: string(d.bytes()),
+		UpdatedAt:       d.time(),
+		TimeSent:        d.time(),
+	}
+	cgs := d.count()
+	rec.PoisonedCgs = make([]string, 0, cgs)
+	for i := 0; i < cgs && d.err == nil; i++ {
+		rec.PoisonedCgs = append(rec.PoisonedCgs, string(d.bytes()))
+	}
+	headers := d.count()
+	rec.Headers = make(map[string]string, headers)
+	for i := 0; i < headers && d.err == nil; i++ {
+		k := string(d.bytes())
+		rec.Headers[k] = string(d.bytes())
+	}
+	rec.Data = d.bytes()
+	if d.err != nil {
+		return models.DlsExportRecord{}, d.err
+	}
+	if d.r.Len() != 0 {
+		return models.DlsExportRecord{}, errDlsArchiveCorrupted
+	}
+	return rec, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"io"
+	"memphis/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDlsRecordsRoundTrip(t *testing.T) {
+	records := []models.DlsExportRecord{
+		{
+			MessageType:  "poison",
+			MessageSeq:   17,
+			ProducerName: "orders-producer",
+			PoisonedCgs:  []string{"billing", "shipping"},
+			UpdatedAt:    time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC),
+			TimeSent:     time.Date(2023, 5, 1, 9, 59, 0, 0, time.UTC),
+			Headers:      map[string]string{"$memphis_producedBy": "orders-producer", "trace-id": "abc"},
+			Data:         []byte{0, 1, 2, 255},
+		},
+		{
+			MessageType:     "schema",
+			ProducerName:    "orders-producer",
+			PoisonedCgs:     []string{},
+			ValidationError: "missing field id",
+			Headers:         map[string]string{},
+			Data:            []byte(`{"name":"order"}`),
+		},
+	}
+
+	for _, format := range []string{dlsExportFormatNdjson, dlsExportFormatArchive} {
+		var buf bytes.Buffer
+		rw, err := newDlsRecordWriter(format, &buf)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", format, err)
+		}
+		for _, rec := range records {
+			if err := rw.write(rec); err != nil {
+				t.Fatalf("%v: unexpected error: %v", format, err)
+			}
+		}
+		if err := rw.close(); err != nil {
+			t.Fatalf("%v: unexpected error: %v", format, err)
+		}
+
+		rr, err := newDlsRecordReader(&buf, dlsImportMaxUncompressedSize)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", format, err)
+		}
+		for i, expected := range records {
+			rec, err := rr.next()
+			if err != nil {
+				t.Fatalf("%v: record %v: unexpected error: %v", format, i, err)
+			}
+			if !rec.UpdatedAt.Equal(expected.UpdatedAt) || !rec.TimeSent.Equal(expected.TimeSent) {
+				t.Fatalf("%v: record %v: unexpected times %v %v", format, i, rec.UpdatedAt, rec.TimeSent)
+			}
+			rec.UpdatedAt, rec.TimeSent = expected.UpdatedAt, expected.TimeSent
+			if !reflect.DeepEqual(rec, expected) {
+				t.Fatalf("%v: record %v: expected %+v, got %+v", format, i, expected, rec)
+			}
+		}
+		if _, err := rr.next(); err != io.EOF {
+			t.Fatalf("%v: expected io.EOF, got %v", format, err)
+		}
+	}
+
+	if _, err := newDlsRecordWriter("csv", io.Discard); err == nil {
+		t.Fatalf("expected an error for an unsupported format")
+	}
+}
+
+func TestDecodeCorruptedDlsArchiveRecord(t *testing.T) {
+	rec := models.DlsExportRecord{MessageType: "poison", PoisonedCgs: []string{"billing"}, Headers: map[string]string{"k": "v"}, Data: []byte("payload")}
+	encoded := encodeDlsArchiveRecord(nil, rec)
+	for _, b := range [][]byte{encoded[:len(encoded)-1], append(append([]byte{}, encoded...), 0)} {
+		if _, err := decodeDlsArchiveRecord(b); err == nil {
+			t.Fatalf("expected an error for a corrupted record")
+		}
+	}
+}
+
+func TestDlsArchiveMaxUncompressedSize(t *testing.T) {
+	var buf bytes.Buffer
+	rw, _ := newDlsRecordWriter(dlsExportFormatArchive, &buf)
+	for i := 0; i < 100; i++ {
+		rw.write(models.DlsExportRecord{MessageType: "poison", Data: bytes.Repeat([]byte{0}, 1024)})
+	}
+	rw.close()
+
+	rr, err := newDlsRecordReader(bytes.NewReader(buf.Bytes()), 10*1024)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for {
+		_, err = rr.next()
+		if err != nil {
+			break
+		}
+	}
+	if err != errDlsArchiveTooLarge {
+		t.Fatalf("expected the archive to exceed the maximum size, got %v", err)
+	}
+}
+
+func TestImportedStationMsgHeaders(t *testing.T) {
+	rec := models.DlsExportRecord{
+		ProducerName: "$memphis_dls",
+		Headers:      map[string]string{"$memphis_producedBy": "$memphis_dls", "$MEMPHIS_connectionId": "c", "trace": "t"},
+	}
+	headers := getImportedStationMsgHeaders(rec, true)
+	expected := map[string]string{"$memphis_producedBy": dlsImportProducer, "trace": "t"}
+	if !reflect.DeepEqual(headers, expected) {
+		t.Fatalf("expected %v, got %v", expected, headers)
+	}
+
+	rec.ProducerName = "orders"
+	if headers := getImportedStationMsgHeaders(rec, true); headers["$memphis_producedBy"] != "orders" {
+		t.Fatalf("expected the original producer to be kept, got %v", headers["$memphis_producedBy"])
+	}
+	if headers := getImportedStationMsgHeaders(rec, false); len(headers) != 1 {
+		t.Fatalf("expected no memphis headers for a non native station, got %v", headers)
+	}
+}
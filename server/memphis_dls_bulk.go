@@ -0,0 +1,330 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/memphis_cache"
+	"memphis/models"
+	"strings"
+	"time"
+)
+
+const (
+	dlsBulkOperationResend = "resend"
+	dlsBulkOperationDrop   = "drop"
+	dlsBulkTaskPrefix      = "dls_bulk_"
+)
+
+var dlsBulkTaskNames = []string{dlsBulkTaskPrefix + dlsBulkOperationResend, dlsBulkTaskPrefix + dlsBulkOperationDrop}
+
+func validateDlsBulkOperation(operation string, filter *models.DlsMsgsFilter) error {
+	switch operation {
+	case dlsBulkOperationResend:
+		// only poison messages can be redelivered to their consumer groups
+		if filter.MessageType != _EMPTY_ && filter.MessageType != "poison" {
+			return errors.New("only poison messages can be resent")
+		}
+		filter.MessageType = "poison"
+	case dlsBulkOperationDrop:
+		err := validateDlsMessageType(filter.MessageType)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported operation %v, supported operations are %v and %v", operation, dlsBulkOperationResend, dlsBulkOperationDrop)
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return errors.New("from has to be earlier than to")
+	}
+	for key := range filter.Headers {
+		if key == _EMPTY_ {
+			return errors.New("header keys can not be empty")
+		}
+	}
+	return nil
+}
+
+func getDlsBulkTaskMetaData(task models.AsyncTask) (models.DlsBulkTaskMetaData, error) {
+	var metaData models.DlsBulkTaskMetaData
+	raw, err := json.Marshal(task.Data)
+	if err != nil {
+		return metaData, err
+	}
+	err = json.Unmarshal(raw, &metaData)
+	if err != nil {
+		return metaData, err
+	}
+	if metaData.Operation == _EMPTY_ {
+		metaData.Operation = strings.TrimPrefix(task.Name, dlsBulkTaskPrefix)
+	}
+	return metaData, nil
+}
+
+func (s *Server) startDlsBulkOperation(user models.User, body models.DlsBulkOperationSchema) (models.DlsBulkOperationResponse, int, error) {
+	operation := strings.ToLower(body.Operation)
+	filter := body.Filter
+	err := validateDlsBulkOperation(operation, &filter)
+	if err != nil {
+		return models.DlsBulkOperationResponse{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	station, statusCode, err := getDlsRetryPolicyStation(user.TenantName, body.StationName)
+	if err != nil {
+		return models.DlsBulkOperationResponse{}, statusCode, err
+	}
+	if operation == dlsBulkOperationDrop && station.Immutability.LegalHold {
+		return models.DlsBulkOperationResponse{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v is under legal hold and its dead-letter messages can not be dropped", station.Name)
+	}
+	if operation == dlsBulkOperationResend && station.ResendDisabled {
+		return models.DlsBulkOperationResponse{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("A resend operation is already in progress in station %v", station.Name)
+	}
+
+	matched, maxId, err := db.CountFilteredDlsMsgs(station.TenantName, station.ID, filter)
+	if err != nil {
+		return models.DlsBulkOperationResponse{}, 500, err
+	}
+	if body.DryRun || matched == 0 {
+		return models.DlsBulkOperationResponse{Matched: matched, DryRun: body.DryRun}, 200, nil
+	}
+
+	taskName := dlsBulkTaskPrefix + operation
+	// the task is created only if it does not exist so concurrent requests can not start the same operation twice
+	created, task, err := db.InsertAsyncTaskIfNotExists(taskName, s.opts.ServerName, time.Now(), station.TenantName, station.ID)
+	if err != nil {
+		return models.DlsBulkOperationResponse{}, 500, err
+	}
+	if !created {
+		return models.DlsBulkOperationResponse{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("A bulk %v operation is already in progress in station %v", operation, station.Name)
+	}
+	// like resending all the messages, a bulk resend disables other resends of the station until it is done
+	if operation == dlsBulkOperationResend {
+		err = db.UpdateResendDisabledInStations(true, []int{station.ID})
+		if err != nil {
+			s.removeDlsBulkTask(station, user, taskName, operation)
+			return models.DlsBulkOperationResponse{}, 500, err
+		}
+	}
+	metaData := models.DlsBulkTaskMetaData{
+		Operation: operation,
+		Filter:    filter,
+		Username:  user.Username,
+		MaxId:     maxId,
+		Total:     matched,
+	}
+	err = db.UpdateAsyncTask(taskName, station.TenantName, time.Now(), metaData, station.ID)
+	if err != nil {
+		s.removeDlsBulkTask(station, user, taskName, metaData.Operation)
+		return models.DlsBulkOperationResponse{}, 500, err
+	}
+
+	go s.runDlsBulkTask(station, user, taskName, metaData)
+	return models.DlsBulkOperationResponse{Matched: matched, TaskId: task.ID}, 200, nil
+}
+
+// runDlsBulkTask processes the matching messages in batches ordered by id,
+// persisting the offset after every batch so a restarted broker can resume the task
+func (s *Server) runDlsBulkTask(station models.Station, user models.User, taskName string, metaData models.DlsBulkTaskMetaData) {
+	for {
+		dlsMsgs, err := db.GetFilteredDlsMsgsBatch(station.TenantName, station.ID, metaData.Filter, metaData.Offset, metaData.MaxId)
+		if err != nil {
+			s.Errorf("[tenant: %v][user: %v][station: %v]runDlsBulkTask at GetFilteredDlsMsgsBatch: %v", station.TenantName, user.Username, station.Name, err.Error())
+			s.handleDlsBulkTaskFailure(station, user, taskName, metaData)
+			return
+		}
+		if len(dlsMsgs) == 0 {
+			break
+		}
+
+		switch metaData.Operation {
+		case dlsBulkOperationResend:
+			for _, dlsMsg := range dlsMsgs {
+				err = s.resendFilteredDlsMsg(dlsMsg, user, station.Name, metaData.Filter.CgName)
+				if err != nil {
+					s.Warnf("[tenant: %v][user: %v][station: %v]runDlsBulkTask at resendFilteredDlsMsg: %v", station.TenantName, user.Username, station.Name, err.Error())
+					metaData.Failed++
+				} else {
+					metaData.Processed++
+				}
+			}
+		case dlsBulkOperationDrop:
+			ids := make([]int, 0, len(dlsMsgs))
+			for _, dlsMsg := range dlsMsgs {
+				ids = append(ids, dlsMsg.ID)
+			}
+			if metaData.Filter.CgName != _EMPTY_ {
+				// only the consumer group is dropped, messages stay in the dls for the rest of their poisoned consumer groups
+				_, err = db.RemoveCgFromDlsMsgs(ids, metaData.Filter.CgName, station.TenantName)
+			} else {
+				err = db.DropDlsMessages(ids)
+			}
+			if err != nil {
+				s.Warnf("[tenant: %v][user: %v][station: %v]runDlsBulkTask at drop: %v", station.TenantName, user.Username, station.Name, err.Error())
+				metaData.Failed += len(ids)
+			} else {
+				metaData.Processed += len(ids)
+			}
+		}
+
+		metaData.Offset = dlsMsgs[len(dlsMsgs)-1].ID
+		err = db.UpdateAsyncTask(taskName, station.TenantName, time.Now(), metaData, station.ID)
+		if err != nil {
+			s.Errorf("[tenant: %v][user: %v][station: %v]runDlsBulkTask at UpdateAsyncTask: %v", station.TenantName, user.Username, station.Name, err.Error())
+		}
+		if metaData.Offset >= metaData.MaxId {
+			break
+		}
+	}
+
+	s.removeDlsBulkTask(station, user, taskName, metaData.Operation)
+	systemMessage := SystemMessage{
+		MessageType:    "Info",
+		MessagePayload: fmt.Sprintf("Bulk %v of dead-letter messages in station %s, triggered by user %s has been completed: %v succeeded, %v failed", metaData.Operation, station.Name, user.Username, metaData.Processed, metaData.Failed),
+	}
+	err := s.sendSystemMessageOnWS(user, systemMessage)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v][station: %v]runDlsBulkTask at sendSystemMessageOnWS: %v", station.TenantName, user.Username, station.Name, err.Error())
+	}
+}
+
+func (s *Server) resendFilteredDlsMsg(dlsMsg models.DlsMessage, user models.User, stationName, cgName string) error {
+	if cgName == _EMPTY_ {
+		_, err := s.ResendUnackedMsg(dlsMsg, user, stationName)
+		return err
+	}
+	err := s.resendDlsMsgToCg(dlsMsg, user.TenantName, stationName, cgName)
+	if err != nil {
+		return err
+	}
+	IncrementEventCounter(user.TenantName, "dls-resend", int64(dlsMsg.MessageDetails.Size), 1, "", []byte{}, []byte{})
+	return nil
+}
+
+// removeDlsBulkTask removes a finished task and enables the resends of the station again once a bulk resend is done
+func (s *Server) removeDlsBulkTask(station models.Station, user models.User, taskName, operation string) {
+	err := db.RemoveAsyncTask(taskName, station.TenantName, station.ID)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v][station: %v]removeDlsBulkTask at RemoveAsyncTask: %v", station.TenantName, user.Username, station.Name, err.Error())
+	}
+	if operation != dlsBulkOperationResend {
+		return
+	}
+	err = db.UpdateResendDisabledInStations(false, []int{station.ID})
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v][station: %v]removeDlsBulkTask at UpdateResendDisabledInStations: %v", station.TenantName, user.Username, station.Name, err.Error())
+	}
+}
+
+func (s *Server) handleDlsBulkTaskFailure(station models.Station, user models.User, taskName string, metaData models.DlsBulkTaskMetaData) {
+	s.removeDlsBulkTask(station, user, taskName, metaData.Operation)
+	systemMessage := SystemMessage{
+		MessageType:    "Error",
+		MessagePayload: fmt.Sprintf("Bulk %v of dead-letter messages in station %s, triggered by user %s has failed due to an internal error after %v messages", metaData.Operation, station.Name, user.Username, metaData.Processed),
+	}
+	err := s.sendSystemMessageOnWS(user, systemMessage)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v][station: %v]handleDlsBulkTaskFailure at sendSystemMessageOnWS: %v", station.TenantName, user.Username, station.Name, err.Error())
+	}
+}
+
+// resumeDlsBulkTasks continues the bulk tasks this broker was running before it went down
+func (s *Server) resumeDlsBulkTasks() {
+	for _, taskName := range dlsBulkTaskNames {
+		exist, asyncTasks, err := db.GetAsyncTaskByNameAndBrokerName(taskName, s.opts.ServerName)
+		if err != nil {
+			s.Errorf("resumeDlsBulkTasks: failed to get async tasks %v: %v", taskName, err.Error())
+			continue
+		}
+		if !exist {
+			continue
+		}
+		for _, asyncTask := range asyncTasks {
+			s.resumeDlsBulkTask(asyncTask)
+		}
+	}
+}
+
+// claimInactiveDlsBulkTasks takes over the bulk tasks which made no progress, probably since their broker is gone,
+// and resumes them from their persisted offset
+func (s *Server) claimInactiveDlsBulkTasks(duration time.Duration) {
+	asyncTasks, err := db.ClaimInactiveAsyncTasksByNames(dlsBulkTaskNames, duration, s.opts.ServerName)
+	if err != nil {
+		s.Errorf("claimInactiveDlsBulkTasks at ClaimInactiveAsyncTasksByNames: %v", err.Error())
+		return
+	}
+	for _, asyncTask := range asyncTasks {
+		s.resumeDlsBulkTask(asyncTask)
+	}
+}
+
+func (s *Server) resumeDlsBulkTask(asyncTask models.AsyncTask) {
+	metaData, err := getDlsBulkTaskMetaData(asyncTask)
+	if err != nil {
+		s.Errorf("[tenant: %v]resumeDlsBulkTask at getDlsBulkTaskMetaData: %v", asyncTask.TenantName, err.Error())
+		return
+	}
+	exist, station, err := db.GetStationById(asyncTask.StationId, asyncTask.TenantName)
+	if err != nil {
+		s.Errorf("[tenant: %v]resumeDlsBulkTask at GetStationById: %v", asyncTask.TenantName, err.Error())
+		return
+	}
+	if !exist {
+		return
+	}
+	exist, user, err := memphis_cache.GetUser(metaData.Username, asyncTask.TenantName)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v]resumeDlsBulkTask at GetUser: %v", asyncTask.TenantName, metaData.Username, err.Error())
+		return
+	}
+	if !exist {
+		s.Warnf("[tenant: %v][user: %v]resumeDlsBulkTask: user does not exist", asyncTask.TenantName, metaData.Username)
+		return
+	}
+	go s.runDlsBulkTask(station, user, asyncTask.Name, metaData)
+}
+
+func (s *Server) getDlsBulkTasks(tenantName, stationName string) ([]models.DlsBulkTask, error) {
+	station, _, err := getDlsRetryPolicyStation(tenantName, stationName)
+	if err != nil {
+		return []models.DlsBulkTask{}, err
+	}
+	asyncTasks, err := db.GetAsyncTasksByStationId(station.ID, station.TenantName)
+	if err != nil {
+		return []models.DlsBulkTask{}, err
+	}
+	tasks := []models.DlsBulkTask{}
+	for _, asyncTask := range asyncTasks {
+		if !strings.HasPrefix(asyncTask.Name, dlsBulkTaskPrefix) {
+			continue
+		}
+		metaData, err := getDlsBulkTaskMetaData(asyncTask)
+		if err != nil {
+			return []models.DlsBulkTask{}, err
+		}
+		tasks = append(tasks, models.DlsBulkTask{
+			ID:             asyncTask.ID,
+			BrokerInCharge: asyncTask.BrokrInCharge,
+			CreatedAt:      asyncTask.CreatedAt,
+			UpdatedAt:      asyncTask.UpdatedAt,
+			Operation:      metaData.Operation,
+			Filter:         metaData.Filter,
+			Username:       metaData.Username,
+			Total:          metaData.Total,
+			Processed:      metaData.Processed,
+			Failed:         metaData.Failed,
+		})
+	}
+	return tasks, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+	"time"
+)
+
+func TestValidateDlsBulkOperation(t *testing.T) {
+	filter := models.DlsMsgsFilter{}
+	if err := validateDlsBulkOperation(dlsBulkOperationResend, &filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter.MessageType != "poison" {
+		t.Fatalf("resend should be limited to poison messages, got %v", filter.MessageType)
+	}
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	invalid := []struct {
+		operation string
+		filter    models.DlsMsgsFilter
+	}{
+		{"purge", models.DlsMsgsFilter{}},
+		{dlsBulkOperationResend, models.DlsMsgsFilter{MessageType: "schema"}},
+		{dlsBulkOperationDrop, models.DlsMsgsFilter{MessageType: "other"}},
+		{dlsBulkOperationDrop, models.DlsMsgsFilter{From: &from, To: &to}},
+		{dlsBulkOperationDrop, models.DlsMsgsFilter{Headers: map[string]string{"": "v"}}},
+	}
+	for _, c := range invalid {
+		if err := validateDlsBulkOperation(c.operation, &c.filter); err == nil {
+			t.Fatalf("expected an error for %v %+v", c.operation, c.filter)
+		}
+	}
+}
+
+func TestGetDlsBulkTaskMetaData(t *testing.T) {
+	task := models.AsyncTask{
+		Name: dlsBulkTaskPrefix + dlsBulkOperationDrop,
+		Data: map[string]interface{}{
+			"filter":    map[string]interface{}{"cg_name": "billing", "headers": map[string]interface{}{"region": "eu"}},
+			"username":  "root",
+			"offset":    float64(120),
+			"max_id":    float64(300),
+			"total":     float64(42),
+			"processed": float64(10),
+		},
+	}
+	metaData, err := getDlsBulkTaskMetaData(task)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if metaData.Operation != dlsBulkOperationDrop || metaData.Offset != 120 || metaData.MaxId != 300 || metaData.Total != 42 || metaData.Processed != 10 {
+		t.Fatalf("unexpected meta data: %+v", metaData)
+	}
+	if metaData.Filter.CgName != "billing" || metaData.Filter.Headers["region"] != "eu" {
+		t.Fatalf("unexpected filter: %+v", metaData.Filter)
+	}
+}
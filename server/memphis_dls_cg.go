@@ -0,0 +1,99 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+)
+
+func isPoisonedCg(dlsMsg models.DlsMessage, cgName string) bool {
+	for _, poisonedCg := range dlsMsg.PoisonedCgs {
+		if poisonedCg == cgName {
+			return true
+		}
+	}
+	return false
+}
+
+// resendDlsMsgsToCg redelivers the messages only to the given consumer group,
+// other consumer groups poisoned by the same messages do not receive a duplicate
+func (s *Server) resendDlsMsgsToCg(user models.User, station models.Station, ids []int, cgName string) (int, error) {
+	dlsMsgs := make([]models.DlsMessage, 0, len(ids))
+	for _, id := range ids {
+		exist, dlsMsg, err := db.GetDlsMessageById(id)
+		if err != nil {
+			return 500, err
+		}
+		if !exist || dlsMsg.StationId != station.ID || !isPoisonedCg(dlsMsg, cgName) {
+			return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Message %v is not a poison message of consumer group %v in station %v", id, cgName, station.Name)
+		}
+		dlsMsgs = append(dlsMsgs, dlsMsg)
+	}
+	for _, dlsMsg := range dlsMsgs {
+		err := s.resendFilteredDlsMsg(dlsMsg, user, station.Name, cgName)
+		if err != nil {
+			return 500, err
+		}
+	}
+	return 200, nil
+}
+
+func (s *Server) getCgDlsMessages(tenantName string, body models.CgDlsMessagesSchema) (models.CgDlsMessagesResponse, int, error) {
+	station, statusCode, err := getDlsRetryPolicyStation(tenantName, body.StationName)
+	if err != nil {
+		return models.CgDlsMessagesResponse{}, statusCode, err
+	}
+	dlsMsgs, err := db.GetDlsMsgsByStationIdAndCg(station.ID, body.CgName)
+	if err != nil {
+		return models.CgDlsMessagesResponse{}, 500, err
+	}
+	total, err := db.GetTotalPoisonMsgsPerCg(body.CgName, station.ID)
+	if err != nil {
+		return models.CgDlsMessagesResponse{}, 500, err
+	}
+
+	poisonMessages := make([]models.LightDlsMessageResponse, 0, len(dlsMsgs))
+	for _, dlsMsg := range dlsMsgs {
+		data := dlsMsg.MessageDetails.Data
+		if len(data) > 80 { // get the first chars for preview needs
+			data = data[0:80]
+		}
+		poisonMessages = append(poisonMessages, models.LightDlsMessageResponse{
+			MessageSeq: dlsMsg.MessageSeq,
+			ID:         dlsMsg.ID,
+			Message: models.MessagePayload{
+				TimeSent: dlsMsg.MessageDetails.TimeSent,
+				Size:     dlsMsg.MessageDetails.Size,
+				Data:     data,
+				Headers:  dlsMsg.MessageDetails.Headers,
+			},
+		})
+	}
+	return models.CgDlsMessagesResponse{CgName: body.CgName, PoisonMessages: poisonMessages, Total: total}, 200, nil
+}
+
+func (s *Server) purgeCgDlsMessages(tenantName string, body models.CgDlsMessagesSchema) (int, int, error) {
+	station, statusCode, err := getDlsRetryPolicyStation(tenantName, body.StationName)
+	if err != nil {
+		return 0, statusCode, err
+	}
+	if station.Immutability.LegalHold {
+		return 0, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v is under legal hold and its dead-letter messages can not be dropped", station.Name)
+	}
+	removed, err := db.RemoveCgFromStationDlsMsgs(station.ID, body.CgName, station.TenantName)
+	if err != nil {
+		return 0, 500, err
+	}
+	return removed, 200, nil
+}
@@ -0,0 +1,416 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"memphis/db"
+	"memphis/models"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	dlsImportTargetDls     = "dls"
+	dlsImportTargetStation = "station"
+	dlsImportProducer      = "$memphis_dls_import"
+	dlsImportBatchSize     = 100
+	dlsImportMaxFileSize   = 100 * 1024 * 1024
+	// archives are compressed, the limit bounds the memory an uploaded archive can expand to
+	dlsImportMaxUncompressedSize = 10 * dlsImportMaxFileSize
+	dlsImportStationTaskName     = "dls_import_station"
+	dlsImportAckTimeout          = 30 * time.Second
+)
+
+func getDlsExportRecord(dlsMsg models.DlsMessage) (models.DlsExportRecord, error) {
+	data, err := hex.DecodeString(dlsMsg.MessageDetails.Data)
+	if err != nil {
+		return models.DlsExportRecord{}, err
+	}
+	poisonedCgs := dlsMsg.PoisonedCgs
+	if poisonedCgs == nil {
+		poisonedCgs = []string{}
+	}
+	headers := dlsMsg.MessageDetails.Headers
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	return models.DlsExportRecord{
+		MessageType:     dlsMsg.MessageType,
+		MessageSeq:      dlsMsg.MessageSeq,
+		ProducerName:    dlsMsg.ProducerName,
+		PoisonedCgs:     poisonedCgs,
+		ValidationError: dlsMsg.ValidationError,
+		UpdatedAt:       dlsMsg.UpdatedAt,
+		TimeSent:        dlsMsg.MessageDetails.TimeSent,
+		Headers:         headers,
+		Data:            data,
+	}, nil
+}
+
+// getImportedDlsMsg builds the dls entry of an imported record, the sequence is left empty
+// since it refers to the stream of the environment the record was exported from
+func getImportedDlsMsg(rec models.DlsExportRecord, station models.Station, poisonedCgs []string, importedAt time.Time) models.DlsMessage {
+	size := len(rec.Data)
+	for k, v := range rec.Headers {
+		size += len(k) + len(v)
+	}
+	return models.DlsMessage{
+		StationId:   station.ID,
+		PoisonedCgs: poisonedCgs,
+		MessageDetails: models.MessagePayload{
+			TimeSent: rec.TimeSent,
+			Size:     size,
+			Data:     hex.EncodeToString(rec.Data),
+			Headers:  rec.Headers,
+		},
+		UpdatedAt:       importedAt,
+		MessageType:     rec.MessageType,
+		ValidationError: rec.ValidationError,
+		TenantName:      station.TenantName,
+		ProducerName:    rec.ProducerName,
+	}
+}
+
+// getImportedStationMsgHeaders drops the reserved memphis headers of an imported record so an uploaded file
+// can not impersonate messages published by the broker itself
+func getImportedStationMsgHeaders(rec models.DlsExportRecord, isNative bool) map[string]string {
+	headers := make(map[string]string, len(rec.Headers)+1)
+	for k, v := range rec.Headers {
+		if strings.HasPrefix(strings.ToLower(k), "$memphis") {
+			continue
+		}
+		headers[k] = v
+	}
+	if isNative {
+		headers["$memphis_producedBy"] = dlsImportProducer
+		if rec.ProducerName != _EMPTY_ && !strings.HasPrefix(strings.ToLower(rec.ProducerName), "$memphis") {
+			headers["$memphis_producedBy"] = rec.ProducerName
+		}
+	}
+	return headers
+}
+
+func validateDlsMessageType(messageType string) error {
+	if messageType != _EMPTY_ && messageType != "poison" && messageType != "schema" {
+		return fmt.Errorf("unsupported message type %v, supported types are poison and schema", messageType)
+	}
+	return nil
+}
+
+func (s *Server) exportDlsMessages(station models.Station, filter models.DlsMsgsFilter, rw dlsRecordWriter) (int, error) {
+	_, maxId, err := db.CountFilteredDlsMsgs(station.TenantName, station.ID, filter)
+	if err != nil {
+		return 0, err
+	}
+	exported := 0
+	offset := 0
+	for offset < maxId {
+		dlsMsgs, err := db.GetFilteredDlsMsgsBatch(station.TenantName, station.ID, filter, offset, maxId)
+		if err != nil {
+			return exported, err
+		}
+		if len(dlsMsgs) == 0 {
+			break
+		}
+		for _, dlsMsg := range dlsMsgs {
+			rec, err := getDlsExportRecord(dlsMsg)
+			if err != nil {
+				return exported, err
+			}
+			err = rw.write(rec)
+			if err != nil {
+				return exported, err
+			}
+			exported++
+		}
+		offset = dlsMsgs[len(dlsMsgs)-1].ID
+	}
+	return exported, rw.close()
+}
+
+func (s *Server) importDlsMessages(user models.User, body models.ImportDlsMessagesSchema, r io.Reader) (models.ImportDlsMessagesResponse, int, error) {
+	resp := models.ImportDlsMessagesResponse{}
+	target := strings.ToLower(body.Target)
+	if target == _EMPTY_ {
+		target = dlsImportTargetDls
+	}
+	if target != dlsImportTargetDls && target != dlsImportTargetStation {
+		return resp, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("unsupported target %v, supported targets are %v and %v", body.Target, dlsImportTargetDls, dlsImportTargetStation)
+	}
+
+	station, statusCode, err := getDlsRetryPolicyStation(user.TenantName, body.StationName)
+	if err != nil {
+		return resp, statusCode, err
+	}
+	sn, err := StationNameFromStr(station.Name)
+	if err != nil {
+		return resp, 500, err
+	}
+	if target == dlsImportTargetStation {
+		if station.Mirror != nil || station.Immutability.Sealed {
+			return resp, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v can not be produced to", station.Name)
+		}
+		return s.startDlsStationImport(user, station, sn, r)
+	}
+
+	cgExists := make(map[string]bool)
+	isExistingCg := func(cgName string) (bool, error) {
+		if exists, ok := cgExists[cgName]; ok {
+			return exists, nil
+		}
+		members, err := db.GetConsumerGroupMembers(cgName, station.ID)
+		if err != nil {
+			return false, err
+		}
+		cgExists[cgName] = len(members) > 0
+		return cgExists[cgName], nil
+	}
+	if body.CgName != _EMPTY_ && target == dlsImportTargetDls {
+		exists, err := isExistingCg(body.CgName)
+		if err != nil {
+			return resp, 500, err
+		}
+		if !exists {
+			return resp, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Consumer group %v does not exist in station %v", body.CgName, station.Name)
+		}
+	}
+
+	reader, err := newDlsRecordReader(r, dlsImportMaxUncompressedSize)
+	if err != nil {
+		return resp, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	importedAt := time.Now()
+	batch := make([]models.DlsMessage, 0, dlsImportBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := db.InsertImportedDlsMsgs(batch)
+		if err != nil {
+			return err
+		}
+		resp.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		rec, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return resp, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("failed reading record %v after importing %v messages: %v", resp.Imported+resp.Skipped+len(batch)+1, resp.Imported, err.Error())
+		}
+		if rec.MessageType != "poison" && rec.MessageType != "schema" {
+			resp.Skipped++
+			continue
+		}
+		if rec.Headers == nil {
+			rec.Headers = map[string]string{}
+		}
+
+		poisonedCgs := []string{}
+		if rec.MessageType == "poison" && station.IsNative {
+			if body.CgName != _EMPTY_ {
+				poisonedCgs = append(poisonedCgs, body.CgName)
+			} else {
+				for _, cgName := range rec.PoisonedCgs {
+					exists, err := isExistingCg(cgName)
+					if err != nil {
+						return resp, 500, err
+					}
+					if exists {
+						poisonedCgs = append(poisonedCgs, cgName)
+					}
+				}
+			}
+			// poison messages are tracked per consumer group, without one there is nothing to redeliver
+			if len(poisonedCgs) == 0 {
+				resp.Skipped++
+				continue
+			}
+		}
+		batch = append(batch, getImportedDlsMsg(rec, station, poisonedCgs, importedAt))
+		if len(batch) >= dlsImportBatchSize {
+			err = flush()
+			if err != nil {
+				return resp, 500, err
+			}
+		}
+	}
+
+	err = flush()
+	if err != nil {
+		return resp, 500, err
+	}
+	if resp.Imported == 0 && resp.Skipped == 0 {
+		return resp, SHOWABLE_ERROR_STATUS_CODE, errors.New("the uploaded file does not contain any dead-letter messages")
+	}
+	return resp, 200, nil
+}
+
+// startDlsStationImport spools the uploaded file and republishes its records into the station in the background,
+// a station runs one import at a time
+func (s *Server) startDlsStationImport(user models.User, station models.Station, sn StationName, r io.Reader) (models.ImportDlsMessagesResponse, int, error) {
+	if enforcer, ok := stationSchemaEnforcers.get(station.TenantName, sn.Intern()); ok && enforcer != nil && enforcer.validate == nil {
+		return models.ImportDlsMessagesResponse{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("The schema of station %v could not be loaded, try again later", station.Name)
+	}
+
+	spool, err := os.CreateTemp(_EMPTY_, "memphis_dls_import_")
+	if err != nil {
+		return models.ImportDlsMessagesResponse{}, 500, err
+	}
+	removeSpool := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+	_, err = io.Copy(spool, r)
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		removeSpool()
+		return models.ImportDlsMessagesResponse{}, 500, err
+	}
+	reader, err := newDlsRecordReader(spool, dlsImportMaxUncompressedSize)
+	if err != nil {
+		removeSpool()
+		return models.ImportDlsMessagesResponse{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	created, task, err := db.InsertAsyncTaskIfNotExists(dlsImportStationTaskName, s.opts.ServerName, time.Now(), station.TenantName, station.ID)
+	if err != nil {
+		removeSpool()
+		return models.ImportDlsMessagesResponse{}, 500, err
+	}
+	if !created {
+		removeSpool()
+		return models.ImportDlsMessagesResponse{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("An import is already in progress in station %v", station.Name)
+	}
+
+	go func() {
+		defer removeSpool()
+		s.runDlsStationImportTask(station, sn, user, reader)
+	}()
+	return models.ImportDlsMessagesResponse{TaskId: task.ID}, 200, nil
+}
+
+// runDlsStationImportTask publishes the records in batches and waits for the station to ack every batch,
+// records are validated against the station's schema since the broker's publishes are not rejected on their way to the dls
+func (s *Server) runDlsStationImportTask(station models.Station, sn StationName, user models.User, reader dlsRecordReader) {
+	metaData := models.DlsImportTaskMetaData{Username: user.Username}
+	account, err := s.lookupAccount(station.TenantName)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v][station: %v]runDlsStationImportTask at lookupAccount: %v", station.TenantName, user.Username, station.Name, err.Error())
+		s.finishDlsStationImportTask(station, user, metaData, err)
+		return
+	}
+
+	subject := sn.Intern() + ".final"
+	batch := make([]jsPublishMsg, 0, dlsImportBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		errs, err := s.publishWithAcks(account, subject, batch, dlsImportAckTimeout)
+		for _, pubErr := range errs {
+			if pubErr != nil {
+				metaData.Failed++
+			} else {
+				metaData.Imported++
+			}
+		}
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		err = db.UpdateAsyncTask(dlsImportStationTaskName, station.TenantName, time.Now(), metaData, station.ID)
+		if err != nil {
+			s.Errorf("[tenant: %v][user: %v][station: %v]runDlsStationImportTask at UpdateAsyncTask: %v", station.TenantName, user.Username, station.Name, err.Error())
+		}
+		return nil
+	}
+
+	var importErr error
+	for {
+		rec, err := reader.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			importErr = fmt.Errorf("failed reading record %v: %v", metaData.Imported+metaData.Skipped+metaData.Failed+len(batch)+1, err.Error())
+			break
+		}
+		if rec.MessageType != "poison" && rec.MessageType != "schema" {
+			metaData.Skipped++
+			continue
+		}
+		if enforcer, ok := stationSchemaEnforcers.get(station.TenantName, sn.Intern()); ok && enforcer != nil {
+			if enforcer.validate == nil || enforcer.validate(rec.Data) != nil {
+				metaData.Failed++
+				continue
+			}
+		}
+		batch = append(batch, jsPublishMsg{hdr: getImportedStationMsgHeaders(rec, station.IsNative), data: rec.Data})
+		if len(batch) >= dlsImportBatchSize {
+			importErr = flush()
+			if importErr != nil {
+				break
+			}
+		}
+	}
+	if importErr == nil {
+		importErr = flush()
+	}
+	if importErr != nil {
+		s.Warnf("[tenant: %v][user: %v][station: %v]runDlsStationImportTask: %v", station.TenantName, user.Username, station.Name, importErr.Error())
+	}
+	s.finishDlsStationImportTask(station, user, metaData, importErr)
+}
+
+func (s *Server) finishDlsStationImportTask(station models.Station, user models.User, metaData models.DlsImportTaskMetaData, importErr error) {
+	err := db.RemoveAsyncTask(dlsImportStationTaskName, station.TenantName, station.ID)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v][station: %v]finishDlsStationImportTask at RemoveAsyncTask: %v", station.TenantName, user.Username, station.Name, err.Error())
+	}
+	systemMessage := SystemMessage{
+		MessageType:    "Info",
+		MessagePayload: fmt.Sprintf("Import of dead-letter messages into station %s, triggered by user %s has been completed: %v imported, %v skipped, %v failed", station.Name, user.Username, metaData.Imported, metaData.Skipped, metaData.Failed),
+	}
+	if importErr != nil {
+		systemMessage = SystemMessage{
+			MessageType:    "Error",
+			MessagePayload: fmt.Sprintf("Import of dead-letter messages into station %s, triggered by user %s has failed after %v messages: %v", station.Name, user.Username, metaData.Imported, importErr.Error()),
+		}
+	}
+	err = s.sendSystemMessageOnWS(user, systemMessage)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v][station: %v]finishDlsStationImportTask at sendSystemMessageOnWS: %v", station.TenantName, user.Username, station.Name, err.Error())
+	}
+}
+
+// removeDlsImportTasks drops station import tasks which can not be resumed since their spooled file is gone with their broker
+func (s *Server) removeDlsImportTasks(asyncTasks []models.AsyncTask) {
+	for _, asyncTask := range asyncTasks {
+		err := db.RemoveAsyncTask(asyncTask.Name, asyncTask.TenantName, asyncTask.StationId)
+		if err != nil {
+			s.Errorf("[tenant: %v]removeDlsImportTasks at RemoveAsyncTask: %v", asyncTask.TenantName, err.Error())
+		}
+	}
+}
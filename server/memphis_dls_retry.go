@@ -0,0 +1,342 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"memphis/db"
+	"memphis/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	dlsRetryInterval                 = 15 * time.Second
+	dlsRetryMaxAttempts              = 100
+	dlsRetryMinBackoffMs             = 1000
+	dlsRetryDefaultBackoffMultiplier = 2
+)
+
+type dlsRetryPoliciesCache struct {
+	lock     sync.Mutex
+	policies map[int]map[string]models.DlsRetryPolicy
+}
+
+var dlsRetryPolicies = &dlsRetryPoliciesCache{policies: make(map[int]map[string]models.DlsRetryPolicy)}
+
+func (c *dlsRetryPoliciesCache) set(policies []models.DlsRetryPolicy) {
+	byStation := make(map[int]map[string]models.DlsRetryPolicy)
+	for _, policy := range policies {
+		if _, ok := byStation[policy.StationId]; !ok {
+			byStation[policy.StationId] = make(map[string]models.DlsRetryPolicy)
+		}
+		byStation[policy.StationId][policy.CgName] = policy
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.policies = byStation
+}
+
+// get returns the policy of the consumer group, falling back to the station wide policy
+func (c *dlsRetryPoliciesCache) get(stationId int, cgName string) (models.DlsRetryPolicy, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	stationPolicies, ok := c.policies[stationId]
+	if !ok {
+		return models.DlsRetryPolicy{}, false
+	}
+	if policy, ok := stationPolicies[cgName]; ok {
+		return policy, true
+	}
+	policy, ok := stationPolicies[_EMPTY_]
+	return policy, ok
+}
+
+func (c *dlsRetryPoliciesCache) stations() map[int]string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	stations := make(map[int]string, len(c.policies))
+	for stationId, stationPolicies := range c.policies {
+		for _, policy := range stationPolicies {
+			stations[stationId] = policy.TenantName
+			break
+		}
+	}
+	return stations
+}
+
+func validateDlsRetryPolicy(policy *models.DlsRetryPolicy) error {
+	if policy.MaxAttempts <= 0 || policy.MaxAttempts > dlsRetryMaxAttempts {
+		return fmt.Errorf("max_attempts has to be between 1 and %v", dlsRetryMaxAttempts)
+	}
+	if policy.InitialBackoffMs < dlsRetryMinBackoffMs {
+		return fmt.Errorf("initial_backoff_ms has to be at least %v", dlsRetryMinBackoffMs)
+	}
+	if policy.BackoffMultiplier == 0 {
+		policy.BackoffMultiplier = dlsRetryDefaultBackoffMultiplier
+	}
+	if policy.BackoffMultiplier < 1 {
+		return errors.New("backoff_multiplier can not be lower than 1")
+	}
+	if policy.MaxBackoffMs != 0 && policy.MaxBackoffMs < policy.InitialBackoffMs {
+		return errors.New("max_backoff_ms can not be lower than initial_backoff_ms")
+	}
+	if policy.MaxAgeSeconds < 0 {
+		return errors.New("max_age_seconds can not be negative")
+	}
+	return nil
+}
+
+// getDlsRetryBackoff returns the time to wait before the attempt following the given number of attempts
+func getDlsRetryBackoff(policy models.DlsRetryPolicy, attempts int) time.Duration {
+	backoffMs := float64(policy.InitialBackoffMs) * math.Pow(policy.BackoffMultiplier, float64(attempts))
+	if policy.MaxBackoffMs > 0 && backoffMs > float64(policy.MaxBackoffMs) {
+		backoffMs = float64(policy.MaxBackoffMs)
+	}
+	if backoffMs > float64(math.MaxInt64/int64(time.Millisecond)) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(backoffMs) * time.Millisecond
+}
+
+// getDlsMsgPoisonedAt returns when the consumer group poisoned the message,
+// messages stored before the per group times were kept fall back to their last update
+func getDlsMsgPoisonedAt(dlsMsg models.DlsMessage, cgName string) time.Time {
+	if poisonedAt, ok := dlsMsg.PoisonedCgsAt[cgName]; ok {
+		return poisonedAt
+	}
+	return dlsMsg.UpdatedAt
+}
+
+// getDlsRetryState reports whether the message is due for another attempt for the consumer group,
+// or whether it has exhausted the policy - either by its age or by the last attempt not being acked in time,
+// along with the time the consumer group should be evaluated again, zero when there is nothing left to retry
+func getDlsRetryState(policy models.DlsRetryPolicy, attempts []models.DlsRetryAttempt, cgName string, poisonedAt, now time.Time) (bool, bool, int, time.Time) {
+	made := 0
+	lastAttemptAt := poisonedAt
+	for _, attempt := range attempts {
+		if attempt.CgName != cgName {
+			continue
+		}
+		if attempt.Exhausted {
+			return false, false, made, time.Time{}
+		}
+		made++
+		if attempt.AttemptedAt.After(lastAttemptAt) {
+			lastAttemptAt = attempt.AttemptedAt
+		}
+	}
+
+	var expiresAt time.Time
+	if policy.MaxAgeSeconds > 0 {
+		expiresAt = poisonedAt.Add(time.Duration(policy.MaxAgeSeconds) * time.Second)
+		if !now.Before(expiresAt) {
+			return false, true, made, time.Time{}
+		}
+	}
+	nextAttemptAt := lastAttemptAt.Add(getDlsRetryBackoff(policy, made))
+	if now.Before(nextAttemptAt) {
+		if !expiresAt.IsZero() && expiresAt.Before(nextAttemptAt) {
+			return false, false, made, expiresAt
+		}
+		return false, false, made, nextAttemptAt
+	}
+	if made >= policy.MaxAttempts {
+		return false, true, made, time.Time{}
+	}
+	return true, false, made, now
+}
+
+func (s *Server) RetryDlsMsgs() {
+	ticker := time.NewTicker(dlsRetryInterval)
+	for range ticker.C {
+		err := s.refreshDlsRetryPolicies()
+		if err != nil {
+			s.Errorf("RetryDlsMsgs at refreshDlsRetryPolicies: %v", err.Error())
+			continue
+		}
+		// every broker keeps the policies for suppressing per message alerts, only the leader applies them
+		if s.JetStreamIsClustered() && !s.JetStreamIsLeader() {
+			continue
+		}
+		for stationId, tenantName := range dlsRetryPolicies.stations() {
+			s.retryStationDlsMsgs(stationId, tenantName)
+		}
+	}
+}
+
+func (s *Server) refreshDlsRetryPolicies() error {
+	policies, err := db.GetAllDlsRetryPolicies()
+	if err != nil {
+		return err
+	}
+	dlsRetryPolicies.set(policies)
+	return nil
+}
+
+func (s *Server) retryStationDlsMsgs(stationId int, tenantName string) {
+	exist, station, err := db.GetStationById(stationId, tenantName)
+	if err != nil {
+		s.Errorf("[tenant: %v]retryStationDlsMsgs at GetStationById: station %v: %v", tenantName, stationId, err.Error())
+		return
+	}
+	if !exist {
+		return
+	}
+	now := time.Now()
+	dlsMsgs, err := db.GetDueDlsMsgsByStationId(stationId, now)
+	if err != nil {
+		s.Errorf("[tenant: %v]retryStationDlsMsgs at GetDueDlsMsgsByStationId: station %v: %v", tenantName, station.Name, err.Error())
+		return
+	}
+
+	for _, dlsMsg := range dlsMsgs {
+		var retryDueAt *time.Time
+		for _, cgName := range dlsMsg.PoisonedCgs {
+			policy, ok := dlsRetryPolicies.get(stationId, cgName)
+			if !ok {
+				continue
+			}
+			due, exhausted, made, nextAt := getDlsRetryState(policy, dlsMsg.RetryAttempts, cgName, getDlsMsgPoisonedAt(dlsMsg, cgName), now)
+			if exhausted {
+				s.exhaustDlsMsgRetries(station, dlsMsg, cgName, made, now)
+				continue
+			}
+			if due {
+				attempt := models.DlsRetryAttempt{CgName: cgName, Attempt: made + 1, AttemptedAt: now}
+				err = s.resendDlsMsgToCg(dlsMsg, station.TenantName, station.Name, cgName)
+				if err != nil {
+					s.Warnf("[tenant: %v]retryStationDlsMsgs at resendDlsMsgToCg: station %v, message %v: %v", station.TenantName, station.Name, dlsMsg.ID, err.Error())
+					attempt.Error = err.Error()
+				} else {
+					IncrementEventCounter(station.TenantName, "dls-resend", int64(dlsMsg.MessageDetails.Size), 1, "", []byte{}, []byte{})
+				}
+				err = db.AppendDlsMsgRetryAttempt(dlsMsg.ID, attempt)
+				if err != nil {
+					s.Errorf("[tenant: %v]retryStationDlsMsgs at AppendDlsMsgRetryAttempt: station %v, message %v: %v", station.TenantName, station.Name, dlsMsg.ID, err.Error())
+				}
+				// the attempt is checked for being acked, or exhausting the policy, after its backoff
+				_, _, _, nextAt = getDlsRetryState(policy, append(dlsMsg.RetryAttempts, attempt), cgName, getDlsMsgPoisonedAt(dlsMsg, cgName), now)
+			}
+			if !nextAt.IsZero() && (retryDueAt == nil || nextAt.Before(*retryDueAt)) {
+				retryDueAt = &nextAt
+			}
+		}
+
+		err = db.UpdateDlsMsgRetryDueAt(dlsMsg.ID, retryDueAt)
+		if err != nil {
+			s.Errorf("[tenant: %v]retryStationDlsMsgs at UpdateDlsMsgRetryDueAt: station %v, message %v: %v", station.TenantName, station.Name, dlsMsg.ID, err.Error())
+		}
+	}
+}
+
+func (s *Server) exhaustDlsMsgRetries(station models.Station, dlsMsg models.DlsMessage, cgName string, made int, now time.Time) {
+	err := db.AppendDlsMsgRetryAttempt(dlsMsg.ID, models.DlsRetryAttempt{CgName: cgName, Attempt: made, AttemptedAt: now, Exhausted: true})
+	if err != nil {
+		s.Errorf("[tenant: %v]exhaustDlsMsgRetries at AppendDlsMsgRetryAttempt: station %v, message %v: %v", station.TenantName, station.Name, dlsMsg.ID, err.Error())
+		return
+	}
+	if alertRules.hasDlsCountRule(station.TenantName, station.ID) { // aggregated by the alert rules engine
+		return
+	}
+
+	msgUrl := s.opts.UiHost + "/stations/" + station.Name + "/" + strconv.Itoa(dlsMsg.ID)
+	msg := fmt.Sprintf("Poison message has exhausted its retry policy for consumer group %v after %v attempts, for more details head to: %v", cgName, made, msgUrl)
+	err = SendNotification(station.TenantName, PoisonMessageTitle, msg, PoisonMAlert)
+	if err != nil {
+		s.Warnf("[tenant: %v]exhaustDlsMsgRetries at SendNotification: Error while sending a poison message notification: %v", station.TenantName, err.Error())
+	}
+}
+
+func (s *Server) setDlsRetryPolicy(tenantName string, body models.SetDlsRetryPolicySchema) (models.DlsRetryPolicy, int, error) {
+	station, statusCode, err := getDlsRetryPolicyStation(tenantName, body.StationName)
+	if err != nil {
+		return models.DlsRetryPolicy{}, statusCode, err
+	}
+	if !station.IsNative {
+		return models.DlsRetryPolicy{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("retry policies are supported only for stations used by Memphis SDKs")
+	}
+
+	policy := models.DlsRetryPolicy{
+		StationId:         station.ID,
+		CgName:            body.CgName,
+		MaxAttempts:       body.MaxAttempts,
+		InitialBackoffMs:  body.InitialBackoffMs,
+		BackoffMultiplier: body.BackoffMultiplier,
+		MaxBackoffMs:      body.MaxBackoffMs,
+		MaxAgeSeconds:     body.MaxAgeSeconds,
+		TenantName:        station.TenantName,
+	}
+	err = validateDlsRetryPolicy(&policy)
+	if err != nil {
+		return models.DlsRetryPolicy{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	policy, err = db.UpsertDlsRetryPolicy(policy)
+	if err != nil {
+		return models.DlsRetryPolicy{}, 500, err
+	}
+	// the messages are evaluated against the new policy on the next retry iteration
+	err = db.ResetDlsMsgsRetryDueAtByStationId(station.ID)
+	if err != nil {
+		return policy, 500, err
+	}
+	err = s.refreshDlsRetryPolicies()
+	if err != nil {
+		return policy, 500, err
+	}
+	return policy, 200, nil
+}
+
+func (s *Server) removeDlsRetryPolicy(tenantName string, body models.RemoveDlsRetryPolicySchema) (int, error) {
+	station, statusCode, err := getDlsRetryPolicyStation(tenantName, body.StationName)
+	if err != nil {
+		return statusCode, err
+	}
+	deleted, err := db.DeleteDlsRetryPolicy(station.ID, body.CgName)
+	if err != nil {
+		return 500, err
+	}
+	if !deleted {
+		if body.CgName == _EMPTY_ {
+			return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v has no station wide retry policy", body.StationName)
+		}
+		return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v has no retry policy for consumer group %v", body.StationName, body.CgName)
+	}
+	err = db.ResetDlsMsgsRetryDueAtByStationId(station.ID)
+	if err != nil {
+		return 500, err
+	}
+	err = s.refreshDlsRetryPolicies()
+	if err != nil {
+		return 500, err
+	}
+	return 200, nil
+}
+
+func getDlsRetryPolicyStation(tenantName, stationName string) (models.Station, int, error) {
+	sn, err := StationNameFromStr(stationName)
+	if err != nil {
+		return models.Station{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	exist, station, err := db.GetStationByName(sn.Ext(), tenantName)
+	if err != nil {
+		return models.Station{}, 500, err
+	}
+	if !exist {
+		return models.Station{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v does not exist", stationName)
+	}
+	return station, 200, nil
+}
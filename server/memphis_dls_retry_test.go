@@ -0,0 +1,111 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+	"time"
+)
+
+func TestGetDlsRetryBackoff(t *testing.T) {
+	policy := models.DlsRetryPolicy{MaxAttempts: 5, InitialBackoffMs: 1000, BackoffMultiplier: 2, MaxBackoffMs: 5000}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for attempts, backoff := range expected {
+		if got := getDlsRetryBackoff(policy, attempts); got != backoff {
+			t.Fatalf("attempt %v: expected %v, got %v", attempts, backoff, got)
+		}
+	}
+}
+
+func TestValidateDlsRetryPolicy(t *testing.T) {
+	policy := models.DlsRetryPolicy{MaxAttempts: 3, InitialBackoffMs: 1000}
+	if err := validateDlsRetryPolicy(&policy); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.BackoffMultiplier != dlsRetryDefaultBackoffMultiplier {
+		t.Fatalf("expected the default multiplier, got %v", policy.BackoffMultiplier)
+	}
+
+	invalid := []models.DlsRetryPolicy{
+		{MaxAttempts: 0, InitialBackoffMs: 1000},
+		{MaxAttempts: dlsRetryMaxAttempts + 1, InitialBackoffMs: 1000},
+		{MaxAttempts: 3, InitialBackoffMs: 10},
+		{MaxAttempts: 3, InitialBackoffMs: 1000, BackoffMultiplier: 0.5},
+		{MaxAttempts: 3, InitialBackoffMs: 2000, MaxBackoffMs: 1000},
+		{MaxAttempts: 3, InitialBackoffMs: 1000, MaxAgeSeconds: -1},
+	}
+	for _, p := range invalid {
+		if err := validateDlsRetryPolicy(&p); err == nil {
+			t.Fatalf("expected an error for %+v", p)
+		}
+	}
+}
+
+func TestGetDlsRetryState(t *testing.T) {
+	policy := models.DlsRetryPolicy{MaxAttempts: 2, InitialBackoffMs: 1000, BackoffMultiplier: 2, MaxAgeSeconds: 60}
+	poisonedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return poisonedAt.Add(d) }
+
+	cases := []struct {
+		name      string
+		attempts  []models.DlsRetryAttempt
+		now       time.Time
+		due       bool
+		exhausted bool
+		nextAt    time.Time
+	}{
+		{"backoff not elapsed", nil, at(500 * time.Millisecond), false, false, at(time.Second)},
+		{"first attempt", nil, at(time.Second), true, false, at(time.Second)},
+		{"other cg attempts are ignored", []models.DlsRetryAttempt{{CgName: "other", Attempt: 1, AttemptedAt: at(time.Second)}}, at(time.Second), true, false, at(time.Second)},
+		{"second attempt waits the grown backoff", []models.DlsRetryAttempt{{CgName: "cg", Attempt: 1, AttemptedAt: at(time.Second)}}, at(2 * time.Second), false, false, at(3 * time.Second)},
+		{"second attempt", []models.DlsRetryAttempt{{CgName: "cg", Attempt: 1, AttemptedAt: at(time.Second)}}, at(3 * time.Second), true, false, at(3 * time.Second)},
+		{"last attempt not acked in time", []models.DlsRetryAttempt{{CgName: "cg", Attempt: 1, AttemptedAt: at(time.Second)}, {CgName: "cg", Attempt: 2, AttemptedAt: at(3 * time.Second)}}, at(7 * time.Second), false, true, time.Time{}},
+		{"max age", nil, at(time.Minute), false, true, time.Time{}},
+		{"already exhausted", []models.DlsRetryAttempt{{CgName: "cg", Attempt: 0, AttemptedAt: at(time.Minute), Exhausted: true}}, at(2 * time.Minute), false, false, time.Time{}},
+	}
+	for _, c := range cases {
+		due, exhausted, _, nextAt := getDlsRetryState(policy, c.attempts, "cg", poisonedAt, c.now)
+		if due != c.due || exhausted != c.exhausted || !nextAt.Equal(c.nextAt) {
+			t.Fatalf("%v: expected due=%v exhausted=%v next=%v, got due=%v exhausted=%v next=%v", c.name, c.due, c.exhausted, c.nextAt, due, exhausted, nextAt)
+		}
+	}
+}
+
+func TestGetDlsMsgPoisonedAt(t *testing.T) {
+	updatedAt := time.Date(2023, 1, 1, 0, 10, 0, 0, time.UTC)
+	poisonedAt := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	dlsMsg := models.DlsMessage{UpdatedAt: updatedAt, PoisonedCgsAt: map[string]time.Time{"billing": poisonedAt}}
+	if got := getDlsMsgPoisonedAt(dlsMsg, "billing"); !got.Equal(poisonedAt) {
+		t.Fatalf("expected the poisoned time of the consumer group, got %v", got)
+	}
+	if got := getDlsMsgPoisonedAt(dlsMsg, "shipping"); !got.Equal(updatedAt) {
+		t.Fatalf("expected the update time for a consumer group without a poisoned time, got %v", got)
+	}
+}
+
+func TestDlsRetryPoliciesCacheGet(t *testing.T) {
+	cache := &dlsRetryPoliciesCache{}
+	cache.set([]models.DlsRetryPolicy{
+		{StationId: 1, CgName: _EMPTY_, MaxAttempts: 3},
+		{StationId: 1, CgName: "billing", MaxAttempts: 5},
+	})
+	if policy, ok := cache.get(1, "billing"); !ok || policy.MaxAttempts != 5 {
+		t.Fatalf("expected the consumer group policy, got %+v", policy)
+	}
+	if policy, ok := cache.get(1, "shipping"); !ok || policy.MaxAttempts != 3 {
+		t.Fatalf("expected the station wide policy, got %+v", policy)
+	}
+	if _, ok := cache.get(2, "billing"); ok {
+		t.Fatalf("expected no policy")
+	}
+}
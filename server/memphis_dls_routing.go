@@ -0,0 +1,102 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+	"strconv"
+)
+
+const (
+	dlsRoutedProducer = "$memphis_dls_router"
+
+	dlsTypePoison      = "poison"
+	dlsTypeSchemaverse = "schemaverse"
+)
+
+// checkDlsStation validates the station dead-letter messages of sn are forwarded to
+func checkDlsStation(tenantName string, sn StationName, dlsStation string) (int, error) {
+	if dlsStation == _EMPTY_ {
+		return 200, nil
+	}
+	dlsStationName, err := StationNameFromStr(dlsStation)
+	if err != nil {
+		return SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	if dlsStationName.Ext() == sn.Ext() {
+		return SHOWABLE_ERROR_STATUS_CODE, errors.New("a station can not forward its dead-letter messages to itself")
+	}
+
+	exist, station, err := db.GetStationByName(dlsStationName.Ext(), tenantName)
+	if err != nil {
+		return 500, err
+	}
+	if !exist {
+		return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("dead-letter station %v does not exist", dlsStation)
+	}
+	if station.Mirror != nil || station.Immutability.Sealed {
+		return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("dead-letter station %v can not be produced to", dlsStation)
+	}
+
+	return 200, nil
+}
+
+// getDlsRoutingHeaders enriches the original headers with the details of the failure
+func getDlsRoutingHeaders(headers map[string]string, station models.Station, dlsType string, messageSeq uint64, cgName string, deliveries uint64, validationErr string) map[string]string {
+	hdrs := make(map[string]string, len(headers)+7)
+	for k, v := range headers {
+		hdrs[k] = v
+	}
+	if hdrs["$memphis_producedBy"] == _EMPTY_ {
+		hdrs["$memphis_producedBy"] = dlsRoutedProducer
+	}
+	hdrs["$memphis_dls_type"] = dlsType
+	hdrs["$memphis_dls_original_station"] = station.Name
+	if messageSeq > 0 {
+		hdrs["$memphis_dls_original_seq"] = strconv.FormatUint(messageSeq, 10)
+	}
+	if cgName != _EMPTY_ {
+		hdrs["$memphis_dls_cg_name"] = cgName
+	}
+	if deliveries > 0 {
+		hdrs["$memphis_dls_delivery_count"] = strconv.FormatUint(deliveries, 10)
+	}
+	if validationErr != _EMPTY_ {
+		hdrs["$memphis_dls_validation_error"] = validationErr
+	}
+	return hdrs
+}
+
+// routeDlsMessage republishes a dead-letter message into the station configured as the dls of the original station
+func (s *Server) routeDlsMessage(station models.Station, data []byte, hdrs map[string]string) error {
+	dlsStationName, err := StationNameFromStr(station.DlsStation)
+	if err != nil {
+		return err
+	}
+	exist, _, err := db.GetStationByName(dlsStationName.Ext(), station.TenantName)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf("dead-letter station %v does not exist", station.DlsStation)
+	}
+
+	account, err := s.lookupAccount(station.TenantName)
+	if err != nil {
+		return err
+	}
+	s.sendInternalMsgWithHeaderLocked(account, dlsStationName.Intern()+".final", hdrs, data)
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+)
+
+func TestGetDlsRoutingHeaders(t *testing.T) {
+	original := map[string]string{"$memphis_producedBy": "orders-producer", "trace-id": "abc"}
+	station := models.Station{Name: "orders"}
+
+	hdrs := getDlsRoutingHeaders(original, station, dlsTypePoison, 42, "billing", 5, _EMPTY_)
+	expected := map[string]string{
+		"$memphis_producedBy":           "orders-producer",
+		"trace-id":                      "abc",
+		"$memphis_dls_type":             dlsTypePoison,
+		"$memphis_dls_original_station": "orders",
+		"$memphis_dls_original_seq":     "42",
+		"$memphis_dls_cg_name":          "billing",
+		"$memphis_dls_delivery_count":   "5",
+	}
+	if len(hdrs) != len(expected) {
+		t.Fatalf("unexpected headers: %v", hdrs)
+	}
+	for k, v := range expected {
+		if hdrs[k] != v {
+			t.Fatalf("header %v: expected %v, got %v", k, v, hdrs[k])
+		}
+	}
+	if _, ok := original["$memphis_dls_type"]; ok {
+		t.Fatalf("the original headers should not be modified")
+	}
+
+	hdrs = getDlsRoutingHeaders(nil, station, dlsTypeSchemaverse, 0, _EMPTY_, 0, "missing field")
+	if hdrs["$memphis_producedBy"] != dlsRoutedProducer || hdrs["$memphis_dls_validation_error"] != "missing field" {
+		t.Fatalf("unexpected headers: %v", hdrs)
+	}
+	if _, ok := hdrs["$memphis_dls_original_seq"]; ok {
+		t.Fatalf("unexpected seq header: %v", hdrs)
+	}
+}
@@ -37,6 +37,8 @@ type Handlers struct {
 	Configurations ConfigurationsHandler
 	Tenants        TenantHandler
 	Billing        BillingHandler
+	AlertRules     AlertRulesHandler
+	SchemaRegistry SchemaRegistryHandler
 	userMgmt       UserMgmtHandler
 }
 
@@ -78,7 +80,7 @@ func getUserDetailsFromMiddleware(c *gin.Context) (models.User, error) {
 func CreateDefaultStation(tenantName string, s *Server, sn StationName, userId int, username string) (models.Station, bool, error) {
 	stationName := sn.Ext()
 	replicas := getDefaultReplicas()
-	err := s.CreateStream(tenantName, sn, "message_age_sec", 604800, "file", 120000, replicas, false)
+	err := s.CreateStream(tenantName, sn, "message_age_sec", 604800, models.StationRetentionPolicy{}, "file", 120000, replicas, false, nil, nil, models.StationImmutability{})
 	if err != nil {
 		return models.Station{}, false, err
 	}
@@ -86,7 +88,7 @@ func CreateDefaultStation(tenantName string, s *Server, sn StationName, userId i
 	schemaName := ""
 	schemaVersionNumber := 0
 
-	newStation, rowsUpdated, err := db.InsertNewStation(stationName, userId, username, "message_age_sec", 604800, "file", replicas, schemaName, schemaVersionNumber, 120000, true, models.DlsConfiguration{Poison: true, Schemaverse: true}, false, tenantName)
+	newStation, rowsUpdated, err := db.InsertNewStation(stationName, userId, username, "message_age_sec", 604800, "file", replicas, schemaName, schemaVersionNumber, 120000, true, models.DlsConfiguration{Poison: true, Schemaverse: true}, false, nil, nil, models.StationImmutability{}, models.StationRetentionPolicy{}, tenantName)
 	if err != nil {
 		return models.Station{}, false, err
 	}
@@ -0,0 +1,250 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"fmt"
+	"memphis/analytics"
+	"memphis/db"
+	"memphis/models"
+	"memphis/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertRulesHandler struct{ S *Server }
+
+const (
+	alertRuleDefaultWindowSeconds      = 300
+	alertRuleDefaultSeverity           = "warning"
+	alertRuleDefaultDedupWindowSeconds = 600
+	alertRuleDefaultCooldownSeconds    = 300
+	alertRuleDefaultStorageThreshold   = 80
+)
+
+func buildAlertRule(body models.CreateAlertRuleSchema, tenantName string, username string) (models.AlertRule, int, error) {
+	name := strings.ToLower(body.Name)
+	err := validateName(name, "alert rule")
+	if err != nil {
+		return models.AlertRule{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	rule := models.AlertRule{
+		Name:               name,
+		TenantName:         tenantName,
+		RuleType:           strings.ToLower(body.RuleType),
+		Threshold:          body.Threshold,
+		WindowSeconds:      body.WindowSeconds,
+		Severity:           strings.ToLower(body.Severity),
+		DedupWindowSeconds: body.DedupWindowSeconds,
+		CooldownSeconds:    body.CooldownSeconds,
+		IsEnabled:          true,
+		CreatedBy:          username,
+	}
+	if body.IsEnabled != nil {
+		rule.IsEnabled = *body.IsEnabled
+	}
+	if rule.WindowSeconds == 0 && alertRuleUsesWindow(rule.RuleType) {
+		rule.WindowSeconds = alertRuleDefaultWindowSeconds
+	}
+	if rule.Severity == "" {
+		rule.Severity = alertRuleDefaultSeverity
+	}
+	if rule.DedupWindowSeconds == 0 {
+		rule.DedupWindowSeconds = alertRuleDefaultDedupWindowSeconds
+	}
+	if rule.CooldownSeconds == 0 {
+		rule.CooldownSeconds = alertRuleDefaultCooldownSeconds
+	}
+	if rule.RuleType == AlertRuleStorageUsage && rule.Threshold == 0 {
+		rule.Threshold = alertRuleDefaultStorageThreshold
+	}
+
+	if body.StationName != "" {
+		stationName, err := StationNameFromStr(body.StationName)
+		if err != nil {
+			return models.AlertRule{}, SHOWABLE_ERROR_STATUS_CODE, err
+		}
+		exist, _, err := db.GetStationByName(stationName.Ext(), tenantName)
+		if err != nil {
+			return models.AlertRule{}, 500, err
+		}
+		if !exist {
+			return models.AlertRule{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("station %v does not exist", body.StationName)
+		}
+		rule.StationName = stationName.Ext()
+	}
+	if body.TagName != "" {
+		tagName := strings.ToLower(body.TagName)
+		exist, _, err := db.GetTagByName(tagName, tenantName)
+		if err != nil {
+			return models.AlertRule{}, 500, err
+		}
+		if !exist {
+			return models.AlertRule{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("tag %v does not exist", body.TagName)
+		}
+		rule.TagName = tagName
+	}
+
+	err = validateAlertRule(rule)
+	if err != nil {
+		return models.AlertRule{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	return rule, 0, nil
+}
+
+func (ah AlertRulesHandler) CreateAlertRule(c *gin.Context) {
+	var body models.CreateAlertRuleSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("CreateAlertRule at getUserDetailsFromMiddleware: %v", err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	rule, errorCode, err := buildAlertRule(body, user.TenantName, user.Username)
+	if err != nil {
+		if errorCode == 500 {
+			serv.Errorf("[tenant: %v][user: %v]CreateAlertRule at buildAlertRule: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		} else {
+			serv.Warnf("[tenant: %v][user: %v]CreateAlertRule at buildAlertRule: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": err.Error()})
+		}
+		return
+	}
+	exist, _, err := db.GetAlertRuleByName(rule.Name, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]CreateAlertRule at db.GetAlertRuleByName: Alert rule %v: %v", user.TenantName, user.Username, rule.Name, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	if exist {
+		errMsg := fmt.Sprintf("Alert rule with the name %v already exists", rule.Name)
+		serv.Warnf("[tenant: %v][user: %v]CreateAlertRule: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+
+	newRule, err := db.InsertNewAlertRule(rule)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]CreateAlertRule at db.InsertNewAlertRule: Alert rule %v: %v", user.TenantName, user.Username, rule.Name, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]Alert rule %v has been created", user.TenantName, user.Username, newRule.Name)
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"rule-type": newRule.RuleType}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-create-alert-rule")
+	}
+	c.IndentedJSON(200, newRule)
+}
+
+func (ah AlertRulesHandler) UpdateAlertRule(c *gin.Context) {
+	var body models.CreateAlertRuleSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("UpdateAlertRule at getUserDetailsFromMiddleware: %v", err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	rule, errorCode, err := buildAlertRule(body, user.TenantName, user.Username)
+	if err != nil {
+		if errorCode == 500 {
+			serv.Errorf("[tenant: %v][user: %v]UpdateAlertRule at buildAlertRule: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		} else {
+			serv.Warnf("[tenant: %v][user: %v]UpdateAlertRule at buildAlertRule: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": err.Error()})
+		}
+		return
+	}
+	exist, _, err := db.GetAlertRuleByName(rule.Name, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]UpdateAlertRule at db.GetAlertRuleByName: Alert rule %v: %v", user.TenantName, user.Username, rule.Name, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	if !exist {
+		errMsg := fmt.Sprintf("Alert rule %v does not exist", rule.Name)
+		serv.Warnf("[tenant: %v][user: %v]UpdateAlertRule: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+
+	updatedRule, err := db.UpdateAlertRule(rule)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]UpdateAlertRule at db.UpdateAlertRule: Alert rule %v: %v", user.TenantName, user.Username, rule.Name, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]Alert rule %v has been updated", user.TenantName, user.Username, updatedRule.Name)
+	c.IndentedJSON(200, updatedRule)
+}
+
+func (ah AlertRulesHandler) GetAllAlertRules(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetAllAlertRules at getUserDetailsFromMiddleware: %v", err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	rules, err := db.GetAllAlertRulesByTenant(user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]GetAllAlertRules at db.GetAllAlertRulesByTenant: %v", user.TenantName, user.Username, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	c.IndentedJSON(200, rules)
+}
+
+func (ah AlertRulesHandler) RemoveAlertRule(c *gin.Context) {
+	var body models.RemoveAlertRuleSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("RemoveAlertRule at getUserDetailsFromMiddleware: %v", err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	name := strings.ToLower(body.Name)
+	err = db.DeleteAlertRule(name, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]RemoveAlertRule at db.DeleteAlertRule: Alert rule %v: %v", user.TenantName, user.Username, name, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]Alert rule %v has been removed", user.TenantName, user.Username, name)
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := make(map[string]interface{})
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-remove-alert-rule")
+	}
+	c.IndentedJSON(200, gin.H{})
+}
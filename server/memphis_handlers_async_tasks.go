@@ -20,6 +20,14 @@ import (
 )
 
 func (s *Server) CompleteRelevantStuckAsyncTasks() {
+	s.resumeDlsBulkTasks()
+	exist, importTasks, err := db.GetAsyncTaskByNameAndBrokerName(dlsImportStationTaskName, s.opts.ServerName)
+	if err != nil {
+		serv.Errorf("CompleteRelevantStuckAsyncTasks: failed to get async tasks %v: %v", dlsImportStationTaskName, err.Error())
+	} else if exist {
+		s.removeDlsImportTasks(importTasks)
+	}
+
 	exist, asyncTasks, err := db.GetAsyncTaskByNameAndBrokerName("resend_all_dls_msgs", s.opts.ServerName)
 	if err != nil {
 		serv.Errorf("CompleteRelevantStuckAsyncTasks: failed to get async tasks resend_all_dls_msgs: %v", err.Error())
@@ -58,6 +66,15 @@ func (s *Server) CompleteRelevantStuckAsyncTasks() {
 
 func (s *Server) RemoveInactiveAsyncTasks() {
 	duration := 20 * time.Minute
+	// bulk dls tasks persist their offset so they are resumed instead of being removed
+	s.claimInactiveDlsBulkTasks(duration)
+	importTasks, err := db.ClaimInactiveAsyncTasksByNames([]string{dlsImportStationTaskName}, duration, s.opts.ServerName)
+	if err != nil {
+		serv.Errorf("RemoveInactiveAsyncTasks at ClaimInactiveAsyncTasksByNames: %v", err.Error())
+	} else {
+		s.removeDlsImportTasks(importTasks)
+	}
+
 	stationIds, err := db.RemoveAllAsyncTasks(duration)
 	if err != nil {
 		serv.Errorf("RemoveInactiveAsyncTasks: failed to get async tasks resend_all_dls_msgs: %v", err.Error())
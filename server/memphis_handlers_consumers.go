@@ -465,6 +465,176 @@ func (ch ConsumersHandler) GetDelayedCgsByTenant(tenantName string) ([]models.De
 	return delayedCgsResp, nil
 }
 
+var cgsLagSortFields = []string{"pending", "ack_pending", "redelivered", "oldest_unacked_age", "total_lag", "station_name", "cg_name"}
+
+func (ch ConsumersHandler) GetCgsLag(tenantName string, filter models.GetCgsLagSchema) ([]models.CgLag, int, error) {
+	sortBy := strings.ToLower(filter.SortBy)
+	if sortBy == "" {
+		sortBy = "pending"
+	}
+	if !slices.Contains(cgsLagSortFields, sortBy) {
+		return []models.CgLag{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("sort_by has to be one of the following: %v", strings.Join(cgsLagSortFields, ", "))
+	}
+	order := strings.ToLower(filter.Order)
+	if order != "" && order != "asc" && order != "desc" {
+		return []models.CgLag{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("order has to be asc or desc")
+	}
+
+	var stations []models.Station
+	if filter.StationName != "" {
+		sn, err := StationNameFromStr(filter.StationName)
+		if err != nil {
+			return []models.CgLag{}, SHOWABLE_ERROR_STATUS_CODE, err
+		}
+		exist, station, err := db.GetStationByName(sn.Ext(), tenantName)
+		if err != nil {
+			return []models.CgLag{}, 500, err
+		}
+		if !exist {
+			return []models.CgLag{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("station %v does not exist", sn.Ext())
+		}
+		stations = []models.Station{station}
+	} else {
+		var err error
+		stations, err = db.GetActiveStationsPerTenant(tenantName)
+		if err != nil {
+			return []models.CgLag{}, 500, err
+		}
+	}
+
+	if filter.TagName != "" {
+		exist, tag, err := db.GetTagByName(strings.ToLower(filter.TagName), tenantName)
+		if err != nil {
+			return []models.CgLag{}, 500, err
+		}
+		if !exist {
+			return []models.CgLag{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("tag %v does not exist", filter.TagName)
+		}
+		taggedStations := make(map[int]bool, len(tag.Stations))
+		for _, id := range tag.Stations {
+			taggedStations[id] = true
+		}
+		filtered := []models.Station{}
+		for _, station := range stations {
+			if taggedStations[station.ID] {
+				filtered = append(filtered, station)
+			}
+		}
+		stations = filtered
+	}
+
+	cgsLag := []models.CgLag{}
+	for _, station := range stations {
+		stationCgsLag, err := ch.getStationCgsLag(tenantName, station)
+		if err != nil {
+			return []models.CgLag{}, 500, err
+		}
+		for _, cgLag := range stationCgsLag {
+			if filter.OnlyLagging && cgLag.TotalLag == 0 {
+				continue
+			}
+			cgsLag = append(cgsLag, cgLag)
+		}
+	}
+
+	sortCgsLag(cgsLag, sortBy, order == "asc")
+	return cgsLag, 200, nil
+}
+
+func (ch ConsumersHandler) getStationCgsLag(tenantName string, station models.Station) ([]models.CgLag, error) {
+	sn, err := StationNameFromStr(station.Name)
+	if err != nil {
+		return []models.CgLag{}, err
+	}
+	consumers, err := db.GetAllConsumersByStation(station.ID)
+	if err != nil {
+		return []models.CgLag{}, err
+	}
+	if len(consumers) == 0 {
+		return []models.CgLag{}, nil
+	}
+
+	tags, err := db.GetTagsByEntityID("station", station.ID)
+	if err != nil {
+		return []models.CgLag{}, err
+	}
+	tagNames := []string{}
+	for _, tag := range tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+
+	cgs := make(map[string]*models.CgLag)
+	cgNames := []string{}
+	for _, consumer := range consumers {
+		if cg, ok := cgs[consumer.ConsumersGroup]; ok {
+			cg.IsActive = cg.IsActive || consumer.IsActive
+			continue
+		}
+		cgs[consumer.ConsumersGroup] = &models.CgLag{
+			StationName:      sn.Ext(),
+			CGName:           consumer.ConsumersGroup,
+			IsActive:         consumer.IsActive,
+			Tags:             tagNames,
+			MaxAckTimeMs:     consumer.MaxAckTimeMs,
+			MaxMsgDeliveries: consumer.MaxMsgDeliveries,
+		}
+		cgNames = append(cgNames, consumer.ConsumersGroup)
+	}
+
+	cgsLag := []models.CgLag{}
+	for _, cgName := range cgNames {
+		cg := cgs[cgName]
+		cgInfo, err := ch.S.GetCgInfo(tenantName, sn, cgName)
+		if err != nil {
+			// the consumer group might have been removed in the meantime
+			continue
+		}
+		cg.NumPending = cgInfo.NumPending
+		cg.NumAckPending = cgInfo.NumAckPending
+		cg.NumRedelivered = cgInfo.NumRedelivered
+		cg.LastDeliveredAt = cgInfo.Delivered.Last
+		cg.TotalLag = cgInfo.NumPending + uint64(cgInfo.NumAckPending)
+		if cgInfo.NumAckPending > 0 {
+			// the first message after the ack floor is the oldest unacked one, it is counted only when the consumer
+			// has already delivered it so messages waiting for their first delivery are not reported as unacked
+			msg, err := ch.S.memphisGetNextMessage(tenantName, sn.Intern(), sn.Intern()+".>", cgInfo.AckFloor.Stream+1)
+			if err == nil && msg != nil && msg.Sequence <= cgInfo.Delivered.Stream {
+				cg.OldestUnackedAgeSec = int64(time.Since(msg.Time).Seconds())
+			}
+		}
+		cgsLag = append(cgsLag, *cg)
+	}
+	return cgsLag, nil
+}
+
+func sortCgsLag(cgsLag []models.CgLag, sortBy string, asc bool) {
+	less := func(i, j int) bool {
+		a, b := cgsLag[i], cgsLag[j]
+		switch sortBy {
+		case "ack_pending":
+			return a.NumAckPending < b.NumAckPending
+		case "redelivered":
+			return a.NumRedelivered < b.NumRedelivered
+		case "oldest_unacked_age":
+			return a.OldestUnackedAgeSec < b.OldestUnackedAgeSec
+		case "total_lag":
+			return a.TotalLag < b.TotalLag
+		case "station_name":
+			return a.StationName < b.StationName
+		case "cg_name":
+			return a.CGName < b.CGName
+		default:
+			return a.NumPending < b.NumPending
+		}
+	}
+	sort.SliceStable(cgsLag, func(i, j int) bool {
+		if asc {
+			return less(i, j)
+		}
+		return less(j, i)
+	})
+}
+
 func (s *Server) destroyConsumerDirect(c *client, reply string, msg []byte) {
 	var dcr destroyConsumerRequestV1
 	tenantName, message, err := s.getTenantNameAndMessage(msg)
@@ -0,0 +1,59 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+)
+
+func TestSortCgsLag(t *testing.T) {
+	cgsLag := []models.CgLag{
+		{StationName: "s1", CGName: "b", NumPending: 5, NumAckPending: 1, TotalLag: 6},
+		{StationName: "s2", CGName: "a", NumPending: 1, NumAckPending: 10, TotalLag: 11},
+		{StationName: "s1", CGName: "c", NumPending: 3, NumAckPending: 0, TotalLag: 3},
+	}
+
+	sortCgsLag(cgsLag, "pending", false)
+	if cgsLag[0].CGName != "b" || cgsLag[2].CGName != "a" {
+		t.Fatalf("Expected descending order by pending, got %v, %v, %v", cgsLag[0].CGName, cgsLag[1].CGName, cgsLag[2].CGName)
+	}
+
+	sortCgsLag(cgsLag, "total_lag", true)
+	if cgsLag[0].CGName != "c" || cgsLag[2].CGName != "a" {
+		t.Fatalf("Expected ascending order by total lag, got %v, %v, %v", cgsLag[0].CGName, cgsLag[1].CGName, cgsLag[2].CGName)
+	}
+
+	sortCgsLag(cgsLag, "cg_name", true)
+	if cgsLag[0].CGName != "a" || cgsLag[1].CGName != "b" {
+		t.Fatalf("Expected ascending order by cg name, got %v, %v, %v", cgsLag[0].CGName, cgsLag[1].CGName, cgsLag[2].CGName)
+	}
+}
+
+func TestGetCgsLagFilterFromWSSubj(t *testing.T) {
+	tests := []struct {
+		subj    string
+		sortBy  string
+		tagName string
+	}{
+		{"cgs_lag_data", "", ""},
+		{"cgs_lag_data.total_lag", "total_lag", ""},
+		{"cgs_lag_data.pending.billing", "pending", "billing"},
+		{"cgs_lag_data.pending.team.billing.eu", "pending", "team.billing.eu"},
+	}
+	for _, tc := range tests {
+		filter := getCgsLagFilterFromWSSubj(tc.subj)
+		if filter.SortBy != tc.sortBy || filter.TagName != tc.tagName {
+			t.Fatalf("%v: expected sort by %q and tag %q, got %q and %q", tc.subj, tc.sortBy, tc.tagName, filter.SortBy, filter.TagName)
+		}
+	}
+}
@@ -96,6 +96,18 @@ func (s *Server) handleNewUnackedMsg(msg []byte) error {
 		poisonedCgs = append(poisonedCgs, cgName)
 	}
 
+	// messages which were already forwarded once are not forwarded again to avoid loops between dead-letter stations
+	if station.DlsStation != _EMPTY_ && headersJson["$memphis_dls_original_station"] == _EMPTY_ {
+		hdrs := getDlsRoutingHeaders(headersJson, station, dlsTypePoison, messageSeq, cgName, message.Deliveries, _EMPTY_)
+		err = s.routeDlsMessage(station, poisonMessageContent.Data, hdrs)
+		if err != nil {
+			serv.Warnf("[tenant: %v]handleNewUnackedMsg at routeDlsMessage: station %v: %v", station.TenantName, station.Name, err.Error())
+		} else if station.DlsStationOnly {
+			memphisMetricsCounters.incPoisonMessages(station.TenantName, station.Name)
+			return nil
+		}
+	}
+
 	messageDetails := models.MessagePayload{
 		TimeSent: poisonMessageContent.Time,
 		Size:     len(poisonMessageContent.Data) + len(poisonMessageContent.Header),
@@ -111,6 +123,13 @@ func (s *Server) handleNewUnackedMsg(msg []byte) error {
 	if dlsMsgId == 0 { // nothing to do
 		return nil
 	}
+	memphisMetricsCounters.incPoisonMessages(station.TenantName, station.Name)
+	if alertRules.hasDlsCountRule(station.TenantName, station.ID) { // aggregated by the alert rules engine
+		return nil
+	}
+	if _, ok := dlsRetryPolicies.get(station.ID, cgName); ok { // alerted once the retry policy is exhausted
+		return nil
+	}
 
 	idForUrl := strconv.Itoa(dlsMsgId)
 	var msgUrl = s.opts.UiHost + "/stations/" + stationName.Ext() + "/" + idForUrl
@@ -145,12 +164,30 @@ func (s *Server) handleSchemaverseDlsMsg(msg []byte) {
 		return
 	}
 
+	if station.DlsStation != _EMPTY_ && message.Message.Headers["$memphis_dls_original_station"] == _EMPTY_ {
+		hdrs := getDlsRoutingHeaders(message.Message.Headers, station, dlsTypeSchemaverse, 0, _EMPTY_, 0, message.ValidationError)
+		if hdrs["$memphis_producedBy"] == dlsRoutedProducer && message.Producer.Name != _EMPTY_ {
+			hdrs["$memphis_producedBy"] = message.Producer.Name
+		}
+		data, err := hex.DecodeString(message.Message.Data)
+		if err == nil {
+			err = s.routeDlsMessage(station, data, hdrs)
+		}
+		if err != nil {
+			serv.Warnf("[tenant: %v]handleSchemaverseDlsMsg at routeDlsMessage: station %v: %v", tenantName, station.Name, err.Error())
+		} else if station.DlsStationOnly {
+			memphisMetricsCounters.incSchemaValidationFailures(station.TenantName, station.Name)
+			return
+		}
+	}
+
 	message.Message.TimeSent = time.Now()
 	_, err = db.InsertSchemaverseDlsMsg(station.ID, 0, message.Producer.Name, []string{}, models.MessagePayload(message.Message), message.ValidationError, tenantName)
 	if err != nil {
 		serv.Errorf("[tenant: %v]handleSchemaverseDlsMsg: %v", tenantName, err.Error())
 		return
 	}
+	memphisMetricsCounters.incSchemaValidationFailures(station.TenantName, station.Name)
 }
 
 func (pmh PoisonMessagesHandler) GetDlsMsgsByStationLight(station models.Station) ([]models.LightDlsMessageResponse, []models.LightDlsMessageResponse, int, error) {
@@ -243,6 +280,7 @@ func (pmh PoisonMessagesHandler) GetDlsMessageDetailsById(messageId int, dlsType
 		UpdatedAt:       dlsMessage.UpdatedAt,
 		MessageType:     dlsMessage.MessageType,
 		ValidationError: dlsMessage.ValidationError,
+		RetryAttempts:   dlsMessage.RetryAttempts,
 	}
 
 	if station.IsNative {
@@ -320,6 +358,10 @@ func (pmh PoisonMessagesHandler) GetDlsMessageDetailsById(messageId int, dlsType
 		UpdatedAt:       dlsMsg.UpdatedAt,
 		PoisonedCgs:     poisonedCgs,
 		ValidationError: dlsMsg.ValidationError,
+		RetryAttempts:   dlsMsg.RetryAttempts,
+	}
+	if result.RetryAttempts == nil {
+		result.RetryAttempts = []models.DlsRetryAttempt{}
 	}
 
 	return result, nil
@@ -85,6 +85,48 @@ func (it IntegrationsHandler) CreateIntegration(c *gin.Context) {
 			return
 		}
 		integration = s3Integration
+	case "local_fs":
+		localFsIntegration, errorCode, err := it.handleCreateLocalFsIntegration(user.TenantName, body.Keys)
+		if err != nil {
+			if errorCode == 500 {
+				serv.Errorf("[tenant: %v][user: %v]CreateLocalFsIntegration at handleCreateLocalFsIntegration code 500: %v", user.TenantName, user.Username, err.Error())
+				message = "Server error"
+			} else {
+				serv.Warnf("[tenant: %v][user: %v]CreateLocalFsIntegration at handleCreateLocalFsIntegration: %v", user.TenantName, user.Username, err.Error())
+				message = err.Error()
+			}
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": message})
+			return
+		}
+		integration = localFsIntegration
+	case "webhook":
+		webhookIntegration, errorCode, err := it.handleCreateWebhookIntegration(user.TenantName, body)
+		if err != nil {
+			if errorCode == 500 {
+				serv.Errorf("[tenant: %v][user: %v]CreateWebhookIntegration at handleCreateWebhookIntegration code 500: %v", user.TenantName, user.Username, err.Error())
+				message = "Server error"
+			} else {
+				serv.Warnf("[tenant: %v][user: %v]CreateWebhookIntegration at handleCreateWebhookIntegration: %v", user.TenantName, user.Username, err.Error())
+				message = err.Error()
+			}
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": message})
+			return
+		}
+		integration = webhookIntegration
+	case "email":
+		emailIntegration, errorCode, err := it.handleCreateEmailIntegration(user.TenantName, body)
+		if err != nil {
+			if errorCode == 500 {
+				serv.Errorf("[tenant: %v][user: %v]CreateEmailIntegration at handleCreateEmailIntegration code 500: %v", user.TenantName, user.Username, err.Error())
+				message = "Server error"
+			} else {
+				serv.Warnf("[tenant: %v][user: %v]CreateEmailIntegration at handleCreateEmailIntegration: %v", user.TenantName, user.Username, err.Error())
+				message = err.Error()
+			}
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": message})
+			return
+		}
+		integration = emailIntegration
 	default:
 		serv.Warnf("[tenant: %v][user: %v]CreateIntegration: Unsupported integration type - %v", user.TenantName, user.Username, integrationType)
 		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": "Unsupported integration type - " + integrationType})
@@ -155,6 +197,48 @@ func (it IntegrationsHandler) UpdateIntegration(c *gin.Context) {
 			return
 		}
 		integration = s3Integration
+	case "local_fs":
+		localFsIntegration, errorCode, err := it.handleUpdateLocalFsIntegration(user.TenantName, body)
+		if err != nil {
+			if errorCode == 500 {
+				serv.Errorf("[tenant: %v]UpdateLocalFsIntegration at handleUpdateLocalFsIntegration code 500: %v", user.TenantName, err.Error())
+				message = "Server error"
+			} else {
+				serv.Warnf("[tenant: %v]UpdateLocalFsIntegration at handleUpdateLocalFsIntegration: %v", user.TenantName, err.Error())
+				message = err.Error()
+			}
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": message})
+			return
+		}
+		integration = localFsIntegration
+	case "webhook":
+		webhookIntegration, errorCode, err := it.handleUpdateWebhookIntegration(user.TenantName, body)
+		if err != nil {
+			if errorCode == 500 {
+				serv.Errorf("[tenant: %v]UpdateWebhookIntegration at handleUpdateWebhookIntegration code 500: %v", user.TenantName, err.Error())
+				message = "Server error"
+			} else {
+				serv.Warnf("[tenant: %v]UpdateWebhookIntegration at handleUpdateWebhookIntegration: %v", user.TenantName, err.Error())
+				message = err.Error()
+			}
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": message})
+			return
+		}
+		integration = webhookIntegration
+	case "email":
+		emailIntegration, errorCode, err := it.handleUpdateEmailIntegration(user.TenantName, body)
+		if err != nil {
+			if errorCode == 500 {
+				serv.Errorf("[tenant: %v]UpdateEmailIntegration at handleUpdateEmailIntegration code 500: %v", user.TenantName, err.Error())
+				message = "Server error"
+			} else {
+				serv.Warnf("[tenant: %v]UpdateEmailIntegration at handleUpdateEmailIntegration: %v", user.TenantName, err.Error())
+				message = err.Error()
+			}
+			c.AbortWithStatusJSON(errorCode, gin.H{"message": message})
+			return
+		}
+		integration = emailIntegration
 
 	default:
 		serv.Warnf("[tenant: %v]UpdateIntegration: Unsupported integration type - %v", user.TenantName, body.Name)
@@ -165,23 +249,57 @@ func (it IntegrationsHandler) UpdateIntegration(c *gin.Context) {
 	c.IndentedJSON(200, integration)
 }
 
-func createIntegrationsKeysAndProperties(integrationType, authToken string, channelID string, pmAlert bool, svfAlert bool, disconnectAlert bool, accessKey, secretKey, bucketName, region, url, forceS3PathStyle string) (map[string]string, map[string]bool) {
+// integrationKeysOpts holds the keys and alerts of every integration type, each type reads only the fields it uses
+type integrationKeysOpts struct {
+	authToken        string
+	channelID        string
+	pmAlert          bool
+	svfAlert         bool
+	disconnectAlert  bool
+	accessKey        string
+	secretKey        string
+	bucketName       string
+	region           string
+	url              string
+	forceS3PathStyle string
+	extraKeys        map[string]string
+}
+
+func createIntegrationsKeysAndProperties(integrationType string, opts integrationKeysOpts) (map[string]string, map[string]bool) {
 	keys := make(map[string]string)
 	properties := make(map[string]bool)
 	switch integrationType {
 	case "slack":
-		keys["auth_token"] = authToken
-		keys["channel_id"] = channelID
-		properties[PoisonMAlert] = pmAlert
-		properties[SchemaVAlert] = svfAlert
-		properties[DisconEAlert] = disconnectAlert
+		keys["auth_token"] = opts.authToken
+		keys["channel_id"] = opts.channelID
+		properties[PoisonMAlert] = opts.pmAlert
+		properties[SchemaVAlert] = opts.svfAlert
+		properties[DisconEAlert] = opts.disconnectAlert
 	case "s3":
-		keys["access_key"] = accessKey
-		keys["secret_key"] = secretKey
-		keys["bucket_name"] = bucketName
-		keys["s3_path_style"] = forceS3PathStyle
-		keys["region"] = region
-		keys["url"] = url
+		keys["access_key"] = opts.accessKey
+		keys["secret_key"] = opts.secretKey
+		keys["bucket_name"] = opts.bucketName
+		keys["s3_path_style"] = opts.forceS3PathStyle
+		keys["region"] = opts.region
+		keys["url"] = opts.url
+	case "local_fs":
+		keys["path"] = opts.extraKeys["path"]
+	case "webhook":
+		keys["url"] = opts.url
+		keys["secret_key"] = opts.secretKey
+		keys["headers"] = opts.extraKeys["headers"]
+		keys["max_retries"] = opts.extraKeys["max_retries"]
+		keys["retry_interval_ms"] = opts.extraKeys["retry_interval_ms"]
+		properties[PoisonMAlert] = opts.pmAlert
+		properties[SchemaVAlert] = opts.svfAlert
+		properties[DisconEAlert] = opts.disconnectAlert
+	case "email":
+		for k, v := range opts.extraKeys {
+			keys[k] = v
+		}
+		properties[PoisonMAlert] = opts.pmAlert
+		properties[SchemaVAlert] = opts.svfAlert
+		properties[DisconEAlert] = opts.disconnectAlert
 	}
 
 	return keys, properties
@@ -229,6 +347,14 @@ func (it IntegrationsHandler) GetIntegrationDetails(c *gin.Context) {
 		lastCharsSecretKey := integration.Keys["secret_key"][len(integration.Keys["secret_key"])-4:]
 		integration.Keys["secret_key"] = "****" + lastCharsSecretKey
 	}
+
+	if integration.Name == "webhook" {
+		integration.Keys["secret_key"] = hideWebhookSecret(integration.Keys["secret_key"])
+	}
+
+	if integration.Name == "email" {
+		integration.Keys["password"] = hideEmailPassword(integration.Keys["password"])
+	}
 	c.IndentedJSON(200, integration)
 }
 
@@ -256,6 +382,12 @@ func (it IntegrationsHandler) GetAllIntegrations(c *gin.Context) {
 			lastCharsSecretKey := integrations[i].Keys["secret_key"][len(integrations[i].Keys["secret_key"])-4:]
 			integrations[i].Keys["secret_key"] = "****" + lastCharsSecretKey
 		}
+		if integrations[i].Name == "webhook" {
+			integrations[i].Keys["secret_key"] = hideWebhookSecret(integrations[i].Keys["secret_key"])
+		}
+		if integrations[i].Name == "email" {
+			integrations[i].Keys["password"] = hideEmailPassword(integrations[i].Keys["password"])
+		}
 	}
 
 	shouldSendAnalytics, _ := shouldSendAnalytics()
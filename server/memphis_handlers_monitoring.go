@@ -471,6 +471,41 @@ func getFakeProdsAndConsForPreview() ([]map[string]interface{}, []map[string]int
 	return connectedProducers, disconnectedProducers, connectedCgs, disconnectedCgs
 }
 
+func (mh MonitoringHandler) GetCgsLag(c *gin.Context) {
+	consumersHandler := ConsumersHandler{S: mh.S}
+	var body models.GetCgsLagSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetCgsLag at getUserDetailsFromMiddleware: %v", err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	cgsLag, statusCode, err := consumersHandler.GetCgsLag(user.TenantName, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]GetCgsLag: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]GetCgsLag: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := make(map[string]interface{})
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-enter-cgs-lag")
+	}
+
+	c.IndentedJSON(200, cgsLag)
+}
+
 func (mh MonitoringHandler) GetStationOverviewData(c *gin.Context) {
 	stationsHandler := StationsHandler{S: mh.S}
 	producersHandler := ProducersHandler{S: mh.S}
@@ -605,7 +640,7 @@ func (mh MonitoringHandler) GetStationOverviewData(c *gin.Context) {
 	if tenantInetgrations, ok := IntegrationsConcurrentCache.Load(user.TenantName); !ok {
 		station.TieredStorageEnabled = false
 	} else {
-		_, ok = tenantInetgrations["s3"].(models.Integration)
+		ok = hasTier2StorageIntegration(tenantInetgrations)
 		if !ok {
 			station.TieredStorageEnabled = false
 		} else if station.TieredStorageEnabled {
@@ -666,6 +701,12 @@ func (mh MonitoringHandler) GetStationOverviewData(c *gin.Context) {
 			"total_dls_messages":            totalDlsAmount,
 			"tiered_storage_enabled":        station.TieredStorageEnabled,
 			"created_by_username":           station.CreatedByUsername,
+			"mirror":                        station.Mirror,
+			"sources":                       station.Sources,
+			"immutability":                  station.Immutability,
+			"retention_policy":              station.RetentionPolicy,
+			"dls_station":                   station.DlsStation,
+			"dls_station_only":              station.DlsStationOnly,
 		}
 	} else {
 		var emptyResponse struct{}
@@ -694,6 +735,12 @@ func (mh MonitoringHandler) GetStationOverviewData(c *gin.Context) {
 				"total_dls_messages":            totalDlsAmount,
 				"tiered_storage_enabled":        station.TieredStorageEnabled,
 				"created_by_username":           station.CreatedByUsername,
+				"mirror":                        station.Mirror,
+				"sources":                       station.Sources,
+				"immutability":                  station.Immutability,
+				"retention_policy":              station.RetentionPolicy,
+				"dls_station":                   station.DlsStation,
+				"dls_station_only":              station.DlsStationOnly,
 			}
 		} else {
 			response = gin.H{
@@ -719,6 +766,12 @@ func (mh MonitoringHandler) GetStationOverviewData(c *gin.Context) {
 				"total_dls_messages":            totalDlsAmount,
 				"tiered_storage_enabled":        station.TieredStorageEnabled,
 				"created_by_username":           station.CreatedByUsername,
+				"mirror":                        station.Mirror,
+				"sources":                       station.Sources,
+				"immutability":                  station.Immutability,
+				"retention_policy":              station.RetentionPolicy,
+				"dls_station":                   station.DlsStation,
+				"dls_station_only":              station.DlsStationOnly,
 			}
 		}
 	}
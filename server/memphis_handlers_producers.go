@@ -106,6 +106,12 @@ func (s *Server) createProducerDirectCommon(c *client, pName, pType, pConnection
 		}
 	}
 
+	if station.Mirror != nil {
+		errMsg := fmt.Sprintf("Station %v is a mirror of station %v and can not be produced to", pStationName.Ext(), station.Mirror.StationName)
+		serv.Warnf("[tenant: %v][user: %v]createProducerDirectCommon: %v", user.TenantName, user.Username, errMsg)
+		return false, false, errors.New(errMsg)
+	}
+
 	newProducer, err := db.InsertNewProducer(name, station.ID, producerType, pConnectionId, station.TenantName)
 	if err != nil {
 		serv.Warnf("[tenant: %v][user: %v]createProducerDirectCommon at InsertNewProducer: %v", user.TenantName, user.Username, err.Error())
@@ -0,0 +1,709 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchemaRegistryHandler serves the subset of the Confluent Schema Registry REST API used by serializers and converters,
+// subjects are mapped to schemas and versions to schema versions, schema ids are the ids of the schema versions
+type SchemaRegistryHandler struct{ S *Server }
+
+const (
+	schemaRegistrySubjectNotFound      = 40401
+	schemaRegistryVersionNotFound      = 40402
+	schemaRegistrySchemaNotFound       = 40403
+	schemaRegistryInvalidSchema        = 42201
+	schemaRegistryInvalidVersion       = 42202
+	schemaRegistryInvalidCompatibility = 42203
+	schemaRegistryIncompatibleSchema   = 409
+	schemaRegistryServerError          = 50001
+
+	schemaRegistryCompatibilityConfKey = "schema_registry_compatibility"
+)
+
+func abortWithSchemaRegistryError(c *gin.Context, statusCode, errorCode int, message string) {
+	c.AbortWithStatusJSON(statusCode, gin.H{"error_code": errorCode, "message": message})
+}
+
+// the registry defaults to avro when no schema type is given
+func fromSchemaRegistryType(schemaType string) string {
+	if schemaType == "" {
+		return "avro"
+	}
+	return strings.ToLower(schemaType)
+}
+
+// avro is the registry default so it is omitted from responses
+func toSchemaRegistryType(schemaType string) string {
+	if schemaType == "avro" {
+		return ""
+	}
+	return strings.ToUpper(schemaType)
+}
+
+func toSchemaRegistryVersion(schema models.Schema, version models.SchemaVersion) models.SchemaRegistrySubjectVersion {
+	return models.SchemaRegistrySubjectVersion{
+		Subject:    schema.Name,
+		ID:         version.ID,
+		Version:    version.VersionNumber,
+		SchemaType: toSchemaRegistryType(schema.Type),
+		Schema:     version.SchemaContent,
+	}
+}
+
+// findSchemaRegistryVersion resolves a version number, latest and -1 stand for the highest version
+func findSchemaRegistryVersion(versions []models.SchemaVersion, version string) (models.SchemaVersion, bool, error) {
+	if version == "latest" || version == "-1" {
+		if len(versions) == 0 {
+			return models.SchemaVersion{}, false, nil
+		}
+		latest := versions[0]
+		for _, v := range versions {
+			if v.VersionNumber > latest.VersionNumber {
+				latest = v
+			}
+		}
+		return latest, true, nil
+	}
+	versionNumber, err := strconv.Atoi(version)
+	if err != nil || versionNumber < 1 {
+		return models.SchemaVersion{}, false, fmt.Errorf("the specified version '%v' is not a valid version id, allowed values are between [1, 2^31-1] and the string \"latest\"", version)
+	}
+	for _, v := range versions {
+		if v.VersionNumber == versionNumber {
+			return v, true, nil
+		}
+	}
+	return models.SchemaVersion{}, false, nil
+}
+
+// sameSchemaVersion compares avro schemas by their canonical form and everything else by content
+func sameSchemaVersion(schemaType string, a, b models.SchemaVersion) bool {
+	if schemaType == "avro" {
+		return a.Descriptor == b.Descriptor
+	}
+	if strings.TrimSpace(a.SchemaContent) != strings.TrimSpace(b.SchemaContent) || len(a.SchemaFiles) != len(b.SchemaFiles) {
+		return false
+	}
+	for name, content := range a.SchemaFiles {
+		if b.SchemaFiles[name] != content {
+			return false
+		}
+	}
+	return true
+}
+
+// newSchemaRegistryVersion validates a registry schema and builds the schema version it maps to,
+// protobuf references are stored along with the schema as a multi-file bundle
+func newSchemaRegistryVersion(subject, schemaType string, versionNumber int, body models.SchemaRegistrySchema, tenantName string) (models.SchemaVersion, error) {
+	newVersion := models.SchemaVersion{VersionNumber: versionNumber, SchemaContent: body.Schema}
+	if len(body.References) > 0 {
+		if schemaType != "protobuf" {
+			return models.SchemaVersion{}, errors.New("schema references are supported for protobuf schemas only")
+		}
+		newVersion.Entrypoint = subject + ".proto"
+		newVersion.SchemaFiles = map[string]string{newVersion.Entrypoint: body.Schema}
+		for _, reference := range body.References {
+			exist, refSchema, err := db.GetSchemaByName(reference.Subject, tenantName)
+			if err != nil {
+				return models.SchemaVersion{}, err
+			}
+			if !exist {
+				return models.SchemaVersion{}, fmt.Errorf("referenced subject %v does not exist", reference.Subject)
+			}
+			refVersions, err := getSchemaVersionsBySchemaId(refSchema.ID)
+			if err != nil {
+				return models.SchemaVersion{}, err
+			}
+			refVersion, found, err := findSchemaRegistryVersion(refVersions, strconv.Itoa(reference.Version))
+			if err != nil {
+				return models.SchemaVersion{}, err
+			}
+			if !found {
+				return models.SchemaVersion{}, fmt.Errorf("version %v of referenced subject %v does not exist", reference.Version, reference.Subject)
+			}
+			for name, content := range refVersion.SchemaFiles {
+				if _, ok := newVersion.SchemaFiles[name]; !ok {
+					newVersion.SchemaFiles[name] = content
+				}
+			}
+			newVersion.SchemaFiles[reference.Name] = refVersion.SchemaContent
+		}
+	}
+
+	err := validateSchemaVersionContent(newVersion.SchemaContent, schemaType, newVersion.SchemaFiles, newVersion.Entrypoint)
+	if err != nil {
+		return models.SchemaVersion{}, err
+	}
+	if schemaType == "protobuf" {
+		newVersion.MessageStructName, err = getProtoMessageStructName(newVersion.SchemaContent)
+		if err != nil {
+			return models.SchemaVersion{}, err
+		}
+	}
+	if len(newVersion.SchemaFiles) > 0 {
+		newVersion.Descriptor, err = generateProtobufBundleDescriptor(newVersion.SchemaFiles, newVersion.Entrypoint, tenantName)
+	} else if schemaType == "protobuf" || schemaType == "avro" {
+		newVersion.Descriptor, err = generateSchemaDescriptor(subject, versionNumber, newVersion.SchemaContent, schemaType, tenantName)
+	}
+	if err != nil {
+		return models.SchemaVersion{}, err
+	}
+	return newVersion, nil
+}
+
+// validateSchemaRegistrySubject rejects subjects memphis can not store as is, subjects are case sensitive
+// in the registry API while schema names are lowercase so other subjects are not rewritten to avoid collisions
+func validateSchemaRegistrySubject(subject string) error {
+	if subject != strings.ToLower(subject) {
+		return fmt.Errorf("subject %v has to be lowercase", subject)
+	}
+	return validateSchemaName(subject)
+}
+
+// getSubject fetches the schema of the subject in the path, aborting the request when it can not be served
+func (srh SchemaRegistryHandler) getSubject(c *gin.Context, user models.User, funcName string) (models.Schema, bool) {
+	subject := c.Param("subject")
+	exist, schema, err := db.GetSchemaByName(subject, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]%v at GetSchemaByName: Subject %v: %v", user.TenantName, user.Username, funcName, subject, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return models.Schema{}, false
+	}
+	if !exist {
+		abortWithSchemaRegistryError(c, 404, schemaRegistrySubjectNotFound, fmt.Sprintf("Subject '%v' not found.", subject))
+		return models.Schema{}, false
+	}
+	return schema, true
+}
+
+// getSubjectVersion fetches the version in the path out of the subject versions, aborting the request when it can not be served
+func (srh SchemaRegistryHandler) getSubjectVersion(c *gin.Context, user models.User, schema models.Schema, funcName string) (models.SchemaVersion, bool) {
+	versions, err := getSchemaVersionsBySchemaId(schema.ID)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]%v at getSchemaVersionsBySchemaId: Subject %v: %v", user.TenantName, user.Username, funcName, schema.Name, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return models.SchemaVersion{}, false
+	}
+	version, found, err := findSchemaRegistryVersion(versions, c.Param("version"))
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidVersion, err.Error())
+		return models.SchemaVersion{}, false
+	}
+	if !found {
+		abortWithSchemaRegistryError(c, 404, schemaRegistryVersionNotFound, fmt.Sprintf("Version %v not found.", c.Param("version")))
+		return models.SchemaVersion{}, false
+	}
+	return version, true
+}
+
+func (srh SchemaRegistryHandler) GetSchemaTypes(c *gin.Context) {
+	c.IndentedJSON(200, []string{"AVRO", "JSON", "PROTOBUF"})
+}
+
+func (srh SchemaRegistryHandler) GetSubjects(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetSubjects at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schemas, err := db.GetAllSchemasDetails(user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]GetSubjects at GetAllSchemasDetails: %v", user.TenantName, user.Username, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	subjects := []string{}
+	for _, schema := range schemas {
+		subjects = append(subjects, schema.Name)
+	}
+	sort.Strings(subjects)
+	c.IndentedJSON(200, subjects)
+}
+
+func (srh SchemaRegistryHandler) GetSubjectVersions(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetSubjectVersions at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "GetSubjectVersions")
+	if !ok {
+		return
+	}
+	versions, err := getSchemaVersionsBySchemaId(schema.ID)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]GetSubjectVersions at getSchemaVersionsBySchemaId: Subject %v: %v", user.TenantName, user.Username, schema.Name, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	versionNumbers := []int{}
+	for _, version := range versions {
+		versionNumbers = append(versionNumbers, version.VersionNumber)
+	}
+	sort.Ints(versionNumbers)
+	c.IndentedJSON(200, versionNumbers)
+}
+
+func (srh SchemaRegistryHandler) GetSubjectVersion(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetSubjectVersion at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "GetSubjectVersion")
+	if !ok {
+		return
+	}
+	version, ok := srh.getSubjectVersion(c, user, schema, "GetSubjectVersion")
+	if !ok {
+		return
+	}
+	c.IndentedJSON(200, toSchemaRegistryVersion(schema, version))
+}
+
+func (srh SchemaRegistryHandler) GetSubjectVersionSchema(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetSubjectVersionSchema at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "GetSubjectVersionSchema")
+	if !ok {
+		return
+	}
+	version, ok := srh.getSubjectVersion(c, user, schema, "GetSubjectVersionSchema")
+	if !ok {
+		return
+	}
+	c.String(200, version.SchemaContent)
+}
+
+func (srh SchemaRegistryHandler) RegisterSchema(c *gin.Context) {
+	var body models.SchemaRegistrySchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, "Invalid schema")
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("RegisterSchema at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	tenantName := user.TenantName
+	subject := c.Param("subject")
+	err = validateSchemaRegistrySubject(subject)
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, err.Error())
+		return
+	}
+	schemaType := fromSchemaRegistryType(body.SchemaType)
+	err = validateSchemaType(schemaType)
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, err.Error())
+		return
+	}
+
+	exist, schema, err := db.GetSchemaByName(subject, tenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]RegisterSchema at GetSchemaByName: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	versions := []models.SchemaVersion{}
+	if exist {
+		if schema.Type != schemaType {
+			abortWithSchemaRegistryError(c, 409, schemaRegistryIncompatibleSchema, fmt.Sprintf("Subject %v holds %v schemas", subject, strings.ToUpper(schema.Type)))
+			return
+		}
+		versions, err = getSchemaVersionsBySchemaId(schema.ID)
+		if err != nil {
+			serv.Errorf("[tenant: %v][user: %v]RegisterSchema at getSchemaVersionsBySchemaId: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+			abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+			return
+		}
+	}
+
+	newVersion, err := newSchemaRegistryVersion(subject, schemaType, len(versions)+1, body, tenantName)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]RegisterSchema at newSchemaRegistryVersion: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, err.Error())
+		return
+	}
+	for _, version := range versions {
+		if sameSchemaVersion(schemaType, version, newVersion) {
+			c.IndentedJSON(200, gin.H{"id": version.ID})
+			return
+		}
+	}
+
+	if exist {
+		violations, err := getSchemaVersionCompatibilityViolations(schema, newVersion)
+		if err != nil {
+			serv.Warnf("[tenant: %v][user: %v]RegisterSchema at getSchemaVersionCompatibilityViolations: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+			abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, err.Error())
+			return
+		}
+		if len(violations) > 0 {
+			errMsg := fmt.Sprintf("Schema being registered is incompatible with an earlier schema for subject %v: %v", subject, formatCompatibilityViolations(violations))
+			abortWithSchemaRegistryError(c, 409, schemaRegistryIncompatibleSchema, errMsg)
+			return
+		}
+	} else {
+		compatibilityMode, err := getSchemaRegistryCompatibility(tenantName)
+		if err != nil {
+			serv.Errorf("[tenant: %v][user: %v]RegisterSchema at getSchemaRegistryCompatibility: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+			abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+			return
+		}
+		var rowsUpdated int64
+		schema, rowsUpdated, err = db.InsertNewSchema(subject, schemaType, user.Username, compatibilityMode, tenantName)
+		if err != nil {
+			serv.Errorf("[tenant: %v][user: %v]RegisterSchema at InsertNewSchema: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+			abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+			return
+		}
+		if rowsUpdated == 0 {
+			abortWithSchemaRegistryError(c, 409, schemaRegistryIncompatibleSchema, fmt.Sprintf("Subject %v has been registered concurrently, please retry", subject))
+			return
+		}
+	}
+
+	// like in a confluent registry the latest registered version is the one producers are validated against
+	insertedVersion, _, err := db.InsertNewSchemaVersion(newVersion.VersionNumber, user.ID, user.Username, newVersion.SchemaContent, schema.ID, newVersion.MessageStructName, newVersion.Descriptor, !exist, tenantName, newVersion.SchemaFiles, newVersion.Entrypoint)
+	if err != nil && err.Error() == "version already exists" {
+		// the version number is unique per schema, another version was registered since the versions were read
+		abortWithSchemaRegistryError(c, 409, schemaRegistryIncompatibleSchema, fmt.Sprintf("Version %v of subject %v has been registered concurrently, please retry", newVersion.VersionNumber, subject))
+		return
+	}
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]RegisterSchema at InsertNewSchemaVersion: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	if exist {
+		err = db.UpdateSchemaActiveVersion(schema.ID, insertedVersion.VersionNumber)
+		if err != nil {
+			serv.Errorf("[tenant: %v][user: %v]RegisterSchema at UpdateSchemaActiveVersion: Subject %v: %v", user.TenantName, user.Username, subject, err.Error())
+			abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+			return
+		}
+		srh.S.reloadSchemaEnforcement(tenantName)
+	}
+	serv.Noticef("[tenant: %v][user: %v]Version %v of schema %v has been registered through the schema registry API", user.TenantName, user.Username, insertedVersion.VersionNumber, subject)
+
+	c.IndentedJSON(200, gin.H{"id": insertedVersion.ID})
+}
+
+func (srh SchemaRegistryHandler) LookupSchema(c *gin.Context) {
+	var body models.SchemaRegistrySchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, "Invalid schema")
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("LookupSchema at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "LookupSchema")
+	if !ok {
+		return
+	}
+	versions, err := getSchemaVersionsBySchemaId(schema.ID)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]LookupSchema at getSchemaVersionsBySchemaId: Subject %v: %v", user.TenantName, user.Username, schema.Name, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	lookupVersion, err := newSchemaRegistryVersion(schema.Name, schema.Type, len(versions)+1, body, user.TenantName)
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, err.Error())
+		return
+	}
+	for _, version := range versions {
+		if sameSchemaVersion(schema.Type, version, lookupVersion) {
+			c.IndentedJSON(200, toSchemaRegistryVersion(schema, version))
+			return
+		}
+	}
+	abortWithSchemaRegistryError(c, 404, schemaRegistrySchemaNotFound, "Schema not found")
+}
+
+func (srh SchemaRegistryHandler) DeleteSubject(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("DeleteSubject at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "DeleteSubject")
+	if !ok {
+		return
+	}
+	versions, err := getSchemaVersionsBySchemaId(schema.ID)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]DeleteSubject at getSchemaVersionsBySchemaId: Subject %v: %v", user.TenantName, user.Username, schema.Name, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+
+	DeleteTagsFromSchema(schema.ID)
+	err = deleteSchemaFromStations(srh.S, schema.Name, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]DeleteSubject at deleteSchemaFromStations: Subject %v: %v", user.TenantName, user.Username, schema.Name, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	err = db.FindAndDeleteSchema([]int{schema.ID})
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]DeleteSubject at FindAndDeleteSchema: Subject %v: %v", user.TenantName, user.Username, schema.Name, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	srh.S.reloadSchemaEnforcement(user.TenantName)
+	serv.Noticef("[tenant: %v][user: %v]Schema %v has been deleted through the schema registry API", user.TenantName, user.Username, schema.Name)
+
+	versionNumbers := []int{}
+	for _, version := range versions {
+		versionNumbers = append(versionNumbers, version.VersionNumber)
+	}
+	sort.Ints(versionNumbers)
+	c.IndentedJSON(200, versionNumbers)
+}
+
+func (srh SchemaRegistryHandler) GetSchemaById(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetSchemaById at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, version, ok := srh.getSchemaVersionById(c, user, "GetSchemaById")
+	if !ok {
+		return
+	}
+	res := gin.H{"schema": version.SchemaContent}
+	if schemaType := toSchemaRegistryType(schema.Type); schemaType != "" {
+		res["schemaType"] = schemaType
+	}
+	c.IndentedJSON(200, res)
+}
+
+func (srh SchemaRegistryHandler) GetSchemaIdVersions(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetSchemaIdVersions at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, version, ok := srh.getSchemaVersionById(c, user, "GetSchemaIdVersions")
+	if !ok {
+		return
+	}
+	c.IndentedJSON(200, []gin.H{{"subject": schema.Name, "version": version.VersionNumber}})
+}
+
+func (srh SchemaRegistryHandler) getSchemaVersionById(c *gin.Context, user models.User, funcName string) (models.Schema, models.SchemaVersion, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithSchemaRegistryError(c, 404, schemaRegistrySchemaNotFound, "Schema not found")
+		return models.Schema{}, models.SchemaVersion{}, false
+	}
+	exist, version, err := db.GetSchemaVersionByID(id, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]%v at GetSchemaVersionByID: Id %v: %v", user.TenantName, user.Username, funcName, id, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return models.Schema{}, models.SchemaVersion{}, false
+	}
+	if !exist {
+		abortWithSchemaRegistryError(c, 404, schemaRegistrySchemaNotFound, "Schema not found")
+		return models.Schema{}, models.SchemaVersion{}, false
+	}
+	exist, schema, err := db.GetSchemaByID(version.SchemaId, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]%v at GetSchemaByID: Id %v: %v", user.TenantName, user.Username, funcName, id, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return models.Schema{}, models.SchemaVersion{}, false
+	}
+	if !exist {
+		abortWithSchemaRegistryError(c, 404, schemaRegistrySchemaNotFound, "Schema not found")
+		return models.Schema{}, models.SchemaVersion{}, false
+	}
+	return schema, version, true
+}
+
+// getSchemaRegistryCompatibility returns the tenant's global compatibility level which is given to new subjects,
+// like the confluent schema registry it defaults to backward
+func getSchemaRegistryCompatibility(tenantName string) (string, error) {
+	exist, configuration, err := db.GetConfiguration(schemaRegistryCompatibilityConfKey, tenantName)
+	if err != nil {
+		return _EMPTY_, err
+	}
+	if !exist {
+		return compatibilityModeBackward, nil
+	}
+	return configuration.Value, nil
+}
+
+// GetConfig returns the compatibility level given to subjects created through the registry
+func (srh SchemaRegistryHandler) GetConfig(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetConfig at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	compatibilityMode, err := getSchemaRegistryCompatibility(user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]GetConfig at getSchemaRegistryCompatibility: %v", user.TenantName, user.Username, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	c.IndentedJSON(200, gin.H{"compatibilityLevel": strings.ToUpper(compatibilityMode)})
+}
+
+func (srh SchemaRegistryHandler) UpdateConfig(c *gin.Context) {
+	var body models.SchemaRegistryCompatibility
+	if err := c.ShouldBindJSON(&body); err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidCompatibility, "Invalid compatibility level")
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("UpdateConfig at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	compatibilityMode := strings.ToLower(body.Compatibility)
+	err = validateCompatibilityMode(compatibilityMode)
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidCompatibility, err.Error())
+		return
+	}
+	err = db.UpsertConfiguration(schemaRegistryCompatibilityConfKey, compatibilityMode, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]UpdateConfig at UpsertConfiguration: %v", user.TenantName, user.Username, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]Global schema registry compatibility mode has been set to %v", user.TenantName, user.Username, compatibilityMode)
+
+	c.IndentedJSON(200, gin.H{"compatibility": strings.ToUpper(compatibilityMode)})
+}
+
+func (srh SchemaRegistryHandler) GetSubjectConfig(c *gin.Context) {
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetSubjectConfig at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "GetSubjectConfig")
+	if !ok {
+		return
+	}
+	c.IndentedJSON(200, gin.H{"compatibilityLevel": strings.ToUpper(schema.CompatibilityMode)})
+}
+
+func (srh SchemaRegistryHandler) UpdateSubjectConfig(c *gin.Context) {
+	var body models.SchemaRegistryCompatibility
+	if err := c.ShouldBindJSON(&body); err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidCompatibility, "Invalid compatibility level")
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("UpdateSubjectConfig at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "UpdateSubjectConfig")
+	if !ok {
+		return
+	}
+	compatibilityMode := strings.ToLower(body.Compatibility)
+	err = validateCompatibilityMode(compatibilityMode)
+	if err == nil && compatibilityMode != compatibilityModeNone {
+		_, err = getSchemaCompatibilityChecker(schema.Type)
+	}
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidCompatibility, err.Error())
+		return
+	}
+	err = db.UpdateSchemaCompatibilityMode(schema.ID, compatibilityMode)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]UpdateSubjectConfig at UpdateSchemaCompatibilityMode: Subject %v: %v", user.TenantName, user.Username, schema.Name, err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]Compatibility mode of schema %v has been set to %v", user.TenantName, user.Username, schema.Name, compatibilityMode)
+
+	c.IndentedJSON(200, gin.H{"compatibility": strings.ToUpper(compatibilityMode)})
+}
+
+func (srh SchemaRegistryHandler) CheckCompatibility(c *gin.Context) {
+	var body models.SchemaRegistrySchema
+	if err := c.ShouldBindJSON(&body); err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, "Invalid schema")
+		return
+	}
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("CheckCompatibility at getUserDetailsFromMiddleware: %v", err.Error())
+		abortWithSchemaRegistryError(c, 500, schemaRegistryServerError, "Server error")
+		return
+	}
+	schema, ok := srh.getSubject(c, user, "CheckCompatibility")
+	if !ok {
+		return
+	}
+	version, ok := srh.getSubjectVersion(c, user, schema, "CheckCompatibility")
+	if !ok {
+		return
+	}
+	newVersion, err := newSchemaRegistryVersion(schema.Name, schema.Type, version.VersionNumber+1, body, user.TenantName)
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, err.Error())
+		return
+	}
+	violations, err := checkSchemaCompatibility(schema.Type, schema.CompatibilityMode, newVersion, []models.SchemaVersion{version})
+	if err != nil {
+		abortWithSchemaRegistryError(c, 422, schemaRegistryInvalidSchema, err.Error())
+		return
+	}
+	if len(violations) > 0 {
+		messages := []string{}
+		for _, violation := range violations {
+			messages = append(messages, formatCompatibilityViolations([]models.SchemaCompatibilityViolation{violation}))
+		}
+		c.IndentedJSON(200, gin.H{"is_compatible": false, "messages": messages})
+		return
+	}
+	c.IndentedJSON(200, gin.H{"is_compatible": true})
+}
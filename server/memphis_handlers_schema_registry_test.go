@@ -0,0 +1,75 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+)
+
+func TestFindSchemaRegistryVersion(t *testing.T) {
+	versions := []models.SchemaVersion{{ID: 7, VersionNumber: 2}, {ID: 9, VersionNumber: 3}, {ID: 4, VersionNumber: 1}}
+
+	for _, latest := range []string{"latest", "-1"} {
+		version, found, err := findSchemaRegistryVersion(versions, latest)
+		if err != nil || !found || version.ID != 9 {
+			t.Fatalf("Expected %v to resolve to the highest version, got %v %v %v", latest, version, found, err)
+		}
+	}
+	version, found, err := findSchemaRegistryVersion(versions, "2")
+	if err != nil || !found || version.ID != 7 {
+		t.Fatalf("Expected version 2 to be found, got %v %v %v", version, found, err)
+	}
+	_, found, err = findSchemaRegistryVersion(versions, "5")
+	if err != nil || found {
+		t.Fatalf("Expected version 5 not to be found")
+	}
+	if _, _, err = findSchemaRegistryVersion(versions, "first"); err == nil {
+		t.Fatalf("Expected an invalid version to fail")
+	}
+}
+
+func TestSchemaRegistryTypes(t *testing.T) {
+	if fromSchemaRegistryType("") != "avro" || fromSchemaRegistryType("PROTOBUF") != "protobuf" {
+		t.Fatalf("Unexpected schema type mapping")
+	}
+	if toSchemaRegistryType("avro") != "" || toSchemaRegistryType("json") != "JSON" {
+		t.Fatalf("Unexpected schema type mapping")
+	}
+}
+
+func TestSameSchemaVersion(t *testing.T) {
+	a := models.SchemaVersion{SchemaContent: `{"type": "string"}`, Descriptor: `"string"`}
+	b := models.SchemaVersion{SchemaContent: `"string"`, Descriptor: `"string"`}
+	if !sameSchemaVersion("avro", a, b) {
+		t.Fatalf("Expected avro schemas to be compared by their canonical form")
+	}
+	if sameSchemaVersion("json", a, b) {
+		t.Fatalf("Expected json schemas to be compared by content")
+	}
+	c := models.SchemaVersion{SchemaContent: "x", SchemaFiles: map[string]string{"a.proto": "x", "b.proto": "y"}}
+	d := models.SchemaVersion{SchemaContent: "x", SchemaFiles: map[string]string{"a.proto": "x", "b.proto": "z"}}
+	if sameSchemaVersion("protobuf", c, d) {
+		t.Fatalf("Expected schemas with different referenced files to differ")
+	}
+}
+
+func TestValidateSchemaRegistrySubject(t *testing.T) {
+	if err := validateSchemaRegistrySubject("orders-value"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, subject := range []string{"Orders-value", "orders value", ""} {
+		if err := validateSchemaRegistrySubject(subject); err == nil {
+			t.Fatalf("Expected subject %q to be invalid", subject)
+		}
+	}
+}
@@ -23,14 +23,18 @@ import (
 	"memphis/models"
 	"memphis/utils"
 	"os"
-	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/graph-gophers/graphql-go"
+	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/protoparse"
 	"github.com/santhosh-tekuri/jsonschema/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 type SchemasHandler struct{ S *Server }
@@ -45,13 +49,79 @@ var (
 	ErrNoSchema = errors.New("no schemas found")
 )
 
-func validateProtobufContent(schemaContent string) error {
+// protobufFilesAccessor serves proto files from memory, imports named <schema name>.proto are resolved against the protobuf schemas of the tenant
+// and unknown files fall back to the well-known types bundled with the parser
+func protobufFilesAccessor(files map[string]string, tenantName string) protoparse.FileAccessor {
+	var lock sync.Mutex
+	resolved := make(map[string]string)
+	return func(filename string) (io.ReadCloser, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		content, ok := files[filename]
+		if !ok {
+			content, ok = resolved[filename]
+		}
+		if !ok && tenantName != "" && strings.HasSuffix(filename, ".proto") && !strings.Contains(filename, "/") {
+			schemaFiles, entrypoint, err := getProtobufSchemaFiles(strings.TrimSuffix(filename, ".proto"), tenantName)
+			if err != nil {
+				return nil, err
+			}
+			if len(schemaFiles) > 0 {
+				for name, fileContent := range schemaFiles {
+					if _, exist := resolved[name]; !exist {
+						resolved[name] = fileContent
+					}
+				}
+				content = schemaFiles[entrypoint]
+				resolved[filename] = content
+				ok = true
+			}
+		}
+		if !ok {
+			return nil, &os.PathError{Op: "open", Path: filename, Err: os.ErrNotExist}
+		}
+		return io.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+// getProtobufSchemaFiles returns the files and entrypoint of the active version of a protobuf schema, nil files means there is no such schema
+func getProtobufSchemaFiles(schemaName, tenantName string) (map[string]string, string, error) {
+	exist, schema, err := db.GetSchemaByName(schemaName, tenantName)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exist || schema.Type != "protobuf" {
+		return nil, "", nil
+	}
+	activeVersion, err := getActiveVersionBySchemaId(schema.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(activeVersion.SchemaFiles) > 0 {
+		return activeVersion.SchemaFiles, activeVersion.Entrypoint, nil
+	}
+	filename := schemaName + ".proto"
+	return map[string]string{filename: activeVersion.SchemaContent}, filename, nil
+}
+
+func parseProtobufFiles(files map[string]string, tenantName string, filenames ...string) ([]*desc.FileDescriptor, error) {
 	parser := protoparse.Parser{
-		Accessor: func(filename string) (io.ReadCloser, error) {
-			return io.NopCloser(strings.NewReader(schemaContent)), nil
-		},
+		Accessor: protobufFilesAccessor(files, tenantName),
+	}
+	return parser.ParseFiles(filenames...)
+}
+
+// validateProtobufFilesSyntax parses the files on their own, imports are checked once the descriptor is generated
+func validateProtobufFilesSyntax(files map[string]string, filenames ...string) error {
+	parser := protoparse.Parser{
+		Accessor: protobufFilesAccessor(files, ""),
 	}
-	_, err := parser.ParseFiles("")
+	_, err := parser.ParseFilesButDoNotLink(filenames...)
+	return err
+}
+
+func validateProtobufContent(schemaContent string) error {
+	err := validateProtobufFilesSyntax(map[string]string{"schema.proto": schemaContent}, "schema.proto")
 	if err != nil {
 		return fmt.Errorf("your Proto file is invalid: %v", err.Error())
 	}
@@ -59,6 +129,31 @@ func validateProtobufContent(schemaContent string) error {
 	return nil
 }
 
+func validateProtobufSchemaFiles(schemaFiles map[string]string, entrypoint string) error {
+	if entrypoint == "" {
+		return errors.New("entrypoint is required when providing multiple schema files")
+	}
+	if _, ok := schemaFiles[entrypoint]; !ok {
+		return fmt.Errorf("entrypoint %v is not one of the schema files", entrypoint)
+	}
+	filenames := []string{}
+	for filename, content := range schemaFiles {
+		if !strings.HasSuffix(filename, ".proto") || strings.HasPrefix(filename, "/") {
+			return fmt.Errorf("invalid file name %v, files have to be relative .proto paths", filename)
+		}
+		if len(content) == 0 {
+			return fmt.Errorf("file %v is empty", filename)
+		}
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	err := validateProtobufFilesSyntax(schemaFiles, filenames...)
+	if err != nil {
+		return fmt.Errorf("your Proto files are invalid: %v", err.Error())
+	}
+	return nil
+}
+
 func validateJsonSchemaContent(schemaContent string) error {
 	_, err := jsonschema.CompileString("test", schemaContent)
 	if err != nil {
@@ -76,35 +171,44 @@ func validateGraphqlSchemaContent(schemaContent string) error {
 	return nil
 }
 
-func generateProtobufDescriptor(schemaName string, schemaVersionNum int, schemaContent string) ([]byte, error) {
+func generateProtobufDescriptor(schemaName string, schemaVersionNum int, schemaContent, tenantName string) ([]byte, error) {
 	filename := fmt.Sprintf("%v_%v.proto", schemaName, schemaVersionNum)
-	descFilename := fmt.Sprintf("%v_%v_desc", schemaName, schemaVersionNum)
-	err := os.WriteFile(filename, []byte(schemaContent), 0644)
-	if err != nil {
-		return nil, err
-	}
+	return generateProtobufFilesDescriptor(map[string]string{filename: schemaContent}, filename, tenantName)
+}
 
-	protoCmd := "protoc"
-	args := []string{"--descriptor_set_out=" + descFilename, filename}
-	cmd := exec.Command(protoCmd, args...)
-	err = cmd.Run()
+func generateProtobufBundleDescriptor(schemaFiles map[string]string, entrypoint, tenantName string) (string, error) {
+	descriptor, err := generateProtobufFilesDescriptor(schemaFiles, entrypoint, tenantName)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	return base64.StdEncoding.EncodeToString(descriptor), nil
+}
 
-	descContent, err := os.ReadFile(descFilename)
+// generateProtobufFilesDescriptor compiles the entrypoint in-process and returns a FileDescriptorSet holding it along with all of its imports, dependencies first
+func generateProtobufFilesDescriptor(files map[string]string, entrypoint, tenantName string) ([]byte, error) {
+	fds, err := parseProtobufFiles(files, tenantName, entrypoint)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, tmpFile := range []string{filename, descFilename} {
-		err = os.Remove(tmpFile)
-		if err != nil {
-			return nil, err
+	descriptorSet := &descriptorpb.FileDescriptorSet{}
+	added := make(map[string]bool)
+	var addFile func(fd *desc.FileDescriptor)
+	addFile = func(fd *desc.FileDescriptor) {
+		if added[fd.GetName()] {
+			return
+		}
+		added[fd.GetName()] = true
+		for _, dep := range fd.GetDependencies() {
+			addFile(dep)
 		}
+		descriptorSet.File = append(descriptorSet.File, fd.AsFileDescriptorProto())
+	}
+	for _, fd := range fds {
+		addFile(fd)
 	}
 
-	return descContent, nil
+	return proto.Marshal(descriptorSet)
 }
 
 func validateSchemaName(schemaName string) error {
@@ -114,13 +218,9 @@ func validateSchemaName(schemaName string) error {
 func validateSchemaType(schemaType string) error {
 	invalidTypeErrStr := "unsupported schema type"
 	invalidTypeErr := errors.New(invalidTypeErrStr)
-	invalidSupportTypeErrStr := "avro is not supported at this time"
-	invalidSupportTypeErr := errors.New(invalidSupportTypeErrStr)
 
-	if schemaType == "protobuf" || schemaType == "json" || schemaType == "graphql" {
+	if schemaType == "protobuf" || schemaType == "json" || schemaType == "graphql" || schemaType == "avro" {
 		return nil
-	} else if schemaType == "avro" {
-		return invalidSupportTypeErr
 	} else {
 		return invalidTypeErr
 	}
@@ -148,21 +248,39 @@ func validateSchemaContent(schemaContent, schemaType string) error {
 			return err
 		}
 	case "avro":
-		break
+		err := validateAvroContent(schemaContent)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func generateSchemaDescriptor(schemaName string, schemaVersionNum int, schemaContent, schemaType string) (string, error) {
+// validateSchemaVersionContent validates either the schema content or, for multi-file protobuf schemas, the bundle of files
+func validateSchemaVersionContent(schemaContent, schemaType string, schemaFiles map[string]string, entrypoint string) error {
+	if len(schemaFiles) == 0 {
+		return validateSchemaContent(schemaContent, schemaType)
+	}
+	if schemaType != "protobuf" {
+		return errors.New("multiple schema files are supported for protobuf schemas only")
+	}
+	return validateProtobufSchemaFiles(schemaFiles, entrypoint)
+}
+
+func generateSchemaDescriptor(schemaName string, schemaVersionNum int, schemaContent, schemaType, tenantName string) (string, error) {
 	if len(schemaContent) == 0 {
 		return "", errors.New("attempt to generate schema descriptor with empty schema")
 	}
 
+	if schemaType == "avro" {
+		return generateAvroDescriptor(schemaContent)
+	}
+
 	if schemaType != "protobuf" {
-		return "", errors.New("descriptor generation with schema type: " + schemaType + ", while protobuf is expected")
+		return "", errors.New("descriptor generation with schema type: " + schemaType + ", while protobuf or avro is expected")
 	}
 
-	descriptor, err := generateProtobufDescriptor(schemaName, schemaVersionNum, schemaContent)
+	descriptor, err := generateProtobufDescriptor(schemaName, schemaVersionNum, schemaContent, tenantName)
 	if err != nil {
 		return "", err
 	}
@@ -217,6 +335,7 @@ func (s *Server) updateStationProducersOfSchemaChange(tenantName string, sn Stat
 		return
 	}
 	s.sendInternalAccountMsg(account, subject, msg)
+	s.reloadSchemaEnforcement(tenantName, sn)
 }
 
 func getSchemaVersionsBySchemaId(id int) ([]models.SchemaVersion, error) {
@@ -235,6 +354,17 @@ func getActiveVersionBySchemaId(id int) (models.SchemaVersion, error) {
 	return schemaVersion, nil
 }
 
+func getSchemaVersionCompatibilityViolations(schema models.Schema, newVersion models.SchemaVersion) ([]models.SchemaCompatibilityViolation, error) {
+	if schema.CompatibilityMode == "" || schema.CompatibilityMode == compatibilityModeNone {
+		return []models.SchemaCompatibilityViolation{}, nil
+	}
+	prevVersions, err := getSchemaVersionsBySchemaId(schema.ID)
+	if err != nil {
+		return []models.SchemaCompatibilityViolation{}, err
+	}
+	return checkSchemaCompatibility(schema.Type, schema.CompatibilityMode, newVersion, prevVersions)
+}
+
 func getSchemaByStationName(sn StationName, tenantName string) (models.Schema, error) {
 	exist, station, err := db.GetStationByName(sn.Ext(), tenantName)
 	if err != nil {
@@ -312,6 +442,7 @@ func (sh SchemasHandler) getExtendedSchemaDetailsUpdateAvailable(schemaVersion i
 		UsedStations:      stations,
 		Tags:              tags,
 		CreatedByUsername: schema.CreatedByUsername,
+		CompatibilityMode: schema.CompatibilityMode,
 	}
 
 	return extedndedSchemaDetails, nil
@@ -343,6 +474,7 @@ func (sh SchemasHandler) getExtendedSchemaDetails(schema models.Schema, tenantNa
 		UsedStations:      stations,
 		Tags:              tags,
 		CreatedByUsername: schema.CreatedByUsername,
+		CompatibilityMode: schema.CompatibilityMode,
 	}
 
 	return extedndedSchemaDetails, nil
@@ -430,17 +562,40 @@ func (sh SchemasHandler) CreateNewSchema(c *gin.Context) {
 		}
 	}
 
+	compatibilityMode := strings.ToLower(body.CompatibilityMode)
+	if compatibilityMode == "" {
+		compatibilityMode = compatibilityModeNone
+	}
+	err = validateCompatibilityMode(compatibilityMode)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]CreateNewSchema at validateCompatibilityMode: Schema %v: %v", user.TenantName, user.Username, schemaName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+
 	schemaContent := body.SchemaContent
-	err = validateSchemaContent(schemaContent, schemaType)
+	schemaFiles := body.SchemaFiles
+	entrypoint := body.Entrypoint
+	err = validateSchemaVersionContent(schemaContent, schemaType, schemaFiles, entrypoint)
 	if err != nil {
-		serv.Warnf("[tenant: %v][user: %v]CreateNewSchema at validateSchemaContent: Schema %v: %v", user.TenantName, user.Username, schemaName, err.Error())
+		serv.Warnf("[tenant: %v][user: %v]CreateNewSchema at validateSchemaVersionContent: Schema %v: %v", user.TenantName, user.Username, schemaName, err.Error())
 		c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
 		return
 	}
+	if len(schemaFiles) > 0 {
+		schemaContent = schemaFiles[entrypoint]
+	}
 	schemaVersionNumber := 1
 	descriptor := ""
-	if schemaType == "protobuf" {
-		descriptor, err = generateSchemaDescriptor(schemaName, schemaVersionNumber, schemaContent, schemaType)
+	if len(schemaFiles) > 0 {
+		descriptor, err = generateProtobufBundleDescriptor(schemaFiles, entrypoint, tenantName)
+		if err != nil {
+			serv.Warnf("[tenant: %v][user: %v]CreateNewSchema at generateProtobufBundleDescriptor: Schema %v: %v", user.TenantName, user.Username, schemaName, err.Error())
+			c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+			return
+		}
+	} else if schemaType == "protobuf" || schemaType == "avro" {
+		descriptor, err = generateSchemaDescriptor(schemaName, schemaVersionNumber, schemaContent, schemaType, tenantName)
 		if err != nil {
 			serv.Warnf("[tenant: %v][user: %v]CreateNewSchema at generateSchemaDescriptor: Schema %v: %v", user.TenantName, user.Username, schemaName, err.Error())
 			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
@@ -448,7 +603,7 @@ func (sh SchemasHandler) CreateNewSchema(c *gin.Context) {
 		}
 	}
 
-	newSchema, rowsUpdated, err := db.InsertNewSchema(schemaName, schemaType, user.Username, tenantName)
+	newSchema, rowsUpdated, err := db.InsertNewSchema(schemaName, schemaType, user.Username, compatibilityMode, tenantName)
 	if err != nil {
 		serv.Errorf("[tenant: %v][user: %v]CreateNewSchema at InsertNewSchema: Schema %v: %v", user.TenantName, user.Username, schemaName, err.Error())
 		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
@@ -456,7 +611,7 @@ func (sh SchemasHandler) CreateNewSchema(c *gin.Context) {
 	}
 
 	if rowsUpdated == 1 {
-		_, _, err = db.InsertNewSchemaVersion(schemaVersionNumber, user.ID, user.Username, schemaContent, newSchema.ID, messageStructName, descriptor, true, tenantName)
+		_, _, err = db.InsertNewSchemaVersion(schemaVersionNumber, user.ID, user.Username, schemaContent, newSchema.ID, messageStructName, descriptor, true, tenantName, schemaFiles, entrypoint)
 		if err != nil {
 			serv.Errorf("[tenant: %v][user: %v]CreateNewSchema at InsertNewSchemaVersion: %v", user.TenantName, user.Username, err.Error())
 			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
@@ -623,6 +778,7 @@ func (sh SchemasHandler) RemoveSchema(c *gin.Context) {
 		for _, name := range body.SchemaNames {
 			serv.Noticef("[tenant: %v][user: %v]Schema %v has been deleted", user.TenantName, user.Username, name)
 		}
+		sh.S.reloadSchemaEnforcement(user.TenantName)
 	}
 
 	shouldSendAnalytics, _ := shouldSendAnalytics()
@@ -671,12 +827,17 @@ func (sh SchemasHandler) CreateNewVersion(c *gin.Context) {
 		}
 	}
 	schemaContent := body.SchemaContent
-	err = validateSchemaContent(schemaContent, schema.Type)
+	schemaFiles := body.SchemaFiles
+	entrypoint := body.Entrypoint
+	err = validateSchemaVersionContent(schemaContent, schema.Type, schemaFiles, entrypoint)
 	if err != nil {
-		serv.Errorf("[tenant: %v][user: %v]CreateNewVersion at validateSchemaContent: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+		serv.Errorf("[tenant: %v][user: %v]CreateNewVersion at validateSchemaVersionContent: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
 		c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
 		return
 	}
+	if len(schemaFiles) > 0 {
+		schemaContent = schemaFiles[entrypoint]
+	}
 
 	countVersions, err := db.GetShcemaVersionsCount(schema.ID, user.TenantName)
 	if err != nil {
@@ -687,15 +848,36 @@ func (sh SchemasHandler) CreateNewVersion(c *gin.Context) {
 
 	versionNumber := countVersions + 1
 	descriptor := ""
-	if schema.Type == "protobuf" {
-		descriptor, err = generateSchemaDescriptor(schemaName, versionNumber, schemaContent, schema.Type)
+	if len(schemaFiles) > 0 {
+		descriptor, err = generateProtobufBundleDescriptor(schemaFiles, entrypoint, user.TenantName)
+		if err != nil {
+			serv.Warnf("[tenant: %v][user: %v]CreateNewVersion at generateProtobufBundleDescriptor: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+			c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+			return
+		}
+	} else if schema.Type == "protobuf" || schema.Type == "avro" {
+		descriptor, err = generateSchemaDescriptor(schemaName, versionNumber, schemaContent, schema.Type, user.TenantName)
 		if err != nil {
 			serv.Warnf("[tenant: %v][user: %v]CreateNewVersion at generateSchemaDescriptor: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
 			c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
 			return
 		}
 	}
-	newSchemaVersion, rowsUpdated, err := db.InsertNewSchemaVersion(versionNumber, user.ID, user.Username, schemaContent, schema.ID, messageStructName, descriptor, false, user.TenantName)
+	newVersion := models.SchemaVersion{VersionNumber: versionNumber, SchemaContent: schemaContent, MessageStructName: messageStructName, Descriptor: descriptor, SchemaFiles: schemaFiles, Entrypoint: entrypoint}
+	violations, err := getSchemaVersionCompatibilityViolations(schema, newVersion)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]CreateNewVersion at getSchemaVersionCompatibilityViolations: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+		c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+	if len(violations) > 0 {
+		errMsg := fmt.Sprintf("The new version is not %v compatible with the previous versions of schema %v", schema.CompatibilityMode, schema.Name)
+		serv.Warnf("[tenant: %v][user: %v]CreateNewVersion: %v: %v", user.TenantName, user.Username, errMsg, formatCompatibilityViolations(violations))
+		c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": errMsg, "violations": violations})
+		return
+	}
+
+	newSchemaVersion, rowsUpdated, err := db.InsertNewSchemaVersion(versionNumber, user.ID, user.Username, schemaContent, schema.ID, messageStructName, descriptor, false, user.TenantName, schemaFiles, entrypoint)
 	if err != nil {
 		serv.Warnf("[tenant: %v][user: %v]CreateNewVersion at InsertNewSchemaVersion: %v", user.TenantName, user.Username, err.Error())
 		c.AbortWithStatusJSON(SCHEMA_VALIDATION_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
@@ -725,6 +907,73 @@ func (sh SchemasHandler) CreateNewVersion(c *gin.Context) {
 
 }
 
+func (sh SchemasHandler) UpdateCompatibilityMode(c *gin.Context) {
+	var body models.UpdateCompatibilityMode
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("UpdateCompatibilityMode at getUserDetailsFromMiddleware: Schema %v: %v", body.SchemaName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server Error"})
+		return
+	}
+	compatibilityMode := strings.ToLower(body.CompatibilityMode)
+	err = validateCompatibilityMode(compatibilityMode)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]UpdateCompatibilityMode at validateCompatibilityMode: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+	schemaName := strings.ToLower(body.SchemaName)
+	exist, schema, err := db.GetSchemaByName(schemaName, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]UpdateCompatibilityMode at GetSchemaByName: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server Error"})
+		return
+	}
+	if !exist {
+		errMsg := fmt.Sprintf("Schema %v does not exist", body.SchemaName)
+		serv.Warnf("[tenant: %v][user: %v]UpdateCompatibilityMode: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+	if compatibilityMode != compatibilityModeNone {
+		_, err = getSchemaCompatibilityChecker(schema.Type)
+		if err != nil {
+			serv.Warnf("[tenant: %v][user: %v]UpdateCompatibilityMode at getSchemaCompatibilityChecker: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+			return
+		}
+	}
+
+	err = db.UpdateSchemaCompatibilityMode(schema.ID, compatibilityMode)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]UpdateCompatibilityMode at UpdateSchemaCompatibilityMode: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	schema.CompatibilityMode = compatibilityMode
+	serv.Noticef("[tenant: %v][user: %v]Compatibility mode of schema %v has been set to %v", user.TenantName, user.Username, schema.Name, compatibilityMode)
+
+	extedndedSchemaDetails, err := sh.getExtendedSchemaDetails(schema, user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]UpdateCompatibilityMode at getExtendedSchemaDetails: Schema %v: %v", user.TenantName, user.Username, body.SchemaName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"compatibility-mode": compatibilityMode}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-update-schema-compatibility-mode")
+	}
+
+	c.IndentedJSON(200, extedndedSchemaDetails)
+}
+
 func (sh SchemasHandler) RollBackVersion(c *gin.Context) {
 	var body models.RollBackVersion
 	ok := utils.Validate(c, &body, false, nil)
@@ -781,6 +1030,7 @@ func (sh SchemasHandler) RollBackVersion(c *gin.Context) {
 			c.AbortWithStatusJSON(500, gin.H{"message": err.Error()})
 			return
 		}
+		sh.S.reloadSchemaEnforcement(user.TenantName)
 	}
 	extedndedSchemaDetails, err = sh.getExtendedSchemaDetails(schema, user.TenantName)
 	if err != nil {
@@ -882,7 +1132,7 @@ func (s *Server) createSchemaDirect(c *client, reply string, msg []byte) {
 
 	if exist {
 		if existedSchema.Type == csr.Type {
-			err = s.updateSchemaVersion(existedSchema.ID, tenantName, csr)
+			err = s.updateSchemaVersion(existedSchema, tenantName, csr)
 			if err != nil {
 				s.Errorf("[tenant: %v]createSchemaDirect at updateSchemaVersion - failed creating Schema: %v : %v", tenantName, csr.Name, err.Error())
 				respondWithRespErr(s.MemphisGlobalAccountString(), s, reply, err, &resp)
@@ -909,7 +1159,8 @@ func (s *Server) createSchemaDirect(c *client, reply string, msg []byte) {
 
 }
 
-func (s *Server) updateSchemaVersion(schemaID int, tenantName string, newSchemaReq CreateSchemaReq) error {
+func (s *Server) updateSchemaVersion(schema models.Schema, tenantName string, newSchemaReq CreateSchemaReq) error {
+	schemaID := schema.ID
 	_, user, err := memphis_cache.GetUser(newSchemaReq.CreatedByUsername, tenantName)
 	if err != nil {
 		s.Errorf("[tenant: %v]updateSchemaVersion at memphis_cache.GetUser: Schema %v: %v", tenantName, newSchemaReq.Name, err.Error())
@@ -937,15 +1188,27 @@ func (s *Server) updateSchemaVersion(schemaID int, tenantName string, newSchemaR
 	versionNumber := countVersions + 1
 
 	descriptor := ""
-	if newSchemaReq.Type == "protobuf" {
-		descriptor, err = generateSchemaDescriptor(newSchemaReq.Name, 1, newSchemaReq.SchemaContent, newSchemaReq.Type)
+	if newSchemaReq.Type == "protobuf" || newSchemaReq.Type == "avro" {
+		descriptor, err = generateSchemaDescriptor(newSchemaReq.Name, 1, newSchemaReq.SchemaContent, newSchemaReq.Type, tenantName)
 		if err != nil {
 			s.Errorf("[tenant: %v][user: %v]CreateNewSchemaDirectn: could not create proto descriptor for %v: %v", tenantName, user.Username, newSchemaReq.Name, err.Error())
 			return err
 		}
 	}
 
-	newSchemaVersion, rowsUpdated, err := db.InsertNewSchemaVersion(versionNumber, user.ID, user.Username, newSchemaReq.SchemaContent, schemaID, newSchemaReq.MessageStructName, descriptor, false, tenantName)
+	newVersion := models.SchemaVersion{VersionNumber: versionNumber, SchemaContent: newSchemaReq.SchemaContent, MessageStructName: newSchemaReq.MessageStructName, Descriptor: descriptor}
+	violations, err := getSchemaVersionCompatibilityViolations(schema, newVersion)
+	if err != nil {
+		s.Warnf("[tenant: %v][user: %v]updateSchemaVersion at getSchemaVersionCompatibilityViolations: Schema %v: %v", tenantName, user.Username, newSchemaReq.Name, err.Error())
+		return err
+	}
+	if len(violations) > 0 {
+		errMsg := fmt.Sprintf("the new version is not %v compatible with the previous versions of schema %v: %v", schema.CompatibilityMode, schema.Name, formatCompatibilityViolations(violations))
+		s.Warnf("[tenant: %v][user: %v]updateSchemaVersion: %v", tenantName, user.Username, errMsg)
+		return errors.New(errMsg)
+	}
+
+	newSchemaVersion, rowsUpdated, err := db.InsertNewSchemaVersion(versionNumber, user.ID, user.Username, newSchemaReq.SchemaContent, schemaID, newSchemaReq.MessageStructName, descriptor, false, tenantName, nil, "")
 	if err != nil {
 		s.Errorf("[tenant: %v][user: %v]updateSchemaVersion: %v", tenantName, user.Username, err.Error())
 		return err
@@ -971,22 +1234,22 @@ func (s *Server) createNewSchema(newSchemaReq CreateSchemaReq, tenantName string
 	}
 
 	descriptor := ""
-	if newSchemaReq.Type == "protobuf" {
-		descriptor, err = generateSchemaDescriptor(newSchemaReq.Name, 1, newSchemaReq.SchemaContent, newSchemaReq.Type)
+	if newSchemaReq.Type == "protobuf" || newSchemaReq.Type == "avro" {
+		descriptor, err = generateSchemaDescriptor(newSchemaReq.Name, 1, newSchemaReq.SchemaContent, newSchemaReq.Type, tenantName)
 		if err != nil {
 			s.Errorf("[tenant: %v][user: %v]CreateNewSchema at generateSchemaDescriptor: Schema %v: %v", tenantName, user.Username, newSchemaReq.Name, err.Error())
 			return err
 		}
 	}
 
-	newSchema, rowUpdated, err := db.InsertNewSchema(newSchemaReq.Name, newSchemaReq.Type, newSchemaReq.CreatedByUsername, tenantName)
+	newSchema, rowUpdated, err := db.InsertNewSchema(newSchemaReq.Name, newSchemaReq.Type, newSchemaReq.CreatedByUsername, compatibilityModeNone, tenantName)
 	if err != nil {
 		s.Errorf("[tenant: %v][user: %v]createNewSchema at db.InsertNewSchema: %v", tenantName, user.Username, err.Error())
 		return err
 	}
 
 	if rowUpdated == 1 {
-		_, _, err := db.InsertNewSchemaVersion(schemaVersionNumber, user.ID, user.Username, newSchemaReq.SchemaContent, newSchema.ID, newSchemaReq.MessageStructName, descriptor, true, tenantName)
+		_, _, err := db.InsertNewSchemaVersion(schemaVersionNumber, user.ID, user.Username, newSchemaReq.SchemaContent, newSchema.ID, newSchemaReq.MessageStructName, descriptor, true, tenantName, nil, "")
 		if err != nil {
 			s.Errorf("[tenant: %v][user: %v]createNewSchema at db.InsertNewSchemaVersion: %v", tenantName, user.Username, err.Error())
 			return err
@@ -998,13 +1261,14 @@ func (s *Server) createNewSchema(newSchemaReq CreateSchemaReq, tenantName string
 
 func getProtoMessageStructName(schema_content string) (string, error) {
 	parser := protoparse.Parser{
-		Accessor: func(filename string) (io.ReadCloser, error) {
-			return io.NopCloser(strings.NewReader(string(schema_content))), nil
-		},
+		Accessor: protobufFilesAccessor(map[string]string{"schema.proto": schema_content}, ""),
 	}
-	something, err := parser.ParseFiles("")
+	something, err := parser.ParseFilesButDoNotLink("schema.proto")
 	if err != nil {
 		return "", errors.New("your Proto file is invalid: " + err.Error())
 	}
-	return something[0].GetMessageTypes()[0].GetName(), nil
+	if len(something[0].GetMessageType()) == 0 {
+		return "", errors.New("your Proto file has no messages")
+	}
+	return something[0].GetMessageType()[0].GetName(), nil
 }
@@ -0,0 +1,89 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGenerateProtobufFilesDescriptor(t *testing.T) {
+	files := map[string]string{
+		"common.proto": `syntax = "proto3";
+package common;
+import "google/protobuf/timestamp.proto";
+message Envelope {
+	string id = 1;
+	google.protobuf.Timestamp sent_at = 2;
+}`,
+		"order.proto": `syntax = "proto3";
+package orders;
+import "common.proto";
+message Order {
+	common.Envelope envelope = 1;
+	int64 amount = 2;
+}`,
+	}
+
+	raw, err := generateProtobufFilesDescriptor(files, "order.proto", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var descriptorSet descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(raw, &descriptorSet); err != nil {
+		t.Fatalf("Failed unmarshaling the descriptor: %v", err)
+	}
+	names := []string{}
+	for _, file := range descriptorSet.File {
+		names = append(names, file.GetName())
+	}
+	expected := []string{"google/protobuf/timestamp.proto", "common.proto", "order.proto"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected files %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected files %v, got %v", expected, names)
+		}
+	}
+
+	_, err = findProtobufMessageDescriptor(base64.StdEncoding.EncodeToString(raw), "Order")
+	if err != nil {
+		t.Fatalf("Expected the message to be resolvable from the descriptor: %v", err)
+	}
+
+	if _, err := generateProtobufFilesDescriptor(files, "missing.proto", ""); err == nil {
+		t.Fatalf("Expected a missing entrypoint to fail")
+	}
+}
+
+func TestValidateProtobufSchemaFiles(t *testing.T) {
+	files := map[string]string{
+		"common.proto": `syntax = "proto3"; message Envelope { string id = 1; }`,
+		"order.proto":  `syntax = "proto3"; import "common.proto"; message Order { Envelope envelope = 1; }`,
+	}
+	if err := validateProtobufSchemaFiles(files, "order.proto"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := validateProtobufSchemaFiles(files, ""); err == nil {
+		t.Fatalf("Expected a missing entrypoint to be invalid")
+	}
+	if err := validateProtobufSchemaFiles(files, "missing.proto"); err == nil {
+		t.Fatalf("Expected an unknown entrypoint to be invalid")
+	}
+	if err := validateSchemaVersionContent("", "json", files, "order.proto"); err == nil {
+		t.Fatalf("Expected multiple files to be rejected for json schemas")
+	}
+}
@@ -124,6 +124,11 @@ func removeStationResources(s *Server, station models.Station, shouldDeleteStrea
 		}
 	}
 
+	s.invalidateSchemaEnforcement(station.TenantName, stationName)
+	err = s.revokeStationSourcesGrants(station)
+	if err != nil {
+		serv.Errorf("[tenant: %v]removeStationResources at revokeStationSourcesGrants: Station %v: %v", station.TenantName, station.Name, err.Error())
+	}
 	DeleteTagsFromStation(station.ID)
 
 	err = db.DeleteDLSMessagesByStationID(station.ID)
@@ -131,6 +136,11 @@ func removeStationResources(s *Server, station models.Station, shouldDeleteStrea
 		return err
 	}
 
+	err = db.DeleteDlsRetryPoliciesByStationId(station.ID)
+	if err != nil {
+		return err
+	}
+
 	err = db.DeleteProducersByStationID(station.ID)
 	if err != nil {
 		return err
@@ -305,9 +315,54 @@ func (s *Server) createStationDirectIntern(c *client,
 		return
 	}
 
+	err = validateRetentionPolicy(csr.RetentionPolicy, csr.IdempotencyWindow)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user:%v]createStationDirect at validateRetentionPolicy: %v", csr.TenantName, csr.Username, err.Error())
+		jsApiResp.Error = NewJSStreamCreateError(err)
+		respondWithErrOrJsApiRespWithEcho(!isNative, c, memphisGlobalAcc, _EMPTY_, reply, _EMPTY_, jsApiResp, err)
+		return
+	}
+
+	if !shouldCreateStream && (csr.Mirror != nil || len(csr.Sources) > 0) {
+		err = errors.New("mirror and sources can be set only on memphis native stations")
+		serv.Warnf("[tenant: %v][user:%v]createStationDirect: Station %v: %v", csr.TenantName, csr.Username, csr.StationName, err.Error())
+		respondWithErr(s.MemphisGlobalAccountString(), s, reply, err)
+		return
+	}
+
+	err = validateStationImmutability(csr.Immutability)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user:%v]createStationDirect at validateStationImmutability: Station %v: %v", csr.TenantName, csr.Username, csr.StationName, err.Error())
+		respondWithErr(s.MemphisGlobalAccountString(), s, reply, err)
+		return
+	}
+
+	_, err = checkStationSources(csr.TenantName, stationName, csr.Mirror, csr.Sources)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user:%v]createStationDirect at checkStationSources: Station %v: %v", csr.TenantName, csr.Username, csr.StationName, err.Error())
+		respondWithErr(s.MemphisGlobalAccountString(), s, reply, err)
+		return
+	}
+
+	_, err = checkDlsStation(csr.TenantName, stationName, csr.DlsConfiguration.Station)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user:%v]createStationDirect at checkDlsStation: Station %v: %v", csr.TenantName, csr.Username, csr.StationName, err.Error())
+		respondWithErr(s.MemphisGlobalAccountString(), s, reply, err)
+		return
+	}
+
 	if shouldCreateStream {
-		err = s.CreateStream(csr.TenantName, stationName, retentionType, retentionValue, storageType, csr.IdempotencyWindow, replicas, csr.TieredStorageEnabled)
+		err = s.applyStationSourcesGrants(csr.TenantName, stationName, csr.Mirror, csr.Sources)
+		if err != nil {
+			serv.Errorf("[tenant: %v][user:%v]createStationDirect at applyStationSourcesGrants: Station %v: %v", csr.TenantName, csr.Username, csr.StationName, err.Error())
+			s.rollbackStationCreation(csr.TenantName, stationName, csr.Mirror, csr.Sources, false)
+			respondWithErr(s.MemphisGlobalAccountString(), s, reply, err)
+			return
+		}
+
+		err = s.CreateStream(csr.TenantName, stationName, retentionType, retentionValue, csr.RetentionPolicy, storageType, csr.IdempotencyWindow, replicas, csr.TieredStorageEnabled, csr.Mirror, csr.Sources, csr.Immutability)
 		if err != nil {
+			s.rollbackStationCreation(csr.TenantName, stationName, csr.Mirror, csr.Sources, false)
 			if IsNatsErr(err, JSStreamReplicasNotSupportedErr) {
 				serv.Warnf("[tenant: %v][user:%v]CreateStationDirect: Station %v: Station can not be created, probably since replicas count is larger than the cluster size", csr.TenantName, csr.Username, stationName.Ext())
 				respondWithErr(s.MemphisGlobalAccountString(), s, reply, errors.New("station can not be created, probably since replicas count is larger than the cluster size"))
@@ -332,7 +387,7 @@ func (s *Server) createStationDirectIntern(c *client,
 		return
 	}
 
-	_, rowsUpdated, err := db.InsertNewStation(stationName.Ext(), user.ID, user.Username, retentionType, retentionValue, storageType, replicas, schemaDetails.SchemaName, schemaDetails.VersionNumber, csr.IdempotencyWindow, isNative, csr.DlsConfiguration, csr.TieredStorageEnabled, user.TenantName)
+	_, rowsUpdated, err := db.InsertNewStation(stationName.Ext(), user.ID, user.Username, retentionType, retentionValue, storageType, replicas, schemaDetails.SchemaName, schemaDetails.VersionNumber, csr.IdempotencyWindow, isNative, csr.DlsConfiguration, csr.TieredStorageEnabled, csr.Mirror, csr.Sources, csr.Immutability, csr.RetentionPolicy, user.TenantName)
 	if err != nil {
 		if !strings.Contains(err.Error(), "already exist") {
 			serv.Errorf("[tenant: %v][user:%v]createStationDirect at InsertNewStation: Station %v: %v", csr.TenantName, csr.Username, csr.StationName, err.Error())
@@ -411,7 +466,7 @@ func (sh StationsHandler) GetStation(c *gin.Context) {
 	if tenantInetgrations, ok := IntegrationsConcurrentCache.Load(user.TenantName); !ok {
 		station.TieredStorageEnabled = false
 	} else {
-		_, ok = tenantInetgrations["s3"].(models.Integration)
+		ok = hasTier2StorageIntegration(tenantInetgrations)
 		if !ok {
 			station.TieredStorageEnabled = false
 		} else if station.TieredStorageEnabled {
@@ -435,9 +490,12 @@ func (sh StationsHandler) GetStation(c *gin.Context) {
 		IsDeleted:            station.IsDeleted,
 		IdempotencyWindow:    station.IdempotencyWindow,
 		IsNative:             station.IsNative,
-		DlsConfiguration:     models.DlsConfiguration{Poison: station.DlsConfigurationPoison, Schemaverse: station.DlsConfigurationSchemaverse},
+		DlsConfiguration:     models.DlsConfiguration{Poison: station.DlsConfigurationPoison, Schemaverse: station.DlsConfigurationSchemaverse, Station: station.DlsStation, StationOnly: station.DlsStationOnly},
 		TieredStorageEnabled: station.TieredStorageEnabled,
 		Tags:                 tags,
+		SchemaEnforced:       station.SchemaEnforced,
+		Immutability:         station.Immutability,
+		RetentionPolicy:      station.RetentionPolicy,
 	}
 
 	c.IndentedJSON(200, stationResponse)
@@ -502,7 +560,7 @@ func (sh StationsHandler) GetStationsDetails(tenantName string) ([]models.Extend
 			if tenantInetgrations, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
 				station.TieredStorageEnabled = false
 			} else {
-				_, ok = tenantInetgrations["s3"].(models.Integration)
+				ok = hasTier2StorageIntegration(tenantInetgrations)
 				if !ok {
 					station.TieredStorageEnabled = false
 				} else if station.TieredStorageEnabled {
@@ -617,7 +675,7 @@ func (sh StationsHandler) GetAllStationsDetails(shouldGetTags bool, tenantName s
 			if tenantInetgrations, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
 				stations[i].TieredStorageEnabled = false
 			} else {
-				_, ok = tenantInetgrations["s3"].(models.Integration)
+				ok = hasTier2StorageIntegration(tenantInetgrations)
 				if !ok {
 					stations[i].TieredStorageEnabled = false
 				} else if stations[i].TieredStorageEnabled {
@@ -702,7 +760,7 @@ func (sh StationsHandler) GetAllStationsDetailsLight(shouldExtend bool, tenantNa
 				if tenantInetgrations, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
 					stations[i].TieredStorageEnabled = false
 				} else {
-					_, ok = tenantInetgrations["s3"].(models.Integration)
+					ok = hasTier2StorageIntegration(tenantInetgrations)
 					if !ok {
 						stations[i].TieredStorageEnabled = false
 					} else if stations[i].TieredStorageEnabled {
@@ -881,7 +939,45 @@ func (sh StationsHandler) CreateStation(c *gin.Context) {
 		body.IdempotencyWindow = 100 // minimum is 100 millis
 	}
 
-	newStation, rowsUpdated, err := db.InsertNewStation(stationName.Ext(), user.ID, user.Username, retentionType, body.RetentionValue, body.StorageType, body.Replicas, schemaName, schemaVersionNumber, body.IdempotencyWindow, true, body.DlsConfiguration, body.TieredStorageEnabled, tenantName)
+	err = validateRetentionPolicy(body.RetentionPolicy, body.IdempotencyWindow)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]CreateStation at validateRetentionPolicy: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+
+	err = validateStationImmutability(body.Immutability)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]CreateStation at validateStationImmutability: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+
+	statusCode, err := checkStationSources(tenantName, stationName, body.Mirror, body.Sources)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]CreateStation at checkStationSources: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]CreateStation at checkStationSources: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+
+	statusCode, err = checkDlsStation(tenantName, stationName, body.DlsConfiguration.Station)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]CreateStation at checkDlsStation: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]CreateStation at checkDlsStation: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+
+	newStation, rowsUpdated, err := db.InsertNewStation(stationName.Ext(), user.ID, user.Username, retentionType, body.RetentionValue, body.StorageType, body.Replicas, schemaName, schemaVersionNumber, body.IdempotencyWindow, true, body.DlsConfiguration, body.TieredStorageEnabled, body.Mirror, body.Sources, body.Immutability, body.RetentionPolicy, tenantName)
 	if err != nil {
 		serv.Errorf("[tenant: %v][user: %v]CreateStation at db.InsertNewStation: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
 		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
@@ -896,8 +992,17 @@ func (sh StationsHandler) CreateStation(c *gin.Context) {
 		return
 	}
 
-	err = sh.S.CreateStream(tenantName, stationName, retentionType, body.RetentionValue, body.StorageType, body.IdempotencyWindow, body.Replicas, body.TieredStorageEnabled)
+	err = sh.S.applyStationSourcesGrants(tenantName, stationName, body.Mirror, body.Sources)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]CreateStation at applyStationSourcesGrants: Station %v: %v", user.TenantName, user.Username, body.Name, err.Error())
+		sh.S.rollbackStationCreation(tenantName, stationName, body.Mirror, body.Sources, true)
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	err = sh.S.CreateStream(tenantName, stationName, retentionType, body.RetentionValue, body.RetentionPolicy, body.StorageType, body.IdempotencyWindow, body.Replicas, body.TieredStorageEnabled, body.Mirror, body.Sources, body.Immutability)
 	if err != nil {
+		sh.S.rollbackStationCreation(tenantName, stationName, body.Mirror, body.Sources, true)
 		if IsNatsErr(err, JSInsufficientResourcesErr) {
 			serv.Warnf("[tenant: %v][user: %v]CreateStation: Station %v: Station can not be created, probably since replicas count is larger than the cluster size", user.TenantName, user.Username, body.Name)
 			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": "Station can not be created, probably since replicas count is larger than the cluster size"})
@@ -958,6 +1063,12 @@ func (sh StationsHandler) CreateStation(c *gin.Context) {
 			"dls_configuration_poison":      newStation.DlsConfigurationPoison,
 			"dls_configuration_schemaverse": newStation.DlsConfigurationSchemaverse,
 			"tiered_storage_enabled":        newStation.TieredStorageEnabled,
+			"mirror":                        newStation.Mirror,
+			"sources":                       newStation.Sources,
+			"immutability":                  newStation.Immutability,
+			"retention_policy":              newStation.RetentionPolicy,
+			"dls_station":                   newStation.DlsStation,
+			"dls_station_only":              newStation.DlsStationOnly,
 		})
 	} else {
 		c.IndentedJSON(200, gin.H{
@@ -977,6 +1088,12 @@ func (sh StationsHandler) CreateStation(c *gin.Context) {
 			"dls_configuration_poison":      newStation.DlsConfigurationPoison,
 			"dls_configuration_schemaverse": newStation.DlsConfigurationSchemaverse,
 			"tiered_storage_enabled":        newStation.TieredStorageEnabled,
+			"mirror":                        newStation.Mirror,
+			"sources":                       newStation.Sources,
+			"immutability":                  newStation.Immutability,
+			"retention_policy":              newStation.RetentionPolicy,
+			"dls_station":                   newStation.DlsStation,
+			"dls_station_only":              newStation.DlsStationOnly,
 		})
 	}
 }
@@ -996,6 +1113,7 @@ func (sh StationsHandler) RemoveStation(c *gin.Context) {
 		return
 	}
 
+	var stations []models.Station
 	for _, name := range body.StationNames {
 		stationName, err := StationNameFromStr(name)
 		if err != nil {
@@ -1018,10 +1136,21 @@ func (sh StationsHandler) RemoveStation(c *gin.Context) {
 			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
 			return
 		}
+		// immutable stations are checked before anything is removed so a batch is never partially deleted
+		if isStationImmutable(station.Immutability) {
+			errMsg := fmt.Sprintf("Station %v is immutable and can not be removed", name)
+			serv.Warnf("[tenant: %v][user: %v]RemoveStation: %v", user.TenantName, user.Username, errMsg)
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+			return
+		}
+
+		stations = append(stations, station)
+	}
 
+	for _, station := range stations {
 		err = removeStationResources(sh.S, station, true)
 		if err != nil {
-			serv.Errorf("[tenant: %v][user: %v]RemoveStation at removeStationResources: Station %v: %v", user.TenantName, user.Username, stationName.external, err.Error())
+			serv.Errorf("[tenant: %v][user: %v]RemoveStation at removeStationResources: Station %v: %v", user.TenantName, user.Username, station.Name, err.Error())
 			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
 			return
 		}
@@ -1136,6 +1265,15 @@ func (s *Server) removeStationDirectIntern(c *client,
 		return
 	}
 
+	if isStationImmutable(station.Immutability) {
+		errMsg := fmt.Sprintf("Station %v is immutable and can not be removed", station.Name)
+		serv.Warnf("[tenant: %v][user: %v]removeStationDirectIntern: %v", dsr.TenantName, dsr.Username, errMsg)
+		err := errors.New(errMsg)
+		jsApiResp.Error = NewJSStreamDeleteError(err)
+		respondWithErrOrJsApiRespWithEcho(!isNative, c, memphisGlobalAcc, _EMPTY_, reply, _EMPTY_, jsApiResp, err)
+		return
+	}
+
 	err = removeStationResources(s, station, shouldDeleteStream)
 	if err != nil {
 		serv.Errorf("[tenant: %v][user: %v]removeStationDirectIntern at removeStationResources: Station %v: %v", dsr.TenantName, dsr.Username, dsr.StationName, err.Error())
@@ -1265,16 +1403,54 @@ func (sh StationsHandler) DropDlsMessages(c *gin.Context) {
 		return
 	}
 
-	err := db.DropDlsMessages(body.DlsMessageIds)
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("DropDlsMessages at getUserDetailsFromMiddleware: %v", err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	stationName, err := StationNameFromStr(body.StationName)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]DropDlsMessages at StationNameFromStr: %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+
+	exist, station, err := db.GetStationByName(stationName.Ext(), user.TenantName)
 	if err != nil {
-		serv.Errorf("DropDlsMessages at db.DropDlsMessages: %v", err.Error())
+		serv.Errorf("[tenant: %v][user: %v]DropDlsMessages at GetStationByName: %v", user.TenantName, user.Username, err.Error())
 		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
 		return
 	}
+	if exist && station.Immutability.LegalHold {
+		errMsg := fmt.Sprintf("Station %v is under legal hold and its dead-letter messages can not be dropped", stationName.Ext())
+		serv.Warnf("[tenant: %v][user: %v]DropDlsMessages: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+
+	if body.CgName != _EMPTY_ {
+		// only the consumer group is removed, messages poisoned by other consumer groups are kept for them
+		for _, id := range body.DlsMessageIds {
+			err = db.RemoveCgFromDlsMsg(id, body.CgName, user.TenantName)
+			if err != nil {
+				serv.Errorf("[tenant: %v][user: %v]DropDlsMessages at db.RemoveCgFromDlsMsg: %v", user.TenantName, user.Username, err.Error())
+				c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+				return
+			}
+		}
+	} else {
+		err = db.DropDlsMessages(body.DlsMessageIds)
+		if err != nil {
+			serv.Errorf("DropDlsMessages at db.DropDlsMessages: %v", err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+			return
+		}
+	}
 
 	shouldSendAnalytics, _ := shouldSendAnalytics()
 	if shouldSendAnalytics {
-		user, _ := getUserDetailsFromMiddleware(c)
 		analyticsParams := make(map[string]interface{})
 		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-ack-poison-message")
 	}
@@ -1285,27 +1461,8 @@ func (sh StationsHandler) DropDlsMessages(c *gin.Context) {
 func (s *Server) ResendUnackedMsg(dlsMsg models.DlsMessage, user models.User, stationName string) (string, error) {
 	size := int64(0)
 	for _, cgName := range dlsMsg.PoisonedCgs {
-		headersJson := map[string]string{}
-		for key, value := range dlsMsg.MessageDetails.Headers {
-			headersJson[key] = value
-		}
-		headersJson["$memphis_pm_id"] = strconv.Itoa(dlsMsg.ID)
-		headersJson["$memphis_pm_cg_name"] = cgName
-
-		headers, err := json.Marshal(headersJson)
+		err := s.resendDlsMsgToCg(dlsMsg, user.TenantName, stationName, cgName)
 		if err != nil {
-			err = fmt.Errorf("Failed ResendUnackedMsg at json.Marshal: Poisoned consumer group: %v: %v", cgName, err.Error())
-			return cgName, err
-		}
-
-		data, err := hex.DecodeString(dlsMsg.MessageDetails.Data)
-		if err != nil {
-			err = fmt.Errorf("Failed ResendUnackedMsg at DecodeString: Poisoned consumer group: %v: %v", cgName, err.Error())
-			return cgName, err
-		}
-		err = s.ResendPoisonMessage(user.TenantName, "$memphis_dls_"+replaceDelimiters(stationName)+"_"+replaceDelimiters(cgName), []byte(data), headers)
-		if err != nil {
-			err = fmt.Errorf("Failed ResendUnackedMsg at ResendPoisonMessage: Poisoned consumer group: %v: %v", cgName, err.Error())
 			return cgName, err
 		}
 		size += int64(dlsMsg.MessageDetails.Size)
@@ -1314,6 +1471,30 @@ func (s *Server) ResendUnackedMsg(dlsMsg models.DlsMessage, user models.User, st
 	return "", nil
 }
 
+func (s *Server) resendDlsMsgToCg(dlsMsg models.DlsMessage, tenantName, stationName, cgName string) error {
+	headersJson := map[string]string{}
+	for key, value := range dlsMsg.MessageDetails.Headers {
+		headersJson[key] = value
+	}
+	headersJson["$memphis_pm_id"] = strconv.Itoa(dlsMsg.ID)
+	headersJson["$memphis_pm_cg_name"] = cgName
+
+	headers, err := json.Marshal(headersJson)
+	if err != nil {
+		return fmt.Errorf("Failed ResendUnackedMsg at json.Marshal: Poisoned consumer group: %v: %v", cgName, err.Error())
+	}
+
+	data, err := hex.DecodeString(dlsMsg.MessageDetails.Data)
+	if err != nil {
+		return fmt.Errorf("Failed ResendUnackedMsg at DecodeString: Poisoned consumer group: %v: %v", cgName, err.Error())
+	}
+	err = s.ResendPoisonMessage(tenantName, "$memphis_dls_"+replaceDelimiters(stationName)+"_"+replaceDelimiters(cgName), []byte(data), headers)
+	if err != nil {
+		return fmt.Errorf("Failed ResendUnackedMsg at ResendPoisonMessage: Poisoned consumer group: %v: %v", cgName, err.Error())
+	}
+	return nil
+}
+
 func (sh StationsHandler) ResendPoisonMessages(c *gin.Context) {
 	var body models.ResendPoisonMessagesSchema
 	ok := utils.Validate(c, &body, false, nil)
@@ -1343,7 +1524,31 @@ func (sh StationsHandler) ResendPoisonMessages(c *gin.Context) {
 		return
 	}
 
-	if len(body.PoisonMessageIds) == 0 {
+	if body.CgName != _EMPTY_ {
+		var statusCode int
+		if len(body.PoisonMessageIds) == 0 {
+			bulkOperation := models.DlsBulkOperationSchema{StationName: stationName, Operation: dlsBulkOperationResend, Filter: models.DlsMsgsFilter{CgName: body.CgName}}
+			_, statusCode, err = sh.S.startDlsBulkOperation(user, bulkOperation)
+		} else {
+			statusCode, err = sh.S.resendDlsMsgsToCg(user, station, body.PoisonMessageIds, body.CgName)
+		}
+		if err != nil {
+			if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+				serv.Warnf("[tenant: %v][user: %v]ResendPoisonMessages: Poisoned consumer group: %v: %v", user.TenantName, user.Username, body.CgName, err.Error())
+				c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+			} else {
+				serv.Errorf("[tenant: %v][user: %v]ResendPoisonMessages: Poisoned consumer group: %v: %v", user.TenantName, user.Username, body.CgName, err.Error())
+				c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+			}
+			return
+		}
+	} else if len(body.PoisonMessageIds) == 0 {
+		if station.ResendDisabled {
+			errMsg := fmt.Sprintf("A resend operation is already in progress in station %v", stationName)
+			serv.Warnf("[tenant: %v][user: %v]ResendPoisonMessages: %v", user.TenantName, user.Username, errMsg)
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+			return
+		}
 		sh.S.ResendAllDlsMsgs(stationName, station.ID, user.TenantName, user)
 	} else {
 		for _, id := range body.PoisonMessageIds {
@@ -1441,15 +1646,26 @@ func (sh StationsHandler) GetMessageDetails(c *gin.Context) {
 		}
 	}
 
+	var decodedData string
+	var schemaValidation *models.MessageSchemaValidation
+	decoder, err := newStationMessageDecoder(station)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]GetMessageDetails at newStationMessageDecoder: Message ID: %v: %v", user.TenantName, user.Username, strconv.Itoa(msgId), err.Error())
+	} else if decoder != nil {
+		decodedData, schemaValidation = decoder.decode(sm.Data, true)
+	}
+
 	// For non-native stations - default values
 	if !station.IsNative {
 		msg := models.MessageResponse{
 			MessageSeq: body.MessageSeq,
 			Message: models.MessagePayload{
-				TimeSent: sm.Time,
-				Size:     len(sm.Subject) + len(sm.Data) + len(sm.Header),
-				Data:     string(sm.Data),
-				Headers:  headersJson,
+				TimeSent:         sm.Time,
+				Size:             len(sm.Subject) + len(sm.Data) + len(sm.Header),
+				Data:             string(sm.Data),
+				Headers:          headersJson,
+				DecodedData:      decodedData,
+				SchemaValidation: schemaValidation,
 			},
 			Producer: models.ProducerDetailsResp{
 				Name:     "",
@@ -1534,10 +1750,12 @@ func (sh StationsHandler) GetMessageDetails(c *gin.Context) {
 	msg := models.MessageResponse{
 		MessageSeq: body.MessageSeq,
 		Message: models.MessagePayload{
-			TimeSent: sm.Time,
-			Size:     len(sm.Subject) + len(sm.Data) + len(sm.Header),
-			Data:     hex.EncodeToString(sm.Data),
-			Headers:  headersJson,
+			TimeSent:         sm.Time,
+			Size:             len(sm.Subject) + len(sm.Data) + len(sm.Header),
+			Data:             hex.EncodeToString(sm.Data),
+			Headers:          headersJson,
+			DecodedData:      decodedData,
+			SchemaValidation: schemaValidation,
 		},
 		Producer: models.ProducerDetailsResp{
 			Name:     producedByHeader,
@@ -1548,6 +1766,35 @@ func (sh StationsHandler) GetMessageDetails(c *gin.Context) {
 	c.IndentedJSON(200, msg)
 }
 
+func (sh StationsHandler) SearchMessages(c *gin.Context) {
+	var body models.SearchMessagesSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("SearchMessages at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	resp, statusCode, err := sh.S.searchStationMessages(user.TenantName, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]SearchMessages at searchStationMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+			return
+		}
+		serv.Errorf("[tenant: %v][user: %v]SearchMessages at searchStationMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	c.IndentedJSON(200, resp)
+}
+
 func (sh StationsHandler) UseSchema(c *gin.Context) {
 	var body models.UseSchema
 	ok := utils.Validate(c, &body, false, nil)
@@ -2015,6 +2262,26 @@ func (sh StationsHandler) UpdateDlsConfig(c *gin.Context) {
 			return
 		}
 	}
+
+	if station.DlsStation != body.Station || station.DlsStationOnly != body.StationOnly {
+		statusCode, err := checkDlsStation(user.TenantName, stationName, body.Station)
+		if err != nil {
+			if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+				serv.Warnf("[tenant: %v][user: %v]UpdateDlsConfig at checkDlsStation: At station, %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+				c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+			} else {
+				serv.Errorf("[tenant: %v][user: %v]UpdateDlsConfig at checkDlsStation: At station, %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+				c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+			}
+			return
+		}
+		err = db.UpdateStationDlsStation(station.Name, body.Station, body.StationOnly, station.TenantName)
+		if err != nil {
+			serv.Errorf("[tenant: %v][user: %v]UpdateDlsConfig at db.UpdateStationDlsStation: At station, %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+			return
+		}
+	}
 	configUpdate := models.SdkClientsUpdates{
 		StationName: stationName.Intern(),
 		Type:        schemaToDlsUpdateType,
@@ -2022,71 +2289,65 @@ func (sh StationsHandler) UpdateDlsConfig(c *gin.Context) {
 	}
 	serv.SendUpdateToClients(configUpdate)
 
-	c.IndentedJSON(200, gin.H{"poison": body.Poison, "schemaverse": body.Schemaverse})
+	c.IndentedJSON(200, gin.H{"poison": body.Poison, "schemaverse": body.Schemaverse, "station": body.Station, "station_only": body.StationOnly})
 }
 
-func (sh StationsHandler) PurgeStation(c *gin.Context) {
-	var body models.PurgeStationSchema
+func (sh StationsHandler) UpdateSchemaEnforcement(c *gin.Context) {
+	var body models.UpdateSchemaEnforcementSchema
 	ok := utils.Validate(c, &body, false, nil)
 	if !ok {
 		return
 	}
 
-	stationName, err := StationNameFromStr(body.StationName)
+	user, err := getUserDetailsFromMiddleware(c)
 	if err != nil {
-		serv.Warnf("PurgeStation: station name at StationNameFromStr: %v: %v", body.StationName, err.Error())
-		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		serv.Errorf("UpdateSchemaEnforcement at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
 		return
 	}
 
-	user, err := getUserDetailsFromMiddleware(c)
+	stationName, err := StationNameFromStr(body.StationName)
 	if err != nil {
-		serv.Errorf("PurgeStation at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
-		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		serv.Warnf("[tenant: %v][user: %v]UpdateSchemaEnforcement at StationNameFromStr: At station, %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
 		return
 	}
 
 	exist, station, err := db.GetStationByName(stationName.Ext(), user.TenantName)
 	if err != nil {
-		serv.Errorf("[tenant: %v][user: %v]PurgeStation at GetStationByName: %v", user.TenantName, user.Username, err.Error())
+		serv.Errorf("[tenant: %v][user: %v]UpdateSchemaEnforcement at GetStationByName: At station, %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
 		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
 		return
 	}
 	if !exist {
-		errMsg := fmt.Sprintf("Station %v does not exist", stationName.external)
-		serv.Warnf("[tenant: %v][user: %v]PurgeStation: %v", user.TenantName, user.Username, errMsg)
+		errMsg := fmt.Sprintf("Station %v does not exist", body.StationName)
+		serv.Warnf("[tenant: %v][user: %v]UpdateSchemaEnforcement: %v", user.TenantName, user.Username, errMsg)
 		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
 		return
 	}
 
-	if body.PurgeStation {
-		err = sh.S.PurgeStream(station.TenantName, stationName.Intern())
-		if err != nil && !IsNatsErr(err, JSStreamNotFoundErr) {
-			serv.Errorf("[tenant: %v][user: %v]PurgeStation: %v", user.TenantName, user.Username, err.Error())
-			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
-			return
-		}
-	}
-
-	if body.PurgeDls {
-		err := db.PurgeDlsMsgsFromStation(station.ID)
+	if station.SchemaEnforced != body.SchemaEnforced {
+		err = db.UpdateStationSchemaEnforcement(station.Name, body.SchemaEnforced, station.TenantName)
 		if err != nil {
-			serv.Errorf("[tenant: %v][user: %v]PurgeStation dls at PurgeDlsMsgsFromStation: %v", user.TenantName, user.Username, err.Error())
+			serv.Errorf("[tenant: %v][user: %v]UpdateSchemaEnforcement at db.UpdateStationSchemaEnforcement: At station, %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
 			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
 			return
 		}
+		sh.S.reloadSchemaEnforcement(station.TenantName, stationName)
+		serv.Noticef("[tenant: %v][user: %v]Schema enforcement on the broker has been set to %v at station %v", user.TenantName, user.Username, body.SchemaEnforced, stationName.Ext())
 	}
 
 	shouldSendAnalytics, _ := shouldSendAnalytics()
 	if shouldSendAnalytics {
-		analyticsParams := make(map[string]interface{})
-		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-purge-station")
+		analyticsParams := map[string]interface{}{"schema-enforced": body.SchemaEnforced}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-update-schema-enforcement")
 	}
-	c.IndentedJSON(200, gin.H{})
+
+	c.IndentedJSON(200, gin.H{"schema_enforced": body.SchemaEnforced})
 }
 
-func (sh StationsHandler) RemoveMessages(c *gin.Context) {
-	var body models.RemoveMessagesSchema
+func (sh StationsHandler) UpdateStation(c *gin.Context) {
+	var body models.UpdateStationSchema
 	ok := utils.Validate(c, &body, false, nil)
 	if !ok {
 		return
@@ -2094,47 +2355,539 @@ func (sh StationsHandler) RemoveMessages(c *gin.Context) {
 
 	user, err := getUserDetailsFromMiddleware(c)
 	if err != nil {
-		serv.Errorf("RemoveMessages at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		serv.Errorf("UpdateStation at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
 		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
 		return
 	}
 
-	stationName, err := StationNameFromStr(body.StationName)
+	station, statusCode, err := sh.S.updateStation(user.TenantName, body)
 	if err != nil {
-		serv.Warnf("[tenant: %v][user: %v]RemoveMessages at StationNameFromStr: station name: %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
-		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]UpdateStation at updateStation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]UpdateStation at updateStation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
 		return
 	}
+	serv.Noticef("[tenant: %v][user: %v]Retention of station %v has been updated", user.TenantName, user.Username, body.StationName)
 
-	exist, station, err := db.GetStationByName(stationName.Ext(), user.TenantName)
-	if err != nil {
-		serv.Errorf("[tenant: %v][user: %v]RemoveMessages at GetStationByName: %v", user.TenantName, user.Username, err.Error())
-		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"retention-type": station.RetentionType, "discard-policy": station.RetentionPolicy.DiscardPolicy}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-update-station")
+	}
+
+	c.IndentedJSON(200, gin.H{
+		"retention_type":   station.RetentionType,
+		"retention_value":  station.RetentionValue,
+		"retention_policy": station.RetentionPolicy,
+	})
+}
+
+func (sh StationsHandler) UpdateStationImmutability(c *gin.Context) {
+	var body models.UpdateStationImmutabilitySchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
 		return
 	}
-	if !exist {
-		errMsg := fmt.Sprintf("Station %v does not exist", stationName.external)
-		serv.Warnf("[tenant: %v][user: %v]RemoveMessages at GetStationByName: %v", user.TenantName, user.Username, errMsg)
-		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("UpdateStationImmutability at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
 		return
 	}
 
-	for _, msg := range body.MessageSeqs {
-		err = sh.S.RemoveMsg(station.TenantName, stationName, msg)
-		if err != nil {
-			if IsNatsErr(err, JSStreamNotFoundErr) || IsNatsErr(err, JSStreamMsgDeleteFailedF) {
-				continue
-			}
-			serv.Errorf("[tenant: %v][user: %v]RemoveMessages at RemoveMsg: %v", user.TenantName, user.Username, err.Error())
+	immutability, statusCode, err := sh.S.updateStationImmutability(user.TenantName, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]UpdateStationImmutability at updateStationImmutability: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]UpdateStationImmutability at updateStationImmutability: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
 			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
-			return
 		}
+		return
 	}
+	serv.Noticef("[tenant: %v][user: %v]Immutability policy of station %v has been set to %+v", user.TenantName, user.Username, body.StationName, immutability)
 
 	shouldSendAnalytics, _ := shouldSendAnalytics()
 	if shouldSendAnalytics {
-		analyticsParams := make(map[string]interface{})
-		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-remove-messages")
+		analyticsParams := map[string]interface{}{"legal-hold": immutability.LegalHold, "sealed": immutability.Sealed}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-update-station-immutability")
+	}
+
+	c.IndentedJSON(200, gin.H{"immutability": immutability})
+}
+
+func (sh StationsHandler) SetDlsRetryPolicy(c *gin.Context) {
+	var body models.SetDlsRetryPolicySchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("SetDlsRetryPolicy at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	policy, statusCode, err := sh.S.setDlsRetryPolicy(user.TenantName, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]SetDlsRetryPolicy at setDlsRetryPolicy: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]SetDlsRetryPolicy at setDlsRetryPolicy: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]DLS retry policy of station %v has been set", user.TenantName, user.Username, body.StationName)
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"max-attempts": policy.MaxAttempts, "per-cg": policy.CgName != _EMPTY_}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-set-dls-retry-policy")
+	}
+
+	c.IndentedJSON(200, policy)
+}
+
+func (sh StationsHandler) RemoveDlsRetryPolicy(c *gin.Context) {
+	var body models.RemoveDlsRetryPolicySchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("RemoveDlsRetryPolicy at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	statusCode, err := sh.S.removeDlsRetryPolicy(user.TenantName, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]RemoveDlsRetryPolicy at removeDlsRetryPolicy: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]RemoveDlsRetryPolicy at removeDlsRetryPolicy: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]DLS retry policy of station %v has been removed", user.TenantName, user.Username, body.StationName)
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := make(map[string]interface{})
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-remove-dls-retry-policy")
+	}
+
+	c.IndentedJSON(200, gin.H{})
+}
+
+func (sh StationsHandler) GetDlsRetryPolicies(c *gin.Context) {
+	var body models.GetDlsRetryPoliciesSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetDlsRetryPolicies at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	station, statusCode, err := getDlsRetryPolicyStation(user.TenantName, body.StationName)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]GetDlsRetryPolicies at getDlsRetryPolicyStation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]GetDlsRetryPolicies at getDlsRetryPolicyStation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+
+	policies, err := db.GetDlsRetryPoliciesByStationId(station.ID)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]GetDlsRetryPolicies at GetDlsRetryPoliciesByStationId: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	c.IndentedJSON(200, policies)
+}
+
+func (sh StationsHandler) DlsBulkOperation(c *gin.Context) {
+	var body models.DlsBulkOperationSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("DlsBulkOperation at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	resp, statusCode, err := sh.S.startDlsBulkOperation(user, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]DlsBulkOperation at startDlsBulkOperation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]DlsBulkOperation at startDlsBulkOperation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+	if resp.TaskId != 0 {
+		serv.Noticef("[tenant: %v][user: %v]Bulk %v of %v dead-letter messages has been started in station %v", user.TenantName, user.Username, body.Operation, resp.Matched, body.StationName)
+	}
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"operation": body.Operation, "dry-run": body.DryRun}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-dls-bulk-operation")
+	}
+
+	c.IndentedJSON(200, resp)
+}
+
+func (sh StationsHandler) ExportDlsMessages(c *gin.Context) {
+	var body models.ExportDlsMessagesSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("ExportDlsMessages at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	format := strings.ToLower(body.Format)
+	if format == _EMPTY_ {
+		format = dlsExportFormatNdjson
+	}
+	contentType, fileExt := "application/x-ndjson", ".ndjson"
+	if format == dlsExportFormatArchive {
+		contentType, fileExt = "application/gzip", ".mdls.gz"
+	}
+	messageType := strings.ToLower(body.MessageType)
+	err = validateDlsMessageType(messageType)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]ExportDlsMessages at validateDlsMessageType: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+
+	station, statusCode, err := getDlsRetryPolicyStation(user.TenantName, body.StationName)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]ExportDlsMessages at getDlsRetryPolicyStation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]ExportDlsMessages at getDlsRetryPolicyStation: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+
+	rw, err := newDlsRecordWriter(format, c.Writer)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]ExportDlsMessages at newDlsRecordWriter: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%v-dls%v", station.Name, fileExt))
+	c.Status(200)
+
+	// the response has already started, failures from here on can only be logged
+	exported, err := sh.S.exportDlsMessages(station, models.DlsMsgsFilter{MessageType: messageType}, rw)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]ExportDlsMessages at exportDlsMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]%v dead-letter messages of station %v have been exported", user.TenantName, user.Username, exported, body.StationName)
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"format": format}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-export-dls-messages")
+	}
+}
+
+func (sh StationsHandler) ImportDlsMessages(c *gin.Context) {
+	var body models.ImportDlsMessagesSchema
+	err := c.ShouldBind(&body)
+	if err != nil {
+		c.AbortWithStatusJSON(400, gin.H{"message": utils.BindingErrorMessage(err)})
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("ImportDlsMessages at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": "Could not complete uploading your file, please check your file"})
+		return
+	}
+	if fileHeader.Size > dlsImportMaxFileSize {
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": fmt.Sprintf("The file is too large, the maximum size is %vMB", dlsImportMaxFileSize/1024/1024)})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]ImportDlsMessages at Open: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	defer file.Close()
+
+	resp, statusCode, err := sh.S.importDlsMessages(user, body, file)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]ImportDlsMessages at importDlsMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]ImportDlsMessages at importDlsMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+	if resp.TaskId != 0 {
+		serv.Noticef("[tenant: %v][user: %v]An import of dead-letter messages into station %v has been started", user.TenantName, user.Username, body.StationName)
+	} else {
+		serv.Noticef("[tenant: %v][user: %v]%v dead-letter messages have been imported into station %v", user.TenantName, user.Username, resp.Imported, body.StationName)
+	}
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"target": body.Target}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-import-dls-messages")
+	}
+
+	c.IndentedJSON(200, resp)
+}
+
+func (sh StationsHandler) GetCgDlsMessages(c *gin.Context) {
+	var body models.CgDlsMessagesSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("GetCgDlsMessages at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	resp, statusCode, err := sh.S.getCgDlsMessages(user.TenantName, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]GetCgDlsMessages at getCgDlsMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]GetCgDlsMessages at getCgDlsMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+
+	c.IndentedJSON(200, resp)
+}
+
+func (sh StationsHandler) PurgeCgDlsMessages(c *gin.Context) {
+	var body models.CgDlsMessagesSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("PurgeCgDlsMessages at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	removed, statusCode, err := sh.S.purgeCgDlsMessages(user.TenantName, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]PurgeCgDlsMessages at purgeCgDlsMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]PurgeCgDlsMessages at purgeCgDlsMessages: At station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+	serv.Noticef("[tenant: %v][user: %v]Consumer group %v has been removed from %v dead-letter messages of station %v", user.TenantName, user.Username, body.CgName, removed, body.StationName)
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := make(map[string]interface{})
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-purge-cg-dls-messages")
+	}
+
+	c.IndentedJSON(200, gin.H{"removed": removed})
+}
+
+func (sh StationsHandler) PurgeStation(c *gin.Context) {
+	var body models.PurgeStationSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	stationName, err := StationNameFromStr(body.StationName)
+	if err != nil {
+		serv.Warnf("PurgeStation: station name at StationNameFromStr: %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("PurgeStation at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	exist, station, err := db.GetStationByName(stationName.Ext(), user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]PurgeStation at GetStationByName: %v", user.TenantName, user.Username, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	if !exist {
+		errMsg := fmt.Sprintf("Station %v does not exist", stationName.external)
+		serv.Warnf("[tenant: %v][user: %v]PurgeStation: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+
+	if body.PurgeStation && stationDeniesPurge(station.Immutability) {
+		errMsg := fmt.Sprintf("Station %v is immutable and can not be purged", stationName.external)
+		serv.Warnf("[tenant: %v][user: %v]PurgeStation: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+	if body.PurgeDls && station.Immutability.LegalHold {
+		errMsg := fmt.Sprintf("Station %v is under legal hold and its dead-letter messages can not be purged", stationName.external)
+		serv.Warnf("[tenant: %v][user: %v]PurgeStation: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+
+	if body.PurgeStation {
+		err = sh.S.PurgeStream(station.TenantName, stationName.Intern())
+		if err != nil && !IsNatsErr(err, JSStreamNotFoundErr) {
+			serv.Errorf("[tenant: %v][user: %v]PurgeStation: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+			return
+		}
+	}
+
+	if body.PurgeDls {
+		err := db.PurgeDlsMsgsFromStation(station.ID)
+		if err != nil {
+			serv.Errorf("[tenant: %v][user: %v]PurgeStation dls at PurgeDlsMsgsFromStation: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+			return
+		}
+	}
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := make(map[string]interface{})
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-purge-station")
+	}
+	c.IndentedJSON(200, gin.H{})
+}
+
+func (sh StationsHandler) RemoveMessages(c *gin.Context) {
+	var body models.RemoveMessagesSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("RemoveMessages at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	stationName, err := StationNameFromStr(body.StationName)
+	if err != nil {
+		serv.Warnf("[tenant: %v][user: %v]RemoveMessages at StationNameFromStr: station name: %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		return
+	}
+
+	exist, station, err := db.GetStationByName(stationName.Ext(), user.TenantName)
+	if err != nil {
+		serv.Errorf("[tenant: %v][user: %v]RemoveMessages at GetStationByName: %v", user.TenantName, user.Username, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+	if !exist {
+		errMsg := fmt.Sprintf("Station %v does not exist", stationName.external)
+		serv.Warnf("[tenant: %v][user: %v]RemoveMessages at GetStationByName: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+
+	if stationDeniesDelete(station.Immutability) {
+		errMsg := fmt.Sprintf("Station %v is immutable and its messages can not be removed", stationName.external)
+		serv.Warnf("[tenant: %v][user: %v]RemoveMessages: %v", user.TenantName, user.Username, errMsg)
+		c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": errMsg})
+		return
+	}
+
+	for _, msg := range body.MessageSeqs {
+		err = sh.S.RemoveMsg(station.TenantName, stationName, msg)
+		if err != nil {
+			if IsNatsErr(err, JSStreamNotFoundErr) || IsNatsErr(err, JSStreamMsgDeleteFailedF) {
+				continue
+			}
+			serv.Errorf("[tenant: %v][user: %v]RemoveMessages at RemoveMsg: %v", user.TenantName, user.Username, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+			return
+		}
+	}
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := make(map[string]interface{})
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-remove-messages")
 	}
 
 	c.IndentedJSON(200, gin.H{})
@@ -2295,3 +3048,142 @@ func (s *Server) handleResendAllFailure(user models.User, stationId int, tenantN
 		return
 	}
 }
+
+func (s *Server) rehydrateTieredStorage(user models.User, body models.RehydrateTieredStorageSchema) (models.RehydrateTieredStorageResponse, int, error) {
+	stationName, err := StationNameFromStr(body.StationName)
+	if err != nil {
+		return models.RehydrateTieredStorageResponse{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	exist, _, err := db.GetStationByName(stationName.Ext(), user.TenantName)
+	if err != nil {
+		return models.RehydrateTieredStorageResponse{}, 500, err
+	}
+	if !exist {
+		return models.RehydrateTieredStorageResponse{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v does not exist", stationName.Ext())
+	}
+	if !body.FromTime.IsZero() && !body.ToTime.IsZero() && body.FromTime.After(body.ToTime) {
+		return models.RehydrateTieredStorageResponse{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("from_time must be before to_time")
+	}
+
+	targetName := body.TargetStationName
+	if targetName == "" {
+		targetName = fmt.Sprintf("%v-rehydrated-%v", stationName.Ext(), time.Now().Unix())
+	}
+	targetStationName, err := StationNameFromStr(targetName)
+	if err != nil {
+		return models.RehydrateTieredStorageResponse{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	exist, _, err = db.GetStationByName(targetStationName.Ext(), user.TenantName)
+	if err != nil {
+		return models.RehydrateTieredStorageResponse{}, 500, err
+	}
+	if !exist {
+		_, _, err = CreateDefaultStation(user.TenantName, s, targetStationName, user.ID, user.Username)
+		if err != nil {
+			return models.RehydrateTieredStorageResponse{}, 500, err
+		}
+	}
+
+	objectsCount, msgsCount, failedCount, err := s.rehydrateFromTier2Storage(user.TenantName, stationName, targetStationName, body.FromTime, body.ToTime, body.ObjectNames)
+	resp := models.RehydrateTieredStorageResponse{
+		TargetStationName:   targetStationName.Ext(),
+		ObjectsCount:        objectsCount,
+		MessagesCount:       msgsCount,
+		FailedMessagesCount: failedCount,
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") || strings.Contains(err.Error(), "does not belong") || strings.Contains(err.Error(), "at a time") {
+			return resp, SHOWABLE_ERROR_STATUS_CODE, err
+		}
+		return resp, 500, err
+	}
+	return resp, 200, nil
+}
+
+func (sh StationsHandler) RehydrateTieredStorage(c *gin.Context) {
+	var body models.RehydrateTieredStorageSchema
+	ok := utils.Validate(c, &body, false, nil)
+	if !ok {
+		return
+	}
+
+	user, err := getUserDetailsFromMiddleware(c)
+	if err != nil {
+		serv.Errorf("RehydrateTieredStorage at getUserDetailsFromMiddleware: At station %v: %v", body.StationName, err.Error())
+		c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		return
+	}
+
+	resp, statusCode, err := sh.S.rehydrateTieredStorage(user, body)
+	if err != nil {
+		if statusCode == SHOWABLE_ERROR_STATUS_CODE {
+			serv.Warnf("[tenant: %v][user: %v]RehydrateTieredStorage at rehydrateTieredStorage: Station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(SHOWABLE_ERROR_STATUS_CODE, gin.H{"message": err.Error()})
+		} else {
+			serv.Errorf("[tenant: %v][user: %v]RehydrateTieredStorage at rehydrateTieredStorage: Station %v: %v", user.TenantName, user.Username, body.StationName, err.Error())
+			c.AbortWithStatusJSON(500, gin.H{"message": "Server error"})
+		}
+		return
+	}
+
+	shouldSendAnalytics, _ := shouldSendAnalytics()
+	if shouldSendAnalytics {
+		analyticsParams := map[string]interface{}{"objects-count": resp.ObjectsCount, "messages-count": resp.MessagesCount}
+		analytics.SendEvent(user.TenantName, user.Username, analyticsParams, "user-rehydrate-tiered-storage")
+	}
+	c.IndentedJSON(200, resp)
+}
+
+func (s *Server) rehydrateTieredStorageDirect(c *client, reply string, msg []byte) {
+	var rtsr rehydrateTieredStorageRequest
+	var resp rehydrateTieredStorageResponse
+	tenantName, message, err := s.getTenantNameAndMessage(msg)
+	if err != nil {
+		s.Errorf("rehydrateTieredStorageDirect at getTenantNameAndMessage: %v", err.Error())
+		respondWithRespErr(s.MemphisGlobalAccountString(), s, reply, err, &resp)
+		return
+	}
+	if err := json.Unmarshal([]byte(message), &rtsr); err != nil {
+		s.Errorf("[tenant: %v]rehydrateTieredStorageDirect at json.Unmarshal: %v", tenantName, err.Error())
+		respondWithRespErr(s.MemphisGlobalAccountString(), s, reply, err, &resp)
+		return
+	}
+
+	username, _, err := getUserAndTenantIdFromString(rtsr.Username)
+	if err != nil {
+		s.Warnf("[tenant: %v][user: %v]rehydrateTieredStorageDirect at getUserAndTenantIdFromString: %v", tenantName, rtsr.Username, err.Error())
+		respondWithRespErr(s.MemphisGlobalAccountString(), s, reply, err, &resp)
+		return
+	}
+	exist, user, err := memphis_cache.GetUser(username, tenantName)
+	if err != nil {
+		s.Errorf("[tenant: %v][user: %v]rehydrateTieredStorageDirect at memphis_cache.GetUser: %v", tenantName, rtsr.Username, err.Error())
+		respondWithRespErr(s.MemphisGlobalAccountString(), s, reply, err, &resp)
+		return
+	}
+	if !exist {
+		err = fmt.Errorf("user %v does not exist", username)
+		s.Warnf("[tenant: %v][user: %v]rehydrateTieredStorageDirect: %v", tenantName, rtsr.Username, err.Error())
+		respondWithRespErr(s.MemphisGlobalAccountString(), s, reply, err, &resp)
+		return
+	}
+
+	body := models.RehydrateTieredStorageSchema{
+		StationName:       rtsr.StationName,
+		TargetStationName: rtsr.TargetStationName,
+		FromTime:          rtsr.FromTime,
+		ToTime:            rtsr.ToTime,
+		ObjectNames:       rtsr.ObjectNames,
+	}
+	result, _, err := s.rehydrateTieredStorage(user, body)
+	resp.TargetStationName = result.TargetStationName
+	resp.ObjectsCount = result.ObjectsCount
+	resp.MessagesCount = result.MessagesCount
+	resp.FailedMessagesCount = result.FailedMessagesCount
+	if err != nil {
+		s.Warnf("[tenant: %v][user: %v]rehydrateTieredStorageDirect at rehydrateTieredStorage: Station %v: %v", tenantName, rtsr.Username, rtsr.StationName, err.Error())
+		respondWithRespErr(s.MemphisGlobalAccountString(), s, reply, err, &resp)
+		return
+	}
+	respondWithResp(s.MemphisGlobalAccountString(), s, reply, &resp)
+}
@@ -140,6 +140,11 @@ func removeTenantResources(tenantName string, user models.User) error {
 		return err
 	}
 
+	err = db.RemoveAlertRulesByTenant(tenantName)
+	if err != nil {
+		return err
+	}
+
 	err = db.RemoveAuditLogsByTenant(tenantName)
 	if err != nil {
 		return err
@@ -150,6 +155,11 @@ func removeTenantResources(tenantName string, user models.User) error {
 		return err
 	}
 
+	err = db.RemoveDlsRetryPoliciesByTenant(tenantName)
+	if err != nil {
+		return err
+	}
+
 	err = db.RemoveStationsByTenant(tenantName)
 	if err != nil {
 		return err
@@ -729,7 +739,7 @@ func (umh UserMgmtHandler) GetFilterDetails(c *gin.Context) {
 			return
 		}
 
-		schemaType := []string{"protobuf", "json", "graphql"}
+		schemaType := []string{"protobuf", "json", "graphql", "avro"}
 		usage := []string{"used", "not used"}
 		c.IndentedJSON(200, gin.H{"tags": tags, "users": users, "type": schemaType, "usage": usage})
 		return
@@ -35,6 +35,8 @@ const (
 	memphisWS_Subj_SysLogsData          = "syslogs_data"
 	memphisWS_Subj_AllSchemasData       = "get_all_schema_data"
 	memphisWS_Subj_GetSystemMessages    = "get_system_messages"
+	memphisWS_Subj_CgsLagData           = "cgs_lag_data"
+	memphisWS_Subj_DlsBulkTasksData     = "dls_bulk_tasks_data"
 	ws_updates_interval_sec             = 5
 )
 
@@ -243,11 +245,34 @@ func memphisWSGetReqFillerFromSubj(s *Server, h *Handlers, subj string, tenantNa
 		return func(string) (any, error) {
 			return h.userMgmt.GetRelevantSystemMessages()
 		}, nil
+	case memphisWS_Subj_CgsLagData:
+		filter := getCgsLagFilterFromWSSubj(subj)
+		return func(string) (any, error) {
+			cgsLag, _, err := h.Consumers.GetCgsLag(tenantName, filter)
+			return cgsLag, err
+		}, nil
+	case memphisWS_Subj_DlsBulkTasksData:
+		stationName := strings.Join(strings.Split(subj, ".")[1:], ".")
+		if stationName == _EMPTY_ {
+			return nil, errors.New("invalid station name")
+		}
+		return func(string) (any, error) {
+			return s.getDlsBulkTasks(tenantName, stationName)
+		}, nil
 	default:
 		return nil, errors.New("invalid subject")
 	}
 }
 
+// getCgsLagFilterFromWSSubj parses cgs_lag_data.<sort_by>.<tag_name>, tag names may contain dots
+func getCgsLagFilterFromWSSubj(subj string) models.GetCgsLagSchema {
+	filter := models.GetCgsLagSchema{SortBy: tokenAt(subj, 2)}
+	if tokens := strings.Split(subj, "."); len(tokens) > 2 {
+		filter.TagName = strings.Join(tokens[2:], ".")
+	}
+	return filter
+}
+
 func memphisWSGetStationOverviewData(s *Server, h *Handlers, stationName string, tenantName string) (map[string]any, error) {
 	sn, err := StationNameFromStr(stationName)
 	if err != nil {
@@ -343,6 +368,12 @@ func memphisWSGetStationOverviewData(s *Server, h *Handlers, stationName string,
 				"dls_configuration_poison":      station.DlsConfigurationPoison,
 				"dls_configuration_schemaverse": station.DlsConfigurationSchemaverse,
 				"total_dls_messages":            totalDlsAmount,
+				"mirror":                        station.Mirror,
+				"sources":                       station.Sources,
+				"immutability":                  station.Immutability,
+				"retention_policy":              station.RetentionPolicy,
+				"dls_station":                   station.DlsStation,
+				"dls_station_only":              station.DlsStationOnly,
 			}
 		} else {
 			response = map[string]any{
@@ -366,6 +397,12 @@ func memphisWSGetStationOverviewData(s *Server, h *Handlers, stationName string,
 				"dls_configuration_poison":      station.DlsConfigurationPoison,
 				"dls_configuration_schemaverse": station.DlsConfigurationSchemaverse,
 				"total_dls_messages":            totalDlsAmount,
+				"mirror":                        station.Mirror,
+				"sources":                       station.Sources,
+				"immutability":                  station.Immutability,
+				"retention_policy":              station.RetentionPolicy,
+				"dls_station":                   station.DlsStation,
+				"dls_station_only":              station.DlsStationOnly,
 			}
 		}
 
@@ -405,6 +442,12 @@ func memphisWSGetStationOverviewData(s *Server, h *Handlers, stationName string,
 		"dls_configuration_poison":      station.DlsConfigurationPoison,
 		"dls_configuration_schemaverse": station.DlsConfigurationSchemaverse,
 		"total_dls_messages":            totalDlsAmount,
+		"mirror":                        station.Mirror,
+		"sources":                       station.Sources,
+		"immutability":                  station.Immutability,
+		"retention_policy":              station.RetentionPolicy,
+		"dls_station":                   station.DlsStation,
+		"dls_station_only":              station.DlsStationOnly,
 	}
 
 	return response, nil
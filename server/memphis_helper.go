@@ -73,6 +73,7 @@ var memphisExportString = `[
 	{service: "$memphis_schema_attachments"},
 	{service: "$memphis_schema_detachments"},
 	{service: "$memphis_schema_creations"},
+	{service: "$memphis_tiered_storage_rehydrations"},
 	{service: "$memphis_ws_subs.>"},
 	{service: "$memphis_integration_updates"},
 	{service: "$memphis_notifications"},
@@ -93,6 +94,7 @@ var memphisImportString = `[
 	{service: {account: "$memphis", subject: "$memphis_schema_attachments"}},
 	{service: {account: "$memphis", subject: "$memphis_schema_detachments"}},
 	{service: {account: "$memphis", subject: "$memphis_schema_creations"}},
+	{service: {account: "$memphis", subject: "$memphis_tiered_storage_rehydrations"}},
 	{service: {account: "$memphis", subject: "$memphis_ws_subs.>"}},
 	{service: {account: "$memphis", subject: "$memphis_integration_updates"}},
 	{service: {account: "$memphis", subject: "$memphis_notifications"}},
@@ -187,23 +189,7 @@ func RemoveUser(username string) error {
 	return nil
 }
 
-func (s *Server) CreateStream(tenantName string, sn StationName, retentionType string, retentionValue int, storageType string, idempotencyW int64, replicas int, tieredStorageEnabled bool) error {
-	var maxMsgs int
-	if retentionType == "messages" && retentionValue > 0 {
-		maxMsgs = retentionValue
-	} else {
-		maxMsgs = -1
-	}
-
-	var maxBytes int
-	if retentionType == "bytes" && retentionValue > 0 {
-		maxBytes = retentionValue
-	} else {
-		maxBytes = -1
-	}
-
-	maxAge := GetStationMaxAge(retentionType, retentionValue)
-
+func (s *Server) CreateStream(tenantName string, sn StationName, retentionType string, retentionValue int, retentionPolicy models.StationRetentionPolicy, storageType string, idempotencyW int64, replicas int, tieredStorageEnabled bool, mirror *models.StationSource, sources []models.StationSource, immutability models.StationImmutability) error {
 	var storage StorageType
 	if storageType == "memory" {
 		storage = MemoryStorage
@@ -220,23 +206,41 @@ func (s *Server) CreateStream(tenantName string, sn StationName, retentionType s
 		idempotencyWindow = time.Duration(idempotencyW) * time.Millisecond
 	}
 
-	return s.
-		memphisAddStream(tenantName, &StreamConfig{
-			Name:                 sn.Intern(),
-			Subjects:             []string{sn.Intern() + ".>"},
-			Retention:            LimitsPolicy,
-			MaxConsumers:         -1,
-			MaxMsgs:              int64(maxMsgs),
-			MaxBytes:             int64(maxBytes),
-			Discard:              DiscardOld,
-			MaxAge:               maxAge,
-			MaxMsgsPer:           -1,
-			Storage:              storage,
-			Replicas:             replicas,
-			NoAck:                false,
-			Duplicates:           idempotencyWindow,
-			TieredStorageEnabled: tieredStorageEnabled,
-		})
+	sc := &StreamConfig{
+		Name:                 sn.Intern(),
+		Subjects:             []string{sn.Intern() + ".>"},
+		Retention:            LimitsPolicy,
+		MaxConsumers:         -1,
+		Storage:              storage,
+		Replicas:             replicas,
+		NoAck:                false,
+		Duplicates:           idempotencyWindow,
+		TieredStorageEnabled: tieredStorageEnabled,
+	}
+	applyStationRetention(sc, retentionType, retentionValue, retentionPolicy)
+
+	if mirror != nil {
+		ss, err := getStreamSource(tenantName, *mirror)
+		if err != nil {
+			return err
+		}
+		// mirrors are read only and can not listen on subjects of their own
+		sc.Subjects = nil
+		sc.Mirror = ss
+	}
+	for _, src := range sources {
+		ss, err := getStreamSource(tenantName, src)
+		if err != nil {
+			return err
+		}
+		sc.Sources = append(sc.Sources, ss)
+	}
+
+	// jetstream does not allow creating sealed streams
+	immutability.Sealed = false
+	applyStationImmutability(sc, immutability)
+
+	return s.memphisAddStream(tenantName, sc)
 }
 
 func (s *Server) WaitForLeaderElection() {
@@ -310,6 +314,18 @@ func (s *Server) CreateInternalJetStreamResources() {
 	s.popFallbackLogs()
 }
 
+func tieredStorageConsumerConfig(uploadIntervalSec int) ConsumerConfig {
+	return ConsumerConfig{
+		DeliverPolicy: DeliverAll,
+		AckPolicy:     AckExplicit,
+		Durable:       TIERED_STORAGE_CONSUMER,
+		FilterSubject: tieredStorageStream + ".>",
+		AckWait:       time.Duration(2) * time.Duration(uploadIntervalSec) * time.Second,
+		MaxAckPending: -1,
+		MaxDeliver:    -1,
+	}
+}
+
 func tryCreateInternalJetStreamResources(s *Server, retentionDur time.Duration, successCh chan error, isCluster bool) {
 	replicas := 1
 	if isCluster {
@@ -373,24 +389,15 @@ func tryCreateInternalJetStreamResources(s *Server, retentionDur time.Duration,
 		TIERED_STORAGE_STREAM_CREATED = true
 	}
 
-	// create tiered storage consumer
-	if !TIERED_STORAGE_CONSUMER_CREATED {
-		cc := ConsumerConfig{
-			DeliverPolicy: DeliverAll,
-			AckPolicy:     AckExplicit,
-			Durable:       TIERED_STORAGE_CONSUMER,
-			FilterSubject: tieredStorageStream + ".>",
-			AckWait:       time.Duration(2) * time.Duration(s.opts.TieredStorageUploadIntervalSec) * time.Second,
-			MaxAckPending: -1,
-			MaxDeliver:    10,
-		}
-		err = serv.memphisAddConsumer(s.MemphisGlobalAccountString(), tieredStorageStream, &cc)
-		if err != nil {
-			successCh <- err
-			return
-		}
-		TIERED_STORAGE_CONSUMER_CREATED = true
+	// create tiered storage consumer, a recovered consumer is updated as well since older versions
+	// limited its deliveries and messages exceeding them would never be uploaded
+	cc := tieredStorageConsumerConfig(s.opts.TieredStorageUploadIntervalSec)
+	err = serv.memphisAddConsumer(s.MemphisGlobalAccountString(), tieredStorageStream, &cc)
+	if err != nil {
+		successCh <- err
+		return
 	}
+	TIERED_STORAGE_CONSUMER_CREATED = true
 
 	// dls unacked messages stream
 	if !DLS_UNACKED_STREAM_CREATED {
@@ -623,7 +630,7 @@ func (s *Server) CreateConsumer(tenantName string, consumer models.Consumer, sta
 		AckPolicy:     AckExplicit,
 		AckWait:       time.Duration(maxAckTimeMs) * time.Millisecond,
 		MaxDeliver:    MaxMsgDeliveries,
-		FilterSubject: stationName.Intern() + ".final",
+		FilterSubject: getStationConsumerFilterSubject(station, stationName),
 		ReplayPolicy:  ReplayInstant,
 		MaxAckPending: -1,
 		HeadersOnly:   false,
@@ -693,10 +700,15 @@ func (s *Server) GetCgInfo(tenantName string, stationName StationName, cgName st
 }
 
 func (s *Server) RemoveStream(tenantName, streamName string) error {
+	streamInfo, err := s.memphisStreamInfo(tenantName, streamName)
+	if err == nil && isStreamImmutable(streamInfo.Config) {
+		return ErrStationImmutable
+	}
+
 	requestSubject := fmt.Sprintf(JSApiStreamDeleteT, streamName)
 
 	var resp JSApiStreamDeleteResponse
-	err := jsApiRequest(tenantName, s, requestSubject, kindDeleteStream, []byte(_EMPTY_), &resp)
+	err = jsApiRequest(tenantName, s, requestSubject, kindDeleteStream, []byte(_EMPTY_), &resp)
 	if err != nil {
 		return err
 	}
@@ -862,7 +874,7 @@ func (s *Server) GetMessages(station models.Station, messagesToFetch int) ([]mod
 		messagesToFetch = int(totalMessages)
 	}
 
-	filterSubj := stationName.Intern() + ".final"
+	filterSubj := getStationConsumerFilterSubject(station, stationName)
 	if !station.IsNative {
 		filterSubj = ""
 	}
@@ -880,54 +892,19 @@ func (s *Server) GetMessages(station models.Station, messagesToFetch int) ([]mod
 	}
 
 	stationIsNative := station.IsNative
+	decoder, err := newStationMessageDecoder(station)
+	if err != nil {
+		s.Warnf("[tenant: %v]GetMessages at newStationMessageDecoder: station %v: %v", station.TenantName, station.Name, err.Error())
+	}
 
 	for _, msg := range msgs {
-		messageDetails := models.MessageDetails{
-			MessageSeq: int(msg.Sequence),
-			TimeSent:   msg.Time,
-			Size:       len(msg.Subject) + len(msg.Data) + len(msg.Header),
-		}
-
-		data := hex.EncodeToString(msg.Data)
-		if len(data) > 80 { // get the first chars for preview needs
-			data = data[0:80]
+		messageDetails, skip, err := getMessageDetailsFromStoredMsg(msg, stationIsNative, decoder)
+		if err != nil {
+			return []models.MessageDetails{}, err
 		}
-		messageDetails.Data = data
-
-		var headersJson map[string]string
-		if stationIsNative {
-			if msg.Header != nil {
-				headersJson, err = DecodeHeader(msg.Header)
-				if err != nil {
-					return nil, err
-				}
-			}
-			connectionIdHeader := headersJson["$memphis_connectionId"]
-			producedByHeader := strings.ToLower(headersJson["$memphis_producedBy"])
-
-			// This check for backward compatability
-			if connectionIdHeader == "" || producedByHeader == "" {
-				connectionIdHeader = headersJson["connectionId"]
-				producedByHeader = strings.ToLower(headersJson["producedBy"])
-				if connectionIdHeader == "" || producedByHeader == "" {
-					return []models.MessageDetails{}, errors.New("missing mandatory message headers, please upgrade the SDK version you are using")
-				}
-			}
-
-			for header := range headersJson {
-				if strings.HasPrefix(header, "$memphis") {
-					delete(headersJson, header)
-				}
-			}
-
-			if producedByHeader == "$memphis_dls" { // skip poison messages which have been resent
-				continue
-			}
-			messageDetails.ProducedBy = producedByHeader
-			messageDetails.ConnectionId = connectionIdHeader
-			messageDetails.Headers = headersJson
+		if skip {
+			continue
 		}
-
 		messages = append(messages, messageDetails)
 	}
 
@@ -938,6 +915,61 @@ func (s *Server) GetMessages(station models.Station, messagesToFetch int) ([]mod
 	return messages, nil
 }
 
+// getMessageDetailsFromStoredMsg builds the message preview shown in the station page,
+// skip is true for poison messages which have been resent and should not be listed
+func getMessageDetailsFromStoredMsg(msg StoredMsg, stationIsNative bool, decoder *messageDecoder) (models.MessageDetails, bool, error) {
+	messageDetails := models.MessageDetails{
+		MessageSeq: int(msg.Sequence),
+		TimeSent:   msg.Time,
+		Size:       len(msg.Subject) + len(msg.Data) + len(msg.Header),
+	}
+
+	messageDetails.Data = messagePreview(hex.EncodeToString(msg.Data)) // get the first chars for preview needs
+	if decoder != nil {
+		decoded, validation := decoder.decode(msg.Data, false)
+		messageDetails.DecodedData = messagePreview(decoded)
+		messageDetails.SchemaValidation = validation
+	}
+
+	if !stationIsNative {
+		return messageDetails, false, nil
+	}
+
+	var headersJson map[string]string
+	var err error
+	if msg.Header != nil {
+		headersJson, err = DecodeHeader(msg.Header)
+		if err != nil {
+			return models.MessageDetails{}, false, err
+		}
+	}
+	connectionIdHeader := headersJson["$memphis_connectionId"]
+	producedByHeader := strings.ToLower(headersJson["$memphis_producedBy"])
+
+	// This check for backward compatability
+	if connectionIdHeader == "" || producedByHeader == "" {
+		connectionIdHeader = headersJson["connectionId"]
+		producedByHeader = strings.ToLower(headersJson["producedBy"])
+		if connectionIdHeader == "" || producedByHeader == "" {
+			return models.MessageDetails{}, false, errors.New("missing mandatory message headers, please upgrade the SDK version you are using")
+		}
+	}
+
+	for header := range headersJson {
+		if strings.HasPrefix(header, "$memphis") {
+			delete(headersJson, header)
+		}
+	}
+
+	if producedByHeader == "$memphis_dls" { // skip poison messages which have been resent
+		return models.MessageDetails{}, true, nil
+	}
+	messageDetails.ProducedBy = producedByHeader
+	messageDetails.ConnectionId = connectionIdHeader
+	messageDetails.Headers = headersJson
+	return messageDetails, false, nil
+}
+
 func getHdrLastIdxFromRaw(msg []byte) int {
 	inCrlf := false
 	inDouble := false
@@ -959,17 +991,29 @@ func getHdrLastIdxFromRaw(msg []byte) int {
 }
 
 func (s *Server) memphisGetMsgs(tenantName, filterSubj, streamName string, startSeq uint64, amount int, timeout time.Duration, findHeader bool) ([]StoredMsg, error) {
-	uid, _ := uuid.NewV4()
-	durableName := "$memphis_fetch_messages_consumer_" + uid.String()
-
 	cc := ConsumerConfig{
 		FilterSubject: filterSubj,
 		OptStartSeq:   startSeq,
 		DeliverPolicy: DeliverByStartSequence,
-		Durable:       durableName,
-		AckPolicy:     AckExplicit,
-		Replicas:      1,
 	}
+	return s.memphisFetchMsgs(tenantName, streamName, cc, amount, timeout, findHeader)
+}
+
+func (s *Server) memphisGetMsgsByStartTime(tenantName, filterSubj, streamName string, startTime time.Time, amount int, timeout time.Duration, findHeader bool) ([]StoredMsg, error) {
+	cc := ConsumerConfig{
+		FilterSubject: filterSubj,
+		OptStartTime:  &startTime,
+		DeliverPolicy: DeliverByStartTime,
+	}
+	return s.memphisFetchMsgs(tenantName, streamName, cc, amount, timeout, findHeader)
+}
+
+func (s *Server) memphisFetchMsgs(tenantName, streamName string, cc ConsumerConfig, amount int, timeout time.Duration, findHeader bool) ([]StoredMsg, error) {
+	uid, _ := uuid.NewV4()
+	durableName := "$memphis_fetch_messages_consumer_" + uid.String()
+	cc.Durable = durableName
+	cc.AckPolicy = AckExplicit
+	cc.Replicas = 1
 
 	err := s.memphisAddConsumer(tenantName, streamName, &cc)
 	if err != nil {
@@ -991,34 +1035,12 @@ func (s *Server) memphisGetMsgs(tenantName, filterSubj, streamName string, start
 			// ack
 			s.sendInternalAccountMsg(account, reply, []byte(_EMPTY_))
 
-			rawTs := tokenAt(reply, 8)
-			seq, _, _ := ackReplyInfo(reply)
-
-			intTs, err := strconv.Atoi(rawTs)
+			storedMsg, err := parseFetchedMsg(reply, msg, findHeader)
 			if err != nil {
 				s.Errorf("memphisGetMsgs: %v", err.Error())
 				return
 			}
-
-			dataFirstIdx := 0
-			dataLen := len(msg)
-			if findHeader {
-				dataFirstIdx = getHdrLastIdxFromRaw(msg) + 1
-				if dataFirstIdx > len(msg)-len(CR_LF) {
-					s.Errorf("memphisGetMsgs: memphis error parsing in station get messages")
-					return
-				}
-
-				dataLen = len(msg) - dataFirstIdx
-			}
-			dataLen -= len(CR_LF)
-
-			respCh <- StoredMsg{
-				Sequence: uint64(seq),
-				Header:   msg[:dataFirstIdx],
-				Data:     msg[dataFirstIdx : dataFirstIdx+dataLen],
-				Time:     time.Unix(0, int64(intTs)),
-			}
+			respCh <- storedMsg
 		}(responseChan, reply, copyBytes(msg), findHeader)
 	})
 	if err != nil {
@@ -1046,6 +1068,145 @@ cleanup:
 	return msgs, nil
 }
 
+func parseFetchedMsg(reply string, msg []byte, findHeader bool) (StoredMsg, error) {
+	rawTs := tokenAt(reply, 8)
+	seq, _, _ := ackReplyInfo(reply)
+
+	intTs, err := strconv.Atoi(rawTs)
+	if err != nil {
+		return StoredMsg{}, err
+	}
+
+	dataFirstIdx := 0
+	dataLen := len(msg)
+	if findHeader {
+		dataFirstIdx = getHdrLastIdxFromRaw(msg) + 1
+		if dataFirstIdx > len(msg)-len(CR_LF) {
+			return StoredMsg{}, errors.New("memphis error parsing in station get messages")
+		}
+
+		dataLen = len(msg) - dataFirstIdx
+	}
+	dataLen -= len(CR_LF)
+
+	return StoredMsg{
+		Sequence: uint64(seq),
+		Header:   msg[:dataFirstIdx],
+		Data:     msg[dataFirstIdx : dataFirstIdx+dataLen],
+		Time:     time.Unix(0, int64(intTs)),
+	}, nil
+}
+
+// memphisMsgsFetcher reads a stream in order through a single ephemeral consumer which requires no acks,
+// so a scan over many batches does not create and delete a consumer for each of them
+type memphisMsgsFetcher struct {
+	s           *Server
+	account     *Account
+	tenantName  string
+	streamName  string
+	name        string
+	reply       string
+	sub         *subscription
+	msgs        chan StoredMsg
+	pending     uint64
+	outstanding int
+}
+
+func (s *Server) newMemphisMsgsFetcher(tenantName, streamName string, cc ConsumerConfig, maxBatch int, findHeader bool) (*memphisMsgsFetcher, error) {
+	cc.AckPolicy = AckNone
+	cc.Replicas = 1
+	cc.MemoryStorage = true
+	// removes the consumer in case the fetcher is not closed
+	cc.InactiveThreshold = time.Minute
+
+	request := CreateConsumerRequest{Stream: streamName, Config: cc}
+	rawRequest, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var resp JSApiConsumerCreateResponse
+	err = jsApiRequest(tenantName, s, fmt.Sprintf(JSApiConsumerCreateT, streamName), kindCreateConsumer, rawRequest, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if err = resp.ToError(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.lookupAccount(tenantName)
+	if err != nil {
+		s.memphisRemoveConsumer(tenantName, streamName, resp.Name)
+		return nil, err
+	}
+	uid, _ := uuid.NewV4()
+	f := &memphisMsgsFetcher{
+		s:          s,
+		account:    account,
+		tenantName: tenantName,
+		streamName: streamName,
+		name:       resp.Name,
+		reply:      "$memphis_fetch_messages_" + uid.String() + "_reply",
+		msgs:       make(chan StoredMsg, maxBatch),
+		pending:    resp.NumPending,
+	}
+	// messages are parsed inline to keep the stream order, at most maxBatch messages are requested at a time
+	f.sub, err = s.subscribeOnAcc(account, f.reply, f.reply+"_sid", func(_ *client, subject, reply string, msg []byte) {
+		storedMsg, err := parseFetchedMsg(reply, copyBytes(msg), findHeader)
+		if err != nil {
+			s.Errorf("memphisMsgsFetcher: %v", err.Error())
+		}
+		select {
+		case f.msgs <- storedMsg:
+		default:
+			// can not happen while no more than maxBatch messages are outstanding, delivery must never block
+		}
+	})
+	if err != nil {
+		s.memphisRemoveConsumer(tenantName, streamName, resp.Name)
+		return nil, err
+	}
+	return f, nil
+}
+
+// fetch returns up to amount messages, never asking for more than the consumer had pending when it was created
+// so reading the tail of the stream does not wait for the timeout
+func (f *memphisMsgsFetcher) fetch(amount int, timeout time.Duration) []StoredMsg {
+	if uint64(amount) > f.pending {
+		amount = int(f.pending)
+	}
+	if amount == 0 {
+		return nil
+	}
+	if f.outstanding < amount {
+		req := []byte(strconv.Itoa(amount - f.outstanding))
+		f.s.sendInternalAccountMsgWithReply(f.account, fmt.Sprintf(JSApiRequestNextT, f.streamName, f.name), f.reply, nil, req, true)
+		f.outstanding = amount
+	}
+
+	var msgs []StoredMsg
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(msgs) < amount {
+		select {
+		case <-timer.C:
+			return msgs
+		case msg := <-f.msgs:
+			f.outstanding--
+			f.pending--
+			// messages which could not be parsed keep the count of outstanding messages right
+			if msg.Sequence > 0 {
+				msgs = append(msgs, msg)
+			}
+		}
+	}
+	return msgs
+}
+
+func (f *memphisMsgsFetcher) close() {
+	f.s.unsubscribeOnAcc(f.account, f.sub)
+	f.s.memphisRemoveConsumer(f.tenantName, f.streamName, f.name)
+}
+
 func (s *Server) GetMessage(tenantName string, stationName StationName, msgSeq uint64) (*StoredMsg, error) {
 	return s.memphisGetMessage(tenantName, stationName.Intern(), msgSeq)
 }
@@ -1091,6 +1252,29 @@ func (s *Server) memphisGetMessage(tenantName, streamName string, msgSeq uint64)
 	return resp.Message, nil
 }
 
+// memphisGetNextMessage returns the first message with a sequence greater than or equal to startSeq
+func (s *Server) memphisGetNextMessage(tenantName, streamName, filterSubj string, startSeq uint64) (*StoredMsg, error) {
+	requestSubject := fmt.Sprintf(JSApiMsgGetT, streamName)
+	request := JSApiMsgGetRequest{Seq: startSeq, NextFor: filterSubj}
+	rawRequest, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp JSApiMsgGetResponse
+	err = jsApiRequest(tenantName, s, requestSubject, kindGetMsg, rawRequest, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	err = resp.ToError()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Message, nil
+}
+
 func (s *Server) queueSubscribe(tenantName string, subj, queueGroupName string, cb simplifiedMsgHandler) error {
 	acc, err := s.lookupAccount(tenantName)
 	if err != nil {
@@ -1168,6 +1352,73 @@ func (s *Server) sendInternalMsgWithHeaderLocked(acc *Account, subj string, hdr
 	s.mu.Unlock()
 }
 
+type jsPublishMsg struct {
+	hdr  map[string]string
+	data []byte
+}
+
+// publishWithAcks publishes the messages into a stream and waits for the stream to ack all of them,
+// so bulk publishes are paced by the stream instead of piling up in the internal send queue,
+// it returns the error of every message the stream rejected or did not ack by its index
+func (s *Server) publishWithAcks(acc *Account, subject string, msgs []jsPublishMsg, timeout time.Duration) ([]error, error) {
+	type pubAck struct {
+		index int
+		msg   []byte
+	}
+	reply := "$memphis_pub_ack_" + nuid.Next()
+	acks := make(chan pubAck, len(msgs))
+	sub, err := s.subscribeOnAcc(acc, reply+".*", reply+"_sid", func(_ *client, subject, _ string, msg []byte) {
+		index, err := strconv.Atoi(strings.TrimPrefix(subject, reply+"."))
+		if err != nil || index < 0 || index >= len(msgs) {
+			return
+		}
+		select {
+		case acks <- pubAck{index: index, msg: copyBytes(msg)}:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer s.unsubscribeOnAcc(acc, sub)
+
+	for i, msg := range msgs {
+		s.sendInternalAccountMsgWithReply(acc, subject, reply+"."+strconv.Itoa(i), msg.hdr, msg.data, true)
+	}
+
+	errs := make([]error, len(msgs))
+	acked := make([]bool, len(msgs))
+	received := 0
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for received < len(msgs) {
+		select {
+		case ack := <-acks:
+			if acked[ack.index] {
+				continue
+			}
+			acked[ack.index] = true
+			received++
+			var resp JSPubAckResponse
+			err := json.Unmarshal(ack.msg, &resp)
+			if err != nil {
+				errs[ack.index] = err
+			} else if resp.Error != nil {
+				errs[ack.index] = resp.Error
+			}
+		case <-timer.C:
+			err := fmt.Errorf("timed out waiting for the acks of %v out of %v messages published to %v", len(msgs)-received, len(msgs), subject)
+			for i := range msgs {
+				if !acked[i] {
+					errs[i] = err
+				}
+			}
+			return errs, err
+		}
+	}
+	return errs, nil
+}
+
 func DecodeHeader(buf []byte) (map[string]string, error) {
 	tp := textproto.NewReader(bufio.NewReader(bytes.NewReader(buf)))
 	l, err := tp.ReadLine()
@@ -1476,7 +1727,7 @@ func (s *Server) MoveResourcesFromOldToNewDefaultAcc() error {
 			return err
 		}
 		stationsMap[station.ID] = station
-		err = s.CreateStream(MEMPHIS_GLOBAL_ACCOUNT, stationName, station.RetentionType, station.RetentionValue, station.StorageType, station.IdempotencyWindow, station.Replicas, station.TieredStorageEnabled)
+		err = s.CreateStream(MEMPHIS_GLOBAL_ACCOUNT, stationName, station.RetentionType, station.RetentionValue, station.RetentionPolicy, station.StorageType, station.IdempotencyWindow, station.Replicas, station.TieredStorageEnabled, station.Mirror, station.Sources, station.Immutability)
 		if err != nil {
 			return err
 		}
@@ -104,6 +104,7 @@ func memphisCreateNonNativeStationIfNeeded(s *Server, reply string, cfg StreamCo
 				},
 				Username:             username,
 				TieredStorageEnabled: false,
+				RetentionPolicy:      getRetentionPolicyFromStreamConfig(cfg),
 			}
 
 			s.createStationDirectIntern(c, reply, &csr, false)
@@ -184,6 +185,19 @@ func (s *Server) memphisJSApiWrapStreamCreate(sub *subscription, c *client, _ *A
 }
 
 func (s *Server) memphisJSApiWrapStreamDelete(sub *subscription, c *client, acc *Account, subject, reply string, rmsg []byte) {
+	var resp = JSApiStreamDeleteResponse{ApiResponse: ApiResponse{Type: JSApiStreamDeleteResponseType}}
+	ci, reqAcc, _, msg, err := s.getRequestInfo(c, rmsg)
+	if err != nil {
+		s.Warnf(badAPIRequestT, msg)
+		return
+	}
+
+	if cfg, ok := s.memphisLocalStreamConfig(reqAcc, streamNameFromSubject(subject)); ok && isStreamImmutable(cfg) {
+		resp.Error = NewJSStreamDeleteError(ErrStationImmutable)
+		s.sendAPIErrResponse(ci, reqAcc, subject, reply, string(msg), s.jsonResponse(&resp))
+		return
+	}
+
 	go memphisDeleteNonNativeStationIfNeeded(s, reply, streamNameFromSubject(subject), c)
 
 	s.jsStreamDeleteRequestIntern(sub, c, acc, subject, reply, rmsg)
@@ -0,0 +1,240 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	searchMessagesDefaultLimit = 50
+	searchMessagesMaxLimit     = 1000
+	searchMessagesBatchSize    = 1000
+	// bounds the work of a single search request, the returned cursor resumes the scan from where it stopped
+	searchMessagesMaxScanned = 100000
+)
+
+var ErrInvalidSearchCursor = errors.New("invalid cursor")
+
+func encodeSearchCursor(seq uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(seq, 10)))
+}
+
+func decodeSearchCursor(cursor string) (uint64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidSearchCursor
+	}
+	seq, err := strconv.ParseUint(string(raw), 10, 64)
+	if err != nil || seq == 0 {
+		return 0, ErrInvalidSearchCursor
+	}
+	return seq, nil
+}
+
+func validateSearchMessagesSchema(body *models.SearchMessagesSchema) error {
+	if !body.FromTime.IsZero() && !body.ToTime.IsZero() && body.FromTime.After(body.ToTime) {
+		return errors.New("from_time must be before to_time")
+	}
+	if body.FromSeq > 0 && body.ToSeq > 0 && body.FromSeq > body.ToSeq {
+		return errors.New("from_seq must be lower than to_seq")
+	}
+	if body.HeaderValue != _EMPTY_ && body.HeaderKey == _EMPTY_ {
+		return errors.New("header_value requires header_key")
+	}
+	if body.JsonValue != _EMPTY_ && body.JsonPath == _EMPTY_ {
+		return errors.New("json_value requires json_path")
+	}
+	if body.Limit < 0 || body.Limit > searchMessagesMaxLimit {
+		return fmt.Errorf("limit must be between 1 and %v", searchMessagesMaxLimit)
+	}
+	if body.Limit == 0 {
+		body.Limit = searchMessagesDefaultLimit
+	}
+	return nil
+}
+
+// messageMatchesSearch checks the filters which are not applied by the scanned range itself
+func messageMatchesSearch(body models.SearchMessagesSchema, msg StoredMsg, details models.MessageDetails) bool {
+	if body.FromSeq > 0 && msg.Sequence < body.FromSeq {
+		return false
+	}
+	if !body.FromTime.IsZero() && msg.Time.Before(body.FromTime) {
+		return false
+	}
+	if body.ProducerName != _EMPTY_ && details.ProducedBy != strings.ToLower(body.ProducerName) {
+		return false
+	}
+	if body.HeaderKey != _EMPTY_ {
+		value, ok := details.Headers[body.HeaderKey]
+		if !ok || (body.HeaderValue != _EMPTY_ && value != body.HeaderValue) {
+			return false
+		}
+	}
+	if body.PayloadContains != _EMPTY_ && !bytes.Contains(msg.Data, []byte(body.PayloadContains)) {
+		return false
+	}
+	if body.JsonPath != _EMPTY_ {
+		value, ok := jsonPathLookup(msg.Data, body.JsonPath)
+		if !ok || (body.JsonValue != _EMPTY_ && value != body.JsonValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonPathLookup resolves a dotted path such as "$.user.addresses.0.city" in a json payload,
+// string values are returned as is and any other value in its json form
+func jsonPathLookup(data []byte, path string) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return _EMPTY_, false
+	}
+
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path != _EMPTY_ {
+		for _, key := range strings.Split(path, ".") {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				field, ok := v[key]
+				if !ok {
+					return _EMPTY_, false
+				}
+				value = field
+			case []interface{}:
+				idx, err := strconv.Atoi(key)
+				if err != nil || idx < 0 || idx >= len(v) {
+					return _EMPTY_, false
+				}
+				value = v[idx]
+			default:
+				return _EMPTY_, false
+			}
+		}
+	}
+
+	if str, ok := value.(string); ok {
+		return str, true
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return _EMPTY_, false
+	}
+	return string(raw), true
+}
+
+func (s *Server) searchStationMessages(tenantName string, body models.SearchMessagesSchema) (models.SearchMessagesResponse, int, error) {
+	if err := validateSearchMessagesSchema(&body); err != nil {
+		return models.SearchMessagesResponse{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	stationName, err := StationNameFromStr(body.StationName)
+	if err != nil {
+		return models.SearchMessagesResponse{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	exist, station, err := db.GetStationByName(stationName.Ext(), tenantName)
+	if err != nil {
+		return models.SearchMessagesResponse{}, 500, err
+	}
+	if !exist {
+		return models.SearchMessagesResponse{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v does not exist", stationName.Ext())
+	}
+
+	startSeq := body.FromSeq
+	byStartTime := !body.FromTime.IsZero()
+	if body.Cursor != _EMPTY_ {
+		startSeq, err = decodeSearchCursor(body.Cursor)
+		if err != nil {
+			return models.SearchMessagesResponse{}, SHOWABLE_ERROR_STATUS_CODE, err
+		}
+		byStartTime = false
+	}
+
+	streamInfo, err := s.memphisStreamInfo(tenantName, stationName.Intern())
+	if err != nil {
+		return models.SearchMessagesResponse{}, 500, err
+	}
+	if startSeq < streamInfo.State.FirstSeq {
+		startSeq = streamInfo.State.FirstSeq
+	}
+	lastSeq := streamInfo.State.LastSeq
+	if body.ToSeq > 0 && body.ToSeq < lastSeq {
+		lastSeq = body.ToSeq
+	}
+
+	filterSubj := getStationConsumerFilterSubject(station, stationName)
+	if !station.IsNative {
+		filterSubj = _EMPTY_
+	}
+	decoder, err := newStationMessageDecoder(station)
+	if err != nil {
+		s.Warnf("[tenant: %v]searchStationMessages at newStationMessageDecoder: station %v: %v", tenantName, stationName.Ext(), err.Error())
+	}
+
+	cc := ConsumerConfig{FilterSubject: filterSubj, OptStartSeq: startSeq, DeliverPolicy: DeliverByStartSequence}
+	if byStartTime {
+		// a from_seq set along with the time is applied by messageMatchesSearch
+		cc = ConsumerConfig{FilterSubject: filterSubj, OptStartTime: &body.FromTime, DeliverPolicy: DeliverByStartTime}
+	}
+	fetcher, err := s.newMemphisMsgsFetcher(tenantName, stationName.Intern(), cc, searchMessagesBatchSize, true)
+	if err != nil {
+		return models.SearchMessagesResponse{}, 500, err
+	}
+	defer fetcher.close()
+
+	resp := models.SearchMessagesResponse{Messages: []models.MessageDetails{}}
+	nextSeq := startSeq
+	done := false
+	for !done && nextSeq <= lastSeq && resp.ScannedMessages < searchMessagesMaxScanned {
+		msgs := fetcher.fetch(searchMessagesBatchSize, 5*time.Second)
+		if len(msgs) == 0 {
+			done = fetcher.pending == 0
+			break
+		}
+
+		for _, msg := range msgs {
+			if msg.Sequence > lastSeq || (!body.ToTime.IsZero() && msg.Time.After(body.ToTime)) {
+				done = true
+				break
+			}
+			nextSeq = msg.Sequence + 1
+			resp.ScannedMessages++
+
+			details, skip, err := getMessageDetailsFromStoredMsg(msg, station.IsNative, decoder)
+			if err != nil || skip || !messageMatchesSearch(body, msg, details) {
+				continue
+			}
+			resp.Messages = append(resp.Messages, details)
+			if len(resp.Messages) == body.Limit {
+				break
+			}
+		}
+		if len(resp.Messages) == body.Limit {
+			break
+		}
+	}
+
+	if !done && nextSeq <= lastSeq {
+		resp.NextCursor = encodeSearchCursor(nextSeq)
+	}
+	return resp, 200, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+	"time"
+)
+
+func TestJsonPathLookup(t *testing.T) {
+	data := []byte(`{"user":{"name":"john","age":30,"tags":["a","b"],"address":{"city":"tlv"}},"active":true}`)
+	tests := []struct {
+		path  string
+		value string
+		found bool
+	}{
+		{"user.name", "john", true},
+		{"$.user.age", "30", true},
+		{"user.tags.1", "b", true},
+		{"user.address", `{"city":"tlv"}`, true},
+		{"active", "true", true},
+		{"user.tags.5", "", false},
+		{"user.missing", "", false},
+		{"user.name.first", "", false},
+	}
+	for _, test := range tests {
+		value, found := jsonPathLookup(data, test.path)
+		if found != test.found || value != test.value {
+			t.Errorf("path %v: expected (%v, %v), got (%v, %v)", test.path, test.value, test.found, value, found)
+		}
+	}
+	if _, found := jsonPathLookup([]byte("not json"), "a"); found {
+		t.Error("expected no match for a non json payload")
+	}
+}
+
+func TestMessageMatchesSearch(t *testing.T) {
+	now := time.Now()
+	msg := StoredMsg{Sequence: 5, Data: []byte(`{"order":{"id":17}}`), Time: now}
+	details := models.MessageDetails{ProducedBy: "orders-producer", Headers: map[string]string{"region": "eu"}}
+
+	tests := []struct {
+		body  models.SearchMessagesSchema
+		match bool
+	}{
+		{models.SearchMessagesSchema{}, true},
+		{models.SearchMessagesSchema{ProducerName: "Orders-Producer"}, true},
+		{models.SearchMessagesSchema{ProducerName: "other"}, false},
+		{models.SearchMessagesSchema{HeaderKey: "region"}, true},
+		{models.SearchMessagesSchema{HeaderKey: "region", HeaderValue: "us"}, false},
+		{models.SearchMessagesSchema{PayloadContains: `"id":17`}, true},
+		{models.SearchMessagesSchema{PayloadContains: "missing"}, false},
+		{models.SearchMessagesSchema{JsonPath: "order.id", JsonValue: "17"}, true},
+		{models.SearchMessagesSchema{JsonPath: "order.id", JsonValue: "18"}, false},
+		{models.SearchMessagesSchema{FromTime: now.Add(time.Minute)}, false},
+		{models.SearchMessagesSchema{FromTime: now.Add(-time.Minute), FromSeq: 5}, true},
+		{models.SearchMessagesSchema{FromTime: now.Add(-time.Minute), FromSeq: 6}, false},
+	}
+	for i, test := range tests {
+		if got := messageMatchesSearch(test.body, msg, details); got != test.match {
+			t.Errorf("test %v: expected %v, got %v", i, test.match, got)
+		}
+	}
+}
+
+func TestSearchCursor(t *testing.T) {
+	seq, err := decodeSearchCursor(encodeSearchCursor(1234))
+	if err != nil || seq != 1234 {
+		t.Fatalf("expected 1234, got %v: %v", seq, err)
+	}
+	if _, err := decodeSearchCursor("%%%"); err != ErrInvalidSearchCursor {
+		t.Fatalf("expected an invalid cursor error, got %v", err)
+	}
+}
+
+func TestValidateSearchMessagesSchema(t *testing.T) {
+	body := models.SearchMessagesSchema{StationName: "s"}
+	if err := validateSearchMessagesSchema(&body); err != nil || body.Limit != searchMessagesDefaultLimit {
+		t.Fatalf("expected the default limit, got %v: %v", body.Limit, err)
+	}
+	invalid := []models.SearchMessagesSchema{
+		{FromSeq: 10, ToSeq: 5},
+		{FromTime: time.Now(), ToTime: time.Now().Add(-time.Hour)},
+		{HeaderValue: "v"},
+		{JsonValue: "v"},
+		{Limit: searchMessagesMaxLimit + 1},
+	}
+	for i, body := range invalid {
+		if err := validateSearchMessagesSchema(&body); err == nil {
+			t.Errorf("test %v: expected a validation error", i)
+		}
+	}
+}
@@ -0,0 +1,346 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"memphis/db"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// aggregates which query the db and jetstream are collected in the background, not on every scrape
+const metricsCollectInterval = 30 * time.Second
+
+type metricsStationKey struct {
+	tenantName  string
+	stationName string
+}
+
+type metricsCounters struct {
+	mu                      sync.Mutex
+	poisonMessages          map[metricsStationKey]uint64
+	schemaValidationFailure map[metricsStationKey]uint64
+}
+
+var memphisMetricsCounters = &metricsCounters{
+	poisonMessages:          make(map[metricsStationKey]uint64),
+	schemaValidationFailure: make(map[metricsStationKey]uint64),
+}
+
+func (mc *metricsCounters) incPoisonMessages(tenantName, stationName string) {
+	mc.mu.Lock()
+	mc.poisonMessages[metricsStationKey{tenantName, stationName}]++
+	mc.mu.Unlock()
+}
+
+func (mc *metricsCounters) incSchemaValidationFailures(tenantName, stationName string) {
+	mc.mu.Lock()
+	mc.schemaValidationFailure[metricsStationKey{tenantName, stationName}]++
+	mc.mu.Unlock()
+}
+
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string
+	samples []string
+}
+
+// metricsRegistry renders metric families in the prometheus text exposition format,
+// samples of a family are grouped together regardless of the order they were added in
+type metricsRegistry struct {
+	families map[string]*metricFamily
+	order    []string
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{families: make(map[string]*metricFamily)}
+}
+
+func (r *metricsRegistry) declare(name, kind, help string) {
+	if _, ok := r.families[name]; ok {
+		return
+	}
+	r.families[name] = &metricFamily{name: name, help: help, kind: kind}
+	r.order = append(r.order, name)
+}
+
+// add expects labels as name, value pairs
+func (r *metricsRegistry) add(name string, value float64, labels ...string) {
+	family, ok := r.families[name]
+	if !ok {
+		return
+	}
+	var sample strings.Builder
+	sample.WriteString(name)
+	if len(labels) > 0 {
+		sample.WriteString("{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				sample.WriteString(",")
+			}
+			sample.WriteString(labels[i])
+			sample.WriteString("=\"")
+			sample.WriteString(escapeMetricLabelValue(labels[i+1]))
+			sample.WriteString("\"")
+		}
+		sample.WriteString("}")
+	}
+	sample.WriteString(" ")
+	sample.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	family.samples = append(family.samples, sample.String())
+}
+
+func (r *metricsRegistry) write(buf *bytes.Buffer) {
+	for _, name := range r.order {
+		family := r.families[name]
+		fmt.Fprintf(buf, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(buf, "# TYPE %s %s\n", family.name, family.kind)
+		for _, sample := range family.samples {
+			buf.WriteString(sample)
+			buf.WriteString("\n")
+		}
+	}
+}
+
+// merge copies the families of another registry, a family declared in both keeps the samples of both
+func (r *metricsRegistry) merge(other *metricsRegistry) {
+	for _, name := range other.order {
+		family := other.families[name]
+		r.declare(family.name, family.kind, family.help)
+		r.families[name].samples = append(r.families[name].samples, family.samples...)
+	}
+}
+
+type collectedMetrics struct {
+	mu       sync.RWMutex
+	registry *metricsRegistry
+}
+
+var memphisCollectedMetrics = &collectedMetrics{registry: newMetricsRegistry()}
+
+func (cm *collectedMetrics) set(registry *metricsRegistry) {
+	cm.mu.Lock()
+	cm.registry = registry
+	cm.mu.Unlock()
+}
+
+func (cm *collectedMetrics) writeTo(registry *metricsRegistry) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+	registry.merge(cm.registry)
+}
+
+func escapeMetricLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
+
+// HandleMetrics serves the memphis metrics in the prometheus text format on the monitoring port,
+// when metrics_token is configured scrapers have to send it as a bearer token
+func (s *Server) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.httpReqStats[MetricsPath]++
+	s.mu.Unlock()
+
+	token := s.getOpts().MetricsToken
+	if token != _EMPTY_ && !isMetricsRequestAuthorized(r, token) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	registry := newMetricsRegistry()
+	memphisCollectedMetrics.writeTo(registry)
+	s.collectMetricsCounters(registry)
+	s.collectTieredStorageMetrics(registry)
+	s.collectThroughputMetrics(registry)
+
+	var buf bytes.Buffer
+	registry.write(&buf)
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func isMetricsRequestAuthorized(r *http.Request, token string) bool {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(authHeader, "Bearer ")), []byte(token)) == 1
+}
+
+// CollectMetrics periodically refreshes the station, consumer group and dead-letter aggregates served by HandleMetrics,
+// the aggregates are cluster wide so only the jetstream meta leader exports them to keep sums across brokers correct
+func (s *Server) CollectMetrics() {
+	s.collectStoredMetrics()
+	ticker := time.NewTicker(metricsCollectInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.collectStoredMetrics()
+	}
+}
+
+func (s *Server) collectStoredMetrics() {
+	registry := newMetricsRegistry()
+	if s.JetStreamIsClustered() && !s.JetStreamIsLeader() {
+		// drops the aggregates collected while this broker was the leader
+		memphisCollectedMetrics.set(registry)
+		return
+	}
+	s.collectStationMetrics(registry)
+	s.collectDlsMetrics(registry)
+	memphisCollectedMetrics.set(registry)
+}
+
+func (s *Server) collectStationMetrics(registry *metricsRegistry) {
+	registry.declare("memphis_stations", "gauge", "Number of stations per tenant")
+	registry.declare("memphis_station_messages", "gauge", "Number of messages stored in the station")
+	registry.declare("memphis_station_bytes", "gauge", "Bytes stored in the station")
+	registry.declare("memphis_station_active_producers", "gauge", "Number of active producers of the station")
+	registry.declare("memphis_consumer_group_active_consumers", "gauge", "Number of active consumers in the consumer group")
+	registry.declare("memphis_consumer_group_unprocessed_messages", "gauge", "Messages not yet delivered to the consumer group")
+	registry.declare("memphis_consumer_group_in_process_messages", "gauge", "Messages delivered to the consumer group and waiting for an ack")
+
+	stations, err := db.GetActiveStations()
+	if err != nil {
+		s.Errorf("collectStationMetrics at GetActiveStations: %v", err.Error())
+		return
+	}
+	producers, err := db.CountActiveProducersPerStation()
+	if err != nil {
+		s.Errorf("collectStationMetrics at CountActiveProducersPerStation: %v", err.Error())
+	}
+	consumers, err := db.GetConsumers()
+	if err != nil {
+		s.Errorf("collectStationMetrics at GetConsumers: %v", err.Error())
+	}
+	cgsPerStation := make(map[int]map[string]int)
+	for _, consumer := range consumers {
+		if _, ok := cgsPerStation[consumer.StationId]; !ok {
+			cgsPerStation[consumer.StationId] = make(map[string]int)
+		}
+		active := 0
+		if consumer.IsActive {
+			active = 1
+		}
+		cgsPerStation[consumer.StationId][consumer.ConsumersGroup] += active
+	}
+
+	stationsPerTenant := make(map[string]int)
+	for _, station := range stations {
+		stationsPerTenant[station.TenantName]++
+		stationName, err := StationNameFromStr(station.Name)
+		if err != nil {
+			continue
+		}
+		registry.add("memphis_station_active_producers", float64(producers[station.ID]), "tenant", station.TenantName, "station", station.Name)
+		info, err := s.memphisStreamInfo(station.TenantName, stationName.Intern())
+		if err == nil {
+			registry.add("memphis_station_messages", float64(info.State.Msgs), "tenant", station.TenantName, "station", station.Name)
+			registry.add("memphis_station_bytes", float64(info.State.Bytes), "tenant", station.TenantName, "station", station.Name)
+		}
+
+		cgNames := make([]string, 0, len(cgsPerStation[station.ID]))
+		for cgName := range cgsPerStation[station.ID] {
+			cgNames = append(cgNames, cgName)
+		}
+		sort.Strings(cgNames)
+		for _, cgName := range cgNames {
+			labels := []string{"tenant", station.TenantName, "station", station.Name, "consumer_group", cgName}
+			registry.add("memphis_consumer_group_active_consumers", float64(cgsPerStation[station.ID][cgName]), labels...)
+			cgInfo, err := s.GetCgInfo(station.TenantName, stationName, cgName)
+			if err != nil {
+				continue
+			}
+			registry.add("memphis_consumer_group_unprocessed_messages", float64(cgInfo.NumPending), labels...)
+			registry.add("memphis_consumer_group_in_process_messages", float64(cgInfo.NumAckPending), labels...)
+		}
+	}
+	for tenantName, count := range stationsPerTenant {
+		registry.add("memphis_stations", float64(count), "tenant", tenantName)
+	}
+}
+
+func (s *Server) collectDlsMetrics(registry *metricsRegistry) {
+	registry.declare("memphis_station_dls_messages", "gauge", "Number of messages in the dead-letter station by type")
+
+	counts, err := db.GetDlsMsgsCountPerStation()
+	if err != nil {
+		s.Errorf("collectDlsMetrics at GetDlsMsgsCountPerStation: %v", err.Error())
+	}
+	for _, count := range counts {
+		registry.add("memphis_station_dls_messages", float64(count.Count), "tenant", count.TenantName, "station", count.StationName, "type", count.MessageType)
+	}
+}
+
+func (s *Server) collectMetricsCounters(registry *metricsRegistry) {
+	registry.declare("memphis_poison_messages_total", "counter", "Poison messages identified by this broker")
+	registry.declare("memphis_schema_validation_failures_total", "counter", "Schema validation failures reported to this broker")
+
+	memphisMetricsCounters.mu.Lock()
+	defer memphisMetricsCounters.mu.Unlock()
+	for key, count := range memphisMetricsCounters.poisonMessages {
+		registry.add("memphis_poison_messages_total", float64(count), "tenant", key.tenantName, "station", key.stationName)
+	}
+	for key, count := range memphisMetricsCounters.schemaValidationFailure {
+		registry.add("memphis_schema_validation_failures_total", float64(count), "tenant", key.tenantName, "station", key.stationName)
+	}
+}
+
+func (s *Server) collectTieredStorageMetrics(registry *metricsRegistry) {
+	registry.declare("memphis_tiered_storage_buffered_messages", "gauge", "Messages waiting to be uploaded to tier 2 storage")
+	registry.declare("memphis_tiered_storage_buffered_bytes", "gauge", "Bytes waiting to be uploaded to tier 2 storage")
+	registry.declare("memphis_tiered_storage_upload_lag_seconds", "gauge", "Age of the oldest message waiting to be uploaded to tier 2 storage")
+	registry.declare("memphis_tiered_storage_failed_uploads", "gauge", "Consecutive failed uploads to tier 2 storage destinations")
+
+	if tieredStorageMsgsBuffer == nil {
+		return
+	}
+	now := time.Now()
+	for tenantName, stats := range tieredStorageMsgsBuffer.stats() {
+		registry.add("memphis_tiered_storage_buffered_messages", float64(stats.msgs), "tenant", tenantName)
+		registry.add("memphis_tiered_storage_buffered_bytes", float64(stats.size), "tenant", tenantName)
+		lag := float64(0)
+		if !stats.oldest.IsZero() {
+			lag = now.Sub(stats.oldest).Seconds()
+		}
+		registry.add("memphis_tiered_storage_upload_lag_seconds", lag, "tenant", tenantName)
+		registry.add("memphis_tiered_storage_failed_uploads", float64(stats.failures), "tenant", tenantName)
+	}
+}
+
+func (s *Server) collectThroughputMetrics(registry *metricsRegistry) {
+	registry.declare("memphis_tenant_read_bytes_per_second", "gauge", "Bytes read by the tenant's clients from this broker in the last second")
+	registry.declare("memphis_tenant_write_bytes_per_second", "gauge", "Bytes written by the tenant's clients to this broker in the last second")
+	registry.declare("memphis_tenant_read_bytes_total", "counter", "Bytes read by the tenant's clients from this broker")
+	registry.declare("memphis_tenant_write_bytes_total", "counter", "Bytes written by the tenant's clients to this broker")
+
+	throughputLock.RLock()
+	defer throughputLock.RUnlock()
+	for tenantName, tp := range LastReadThroughputMap {
+		registry.add("memphis_tenant_read_bytes_per_second", float64(tp.BytesPerSec), "tenant", tenantName)
+		registry.add("memphis_tenant_read_bytes_total", float64(tp.Bytes), "tenant", tenantName)
+	}
+	for tenantName, tp := range LastWriteThroughputMap {
+		registry.add("memphis_tenant_write_bytes_per_second", float64(tp.BytesPerSec), "tenant", tenantName)
+		registry.add("memphis_tenant_write_bytes_total", float64(tp.Bytes), "tenant", tenantName)
+	}
+}
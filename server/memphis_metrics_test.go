@@ -0,0 +1,88 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+func TestMetricsRegistryWrite(t *testing.T) {
+	registry := newMetricsRegistry()
+	registry.declare("memphis_station_messages", "gauge", "Number of messages stored in the station")
+	registry.declare("memphis_stations", "gauge", "Number of stations per tenant")
+	registry.add("memphis_station_messages", 5, "tenant", "t1", "station", "s1")
+	registry.add("memphis_stations", 1, "tenant", "t1")
+	registry.add("memphis_station_messages", 7, "tenant", "t\"2", "station", "s\\2")
+	registry.add("memphis_undeclared", 1)
+
+	var buf bytes.Buffer
+	registry.write(&buf)
+	expected := `# HELP memphis_station_messages Number of messages stored in the station
+# TYPE memphis_station_messages gauge
+memphis_station_messages{tenant="t1",station="s1"} 5
+memphis_station_messages{tenant="t\"2",station="s\\2"} 7
+# HELP memphis_stations Number of stations per tenant
+# TYPE memphis_stations gauge
+memphis_stations{tenant="t1"} 1
+`
+	if buf.String() != expected {
+		t.Fatalf("Unexpected metrics output:\n%s", buf.String())
+	}
+}
+
+func TestMetricsRegistryMerge(t *testing.T) {
+	collected := newMetricsRegistry()
+	collected.declare("memphis_stations", "gauge", "Number of stations per tenant")
+	collected.add("memphis_stations", 2, "tenant", "t1")
+
+	registry := newMetricsRegistry()
+	registry.declare("memphis_stations", "gauge", "Number of stations per tenant")
+	registry.add("memphis_stations", 1, "tenant", "t2")
+	registry.merge(collected)
+
+	var buf bytes.Buffer
+	registry.write(&buf)
+	expected := `# HELP memphis_stations Number of stations per tenant
+# TYPE memphis_stations gauge
+memphis_stations{tenant="t2"} 1
+memphis_stations{tenant="t1"} 2
+`
+	if buf.String() != expected {
+		t.Fatalf("Unexpected metrics output:\n%s", buf.String())
+	}
+}
+
+func TestIsMetricsRequestAuthorized(t *testing.T) {
+	tests := []struct {
+		header     string
+		authorized bool
+	}{
+		{"Bearer secret", true},
+		{"Bearer other", false},
+		{"secret", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		r, err := http.NewRequest("GET", "/metrics", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.header != _EMPTY_ {
+			r.Header.Set("Authorization", test.header)
+		}
+		if got := isMetricsRequestAuthorized(r, "secret"); got != test.authorized {
+			t.Errorf("header %q: expected %v, got %v", test.header, test.authorized, got)
+		}
+	}
+}
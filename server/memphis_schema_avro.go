@@ -0,0 +1,842 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"memphis/models"
+
+	"k8s.io/utils/strings/slices"
+)
+
+const (
+	avroMaxDecodeDepth = 512
+	// bounds the array and map items of a message, items such as nulls or empty records are encoded in zero bytes
+	// so the message size does not bound them
+	avroMaxDecodeItems = 1 << 20
+
+	avroEncodingAttribute = "memphis.encoding"
+	avroEncodingBinary    = "binary"
+	avroEncodingJson      = "json"
+)
+
+var avroNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+var avroPrimitives = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"int":     true,
+	"long":    true,
+	"float":   true,
+	"double":  true,
+	"bytes":   true,
+	"string":  true,
+}
+
+type avroSchema struct {
+	Type        string
+	Name        string
+	Aliases     []string
+	Fields      []*avroField
+	Symbols     []string
+	EnumDefault string
+	Items       *avroSchema
+	Values      *avroSchema
+	Branches    []*avroSchema
+	Size        int
+}
+
+type avroField struct {
+	Name       string
+	Aliases    []string
+	Type       *avroSchema
+	HasDefault bool
+}
+
+func (as *avroSchema) isNamed() bool {
+	return as.Type == "record" || as.Type == "enum" || as.Type == "fixed"
+}
+
+// typeName is how the schema is referenced within unions, full name for named types and the type otherwise
+func (as *avroSchema) typeName() string {
+	if as.isNamed() {
+		return as.Name
+	}
+	return as.Type
+}
+
+func avroShortName(fullName string) string {
+	return fullName[strings.LastIndex(fullName, ".")+1:]
+}
+
+type avroParser struct {
+	named map[string]*avroSchema
+}
+
+func parseAvroSchema(schemaContent string) (*avroSchema, error) {
+	var raw any
+	decoder := json.NewDecoder(strings.NewReader(schemaContent))
+	decoder.UseNumber()
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return nil, fmt.Errorf("avro schema has to be a valid json: %v", err.Error())
+	}
+	p := avroParser{named: make(map[string]*avroSchema)}
+	schema, err := p.parse(raw, _EMPTY_)
+	if err != nil {
+		return nil, err
+	}
+	for _, named := range p.named {
+		if err := checkAvroRecordRecursion(named, map[*avroSchema]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	return schema, nil
+}
+
+// checkAvroRecordRecursion rejects records which contain themselves through record fields only,
+// such a record has no finite encoding since only unions, arrays and maps can end the recursion
+func checkAvroRecordRecursion(schema *avroSchema, path map[*avroSchema]bool) error {
+	if schema.Type != "record" {
+		return nil
+	}
+	if path[schema] {
+		return fmt.Errorf("avro record %v contains itself without a union, array or map in between", schema.Name)
+	}
+	path[schema] = true
+	for _, field := range schema.Fields {
+		if err := checkAvroRecordRecursion(field.Type, path); err != nil {
+			return err
+		}
+	}
+	delete(path, schema)
+	return nil
+}
+
+func (p *avroParser) fullName(name, namespace string) (string, error) {
+	parts := strings.Split(name, ".")
+	for _, part := range parts {
+		if !avroNameRegex.MatchString(part) {
+			return _EMPTY_, fmt.Errorf("invalid avro name %v", name)
+		}
+	}
+	if len(parts) > 1 || namespace == _EMPTY_ {
+		return name, nil
+	}
+	return namespace + "." + name, nil
+}
+
+func (p *avroParser) resolve(name, namespace string) (*avroSchema, error) {
+	if avroPrimitives[name] {
+		return &avroSchema{Type: name}, nil
+	}
+	fullName, err := p.fullName(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if schema, ok := p.named[fullName]; ok {
+		return schema, nil
+	}
+	if schema, ok := p.named[name]; ok {
+		return schema, nil
+	}
+	return nil, fmt.Errorf("unknown avro type %v", name)
+}
+
+func (p *avroParser) register(schema *avroSchema, m map[string]any, namespace string) (string, error) {
+	name, _ := m["name"].(string)
+	if name == _EMPTY_ {
+		return _EMPTY_, fmt.Errorf("avro %v has to have a name", schema.Type)
+	}
+	if ns, ok := m["namespace"].(string); ok && !strings.Contains(name, ".") {
+		namespace = ns
+	}
+	fullName, err := p.fullName(name, namespace)
+	if err != nil {
+		return _EMPTY_, err
+	}
+	if _, ok := p.named[fullName]; ok {
+		return _EMPTY_, fmt.Errorf("avro type %v is defined more than once", fullName)
+	}
+	schema.Name = fullName
+	schema.Aliases = jsonStrings(m["aliases"])
+	p.named[fullName] = schema
+	if idx := strings.LastIndex(fullName, "."); idx >= 0 {
+		return fullName[:idx], nil
+	}
+	return _EMPTY_, nil
+}
+
+func jsonStrings(value any) []string {
+	list, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	res := []string{}
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func (p *avroParser) parse(raw any, namespace string) (*avroSchema, error) {
+	switch v := raw.(type) {
+	case string:
+		return p.resolve(v, namespace)
+	case []any:
+		return p.parseUnion(v, namespace)
+	case map[string]any:
+		return p.parseComplex(v, namespace)
+	default:
+		return nil, fmt.Errorf("invalid avro schema %v", raw)
+	}
+}
+
+func (p *avroParser) parseUnion(raw []any, namespace string) (*avroSchema, error) {
+	union := &avroSchema{Type: "union"}
+	seen := make(map[string]bool)
+	for _, b := range raw {
+		branch, err := p.parse(b, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if branch.Type == "union" {
+			return nil, errors.New("avro unions can not immediately contain other unions")
+		}
+		if seen[branch.typeName()] {
+			return nil, fmt.Errorf("avro union contains %v more than once", branch.typeName())
+		}
+		seen[branch.typeName()] = true
+		union.Branches = append(union.Branches, branch)
+	}
+	return union, nil
+}
+
+func (p *avroParser) parseComplex(m map[string]any, namespace string) (*avroSchema, error) {
+	t, ok := m["type"].(string)
+	if !ok {
+		if m["type"] == nil {
+			return nil, errors.New("avro schema is missing a type")
+		}
+		return p.parse(m["type"], namespace)
+	}
+
+	switch t {
+	case "record", "error":
+		schema := &avroSchema{Type: "record"}
+		ns, err := p.register(schema, m, namespace)
+		if err != nil {
+			return nil, err
+		}
+		rawFields, ok := m["fields"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("avro record %v has to have fields", schema.Name)
+		}
+		fieldNames := make(map[string]bool)
+		for _, rf := range rawFields {
+			fm, ok := rf.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("invalid field at avro record %v", schema.Name)
+			}
+			name, _ := fm["name"].(string)
+			if !avroNameRegex.MatchString(name) {
+				return nil, fmt.Errorf("invalid field name %v at avro record %v", name, schema.Name)
+			}
+			if fieldNames[name] {
+				return nil, fmt.Errorf("field %v is defined more than once at avro record %v", name, schema.Name)
+			}
+			fieldNames[name] = true
+			if _, ok := fm["type"]; !ok {
+				return nil, fmt.Errorf("field %v at avro record %v is missing a type", name, schema.Name)
+			}
+			fieldType, err := p.parse(fm["type"], ns)
+			if err != nil {
+				return nil, err
+			}
+			_, hasDefault := fm["default"]
+			schema.Fields = append(schema.Fields, &avroField{Name: name, Aliases: jsonStrings(fm["aliases"]), Type: fieldType, HasDefault: hasDefault})
+		}
+		return schema, nil
+
+	case "enum":
+		schema := &avroSchema{Type: "enum"}
+		_, err := p.register(schema, m, namespace)
+		if err != nil {
+			return nil, err
+		}
+		schema.Symbols = jsonStrings(m["symbols"])
+		if len(schema.Symbols) == 0 {
+			return nil, fmt.Errorf("avro enum %v has to have symbols", schema.Name)
+		}
+		seen := make(map[string]bool)
+		for _, symbol := range schema.Symbols {
+			if !avroNameRegex.MatchString(symbol) || seen[symbol] {
+				return nil, fmt.Errorf("invalid or duplicate symbol %v at avro enum %v", symbol, schema.Name)
+			}
+			seen[symbol] = true
+		}
+		if def, ok := m["default"].(string); ok {
+			if !seen[def] {
+				return nil, fmt.Errorf("default %v of avro enum %v is not one of its symbols", def, schema.Name)
+			}
+			schema.EnumDefault = def
+		}
+		return schema, nil
+
+	case "fixed":
+		schema := &avroSchema{Type: "fixed"}
+		_, err := p.register(schema, m, namespace)
+		if err != nil {
+			return nil, err
+		}
+		size, ok := m["size"].(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("avro fixed %v has to have a size", schema.Name)
+		}
+		s, err := size.Int64()
+		if err != nil || s < 0 {
+			return nil, fmt.Errorf("invalid size at avro fixed %v", schema.Name)
+		}
+		schema.Size = int(s)
+		return schema, nil
+
+	case "array":
+		if _, ok := m["items"]; !ok {
+			return nil, errors.New("avro array has to have items")
+		}
+		items, err := p.parse(m["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroSchema{Type: "array", Items: items}, nil
+
+	case "map":
+		if _, ok := m["values"]; !ok {
+			return nil, errors.New("avro map has to have values")
+		}
+		values, err := p.parse(m["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroSchema{Type: "map", Values: values}, nil
+
+	default:
+		// primitives with attributes such as logical types, or references to named types
+		return p.resolve(t, namespace)
+	}
+}
+
+// avroCanonicalForm returns the parsing canonical form of the schema as defined by the avro specification
+func avroCanonicalForm(schema *avroSchema) string {
+	var buf bytes.Buffer
+	writeAvroCanonicalForm(&buf, schema, make(map[string]bool))
+	return buf.String()
+}
+
+func writeAvroCanonicalString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+func writeAvroCanonicalForm(buf *bytes.Buffer, schema *avroSchema, written map[string]bool) {
+	if schema.isNamed() {
+		if written[schema.Name] {
+			writeAvroCanonicalString(buf, schema.Name)
+			return
+		}
+		written[schema.Name] = true
+	}
+
+	switch schema.Type {
+	case "union":
+		buf.WriteByte('[')
+		for i, branch := range schema.Branches {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeAvroCanonicalForm(buf, branch, written)
+		}
+		buf.WriteByte(']')
+	case "record":
+		buf.WriteString(`{"name":`)
+		writeAvroCanonicalString(buf, schema.Name)
+		buf.WriteString(`,"type":"record","fields":[`)
+		for i, field := range schema.Fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`{"name":`)
+			writeAvroCanonicalString(buf, field.Name)
+			buf.WriteString(`,"type":`)
+			writeAvroCanonicalForm(buf, field.Type, written)
+			buf.WriteByte('}')
+		}
+		buf.WriteString(`]}`)
+	case "enum":
+		buf.WriteString(`{"name":`)
+		writeAvroCanonicalString(buf, schema.Name)
+		buf.WriteString(`,"type":"enum","symbols":[`)
+		for i, symbol := range schema.Symbols {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeAvroCanonicalString(buf, symbol)
+		}
+		buf.WriteString(`]}`)
+	case "fixed":
+		buf.WriteString(`{"name":`)
+		writeAvroCanonicalString(buf, schema.Name)
+		buf.WriteString(fmt.Sprintf(`,"type":"fixed","size":%d}`, schema.Size))
+	case "array":
+		buf.WriteString(`{"type":"array","items":`)
+		writeAvroCanonicalForm(buf, schema.Items, written)
+		buf.WriteByte('}')
+	case "map":
+		buf.WriteString(`{"type":"map","values":`)
+		writeAvroCanonicalForm(buf, schema.Values, written)
+		buf.WriteByte('}')
+	default:
+		writeAvroCanonicalString(buf, schema.Type)
+	}
+}
+
+func validateAvroContent(schemaContent string) error {
+	_, err := parseAvroSchema(schemaContent)
+	if err == nil {
+		_, err = getAvroMessageEncoding(schemaContent)
+	}
+	if err != nil {
+		return fmt.Errorf("your Avro schema is invalid: %v", err.Error())
+	}
+	return nil
+}
+
+// getAvroMessageEncoding returns the encoding messages of the schema are expected in, avro binary by default
+// or the avro json encoding when the schema sets the memphis.encoding attribute to json
+func getAvroMessageEncoding(schemaContent string) (string, error) {
+	var attributes map[string]any
+	if err := json.Unmarshal([]byte(schemaContent), &attributes); err != nil {
+		// primitive and union schemas can not have attributes
+		return avroEncodingBinary, nil
+	}
+	raw, ok := attributes[avroEncodingAttribute]
+	if !ok {
+		return avroEncodingBinary, nil
+	}
+	encoding, _ := raw.(string)
+	encoding = strings.ToLower(encoding)
+	if encoding != avroEncodingBinary && encoding != avroEncodingJson {
+		return _EMPTY_, fmt.Errorf("%v can be either %v or %v", avroEncodingAttribute, avroEncodingBinary, avroEncodingJson)
+	}
+	return encoding, nil
+}
+
+func generateAvroDescriptor(schemaContent string) (string, error) {
+	schema, err := parseAvroSchema(schemaContent)
+	if err != nil {
+		return _EMPTY_, err
+	}
+	return avroCanonicalForm(schema), nil
+}
+
+// Message validation, messages are validated in the single encoding of their schema
+
+func newAvroSchemaValidator(schemaContent string) (schemaValidator, error) {
+	schema, err := parseAvroSchema(schemaContent)
+	if err != nil {
+		return nil, err
+	}
+	encoding, err := getAvroMessageEncoding(schemaContent)
+	if err != nil {
+		return nil, err
+	}
+	if encoding == avroEncodingJson {
+		return func(msg []byte) error {
+			var value any
+			decoder := json.NewDecoder(bytes.NewReader(msg))
+			decoder.UseNumber()
+			if err := decoder.Decode(&value); err != nil {
+				return errors.New("expecting the message to be a valid json")
+			}
+			if decoder.More() {
+				return errors.New("unexpected data after the json message")
+			}
+			return validateAvroJson(schema, value, "$")
+		}, nil
+	}
+	return func(msg []byte) error {
+		d := avroBinaryDecoder{data: msg}
+		pos, err := d.decode(schema, 0, 0)
+		if err != nil {
+			return fmt.Errorf("message is not valid avro binary: %v", err.Error())
+		}
+		if pos != len(msg) {
+			return errors.New("message is not valid avro binary: unexpected trailing bytes")
+		}
+		return nil
+	}, nil
+}
+
+func readAvroLong(data []byte, pos int) (int64, int, error) {
+	value, n := binary.Uvarint(data[pos:])
+	if n <= 0 {
+		return 0, pos, errors.New("invalid varint")
+	}
+	return int64(value>>1) ^ -int64(value&1), pos + n, nil
+}
+
+type avroBinaryDecoder struct {
+	data  []byte
+	items int64
+}
+
+func (d *avroBinaryDecoder) decode(schema *avroSchema, pos, depth int) (int, error) {
+	data := d.data
+	if depth > avroMaxDecodeDepth {
+		return pos, errors.New("maximum nesting depth exceeded")
+	}
+	depth++
+	switch schema.Type {
+	case "null":
+		return pos, nil
+	case "boolean":
+		if pos >= len(data) || data[pos] > 1 {
+			return pos, errors.New("invalid boolean")
+		}
+		return pos + 1, nil
+	case "int", "long":
+		value, next, err := readAvroLong(data, pos)
+		if err != nil {
+			return pos, err
+		}
+		if schema.Type == "int" && (value > math.MaxInt32 || value < math.MinInt32) {
+			return pos, errors.New("int out of range")
+		}
+		return next, nil
+	case "float":
+		if pos+4 > len(data) {
+			return pos, errors.New("truncated float")
+		}
+		return pos + 4, nil
+	case "double":
+		if pos+8 > len(data) {
+			return pos, errors.New("truncated double")
+		}
+		return pos + 8, nil
+	case "bytes", "string":
+		length, next, err := readAvroLong(data, pos)
+		if err != nil {
+			return pos, err
+		}
+		if length < 0 || int64(len(data)-next) < length {
+			return pos, fmt.Errorf("invalid %v length", schema.Type)
+		}
+		end := next + int(length)
+		if schema.Type == "string" && !utf8.Valid(data[next:end]) {
+			return pos, errors.New("invalid utf8 string")
+		}
+		return end, nil
+	case "fixed":
+		if pos+schema.Size > len(data) {
+			return pos, fmt.Errorf("truncated fixed %v", schema.Name)
+		}
+		return pos + schema.Size, nil
+	case "enum":
+		index, next, err := readAvroLong(data, pos)
+		if err != nil {
+			return pos, err
+		}
+		if index < 0 || index >= int64(len(schema.Symbols)) {
+			return pos, fmt.Errorf("invalid symbol index for enum %v", schema.Name)
+		}
+		return next, nil
+	case "union":
+		index, next, err := readAvroLong(data, pos)
+		if err != nil {
+			return pos, err
+		}
+		if index < 0 || index >= int64(len(schema.Branches)) {
+			return pos, errors.New("invalid union branch")
+		}
+		return d.decode(schema.Branches[index], next, depth)
+	case "record":
+		var err error
+		for _, field := range schema.Fields {
+			pos, err = d.decode(field.Type, pos, depth)
+			if err != nil {
+				return pos, err
+			}
+		}
+		return pos, nil
+	case "array", "map":
+		for {
+			count, next, err := readAvroLong(data, pos)
+			if err != nil {
+				return pos, err
+			}
+			pos = next
+			if count == 0 {
+				return pos, nil
+			}
+			if count < 0 {
+				count = -count
+				// the block size in bytes follows a negative count
+				if _, pos, err = readAvroLong(data, pos); err != nil {
+					return pos, err
+				}
+			}
+			if count < 0 || count > avroMaxDecodeItems-d.items {
+				return pos, fmt.Errorf("%v block count exceeds the maximum of %v items", schema.Type, avroMaxDecodeItems)
+			}
+			d.items += count
+			for i := int64(0); i < count; i++ {
+				if schema.Type == "map" {
+					if pos, err = d.decode(&avroSchema{Type: "string"}, pos, depth); err != nil {
+						return pos, err
+					}
+					if pos, err = d.decode(schema.Values, pos, depth); err != nil {
+						return pos, err
+					}
+				} else if pos, err = d.decode(schema.Items, pos, depth); err != nil {
+					return pos, err
+				}
+			}
+		}
+	default:
+		return pos, fmt.Errorf("unsupported avro type %v", schema.Type)
+	}
+}
+
+func validateAvroJson(schema *avroSchema, value any, path string) error {
+	switch schema.Type {
+	case "null":
+		if value != nil {
+			return fmt.Errorf("%v: expected null", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%v: expected a boolean", path)
+		}
+	case "int", "long":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%v: expected %v", path, schema.Type)
+		}
+		i, err := n.Int64()
+		if err != nil || (schema.Type == "int" && (i > math.MaxInt32 || i < math.MinInt32)) {
+			return fmt.Errorf("%v: expected %v", path, schema.Type)
+		}
+	case "float", "double":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("%v: expected a number", path)
+		}
+	case "bytes", "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%v: expected a string", path)
+		}
+	case "fixed":
+		s, ok := value.(string)
+		if !ok || len(s) != schema.Size {
+			return fmt.Errorf("%v: expected %v bytes", path, schema.Size)
+		}
+	case "enum":
+		s, ok := value.(string)
+		if !ok || !slices.Contains(schema.Symbols, s) {
+			return fmt.Errorf("%v: expected one of %v", path, strings.Join(schema.Symbols, ", "))
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%v: expected an array", path)
+		}
+		for i, item := range items {
+			if err := validateAvroJson(schema.Items, item, fmt.Sprintf("%v[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "map":
+		m, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%v: expected an object", path)
+		}
+		for k, v := range m {
+			if err := validateAvroJson(schema.Values, v, path+"."+k); err != nil {
+				return err
+			}
+		}
+	case "record":
+		m, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%v: expected an object", path)
+		}
+		for _, field := range schema.Fields {
+			v, ok := m[field.Name]
+			if !ok {
+				if field.HasDefault {
+					continue
+				}
+				v = nil
+			}
+			if err := validateAvroJson(field.Type, v, path+"."+field.Name); err != nil {
+				return err
+			}
+		}
+	case "union":
+		// the avro json encoding wraps non null union values as {"type name": value}
+		if m, ok := value.(map[string]any); ok && len(m) == 1 {
+			for k, v := range m {
+				for _, branch := range schema.Branches {
+					if branch.typeName() == k || (branch.isNamed() && avroShortName(branch.Name) == k) {
+						return validateAvroJson(branch, v, path)
+					}
+				}
+			}
+		}
+		for _, branch := range schema.Branches {
+			if validateAvroJson(branch, value, path) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%v: value does not match any of the union types", path)
+	}
+	return nil
+}
+
+// Schema evolution
+
+func checkAvroCompatibility(reader, writer models.SchemaVersion) ([]models.SchemaCompatibilityViolation, error) {
+	readerSchema, err := parseAvroSchema(reader.SchemaContent)
+	if err != nil {
+		return nil, err
+	}
+	writerSchema, err := parseAvroSchema(writer.SchemaContent)
+	if err != nil {
+		return nil, err
+	}
+	return compareAvroSchemas(readerSchema, writerSchema, "$", make(map[string]bool)), nil
+}
+
+func avroNamesMatch(reader, writer *avroSchema) bool {
+	if avroShortName(reader.Name) == avroShortName(writer.Name) {
+		return true
+	}
+	for _, alias := range reader.Aliases {
+		if avroShortName(alias) == avroShortName(writer.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+var avroPromotions = map[string][]string{
+	"int":    {"long", "float", "double"},
+	"long":   {"float", "double"},
+	"float":  {"double"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+}
+
+// avroTypesMatch reports whether the reader can resolve the writer without looking into nested types
+func avroTypesMatch(reader, writer *avroSchema) bool {
+	if reader.Type == writer.Type {
+		if reader.isNamed() {
+			return avroNamesMatch(reader, writer)
+		}
+		return true
+	}
+	return slices.Contains(avroPromotions[writer.Type], reader.Type)
+}
+
+func compareAvroSchemas(reader, writer *avroSchema, path string, visited map[string]bool) []models.SchemaCompatibilityViolation {
+	violations := []models.SchemaCompatibilityViolation{}
+
+	if writer.Type == "union" {
+		for _, branch := range writer.Branches {
+			violations = append(violations, compareAvroSchemas(reader, branch, path, visited)...)
+		}
+		return violations
+	}
+	if reader.Type == "union" {
+		for _, branch := range reader.Branches {
+			if avroTypesMatch(branch, writer) {
+				return compareAvroSchemas(branch, writer, path, visited)
+			}
+		}
+		return append(violations, newCompatibilityViolation(path, "type %v is not part of the union", writer.typeName()))
+	}
+	if !avroTypesMatch(reader, writer) {
+		return append(violations, newCompatibilityViolation(path, "type changed from %v to %v", writer.typeName(), reader.typeName()))
+	}
+
+	switch reader.Type {
+	case "record":
+		key := reader.Name + "|" + writer.Name
+		if visited[key] {
+			return violations
+		}
+		visited[key] = true
+		writerFields := make(map[string]*avroField)
+		for _, field := range writer.Fields {
+			writerFields[field.Name] = field
+		}
+		for _, field := range reader.Fields {
+			wf, ok := writerFields[field.Name]
+			for _, alias := range field.Aliases {
+				if ok {
+					break
+				}
+				wf, ok = writerFields[alias]
+			}
+			if !ok {
+				if !field.HasDefault {
+					violations = append(violations, newCompatibilityViolation(path+"."+field.Name, "field was added without a default value"))
+				}
+				continue
+			}
+			violations = append(violations, compareAvroSchemas(field.Type, wf.Type, path+"."+field.Name, visited)...)
+		}
+	case "enum":
+		if reader.EnumDefault == _EMPTY_ {
+			missing := []string{}
+			for _, symbol := range writer.Symbols {
+				if !slices.Contains(reader.Symbols, symbol) {
+					missing = append(missing, symbol)
+				}
+			}
+			sort.Strings(missing)
+			for _, symbol := range missing {
+				violations = append(violations, newCompatibilityViolation(path, "enum symbol %v was removed", symbol))
+			}
+		}
+	case "fixed":
+		if reader.Size != writer.Size {
+			violations = append(violations, newCompatibilityViolation(path, "fixed size changed from %v to %v", writer.Size, reader.Size))
+		}
+	case "array":
+		violations = append(violations, compareAvroSchemas(reader.Items, writer.Items, path+"[]", visited)...)
+	case "map":
+		violations = append(violations, compareAvroSchemas(reader.Values, writer.Values, path+"{}", visited)...)
+	}
+	return violations
+}
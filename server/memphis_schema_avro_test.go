@@ -0,0 +1,165 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"memphis/models"
+	"strings"
+	"testing"
+)
+
+const testAvroSchema = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "com.example",
+	"doc": "a user",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "name", "type": ["null", "string"], "default": null},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE", "INACTIVE"]}},
+		{"name": "tags", "type": {"type": "array", "items": "string"}}
+	]
+}`
+
+func TestAvroCanonicalForm(t *testing.T) {
+	descriptor, err := generateAvroDescriptor(testAvroSchema)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"name":"com.example.User","type":"record","fields":[{"name":"id","type":"long"},{"name":"name","type":["null","string"]},{"name":"status","type":{"name":"com.example.Status","type":"enum","symbols":["ACTIVE","INACTIVE"]}},{"name":"tags","type":{"type":"array","items":"string"}}]}`
+	if descriptor != expected {
+		t.Fatalf("Unexpected canonical form: %v", descriptor)
+	}
+
+	for _, invalid := range []string{
+		`{"type": "record", "fields": []}`,
+		`{"type": "record", "name": "A", "fields": [{"name": "a", "type": "Missing"}]}`,
+		`{"type": "enum", "name": "E", "symbols": ["A", "A"]}`,
+		`["int", "int"]`,
+	} {
+		if err := validateAvroContent(invalid); err == nil {
+			t.Fatalf("Expected schema %v to be invalid", invalid)
+		}
+	}
+}
+
+func TestAvroSchemaValidator(t *testing.T) {
+	validate, err := newAvroSchemaValidator(testAvroSchema)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// id=1, name=union branch 1 "ab", status=INACTIVE, tags=["x"]
+	valid := []byte{0x02, 0x02, 0x04, 'a', 'b', 0x02, 0x02, 0x02, 'x', 0x00}
+	if err := validate(valid); err != nil {
+		t.Fatalf("Expected binary message to be valid: %v", err)
+	}
+	if err := validate(valid[:len(valid)-1]); err == nil {
+		t.Fatalf("Expected truncated binary message to be invalid")
+	}
+	jsonMsg := []byte(`{"id": 1, "name": {"string": "ab"}, "status": "ACTIVE", "tags": []}`)
+	if err := validate(jsonMsg); err == nil {
+		t.Fatalf("Expected json message to be invalid for a binary encoded schema")
+	}
+
+	jsonSchema := strings.Replace(testAvroSchema, `"doc": "a user",`, `"doc": "a user", "memphis.encoding": "json",`, 1)
+	validate, err = newAvroSchemaValidator(jsonSchema)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := validate(jsonMsg); err != nil {
+		t.Fatalf("Expected json message to be valid: %v", err)
+	}
+	if err := validate([]byte(`{"id": 1, "status": "DELETED", "tags": []}`)); err == nil {
+		t.Fatalf("Expected json message with an unknown enum symbol to be invalid")
+	}
+	if err := validate(valid); err == nil {
+		t.Fatalf("Expected binary message to be invalid for a json encoded schema")
+	}
+
+	invalidEncoding := strings.Replace(testAvroSchema, `"doc": "a user",`, `"doc": "a user", "memphis.encoding": "xml",`, 1)
+	if err := validateAvroContent(invalidEncoding); err == nil {
+		t.Fatalf("Expected an unknown encoding to be invalid")
+	}
+}
+
+func TestAvroZeroSizeItems(t *testing.T) {
+	validate, err := newAvroSchemaValidator(`{"type": "record", "name": "R", "fields": [{"name": "nulls", "type": {"type": "array", "items": "null"}}, {"name": "empty", "type": {"type": "map", "values": {"type": "record", "name": "E", "fields": []}}}]}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// nulls=[null x 50], empty={"a": E{}}
+	if err := validate([]byte{0x64, 0x00, 0x02, 0x02, 'a', 0x00}); err != nil {
+		t.Fatalf("Expected zero size items to be valid: %v", err)
+	}
+	// a single block of avroMaxDecodeItems+1 nulls
+	if err := validate([]byte{0x82, 0x80, 0x80, 0x01, 0x00, 0x00}); err == nil {
+		t.Fatalf("Expected a block above the maximum item count to be invalid")
+	}
+}
+
+func TestAvroSchemaCompatibility(t *testing.T) {
+	v1 := models.SchemaVersion{VersionNumber: 1, SchemaContent: `{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}]}`}
+	v2 := models.SchemaVersion{VersionNumber: 2, SchemaContent: `{"type": "record", "name": "R", "fields": [{"name": "a", "type": "long"}, {"name": "b", "type": "string", "default": ""}]}`}
+	v3 := models.SchemaVersion{VersionNumber: 3, SchemaContent: `{"type": "record", "name": "R", "fields": [{"name": "a", "type": "long"}, {"name": "c", "type": "string"}]}`}
+
+	violations, err := checkSchemaCompatibility("avro", compatibilityModeBackward, v2, []models.SchemaVersion{v1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("Expected promoting int to long and adding a defaulted field to be backward compatible, got: %v", violations)
+	}
+
+	violations, err = checkSchemaCompatibility("avro", compatibilityModeForward, v2, []models.SchemaVersion{v1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 1 {
+		t.Fatalf("Expected long to int to be forward incompatible, got: %v", violations)
+	}
+
+	violations, err = checkSchemaCompatibility("avro", compatibilityModeBackward, v3, []models.SchemaVersion{v1, v2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Path != "$.c" {
+		t.Fatalf("Expected adding a field without a default to be backward incompatible, got: %v", violations)
+	}
+}
+
+func TestAvroRecursiveRecords(t *testing.T) {
+	invalid := []string{
+		`{"type": "record", "name": "Node", "fields": [{"name": "next", "type": "Node"}]}`,
+		`{"type": "record", "name": "A", "fields": [{"name": "b", "type": {"type": "record", "name": "B", "fields": [{"name": "a", "type": "A"}]}}]}`,
+	}
+	for _, schema := range invalid {
+		if _, err := newAvroSchemaValidator(schema); err == nil {
+			t.Fatalf("Expected a record containing itself to be rejected: %v", schema)
+		}
+	}
+
+	validate, err := newAvroSchemaValidator(`{"type": "record", "name": "Node", "fields": [{"name": "next", "type": ["null", "Node"]}, {"name": "children", "type": {"type": "array", "items": "Node"}}]}`)
+	if err != nil {
+		t.Fatalf("Expected recursion through unions and arrays to be allowed: %v", err)
+	}
+	// next=Node{next=null, children=[]}, children=[]
+	if err := validate([]byte{0x02, 0x00, 0x00, 0x00}); err != nil {
+		t.Fatalf("Expected binary message to be valid: %v", err)
+	}
+
+	deep := bytes.Repeat([]byte{0x02}, avroMaxDecodeDepth+10)
+	if err := validate(deep); err == nil {
+		t.Fatalf("Expected a message nested beyond the maximum depth to be invalid")
+	}
+}
@@ -0,0 +1,425 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"memphis/models"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/types"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"k8s.io/utils/strings/slices"
+)
+
+const (
+	compatibilityModeNone               = "none"
+	compatibilityModeBackward           = "backward"
+	compatibilityModeBackwardTransitive = "backward_transitive"
+	compatibilityModeForward            = "forward"
+	compatibilityModeForwardTransitive  = "forward_transitive"
+	compatibilityModeFull               = "full"
+	compatibilityModeFullTransitive     = "full_transitive"
+)
+
+var compatibilityModes = []string{
+	compatibilityModeNone,
+	compatibilityModeBackward,
+	compatibilityModeBackwardTransitive,
+	compatibilityModeForward,
+	compatibilityModeForwardTransitive,
+	compatibilityModeFull,
+	compatibilityModeFullTransitive,
+}
+
+func validateCompatibilityMode(mode string) error {
+	if !slices.Contains(compatibilityModes, mode) {
+		return fmt.Errorf("compatibility mode has to be one of the following: %v", strings.Join(compatibilityModes, ", "))
+	}
+	return nil
+}
+
+// schemaCompatibilityChecker reports what prevents data (or queries) written against the writer schema from being handled by the reader schema
+type schemaCompatibilityChecker func(reader, writer models.SchemaVersion) ([]models.SchemaCompatibilityViolation, error)
+
+func getSchemaCompatibilityChecker(schemaType string) (schemaCompatibilityChecker, error) {
+	switch schemaType {
+	case "json":
+		return checkJsonSchemaCompatibility, nil
+	case "protobuf":
+		return checkProtobufCompatibility, nil
+	case "graphql":
+		return checkGraphqlCompatibility, nil
+	case "avro":
+		return checkAvroCompatibility, nil
+	default:
+		return nil, fmt.Errorf("compatibility checks are not supported for schema type %v", schemaType)
+	}
+}
+
+// checkSchemaCompatibility diffs a new version against the previous versions of a schema according to its compatibility mode,
+// backward means the new version can read data written with the previous ones and forward means the previous ones can read data written with the new one
+func checkSchemaCompatibility(schemaType, mode string, newVersion models.SchemaVersion, prevVersions []models.SchemaVersion) ([]models.SchemaCompatibilityViolation, error) {
+	violations := []models.SchemaCompatibilityViolation{}
+	if mode == _EMPTY_ || mode == compatibilityModeNone || len(prevVersions) == 0 {
+		return violations, nil
+	}
+	checker, err := getSchemaCompatibilityChecker(schemaType)
+	if err != nil {
+		return violations, err
+	}
+
+	versions := make([]models.SchemaVersion, len(prevVersions))
+	copy(versions, prevVersions)
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].VersionNumber > versions[j].VersionNumber
+	})
+	if !strings.HasSuffix(mode, "_transitive") {
+		versions = versions[:1]
+	}
+	checkBackward := strings.HasPrefix(mode, compatibilityModeBackward) || strings.HasPrefix(mode, compatibilityModeFull)
+	checkForward := strings.HasPrefix(mode, compatibilityModeForward) || strings.HasPrefix(mode, compatibilityModeFull)
+
+	for _, prevVersion := range versions {
+		if checkBackward {
+			found, err := checker(newVersion, prevVersion)
+			if err != nil {
+				return violations, err
+			}
+			for _, v := range found {
+				v.VersionNumber = prevVersion.VersionNumber
+				v.Direction = compatibilityModeBackward
+				violations = append(violations, v)
+			}
+		}
+		if checkForward {
+			found, err := checker(prevVersion, newVersion)
+			if err != nil {
+				return violations, err
+			}
+			for _, v := range found {
+				v.VersionNumber = prevVersion.VersionNumber
+				v.Direction = compatibilityModeForward
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations, nil
+}
+
+func formatCompatibilityViolations(violations []models.SchemaCompatibilityViolation) string {
+	msgs := make([]string, 0, len(violations))
+	for _, v := range violations {
+		msgs = append(msgs, fmt.Sprintf("%v incompatibility with version %v at %v: %v", v.Direction, v.VersionNumber, v.Path, v.Message))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func newCompatibilityViolation(path, format string, args ...any) models.SchemaCompatibilityViolation {
+	return models.SchemaCompatibilityViolation{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// JSON Schema
+
+func checkJsonSchemaCompatibility(reader, writer models.SchemaVersion) ([]models.SchemaCompatibilityViolation, error) {
+	var readerSchema, writerSchema map[string]any
+	if err := json.Unmarshal([]byte(reader.SchemaContent), &readerSchema); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(writer.SchemaContent), &writerSchema); err != nil {
+		return nil, err
+	}
+	return compareJsonSchemas(readerSchema, writerSchema, "$"), nil
+}
+
+func jsonSchemaTypes(schema map[string]any) []string {
+	switch t := schema["type"].(type) {
+	case string:
+		return []string{t}
+	case []any:
+		res := []string{}
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func jsonSchemaStrings(value any) []string {
+	list, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	res := []string{}
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func compareJsonSchemas(reader, writer map[string]any, path string) []models.SchemaCompatibilityViolation {
+	violations := []models.SchemaCompatibilityViolation{}
+
+	readerTypes, writerTypes := jsonSchemaTypes(reader), jsonSchemaTypes(writer)
+	if len(readerTypes) > 0 {
+		if len(writerTypes) == 0 {
+			violations = append(violations, newCompatibilityViolation(path, "type was restricted to %v", strings.Join(readerTypes, ", ")))
+		}
+		for _, t := range writerTypes {
+			if !slices.Contains(readerTypes, t) && !(t == "integer" && slices.Contains(readerTypes, "number")) {
+				violations = append(violations, newCompatibilityViolation(path, "type %v is no longer accepted (accepted types: %v)", t, strings.Join(readerTypes, ", ")))
+			}
+		}
+	}
+
+	if readerEnum, ok := reader["enum"].([]any); ok {
+		writerEnum, ok := writer["enum"].([]any)
+		if !ok {
+			violations = append(violations, newCompatibilityViolation(path, "values were restricted to an enum"))
+		} else {
+			for _, wv := range writerEnum {
+				found := false
+				for _, rv := range readerEnum {
+					if fmt.Sprint(rv) == fmt.Sprint(wv) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					violations = append(violations, newCompatibilityViolation(path, "enum value %v was removed", wv))
+				}
+			}
+		}
+	}
+
+	writerRequired := jsonSchemaStrings(writer["required"])
+	for _, field := range jsonSchemaStrings(reader["required"]) {
+		if !slices.Contains(writerRequired, field) {
+			violations = append(violations, newCompatibilityViolation(path+"."+field, "field became required"))
+		}
+	}
+
+	readerProps, _ := reader["properties"].(map[string]any)
+	writerProps, _ := writer["properties"].(map[string]any)
+	closed := reader["additionalProperties"] == false
+	writerFields := make([]string, 0, len(writerProps))
+	for field := range writerProps {
+		writerFields = append(writerFields, field)
+	}
+	sort.Strings(writerFields)
+	for _, field := range writerFields {
+		readerProp, ok := readerProps[field].(map[string]any)
+		if !ok {
+			if closed {
+				violations = append(violations, newCompatibilityViolation(path+"."+field, "field was removed while additional properties are not allowed"))
+			}
+			continue
+		}
+		if writerProp, ok := writerProps[field].(map[string]any); ok {
+			violations = append(violations, compareJsonSchemas(readerProp, writerProp, path+"."+field)...)
+		}
+	}
+
+	readerItems, readerOk := reader["items"].(map[string]any)
+	writerItems, writerOk := writer["items"].(map[string]any)
+	if readerOk && writerOk {
+		violations = append(violations, compareJsonSchemas(readerItems, writerItems, path+"[]")...)
+	}
+
+	return violations
+}
+
+// Protobuf
+
+func checkProtobufCompatibility(reader, writer models.SchemaVersion) ([]models.SchemaCompatibilityViolation, error) {
+	readerMd, err := findProtobufMessageDescriptor(reader.Descriptor, reader.MessageStructName)
+	if err != nil {
+		return nil, err
+	}
+	writerMd, err := findProtobufMessageDescriptor(writer.Descriptor, writer.MessageStructName)
+	if err != nil {
+		return nil, err
+	}
+	visited := make(map[string]bool)
+	return compareProtobufMessages(readerMd, writerMd, string(readerMd.Name()), visited), nil
+}
+
+// protobufWireGroup groups field kinds that share a wire representation and can be read as one another
+func protobufWireGroup(kind protoreflect.Kind) string {
+	switch kind {
+	case protoreflect.Int32Kind, protoreflect.Uint32Kind, protoreflect.Int64Kind, protoreflect.Uint64Kind, protoreflect.BoolKind, protoreflect.EnumKind:
+		return "varint"
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return "zigzag"
+	case protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind:
+		return "fixed32"
+	case protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind:
+		return "fixed64"
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		return "bytes"
+	default:
+		return kind.String()
+	}
+}
+
+func compareProtobufMessages(reader, writer protoreflect.MessageDescriptor, path string, visited map[string]bool) []models.SchemaCompatibilityViolation {
+	violations := []models.SchemaCompatibilityViolation{}
+	key := string(reader.FullName()) + "|" + string(writer.FullName())
+	if visited[key] {
+		return violations
+	}
+	visited[key] = true
+
+	readerFields, writerFields := reader.Fields(), writer.Fields()
+	for i := 0; i < writerFields.Len(); i++ {
+		wf := writerFields.Get(i)
+		fieldPath := path + "." + string(wf.Name())
+		if rf := readerFields.ByName(wf.Name()); rf != nil && rf.Number() != wf.Number() {
+			violations = append(violations, newCompatibilityViolation(fieldPath, "field was renumbered from %v to %v", wf.Number(), rf.Number()))
+			continue
+		}
+
+		// fields the reader doesn't know are skipped as unknown fields
+		rf := readerFields.ByNumber(wf.Number())
+		if rf == nil {
+			continue
+		}
+		if rf.Name() != wf.Name() {
+			violations = append(violations, newCompatibilityViolation(fieldPath, "field number %v was reused by field %v", wf.Number(), rf.Name()))
+			continue
+		}
+		if protobufWireGroup(rf.Kind()) != protobufWireGroup(wf.Kind()) {
+			violations = append(violations, newCompatibilityViolation(fieldPath, "field type changed from %v to %v", wf.Kind(), rf.Kind()))
+			continue
+		}
+		if rf.IsList() != wf.IsList() || rf.IsMap() != wf.IsMap() {
+			violations = append(violations, newCompatibilityViolation(fieldPath, "field cardinality changed"))
+			continue
+		}
+		if rf.Message() != nil && wf.Message() != nil {
+			violations = append(violations, compareProtobufMessages(rf.Message(), wf.Message(), fieldPath, visited)...)
+		}
+	}
+
+	for i := 0; i < readerFields.Len(); i++ {
+		rf := readerFields.Get(i)
+		if rf.Cardinality() == protoreflect.Required && writerFields.ByNumber(rf.Number()) == nil {
+			violations = append(violations, newCompatibilityViolation(path+"."+string(rf.Name()), "required field was added"))
+		}
+	}
+	return violations
+}
+
+// GraphQL
+
+func checkGraphqlCompatibility(reader, writer models.SchemaVersion) ([]models.SchemaCompatibilityViolation, error) {
+	readerSchema, err := graphql.ParseSchema(reader.SchemaContent, nil)
+	if err != nil {
+		return nil, err
+	}
+	writerSchema, err := graphql.ParseSchema(writer.SchemaContent, nil)
+	if err != nil {
+		return nil, err
+	}
+	return compareGraphqlSchemas(readerSchema.ASTSchema(), writerSchema.ASTSchema()), nil
+}
+
+func compareGraphqlFields(readerFields, writerFields types.FieldsDefinition, path string) []models.SchemaCompatibilityViolation {
+	violations := []models.SchemaCompatibilityViolation{}
+	for _, wf := range writerFields {
+		fieldPath := path + "." + wf.Name
+		rf := readerFields.Get(wf.Name)
+		if rf == nil {
+			violations = append(violations, newCompatibilityViolation(fieldPath, "field was removed"))
+			continue
+		}
+		if rf.Type.String() != wf.Type.String() {
+			violations = append(violations, newCompatibilityViolation(fieldPath, "field type changed from %v to %v", wf.Type, rf.Type))
+		}
+		violations = append(violations, compareGraphqlInputValues(rf.Arguments, wf.Arguments, fieldPath, "argument")...)
+	}
+	return violations
+}
+
+func compareGraphqlInputValues(readerValues, writerValues types.ArgumentsDefinition, path, kind string) []models.SchemaCompatibilityViolation {
+	violations := []models.SchemaCompatibilityViolation{}
+	for _, wv := range writerValues {
+		rv := readerValues.Get(wv.Name.Name)
+		if rv == nil {
+			violations = append(violations, newCompatibilityViolation(path+"."+wv.Name.Name, "%v was removed", kind))
+			continue
+		}
+		if rv.Type.String() != wv.Type.String() {
+			violations = append(violations, newCompatibilityViolation(path+"."+wv.Name.Name, "%v type changed from %v to %v", kind, wv.Type, rv.Type))
+		}
+	}
+	for _, rv := range readerValues {
+		if writerValues.Get(rv.Name.Name) != nil {
+			continue
+		}
+		if _, nonNull := rv.Type.(*types.NonNull); nonNull && rv.Default == nil {
+			violations = append(violations, newCompatibilityViolation(path+"."+rv.Name.Name, "required %v was added", kind))
+		}
+	}
+	return violations
+}
+
+func compareGraphqlSchemas(reader, writer *types.Schema) []models.SchemaCompatibilityViolation {
+	violations := []models.SchemaCompatibilityViolation{}
+	typeNames := make([]string, 0, len(writer.Types))
+	for name := range writer.Types {
+		if !strings.HasPrefix(name, "__") {
+			typeNames = append(typeNames, name)
+		}
+	}
+	sort.Strings(typeNames)
+
+	for _, name := range typeNames {
+		readerType, ok := reader.Types[name]
+		if !ok {
+			violations = append(violations, newCompatibilityViolation(name, "type was removed"))
+			continue
+		}
+		writerType := writer.Types[name]
+		if readerType.Kind() != writerType.Kind() {
+			violations = append(violations, newCompatibilityViolation(name, "type kind changed from %v to %v", writerType.Kind(), readerType.Kind()))
+			continue
+		}
+		switch wt := writerType.(type) {
+		case *types.ObjectTypeDefinition:
+			violations = append(violations, compareGraphqlFields(readerType.(*types.ObjectTypeDefinition).Fields, wt.Fields, name)...)
+		case *types.InterfaceTypeDefinition:
+			violations = append(violations, compareGraphqlFields(readerType.(*types.InterfaceTypeDefinition).Fields, wt.Fields, name)...)
+		case *types.InputObject:
+			violations = append(violations, compareGraphqlInputValues(readerType.(*types.InputObject).Values, wt.Values, name, "input field")...)
+		case *types.EnumTypeDefinition:
+			readerValues := []string{}
+			for _, v := range readerType.(*types.EnumTypeDefinition).EnumValuesDefinition {
+				readerValues = append(readerValues, v.EnumValue)
+			}
+			for _, v := range wt.EnumValuesDefinition {
+				if !slices.Contains(readerValues, v.EnumValue) {
+					violations = append(violations, newCompatibilityViolation(name+"."+v.EnumValue, "enum value was removed"))
+				}
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,98 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/base64"
+	"memphis/models"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func testProtobufVersion(t *testing.T, versionNumber int, fields map[string]int32) models.SchemaVersion {
+	msg := &descriptorpb.DescriptorProto{Name: proto.String("Test")}
+	for name, number := range fields {
+		msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+		})
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String("test.proto"),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{msg},
+	}
+	raw, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	if err != nil {
+		t.Fatalf("Failed marshaling the descriptor: %v", err)
+	}
+	return models.SchemaVersion{VersionNumber: versionNumber, MessageStructName: "Test", Descriptor: base64.StdEncoding.EncodeToString(raw)}
+}
+
+func TestJsonSchemaCompatibility(t *testing.T) {
+	v1 := models.SchemaVersion{VersionNumber: 1, SchemaContent: `{"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}, "required": ["id"]}`}
+	v2 := models.SchemaVersion{VersionNumber: 2, SchemaContent: `{"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string"}, "age": {"type": "integer"}}, "required": ["id"]}`}
+	v3 := models.SchemaVersion{VersionNumber: 3, SchemaContent: `{"type": "object", "properties": {"id": {"type": "string"}, "name": {"type": "string"}}, "required": ["id", "name"]}`}
+
+	violations, err := checkSchemaCompatibility("json", compatibilityModeFull, v2, []models.SchemaVersion{v1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("Expected adding an optional field to be fully compatible, got: %v", violations)
+	}
+
+	violations, err = checkSchemaCompatibility("json", compatibilityModeBackward, v3, []models.SchemaVersion{v1, v2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("Expected a type change and a new required field, got: %v", violations)
+	}
+	for _, v := range violations {
+		if v.VersionNumber != 2 || v.Direction != compatibilityModeBackward {
+			t.Fatalf("Expected a non transitive check against the latest version only, got: %+v", v)
+		}
+	}
+
+	violations, err = checkSchemaCompatibility("json", compatibilityModeNone, v3, []models.SchemaVersion{v1, v2})
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("Expected no checks when the compatibility mode is none, got: %v, %v", violations, err)
+	}
+}
+
+func TestProtobufCompatibility(t *testing.T) {
+	v1 := testProtobufVersion(t, 1, map[string]int32{"id": 1, "name": 2})
+	v2 := testProtobufVersion(t, 2, map[string]int32{"id": 1, "name": 2, "email": 3})
+	v3 := testProtobufVersion(t, 3, map[string]int32{"id": 1, "name": 4, "email": 3})
+
+	violations, err := checkSchemaCompatibility("protobuf", compatibilityModeFullTransitive, v2, []models.SchemaVersion{v1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("Expected adding a field to be fully compatible, got: %v", violations)
+	}
+
+	violations, err = checkSchemaCompatibility("protobuf", compatibilityModeBackwardTransitive, v3, []models.SchemaVersion{v1, v2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("Expected the renumbered field to be reported against both versions, got: %v", violations)
+	}
+}
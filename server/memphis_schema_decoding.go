@@ -0,0 +1,136 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"memphis/models"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const messagePreviewLength = 80
+
+// messageDecoder renders messages of a station according to the active version of its attached schema
+type messageDecoder struct {
+	schemaName    string
+	schemaType    string
+	versionNumber int
+	validate      schemaValidator
+	protoMsg      protoreflect.MessageDescriptor
+}
+
+func newStationMessageDecoder(station models.Station) (*messageDecoder, error) {
+	if station.SchemaName == _EMPTY_ {
+		return nil, nil
+	}
+	sn, err := StationNameFromStr(station.Name)
+	if err != nil {
+		return nil, err
+	}
+	schemaUpdate, err := getSchemaUpdateInitFromStation(sn, station.TenantName)
+	if err == ErrNoSchema {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newMessageDecoder(*schemaUpdate)
+}
+
+func newMessageDecoder(schemaUpdate models.ProducerSchemaUpdateInit) (*messageDecoder, error) {
+	validate, err := newSchemaValidator(schemaUpdate.SchemaType, schemaUpdate.ActiveVersion)
+	if err != nil {
+		return nil, err
+	}
+	decoder := &messageDecoder{
+		schemaName:    schemaUpdate.SchemaName,
+		schemaType:    schemaUpdate.SchemaType,
+		versionNumber: schemaUpdate.ActiveVersion.VersionNumber,
+		validate:      validate,
+	}
+	if schemaUpdate.SchemaType == "protobuf" {
+		decoder.protoMsg, err = findProtobufMessageDescriptor(schemaUpdate.ActiveVersion.Descriptor, schemaUpdate.ActiveVersion.MessageStructName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decoder, nil
+}
+
+// decode returns a readable view of the message, an empty string when the message can not be rendered by the schema,
+// along with the result of validating the message against the active schema version
+func (md *messageDecoder) decode(data []byte, indent bool) (string, *models.MessageSchemaValidation) {
+	validation := &models.MessageSchemaValidation{
+		SchemaName:    md.schemaName,
+		SchemaType:    md.schemaType,
+		VersionNumber: md.versionNumber,
+		IsValid:       true,
+	}
+	if err := md.validate(data); err != nil {
+		validation.IsValid = false
+		validation.Error = err.Error()
+	}
+
+	switch md.schemaType {
+	case "protobuf":
+		return decodeProtobufMessage(md.protoMsg, data, indent), validation
+	case "json", "avro":
+		return formatJsonMessage(data, indent), validation
+	case "graphql":
+		if utf8.Valid(data) {
+			return string(data), validation
+		}
+	}
+	return _EMPTY_, validation
+}
+
+func decodeProtobufMessage(md protoreflect.MessageDescriptor, data []byte, indent bool) string {
+	msg := dynamicpb.NewMessage(md)
+	err := proto.Unmarshal(data, msg)
+	if err != nil {
+		return _EMPTY_
+	}
+	decoded, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return _EMPTY_
+	}
+	// protojson randomizes its whitespaces, normalize it the same way json messages are
+	return formatJsonMessage(decoded, indent)
+}
+
+func formatJsonMessage(data []byte, indent bool) string {
+	var buf bytes.Buffer
+	var err error
+	if indent {
+		err = json.Indent(&buf, data, "", "  ")
+	} else {
+		err = json.Compact(&buf, data)
+	}
+	if err != nil {
+		return _EMPTY_
+	}
+	return buf.String()
+}
+
+func messagePreview(data string) string {
+	runes := []rune(data)
+	if len(runes) > messagePreviewLength {
+		return string(runes[0:messagePreviewLength])
+	}
+	return data
+}
@@ -0,0 +1,78 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+func TestFormatJsonMessage(t *testing.T) {
+	if got := formatJsonMessage([]byte(`{ "a": 1,  "b": [1, 2] }`), false); got != `{"a":1,"b":[1,2]}` {
+		t.Fatalf("unexpected compact json: %v", got)
+	}
+	if got := formatJsonMessage([]byte(`{"a":1}`), true); got != "{\n  \"a\": 1\n}" {
+		t.Fatalf("unexpected indented json: %v", got)
+	}
+	if got := formatJsonMessage([]byte{0x0a, 0x03}, true); got != _EMPTY_ {
+		t.Fatalf("expected no decoded view for a non json message, got %v", got)
+	}
+}
+
+func TestDecodeProtobufMessage(t *testing.T) {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:   proto.String("event.proto"),
+		Syntax: proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Event"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("name"), JsonName: proto.String("name"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("count"), JsonName: proto.String("count"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum(), Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := fd.Messages().ByName("Event")
+	msg := dynamicpb.NewMessage(md)
+	msg.Set(md.Fields().ByName("name"), protoreflect.ValueOfString("memphis"))
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := decodeProtobufMessage(md, data, false); got != `{"name":"memphis","count":0}` {
+		t.Fatalf("unexpected decoded message: %v", got)
+	}
+	if got := decodeProtobufMessage(md, []byte{0xff, 0xff}, false); got != _EMPTY_ {
+		t.Fatalf("expected no decoded view for a malformed message, got %v", got)
+	}
+}
+
+func TestMessagePreview(t *testing.T) {
+	long := make([]rune, messagePreviewLength+5)
+	for i := range long {
+		long[i] = 'ש'
+	}
+	if got := []rune(messagePreview(string(long))); len(got) != messagePreviewLength {
+		t.Fatalf("expected the preview to be cut to %v characters, got %v", messagePreviewLength, len(got))
+	}
+	if got := messagePreview("abc"); got != "abc" {
+		t.Fatalf("unexpected preview: %v", got)
+	}
+}
@@ -0,0 +1,581 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	schemaEnforcementCacheType       = "schema_enforcement"
+	schemaEnforcementProducer        = "$memphis_schema_enforcement"
+	schemaEnforcementFailedErrorCode = 400
+	schemaEnforcersRetryInterval     = 10 * time.Second
+)
+
+type schemaValidator func(msg []byte) error
+
+// schemaEnforcer holds the compiled active schema version of a station that enforces its schema on the broker,
+// a nil validate means the station's schema could not be loaded yet and its messages are rejected until it is
+type schemaEnforcer struct {
+	schemaName    string
+	versionNumber int
+	validate      schemaValidator
+}
+
+type schemaEnforcersCache struct {
+	sync.RWMutex
+	// tenant name -> station internal name -> enforcer, stations without an enforcer are not enforced,
+	// the cache is filled at startup and on reloads so publishing never waits on the db
+	tenants map[string]map[string]*schemaEnforcer
+	// tenants whose enforcers should be synced with the db again, an empty tenant name stands for all of them
+	pendingSyncs map[string]bool
+	// tenant name -> station internal names whose enforcer failed loading
+	pendingReloads map[string]map[string]bool
+}
+
+var stationSchemaEnforcers = schemaEnforcersCache{tenants: make(map[string]map[string]*schemaEnforcer)}
+
+func (sec *schemaEnforcersCache) get(tenantName, streamName string) (*schemaEnforcer, bool) {
+	sec.RLock()
+	defer sec.RUnlock()
+	stations, ok := sec.tenants[tenantName]
+	if !ok {
+		return nil, false
+	}
+	enforcer, ok := stations[streamName]
+	return enforcer, ok
+}
+
+func (sec *schemaEnforcersCache) set(tenantName, streamName string, enforcer *schemaEnforcer) {
+	sec.Lock()
+	defer sec.Unlock()
+	if _, ok := sec.tenants[tenantName]; !ok {
+		sec.tenants[tenantName] = make(map[string]*schemaEnforcer)
+	}
+	sec.tenants[tenantName][streamName] = enforcer
+	sec.removePendingReload(tenantName, streamName)
+}
+
+func (sec *schemaEnforcersCache) remove(tenantName, streamName string) {
+	sec.Lock()
+	defer sec.Unlock()
+	if stations, ok := sec.tenants[tenantName]; ok {
+		delete(stations, streamName)
+	}
+	sec.removePendingReload(tenantName, streamName)
+}
+
+// snapshot returns the cached enforcers of the tenant, or of all tenants for an empty tenant name
+func (sec *schemaEnforcersCache) snapshot(tenantName string) map[string]map[string]*schemaEnforcer {
+	sec.RLock()
+	defer sec.RUnlock()
+	snapshot := make(map[string]map[string]*schemaEnforcer)
+	for t, stations := range sec.tenants {
+		if tenantName != _EMPTY_ && t != tenantName {
+			continue
+		}
+		snapshot[t] = make(map[string]*schemaEnforcer, len(stations))
+		for streamName, enforcer := range stations {
+			snapshot[t][streamName] = enforcer
+		}
+	}
+	return snapshot
+}
+
+func (sec *schemaEnforcersCache) removeIfUnchanged(tenantName, streamName string, enforcer *schemaEnforcer) {
+	sec.Lock()
+	defer sec.Unlock()
+	if current, ok := sec.tenants[tenantName][streamName]; ok && current == enforcer {
+		delete(sec.tenants[tenantName], streamName)
+	}
+}
+
+// markFailed keeps rejecting the messages of a station already known to enforce its schema and retries loading it later,
+// stations which were not known to enforce are kept unenforced meanwhile
+// Lock should be held
+func (sec *schemaEnforcersCache) markFailed(tenantName, streamName string) {
+	if enforcer := sec.tenants[tenantName][streamName]; enforcer != nil {
+		sec.tenants[tenantName][streamName] = &schemaEnforcer{schemaName: enforcer.schemaName, versionNumber: enforcer.versionNumber}
+	}
+	if sec.pendingReloads == nil {
+		sec.pendingReloads = make(map[string]map[string]bool)
+	}
+	if _, ok := sec.pendingReloads[tenantName]; !ok {
+		sec.pendingReloads[tenantName] = make(map[string]bool)
+	}
+	sec.pendingReloads[tenantName][streamName] = true
+}
+
+// Lock should be held
+func (sec *schemaEnforcersCache) removePendingReload(tenantName, streamName string) {
+	if stations, ok := sec.pendingReloads[tenantName]; ok {
+		delete(stations, streamName)
+		if len(stations) == 0 {
+			delete(sec.pendingReloads, tenantName)
+		}
+	}
+}
+
+func (sec *schemaEnforcersCache) reloadFailed(tenantName, streamName string) {
+	sec.Lock()
+	defer sec.Unlock()
+	sec.markFailed(tenantName, streamName)
+}
+
+// syncFailed marks the cached enforcers of the tenant, or of all tenants for an empty tenant name, as failed
+// and requests another sync
+func (sec *schemaEnforcersCache) syncFailed(tenantName string) {
+	sec.Lock()
+	defer sec.Unlock()
+	for t, stations := range sec.tenants {
+		if tenantName != _EMPTY_ && t != tenantName {
+			continue
+		}
+		for streamName, enforcer := range stations {
+			if enforcer != nil {
+				sec.markFailed(t, streamName)
+			}
+		}
+	}
+	sec.requestSyncLocked(tenantName)
+}
+
+func (sec *schemaEnforcersCache) requestSync(tenantName string) {
+	sec.Lock()
+	defer sec.Unlock()
+	sec.requestSyncLocked(tenantName)
+}
+
+// Lock should be held
+func (sec *schemaEnforcersCache) requestSyncLocked(tenantName string) {
+	if sec.pendingSyncs == nil {
+		sec.pendingSyncs = make(map[string]bool)
+	}
+	sec.pendingSyncs[tenantName] = true
+}
+
+// takePending returns and clears the pending syncs and reloads
+func (sec *schemaEnforcersCache) takePending() ([]string, map[string][]string) {
+	sec.Lock()
+	defer sec.Unlock()
+	tenants := make([]string, 0, len(sec.pendingSyncs))
+	for tenantName := range sec.pendingSyncs {
+		tenants = append(tenants, tenantName)
+	}
+	reloads := make(map[string][]string, len(sec.pendingReloads))
+	for tenantName, stations := range sec.pendingReloads {
+		for streamName := range stations {
+			reloads[tenantName] = append(reloads[tenantName], streamName)
+		}
+	}
+	sec.pendingSyncs = nil
+	sec.pendingReloads = nil
+	return tenants, reloads
+}
+
+// enforcedStations returns the tenant's cached stations which have an enforcer
+func (sec *schemaEnforcersCache) enforcedStations(tenantName string) []string {
+	sec.RLock()
+	defer sec.RUnlock()
+	streamNames := []string{}
+	for streamName, enforcer := range sec.tenants[tenantName] {
+		if enforcer != nil {
+			streamNames = append(streamNames, streamName)
+		}
+	}
+	return streamNames
+}
+
+// invalidate drops the given stations from the cache, or all of the tenant's stations when none are given
+func (sec *schemaEnforcersCache) invalidate(tenantName string, streamNames []string) {
+	sec.Lock()
+	defer sec.Unlock()
+	if len(streamNames) == 0 {
+		delete(sec.tenants, tenantName)
+		delete(sec.pendingReloads, tenantName)
+		return
+	}
+	for _, streamName := range streamNames {
+		if stations, ok := sec.tenants[tenantName]; ok {
+			delete(stations, streamName)
+		}
+		sec.removePendingReload(tenantName, streamName)
+	}
+}
+
+func newSchemaValidator(schemaType string, version models.ProducerSchemaUpdateVersion) (schemaValidator, error) {
+	switch schemaType {
+	case "json":
+		return newJsonSchemaValidator(version.Content)
+	case "graphql":
+		return newGraphqlSchemaValidator(version.Content)
+	case "protobuf":
+		return newProtobufSchemaValidator(version.Descriptor, version.MessageStructName)
+	case "avro":
+		return newAvroSchemaValidator(version.Content)
+	default:
+		return nil, fmt.Errorf("schema type %v can not be enforced on the broker", schemaType)
+	}
+}
+
+func newJsonSchemaValidator(schemaContent string) (schemaValidator, error) {
+	schema, err := jsonschema.CompileString("schema.json", schemaContent)
+	if err != nil {
+		return nil, err
+	}
+	return func(msg []byte) error {
+		var data interface{}
+		err := json.Unmarshal(msg, &data)
+		if err != nil {
+			return errors.New("expecting the message to be a valid json")
+		}
+		return schema.Validate(data)
+	}, nil
+}
+
+func newGraphqlSchemaValidator(schemaContent string) (schemaValidator, error) {
+	schema, err := graphql.ParseSchema(schemaContent, nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(msg []byte) error {
+		validationErrs := schema.Validate(string(msg))
+		if len(validationErrs) == 0 {
+			return nil
+		}
+		errMsgs := make([]string, 0, len(validationErrs))
+		for _, validationErr := range validationErrs {
+			errMsgs = append(errMsgs, validationErr.Error())
+		}
+		return errors.New(strings.Join(errMsgs, "; "))
+	}, nil
+}
+
+func newProtobufSchemaValidator(descriptor, messageStructName string) (schemaValidator, error) {
+	md, err := findProtobufMessageDescriptor(descriptor, messageStructName)
+	if err != nil {
+		return nil, err
+	}
+	return func(msg []byte) error {
+		err := proto.Unmarshal(msg, dynamicpb.NewMessage(md))
+		if err != nil {
+			return fmt.Errorf("expecting the message to be a valid %v: %v", md.Name(), err.Error())
+		}
+		return nil
+	}, nil
+}
+
+func findProtobufMessageDescriptor(descriptor, messageStructName string) (protoreflect.MessageDescriptor, error) {
+	rawDescriptor, err := base64.StdEncoding.DecodeString(descriptor)
+	if err != nil {
+		return nil, err
+	}
+	var descriptorSet descriptorpb.FileDescriptorSet
+	err = proto.Unmarshal(rawDescriptor, &descriptorSet)
+	if err != nil {
+		return nil, err
+	}
+	files, err := protodesc.NewFiles(&descriptorSet)
+	if err != nil {
+		return nil, err
+	}
+
+	if d, err := files.FindDescriptorByName(protoreflect.FullName(messageStructName)); err == nil {
+		if md, ok := d.(protoreflect.MessageDescriptor); ok {
+			return md, nil
+		}
+	}
+	var md protoreflect.MessageDescriptor
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		md = fd.Messages().ByName(protoreflect.Name(messageStructName))
+		return md == nil
+	})
+	if md == nil {
+		return nil, fmt.Errorf("message %v was not found in the schema descriptor", messageStructName)
+	}
+	return md, nil
+}
+
+func loadSchemaEnforcer(tenantName, streamName string) (*schemaEnforcer, error) {
+	sn := StationNameFromStreamName(streamName)
+	exist, station, err := db.GetStationByName(sn.Ext(), tenantName)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, nil
+	}
+	return loadStationSchemaEnforcer(station, sn, tenantName)
+}
+
+func loadStationSchemaEnforcer(station models.Station, sn StationName, tenantName string) (*schemaEnforcer, error) {
+	if !station.SchemaEnforced || station.SchemaName == _EMPTY_ {
+		return nil, nil
+	}
+
+	schemaUpdate, err := getSchemaUpdateInitFromStation(sn, tenantName)
+	if err == ErrNoSchema {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	validate, err := newSchemaValidator(schemaUpdate.SchemaType, schemaUpdate.ActiveVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &schemaEnforcer{
+		schemaName:    schemaUpdate.SchemaName,
+		versionNumber: schemaUpdate.ActiveVersion.VersionNumber,
+		validate:      validate,
+	}, nil
+}
+
+// enforceStationSchema validates messages published into a station that enforces its schema on the broker,
+// it returns false when the message should not be stored
+func (mset *stream) enforceStationSchema(c *client, reply string, hdr, msg []byte) bool {
+	mset.mu.RLock()
+	s, acc, outq := mset.srv, mset.acc, mset.outq
+	streamName, canRespond := mset.cfg.Name, !mset.cfg.NoAck && len(reply) > 0
+	mset.mu.RUnlock()
+
+	if s == nil || acc == nil || strings.HasPrefix(streamName, "$memphis") {
+		return true
+	}
+	tenantName := acc.GetName()
+	if tenantName == DEFAULT_SYSTEM_ACCOUNT {
+		return true
+	}
+
+	enforcer, ok := stationSchemaEnforcers.get(tenantName, streamName)
+	if !ok || enforcer == nil {
+		return true
+	}
+	if enforcer.validate == nil {
+		// the station enforces a schema which could not be loaded, the message is rejected rather than stored unvalidated
+		if canRespond && outq != nil {
+			sendSchemaEnforcementError(outq, reply, streamName, "failed loading the station's schema, try again later")
+		}
+		return false
+	}
+
+	headers := make(map[string]string)
+	if len(hdr) > 0 {
+		if decoded, err := DecodeHeader(hdr); err == nil {
+			headers = decoded
+		}
+	}
+	// messages resent from the dls were already validated when they were first produced,
+	// the header is trusted only when the message has been published by the broker itself
+	if isInternalPublisher(c) && headers["$memphis_producedBy"] == "$memphis_dls" {
+		return true
+	}
+
+	err := enforcer.validate(msg)
+	if err == nil {
+		return true
+	}
+
+	sn := StationNameFromStreamName(streamName)
+	s.sendSchemaverseDlsMsg(tenantName, sn, headers, msg, err.Error())
+
+	if canRespond && outq != nil {
+		sendSchemaEnforcementError(outq, reply, streamName, fmt.Sprintf("schema validation has failed against %v version %v: %v", enforcer.schemaName, enforcer.versionNumber, err.Error()))
+	}
+	return false
+}
+
+func isInternalPublisher(c *client) bool {
+	return c != nil && (c.kind == SYSTEM || c.kind == JETSTREAM || c.kind == ACCOUNT)
+}
+
+func sendSchemaEnforcementError(outq *jsOutQ, reply, streamName, description string) {
+	resp := &JSPubAckResponse{
+		PubAck: &PubAck{Stream: streamName},
+		Error: &ApiError{
+			Code:        schemaEnforcementFailedErrorCode,
+			Description: description,
+		},
+	}
+	b, _ := json.Marshal(resp)
+	outq.sendMsg(reply, b)
+}
+
+// sendSchemaverseDlsMsg stores a message that failed the broker's schema validation through the same path the sdks use,
+// it only queues an internal publish so it is safe to call from the publishing client's read loop
+func (s *Server) sendSchemaverseDlsMsg(tenantName string, sn StationName, headers map[string]string, msg []byte, validationErr string) {
+	producerName := headers["$memphis_producedBy"]
+	if producerName == _EMPTY_ {
+		producerName = schemaEnforcementProducer
+	}
+	dlsMsg := models.SchemaVerseDlsMessageSdk{
+		StationName: sn.Ext(),
+		Producer: models.ProducerDetails{
+			Name:         producerName,
+			ConnectionId: headers["$memphis_connectionId"],
+		},
+		Message: models.MessagePayload{
+			TimeSent: time.Now(),
+			Size:     len(msg),
+			Data:     hex.EncodeToString(msg),
+			Headers:  headers,
+		},
+		ValidationError: validationErr,
+	}
+	data, err := json.Marshal(dlsMsg)
+	if err != nil {
+		s.Errorf("[tenant: %v]sendSchemaverseDlsMsg: station %v: %v", tenantName, sn.Ext(), err.Error())
+		return
+	}
+
+	if tenantName != s.MemphisGlobalAccountString() {
+		ci, err := json.Marshal(ClientInfo{Account: tenantName})
+		if err != nil {
+			s.Errorf("[tenant: %v]sendSchemaverseDlsMsg: station %v: %v", tenantName, sn.Ext(), err.Error())
+			return
+		}
+		data = append(genHeader(nil, ClientInfoHdr, string(ci)), data...)
+	}
+	s.sendInternalAccountMsg(s.MemphisGlobalAccount(), SCHEMAVERSE_DLS_INNER_SUBJ, data)
+}
+
+// invalidateSchemaEnforcement drops removed stations from the enforcers cache of every server
+func (s *Server) invalidateSchemaEnforcement(tenantName string, stationNames ...StationName) {
+	s.sendSchemaEnforcementCacheUpdate("delete", tenantName, stationNames)
+}
+
+// reloadSchemaEnforcement reloads the enforcers of stations whose schema has been attached or updated on every server,
+// so publishing does not wait on the db, all of the tenant's enforced stations are reloaded when none are given
+func (s *Server) reloadSchemaEnforcement(tenantName string, stationNames ...StationName) {
+	s.sendSchemaEnforcementCacheUpdate("reload", tenantName, stationNames)
+}
+
+func (s *Server) sendSchemaEnforcementCacheUpdate(operation, tenantName string, stationNames []StationName) {
+	streamNames := make([]string, 0, len(stationNames))
+	for _, sn := range stationNames {
+		streamNames = append(streamNames, sn.Intern())
+	}
+	msg, err := json.Marshal(models.CacheUpdateRequest{
+		CacheType:  schemaEnforcementCacheType,
+		Operation:  operation,
+		TenantName: tenantName,
+		Stations:   streamNames,
+	})
+	if err != nil {
+		s.Errorf("[tenant: %v]sendSchemaEnforcementCacheUpdate: %v", tenantName, err.Error())
+		return
+	}
+	s.sendInternalAccountMsg(s.MemphisGlobalAccount(), CACHE_UDATES_SUBJ, msg)
+}
+
+func (s *Server) reloadSchemaEnforcers(tenantName string, streamNames []string) {
+	if len(streamNames) == 0 {
+		// the whole tenant is synced with the db so stations which started enforcing are loaded too
+		s.syncSchemaEnforcers(tenantName)
+		return
+	}
+	for _, streamName := range streamNames {
+		enforcer, err := loadSchemaEnforcer(tenantName, streamName)
+		if err != nil {
+			s.Errorf("[tenant: %v]reloadSchemaEnforcers at loadSchemaEnforcer: station %v: %v", tenantName, streamName, err.Error())
+			stationSchemaEnforcers.reloadFailed(tenantName, streamName)
+			continue
+		}
+		if enforcer == nil {
+			stationSchemaEnforcers.remove(tenantName, streamName)
+			continue
+		}
+		stationSchemaEnforcers.set(tenantName, streamName, enforcer)
+	}
+}
+
+// syncSchemaEnforcers loads the enforcers of every enforced station of the tenant, or of all tenants for an empty
+// tenant name, and drops the enforcers of stations which are no longer enforced
+func (s *Server) syncSchemaEnforcers(tenantName string) {
+	cached := stationSchemaEnforcers.snapshot(tenantName)
+	stations, err := db.GetSchemaEnforcedStations()
+	if err != nil {
+		s.Errorf("[tenant: %v]syncSchemaEnforcers at GetSchemaEnforcedStations: %v", tenantName, err.Error())
+		stationSchemaEnforcers.syncFailed(tenantName)
+		return
+	}
+
+	enforced := make(map[string]map[string]bool)
+	for _, station := range stations {
+		if tenantName != _EMPTY_ && station.TenantName != tenantName {
+			continue
+		}
+		sn, err := StationNameFromStr(station.Name)
+		if err != nil {
+			continue
+		}
+		if _, ok := enforced[station.TenantName]; !ok {
+			enforced[station.TenantName] = make(map[string]bool)
+		}
+		enforced[station.TenantName][sn.Intern()] = true
+		enforcer, err := loadStationSchemaEnforcer(station, sn, station.TenantName)
+		if err != nil {
+			s.Errorf("[tenant: %v]syncSchemaEnforcers at loadStationSchemaEnforcer: station %v: %v", station.TenantName, station.Name, err.Error())
+			stationSchemaEnforcers.reloadFailed(station.TenantName, sn.Intern())
+			continue
+		}
+		if enforcer == nil {
+			stationSchemaEnforcers.remove(station.TenantName, sn.Intern())
+			continue
+		}
+		stationSchemaEnforcers.set(station.TenantName, sn.Intern(), enforcer)
+	}
+
+	for t, enforcers := range cached {
+		for streamName, enforcer := range enforcers {
+			if !enforced[t][streamName] {
+				// enforcers reloaded while the db was queried are kept
+				stationSchemaEnforcers.removeIfUnchanged(t, streamName, enforcer)
+			}
+		}
+	}
+}
+
+// LoadSchemaEnforcers fills the enforcers cache on startup and keeps retrying the syncs and reloads which failed
+func (s *Server) LoadSchemaEnforcers() {
+	stationSchemaEnforcers.requestSync(_EMPTY_)
+	ticker := time.NewTicker(schemaEnforcersRetryInterval)
+	defer ticker.Stop()
+	for {
+		tenants, reloads := stationSchemaEnforcers.takePending()
+		for _, tenantName := range tenants {
+			s.syncSchemaEnforcers(tenantName)
+		}
+		for tenantName, streamNames := range reloads {
+			s.reloadSchemaEnforcers(tenantName, streamNames)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/base64"
+	"memphis/models"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func testProtobufDescriptor(t *testing.T) string {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:   proto.String("test_1.proto"),
+		Syntax: proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Test"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("field1"),
+				JsonName: proto.String("field1"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}},
+		}},
+	}
+	raw, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	if err != nil {
+		t.Fatalf("Failed marshaling the descriptor: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestProtobufSchemaValidator(t *testing.T) {
+	descriptor := testProtobufDescriptor(t)
+	if _, err := newProtobufSchemaValidator(descriptor, "Missing"); err == nil {
+		t.Fatalf("Expected an error for a message struct that is not in the descriptor")
+	}
+
+	validate, err := newSchemaValidator("protobuf", models.ProducerSchemaUpdateVersion{Descriptor: descriptor, MessageStructName: "Test"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// field 1, wire type 2 (length delimited), "hi"
+	if err := validate([]byte{0x0a, 0x02, 'h', 'i'}); err != nil {
+		t.Fatalf("Expected a valid message, got: %v", err)
+	}
+	// field 1 declares 5 bytes while only 2 follow
+	if err := validate([]byte{0x0a, 0x05, 'h', 'i'}); err == nil {
+		t.Fatalf("Expected a truncated message to fail validation")
+	}
+}
+
+func TestJsonSchemaValidator(t *testing.T) {
+	schema := `{"type": "object", "properties": {"id": {"type": "integer"}}, "required": ["id"]}`
+	validate, err := newSchemaValidator("json", models.ProducerSchemaUpdateVersion{Content: schema})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := validate([]byte(`{"id": 1}`)); err != nil {
+		t.Fatalf("Expected a valid message, got: %v", err)
+	}
+	if err := validate([]byte(`{"name": "a"}`)); err == nil {
+		t.Fatalf("Expected a message without the required field to fail validation")
+	}
+	if err := validate([]byte(`not json`)); err == nil {
+		t.Fatalf("Expected a non json message to fail validation")
+	}
+}
+
+func TestSchemaEnforcersCacheInvalidate(t *testing.T) {
+	cache := schemaEnforcersCache{tenants: make(map[string]map[string]*schemaEnforcer)}
+	cache.set("t1", "s1", &schemaEnforcer{})
+	cache.set("t1", "s2", nil)
+	cache.set("t2", "s1", nil)
+
+	cache.invalidate("t1", []string{"s1"})
+	if _, ok := cache.get("t1", "s1"); ok {
+		t.Fatalf("Expected s1 to be invalidated")
+	}
+	if _, ok := cache.get("t1", "s2"); !ok {
+		t.Fatalf("Expected s2 to remain cached")
+	}
+
+	cache.invalidate("t1", nil)
+	if _, ok := cache.get("t1", "s2"); ok {
+		t.Fatalf("Expected all of the tenant's stations to be invalidated")
+	}
+	if _, ok := cache.get("t2", "s1"); !ok {
+		t.Fatalf("Expected other tenants to remain cached")
+	}
+}
+
+func TestSchemaEnforcersCacheEnforcedStations(t *testing.T) {
+	cache := schemaEnforcersCache{tenants: make(map[string]map[string]*schemaEnforcer)}
+	cache.set("t1", "s1", &schemaEnforcer{})
+	cache.set("t1", "s2", nil)
+	if stations := cache.enforcedStations("t1"); len(stations) != 1 || stations[0] != "s1" {
+		t.Fatalf("Expected only enforced stations to be returned, got: %v", stations)
+	}
+	if stations := cache.enforcedStations("t2"); len(stations) != 0 {
+		t.Fatalf("Expected no stations for an unknown tenant, got: %v", stations)
+	}
+}
+
+func TestSchemaEnforcersCacheReloadFailed(t *testing.T) {
+	cache := schemaEnforcersCache{tenants: make(map[string]map[string]*schemaEnforcer)}
+	cache.set("t1", "s1", &schemaEnforcer{schemaName: "orders", validate: func([]byte) error { return nil }})
+
+	cache.reloadFailed("t1", "s1")
+	cache.reloadFailed("t1", "s2")
+	if enforcer, ok := cache.get("t1", "s1"); !ok || enforcer.validate != nil || enforcer.schemaName != "orders" {
+		t.Fatalf("Expected a station known to enforce to keep rejecting messages, got: %+v", enforcer)
+	}
+	if _, ok := cache.get("t1", "s2"); ok {
+		t.Fatalf("Expected a station not known to enforce to stay unenforced")
+	}
+
+	cache.syncFailed("t2")
+	tenants, reloads := cache.takePending()
+	if len(tenants) != 1 || tenants[0] != "t2" {
+		t.Fatalf("Expected a sync of t2 to be pending, got: %v", tenants)
+	}
+	if len(reloads["t1"]) != 2 {
+		t.Fatalf("Expected both stations to be reloaded again, got: %v", reloads)
+	}
+	if tenants, reloads := cache.takePending(); len(tenants) != 0 || len(reloads) != 0 {
+		t.Fatalf("Expected the pending work to be cleared, got: %v %v", tenants, reloads)
+	}
+
+	cache.reloadFailed("t1", "s1")
+	cache.set("t1", "s1", &schemaEnforcer{validate: func([]byte) error { return nil }})
+	if _, reloads := cache.takePending(); len(reloads) != 0 {
+		t.Fatalf("Expected a loaded enforcer not to be reloaded again, got: %v", reloads)
+	}
+}
+
+func TestIsInternalPublisher(t *testing.T) {
+	if isInternalPublisher(&client{kind: CLIENT}) || isInternalPublisher(&client{kind: ROUTER}) || isInternalPublisher(nil) {
+		t.Fatalf("Expected client and route connections not to be trusted as internal publishers")
+	}
+	if !isInternalPublisher(&client{kind: ACCOUNT}) || !isInternalPublisher(&client{kind: SYSTEM}) {
+		t.Fatalf("Expected the broker's internal clients to be trusted")
+	}
+}
@@ -14,6 +14,7 @@ package server
 import (
 	"encoding/json"
 	"memphis/models"
+	"time"
 )
 
 const sdkClientsUpdatesSubject = "$memphis_sdk_clients_updates"
@@ -25,17 +26,21 @@ type memphisResponse interface {
 }
 
 type createStationRequest struct {
-	StationName          string                  `json:"name"`
-	SchemaName           string                  `json:"schema_name"`
-	RetentionType        string                  `json:"retention_type"`
-	RetentionValue       int                     `json:"retention_value"`
-	StorageType          string                  `json:"storage_type"`
-	Replicas             int                     `json:"replicas"`
-	IdempotencyWindow    int64                   `json:"idempotency_window_in_ms"`
-	DlsConfiguration     models.DlsConfiguration `json:"dls_configuration"`
-	Username             string                  `json:"username"`
-	TieredStorageEnabled bool                    `json:"tiered_storage_enabled"`
-	TenantName           string                  `json:"tenant_name"`
+	StationName          string                        `json:"name"`
+	SchemaName           string                        `json:"schema_name"`
+	RetentionType        string                        `json:"retention_type"`
+	RetentionValue       int                           `json:"retention_value"`
+	StorageType          string                        `json:"storage_type"`
+	Replicas             int                           `json:"replicas"`
+	IdempotencyWindow    int64                         `json:"idempotency_window_in_ms"`
+	DlsConfiguration     models.DlsConfiguration       `json:"dls_configuration"`
+	Username             string                        `json:"username"`
+	TieredStorageEnabled bool                          `json:"tiered_storage_enabled"`
+	TenantName           string                        `json:"tenant_name"`
+	Mirror               *models.StationSource         `json:"mirror"`
+	Sources              []models.StationSource        `json:"sources"`
+	Immutability         models.StationImmutability    `json:"immutability"`
+	RetentionPolicy      models.StationRetentionPolicy `json:"retention_policy"`
 }
 
 type destroyStationRequest struct {
@@ -156,6 +161,24 @@ type SchemaResponse struct {
 	Err string `json:"error"`
 }
 
+type rehydrateTieredStorageRequest struct {
+	StationName       string    `json:"station_name"`
+	TargetStationName string    `json:"target_station_name"`
+	FromTime          time.Time `json:"from_time"`
+	ToTime            time.Time `json:"to_time"`
+	ObjectNames       []string  `json:"object_names"`
+	Username          string    `json:"username"`
+	TenantName        string    `json:"tenant_name"`
+}
+
+type rehydrateTieredStorageResponse struct {
+	TargetStationName   string `json:"target_station_name"`
+	ObjectsCount        int    `json:"objects_count"`
+	MessagesCount       int    `json:"messages_count"`
+	FailedMessagesCount int    `json:"failed_messages_count"`
+	Err                 string `json:"error"`
+}
+
 func (cpr *createProducerResponse) SetError(err error) {
 	cpr.Err = err.Error()
 }
@@ -172,6 +195,10 @@ func (csresp *SchemaResponse) SetError(err error) {
 	}
 }
 
+func (rtsresp *rehydrateTieredStorageResponse) SetError(err error) {
+	rtsresp.Err = err.Error()
+}
+
 func (s *Server) initializeSDKHandlers() {
 	//stations
 	s.queueSubscribe(s.MemphisGlobalAccountString(), "$memphis_station_creations",
@@ -208,6 +235,10 @@ func (s *Server) initializeSDKHandlers() {
 		"memphis_schema_creations_listeners_group",
 		createSchemaHandler(s))
 
+	// tiered storage
+	s.queueSubscribe(s.MemphisGlobalAccountString(), "$memphis_tiered_storage_rehydrations",
+		"memphis_tiered_storage_rehydrations_listeners_group",
+		rehydrateTieredStorageHandler(s))
 }
 
 func createSchemaHandler(s *Server) simplifiedMsgHandler {
@@ -264,6 +295,12 @@ func detachSchemaHandler(s *Server) simplifiedMsgHandler {
 	}
 }
 
+func rehydrateTieredStorageHandler(s *Server) simplifiedMsgHandler {
+	return func(c *client, subject, reply string, msg []byte) {
+		go s.rehydrateTieredStorageDirect(c, reply, copyBytes(msg))
+	}
+}
+
 func respondWithErr(tenantName string, s *Server, replySubject string, err error) {
 	resp := []byte("")
 	if err != nil {
@@ -0,0 +1,143 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+)
+
+var ErrStationImmutable = errors.New("station is immutable")
+
+// isStationImmutable returns true once any immutability policy has been set, such stations can never be removed
+func isStationImmutable(im models.StationImmutability) bool {
+	return im.DenyDelete || im.DenyPurge || im.LegalHold || im.Sealed
+}
+
+func stationDeniesDelete(im models.StationImmutability) bool {
+	return im.DenyDelete || im.LegalHold || im.Sealed
+}
+
+func stationDeniesPurge(im models.StationImmutability) bool {
+	return im.DenyPurge || im.LegalHold || im.Sealed
+}
+
+func isStreamImmutable(cfg StreamConfig) bool {
+	return cfg.DenyDelete || cfg.DenyPurge || cfg.Sealed
+}
+
+func validateStationImmutability(im models.StationImmutability) error {
+	if im.Sealed {
+		return errors.New("a station can not be sealed on creation")
+	}
+	return nil
+}
+
+// mergeStationImmutability returns the requested policy as long as it does not relax the current one
+func mergeStationImmutability(current models.StationImmutability, body models.UpdateStationImmutabilitySchema) (models.StationImmutability, error) {
+	if (current.DenyDelete && !body.DenyDelete) || (current.DenyPurge && !body.DenyPurge) || (current.LegalHold && !body.LegalHold) || (current.Sealed && !body.Seal) {
+		return current, errors.New("the immutability policy of a station can not be relaxed")
+	}
+
+	return models.StationImmutability{
+		DenyDelete: body.DenyDelete,
+		DenyPurge:  body.DenyPurge,
+		LegalHold:  body.LegalHold,
+		Sealed:     body.Seal,
+	}, nil
+}
+
+// applyStationImmutability only ever tightens the stream config since jetstream rejects relaxing these flags anyway
+func applyStationImmutability(sc *StreamConfig, im models.StationImmutability) {
+	if im.DenyDelete || im.LegalHold {
+		sc.DenyDelete = true
+	}
+	if im.DenyPurge || im.LegalHold {
+		sc.DenyPurge = true
+	}
+	if im.LegalHold {
+		// held messages must not be removed by the retention policy either
+		sc.MaxAge = 0
+		sc.MaxMsgs = -1
+		sc.MaxBytes = -1
+		sc.MaxMsgsPer = -1
+	}
+	if im.Sealed {
+		sc.Sealed = true
+	}
+}
+
+func (s *Server) updateStationImmutability(tenantName string, body models.UpdateStationImmutabilitySchema) (models.StationImmutability, int, error) {
+	stationName, err := StationNameFromStr(body.StationName)
+	if err != nil {
+		return models.StationImmutability{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	exist, station, err := db.GetStationByName(stationName.Ext(), tenantName)
+	if err != nil {
+		return models.StationImmutability{}, 500, err
+	}
+	if !exist {
+		return models.StationImmutability{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v does not exist", body.StationName)
+	}
+
+	immutability, err := mergeStationImmutability(station.Immutability, body)
+	if err != nil {
+		return station.Immutability, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	if immutability == station.Immutability {
+		return immutability, 200, nil
+	}
+
+	streamInfo, err := s.memphisStreamInfo(station.TenantName, stationName.Intern())
+	if err != nil {
+		return station.Immutability, 500, err
+	}
+	sc := streamInfo.Config
+	applyStationImmutability(&sc, immutability)
+	err = s.memphisUpdateStream(station.TenantName, &sc)
+	if err != nil {
+		return station.Immutability, 500, err
+	}
+
+	err = db.UpdateStationImmutability(station.Name, immutability, station.TenantName)
+	if err != nil {
+		return station.Immutability, 500, err
+	}
+
+	return immutability, 200, nil
+}
+
+// memphisLocalStreamConfig looks the stream config up without going through the jetstream api, so it is safe to call from within api handlers
+func (s *Server) memphisLocalStreamConfig(acc *Account, streamName string) (StreamConfig, bool) {
+	if s.JetStreamIsClustered() {
+		js, cc := s.getJetStreamCluster()
+		if js == nil || cc == nil {
+			return StreamConfig{}, false
+		}
+		js.mu.RLock()
+		defer js.mu.RUnlock()
+		sa := js.streamAssignment(acc.Name, streamName)
+		if sa == nil || sa.Config == nil {
+			return StreamConfig{}, false
+		}
+		return *sa.Config, true
+	}
+
+	mset, err := acc.lookupStream(streamName)
+	if err != nil {
+		return StreamConfig{}, false
+	}
+	return mset.config(), true
+}
@@ -0,0 +1,57 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+	"time"
+)
+
+func TestMergeStationImmutability(t *testing.T) {
+	current := models.StationImmutability{DenyDelete: true}
+
+	if _, err := mergeStationImmutability(current, models.UpdateStationImmutabilitySchema{DenyPurge: true}); err == nil {
+		t.Fatalf("expected an error when deny delete is removed")
+	}
+
+	im, err := mergeStationImmutability(current, models.UpdateStationImmutabilitySchema{DenyDelete: true, LegalHold: true, Seal: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !im.DenyDelete || !im.LegalHold || !im.Sealed || im.DenyPurge {
+		t.Fatalf("unexpected policy: %+v", im)
+	}
+
+	if _, err := mergeStationImmutability(im, models.UpdateStationImmutabilitySchema{DenyDelete: true, LegalHold: true}); err == nil {
+		t.Fatalf("expected an error when a sealed station is unsealed")
+	}
+}
+
+func TestApplyStationImmutability(t *testing.T) {
+	sc := StreamConfig{MaxAge: time.Hour, MaxMsgs: 10, MaxBytes: 1024, MaxMsgsPer: -1}
+	applyStationImmutability(&sc, models.StationImmutability{DenyPurge: true})
+	if sc.DenyDelete || !sc.DenyPurge || sc.MaxAge != time.Hour {
+		t.Fatalf("unexpected config: %+v", sc)
+	}
+
+	applyStationImmutability(&sc, models.StationImmutability{LegalHold: true})
+	if !sc.DenyDelete || !sc.DenyPurge || sc.Sealed {
+		t.Fatalf("legal hold should deny deletes and purges: %+v", sc)
+	}
+	if sc.MaxAge != 0 || sc.MaxMsgs != -1 || sc.MaxBytes != -1 {
+		t.Fatalf("legal hold should suspend retention: %+v", sc)
+	}
+	if !isStreamImmutable(sc) {
+		t.Fatalf("expected the stream to be immutable")
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+	"strings"
+	"time"
+)
+
+const (
+	discardPolicyOld = "old"
+	discardPolicyNew = "new"
+)
+
+func validateRetentionPolicy(policy models.StationRetentionPolicy, idempotencyWindow int64) error {
+	if policy.MaxAgeSeconds < 0 || policy.MaxMessages < 0 || policy.MaxBytes < 0 || policy.MaxMessagesPerSubject < 0 || policy.MaxMessageSize < 0 {
+		return errors.New("retention limits can not be negative")
+	}
+	if policy.DiscardPolicy != _EMPTY_ && policy.DiscardPolicy != discardPolicyOld && policy.DiscardPolicy != discardPolicyNew {
+		return errors.New("discard policy can be one of the following old/new")
+	}
+	if policy.MaxAgeSeconds > 0 {
+		return validateIdempotencyWindow("message_age_sec", policy.MaxAgeSeconds, idempotencyWindow)
+	}
+
+	return nil
+}
+
+// combineLimits returns the stricter of two limits where a non positive value means unlimited
+func combineLimits(a, b int64) int64 {
+	if a <= 0 && b <= 0 {
+		return -1
+	}
+	if a <= 0 {
+		return b
+	}
+	if b <= 0 || a < b {
+		return a
+	}
+	return b
+}
+
+// applyStationRetention sets the stream limits, the retention type/value and the extra policy limits all apply together
+func applyStationRetention(sc *StreamConfig, retentionType string, retentionValue int, policy models.StationRetentionPolicy) {
+	var maxMsgs, maxBytes int64
+	if retentionType == "messages" && retentionValue > 0 {
+		maxMsgs = int64(retentionValue)
+	}
+	if retentionType == "bytes" && retentionValue > 0 {
+		maxBytes = int64(retentionValue)
+	}
+	sc.MaxMsgs = combineLimits(maxMsgs, policy.MaxMessages)
+	sc.MaxBytes = combineLimits(maxBytes, policy.MaxBytes)
+
+	maxAge := combineLimits(int64(GetStationMaxAge(retentionType, retentionValue)), int64(time.Duration(policy.MaxAgeSeconds)*time.Second))
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	sc.MaxAge = time.Duration(maxAge)
+
+	sc.MaxMsgsPer = combineLimits(policy.MaxMessagesPerSubject, 0)
+	sc.MaxMsgSize = int32(combineLimits(int64(policy.MaxMessageSize), 0))
+	if policy.DiscardPolicy == discardPolicyNew {
+		sc.Discard = DiscardNew
+	} else {
+		sc.Discard = DiscardOld
+	}
+}
+
+// mergeRetentionPolicy applies only the supplied limits of an update on top of the current policy
+func mergeRetentionPolicy(policy models.StationRetentionPolicy, update models.UpdateStationRetentionPolicy) models.StationRetentionPolicy {
+	if update.MaxAgeSeconds != nil {
+		policy.MaxAgeSeconds = *update.MaxAgeSeconds
+	}
+	if update.MaxMessages != nil {
+		policy.MaxMessages = *update.MaxMessages
+	}
+	if update.MaxBytes != nil {
+		policy.MaxBytes = *update.MaxBytes
+	}
+	if update.MaxMessagesPerSubject != nil {
+		policy.MaxMessagesPerSubject = *update.MaxMessagesPerSubject
+	}
+	if update.MaxMessageSize != nil {
+		policy.MaxMessageSize = *update.MaxMessageSize
+	}
+	if update.DiscardPolicy != nil {
+		policy.DiscardPolicy = strings.ToLower(*update.DiscardPolicy)
+	}
+	return policy
+}
+
+// getStationMaxBytes returns the effective storage limit of the station, 0 when its storage is not limited
+func getStationMaxBytes(station models.Station) int64 {
+	var maxBytes int64
+	if station.RetentionType == "bytes" && station.RetentionValue > 0 {
+		maxBytes = int64(station.RetentionValue)
+	}
+	maxBytes = combineLimits(maxBytes, station.RetentionPolicy.MaxBytes)
+	if maxBytes < 0 {
+		return 0
+	}
+	return maxBytes
+}
+
+// getRetentionPolicyFromStreamConfig is used for streams created by nats clients, every limit which was set is kept as part of the policy
+func getRetentionPolicyFromStreamConfig(cfg StreamConfig) models.StationRetentionPolicy {
+	policy := models.StationRetentionPolicy{DiscardPolicy: discardPolicyOld}
+	if cfg.MaxAge > 0 {
+		policy.MaxAgeSeconds = int(cfg.MaxAge / time.Second)
+	}
+	if cfg.MaxMsgs > 0 {
+		policy.MaxMessages = cfg.MaxMsgs
+	}
+	if cfg.MaxBytes > 0 {
+		policy.MaxBytes = cfg.MaxBytes
+	}
+	if cfg.MaxMsgsPer > 0 {
+		policy.MaxMessagesPerSubject = cfg.MaxMsgsPer
+	}
+	if cfg.MaxMsgSize > 0 {
+		policy.MaxMessageSize = cfg.MaxMsgSize
+	}
+	if cfg.Discard == DiscardNew {
+		policy.DiscardPolicy = discardPolicyNew
+	}
+	return policy
+}
+
+func (s *Server) updateStation(tenantName string, body models.UpdateStationSchema) (models.Station, int, error) {
+	stationName, err := StationNameFromStr(body.StationName)
+	if err != nil {
+		return models.Station{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	exist, station, err := db.GetStationByName(stationName.Ext(), tenantName)
+	if err != nil {
+		return models.Station{}, 500, err
+	}
+	if !exist {
+		return models.Station{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v does not exist", body.StationName)
+	}
+	if station.Immutability.Sealed {
+		return station, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("Station %v is sealed and can not be updated", body.StationName)
+	}
+
+	retentionType := station.RetentionType
+	retentionValue := station.RetentionValue
+	if body.RetentionType != _EMPTY_ && body.RetentionValue > 0 {
+		retentionType = strings.ToLower(body.RetentionType)
+		err = validateRetentionType(retentionType)
+		if err != nil {
+			return station, SHOWABLE_ERROR_STATUS_CODE, err
+		}
+		retentionValue = body.RetentionValue
+	}
+	err = validateIdempotencyWindow(retentionType, retentionValue, station.IdempotencyWindow)
+	if err != nil {
+		return station, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	retentionPolicy := mergeRetentionPolicy(station.RetentionPolicy, body.RetentionPolicy)
+	err = validateRetentionPolicy(retentionPolicy, station.IdempotencyWindow)
+	if err != nil {
+		return station, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	streamInfo, err := s.memphisStreamInfo(station.TenantName, stationName.Intern())
+	if err != nil {
+		return station, 500, err
+	}
+	sc := streamInfo.Config
+	applyStationRetention(&sc, retentionType, retentionValue, retentionPolicy)
+	// a legal hold keeps suspending the retention limits
+	applyStationImmutability(&sc, station.Immutability)
+	err = s.memphisUpdateStream(station.TenantName, &sc)
+	if err != nil {
+		if IsNatsErr(err, JSStreamInvalidConfigF) {
+			return station, SHOWABLE_ERROR_STATUS_CODE, err
+		}
+		return station, 500, err
+	}
+
+	err = db.UpdateStationRetention(station.Name, retentionType, retentionValue, retentionPolicy, station.TenantName)
+	if err != nil {
+		return station, 500, err
+	}
+
+	station.RetentionType = retentionType
+	station.RetentionValue = retentionValue
+	station.RetentionPolicy = retentionPolicy
+	return station, 200, nil
+}
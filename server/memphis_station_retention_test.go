@@ -0,0 +1,87 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+	"time"
+)
+
+func TestApplyStationRetention(t *testing.T) {
+	sc := StreamConfig{}
+	applyStationRetention(&sc, "message_age_sec", 604800, models.StationRetentionPolicy{MaxBytes: 50 << 30, MaxAgeSeconds: 3600, DiscardPolicy: discardPolicyNew})
+	if sc.MaxAge != time.Hour {
+		t.Fatalf("expected the stricter max age, got %v", sc.MaxAge)
+	}
+	if sc.MaxBytes != 50<<30 || sc.MaxMsgs != -1 || sc.MaxMsgsPer != -1 || sc.MaxMsgSize != -1 {
+		t.Fatalf("unexpected limits: %+v", sc)
+	}
+	if sc.Discard != DiscardNew {
+		t.Fatalf("expected discard new")
+	}
+
+	sc = StreamConfig{}
+	applyStationRetention(&sc, "messages", 100, models.StationRetentionPolicy{MaxMessages: 1000, MaxMessagesPerSubject: 10, MaxMessageSize: 1024})
+	if sc.MaxMsgs != 100 || sc.MaxAge != 0 || sc.MaxMsgsPer != 10 || sc.MaxMsgSize != 1024 || sc.Discard != DiscardOld {
+		t.Fatalf("unexpected limits: %+v", sc)
+	}
+}
+
+func TestValidateRetentionPolicy(t *testing.T) {
+	if err := validateRetentionPolicy(models.StationRetentionPolicy{DiscardPolicy: "oldest"}, 0); err == nil {
+		t.Fatalf("expected an error for an unknown discard policy")
+	}
+	if err := validateRetentionPolicy(models.StationRetentionPolicy{MaxBytes: -1}, 0); err == nil {
+		t.Fatalf("expected an error for a negative limit")
+	}
+	if err := validateRetentionPolicy(models.StationRetentionPolicy{MaxAgeSeconds: 60}, 120000); err == nil {
+		t.Fatalf("expected an error when the idempotency window exceeds the max age")
+	}
+	if err := validateRetentionPolicy(models.StationRetentionPolicy{MaxAgeSeconds: 3600, DiscardPolicy: discardPolicyOld}, 120000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMergeRetentionPolicy(t *testing.T) {
+	current := models.StationRetentionPolicy{MaxAgeSeconds: 3600, MaxBytes: 1 << 30, MaxMessages: 1000, DiscardPolicy: discardPolicyNew}
+	maxMessages := int64(5000)
+	merged := mergeRetentionPolicy(current, models.UpdateStationRetentionPolicy{MaxMessages: &maxMessages})
+	expected := models.StationRetentionPolicy{MaxAgeSeconds: 3600, MaxBytes: 1 << 30, MaxMessages: 5000, DiscardPolicy: discardPolicyNew}
+	if merged != expected {
+		t.Fatalf("Expected only the supplied limit to change, got %+v", merged)
+	}
+
+	unlimited := int64(0)
+	discard := "OLD"
+	merged = mergeRetentionPolicy(current, models.UpdateStationRetentionPolicy{MaxBytes: &unlimited, DiscardPolicy: &discard})
+	if merged.MaxBytes != 0 || merged.DiscardPolicy != discardPolicyOld || merged.MaxAgeSeconds != 3600 {
+		t.Fatalf("Expected supplied zero values to be applied, got %+v", merged)
+	}
+}
+
+func TestGetStationMaxBytes(t *testing.T) {
+	tests := []struct {
+		station  models.Station
+		maxBytes int64
+	}{
+		{models.Station{RetentionType: "message_age_sec", RetentionValue: 3600}, 0},
+		{models.Station{RetentionType: "bytes", RetentionValue: 1000}, 1000},
+		{models.Station{RetentionType: "message_age_sec", RetentionValue: 3600, RetentionPolicy: models.StationRetentionPolicy{MaxBytes: 2000}}, 2000},
+		{models.Station{RetentionType: "bytes", RetentionValue: 1000, RetentionPolicy: models.StationRetentionPolicy{MaxBytes: 500}}, 500},
+	}
+	for i, test := range tests {
+		if got := getStationMaxBytes(test.station); got != test.maxBytes {
+			t.Fatalf("test %v: expected %v, got %v", i, test.maxBytes, got)
+		}
+	}
+}
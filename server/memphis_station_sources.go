@@ -0,0 +1,467 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"memphis/conf"
+	"memphis/db"
+	"memphis/models"
+	"strings"
+	"sync"
+)
+
+const (
+	stationSourcesCacheType = "station_sources"
+)
+
+// checkStationSources validates the mirror/sources a new station replicates from,
+// stations of other tenants can be replicated only by the global account
+func checkStationSources(tenantName string, sn StationName, mirror *models.StationSource, sources []models.StationSource) (int, error) {
+	if mirror != nil && len(sources) > 0 {
+		return SHOWABLE_ERROR_STATUS_CODE, errors.New("a station can either mirror another station or aggregate source stations, not both")
+	}
+
+	seen := make(map[string]bool)
+	for _, src := range getStationSources(mirror, sources) {
+		sourceTenant := getStationSourceTenant(tenantName, src)
+		if sourceTenant != tenantName && tenantName != conf.GlobalAccount {
+			return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("replicating station %v of tenant %v is not allowed", src.StationName, sourceTenant)
+		}
+		sourceName, err := StationNameFromStr(src.StationName)
+		if err != nil {
+			return SHOWABLE_ERROR_STATUS_CODE, err
+		}
+		if sourceTenant == tenantName && sourceName.Ext() == sn.Ext() {
+			return SHOWABLE_ERROR_STATUS_CODE, errors.New("a station can not replicate itself")
+		}
+		key := sourceTenant + "/" + sourceName.Ext()
+		if seen[key] {
+			return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("station %v appears more than once", sourceName.Ext())
+		}
+		seen[key] = true
+
+		exist, _, err := db.GetStationByName(sourceName.Ext(), sourceTenant)
+		if err != nil {
+			return 500, err
+		}
+		if !exist {
+			return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("source station %v does not exist", sourceName.Ext())
+		}
+	}
+	return 200, nil
+}
+
+func getStationSources(mirror *models.StationSource, sources []models.StationSource) []models.StationSource {
+	if mirror != nil {
+		return []models.StationSource{*mirror}
+	}
+	return sources
+}
+
+func getStationSourceTenant(tenantName string, src models.StationSource) string {
+	if src.TenantName == _EMPTY_ {
+		return tenantName
+	}
+	if src.TenantName == conf.GlobalAccount {
+		return src.TenantName
+	}
+	return strings.ToLower(src.TenantName)
+}
+
+func getStationSourceApiPrefix(sourceTenant string) string {
+	return fmt.Sprintf("$memphis_sources.%v.API", sourceTenant)
+}
+
+func getStationSourceDeliverPrefix(sourceTenant, tenantName string) string {
+	return fmt.Sprintf("$memphis_sources.%v.deliver.%v", sourceTenant, tenantName)
+}
+
+func getStreamSource(tenantName string, src models.StationSource) (*StreamSource, error) {
+	sourceName, err := StationNameFromStr(src.StationName)
+	if err != nil {
+		return nil, err
+	}
+	ss := &StreamSource{Name: sourceName.Intern()}
+	if sourceTenant := getStationSourceTenant(tenantName, src); sourceTenant != tenantName {
+		ss.External = &ExternalStream{
+			ApiPrefix:     getStationSourceApiPrefix(sourceTenant),
+			DeliverPrefix: getStationSourceDeliverPrefix(sourceTenant, tenantName),
+		}
+	}
+	return ss, nil
+}
+
+func getStationConsumerFilterSubject(station models.Station, sn StationName) string {
+	// replicated messages keep the subjects of their origin station
+	if station.Mirror != nil || len(station.Sources) > 0 {
+		return _EMPTY_
+	}
+	return sn.Intern() + ".final"
+}
+
+type stationSourceGrant struct {
+	sourceTenant string
+	tenantName   string
+	streamName   string
+}
+
+type stationSourceGrantsCache struct {
+	sync.Mutex
+	// grant -> internal names of the replicating stations which use it
+	grants map[stationSourceGrant]map[string]bool
+}
+
+var stationSourcesGrants = stationSourceGrantsCache{grants: make(map[stationSourceGrant]map[string]bool)}
+
+func getStationSourceFlowControlSubject(streamName string) string {
+	return jsFlowControlPre + streamName + ".>"
+}
+
+// hasTenantsGrant reports whether the replicating tenant holds any grant of the source tenant
+// Lock should be held
+func (sgc *stationSourceGrantsCache) hasTenantsGrant(sourceTenant, tenantName string) bool {
+	for grant := range sgc.grants {
+		if grant.sourceTenant == sourceTenant && grant.tenantName == tenantName {
+			return true
+		}
+	}
+	return false
+}
+
+// grant exports the jetstream api, the flow control and the delivery subjects of a station into the tenant which replicates it
+func (sgc *stationSourceGrantsCache) grant(sourceAcc, acc *Account, grant stationSourceGrant, stationName string) error {
+	sgc.Lock()
+	defer sgc.Unlock()
+	if stations, ok := sgc.grants[grant]; ok {
+		stations[stationName] = true
+		return nil
+	}
+
+	apiSubject := fmt.Sprintf(JSApiConsumerCreateT, grant.streamName)
+	if err := sourceAcc.AddServiceExport(apiSubject, []*Account{acc}); err != nil {
+		return err
+	}
+	if err := acc.AddServiceImport(sourceAcc, strings.Replace(apiSubject, JSApiPrefix, getStationSourceApiPrefix(grant.sourceTenant), 1), apiSubject); err != nil {
+		return err
+	}
+
+	// flow control replies of the source consumer are answered from the replicating tenant
+	fcSubject := getStationSourceFlowControlSubject(grant.streamName)
+	if err := sourceAcc.AddServiceExport(fcSubject, []*Account{acc}); err != nil {
+		return err
+	}
+	if err := acc.AddServiceImport(sourceAcc, fcSubject, fcSubject); err != nil {
+		return err
+	}
+
+	// the delivery subjects are shared by all the streams the tenant replicates from the source tenant
+	if !sgc.hasTenantsGrant(grant.sourceTenant, grant.tenantName) {
+		deliverSubject := getStationSourceDeliverPrefix(grant.sourceTenant, grant.tenantName) + ".>"
+		if err := sourceAcc.AddStreamExport(deliverSubject, []*Account{acc}); err != nil {
+			return err
+		}
+		if err := acc.AddStreamImport(sourceAcc, deliverSubject, _EMPTY_); err != nil && err != ErrStreamImportDuplicate {
+			return err
+		}
+	}
+
+	sgc.grants[grant] = map[string]bool{stationName: true}
+	return nil
+}
+
+// revoke drops the station from the grant, the grant is removed from the accounts once no station uses it,
+// an empty station name drops all the stations since the source station itself is gone
+func (sgc *stationSourceGrantsCache) revoke(sourceAcc, acc *Account, grant stationSourceGrant, stationName string) {
+	sgc.Lock()
+	defer sgc.Unlock()
+	stations, ok := sgc.grants[grant]
+	if !ok {
+		return
+	}
+	if stationName != _EMPTY_ {
+		delete(stations, stationName)
+		if len(stations) > 0 {
+			return
+		}
+	}
+	delete(sgc.grants, grant)
+
+	apiSubject := fmt.Sprintf(JSApiConsumerCreateT, grant.streamName)
+	acc.removeServiceImport(strings.Replace(apiSubject, JSApiPrefix, getStationSourceApiPrefix(grant.sourceTenant), 1))
+	sourceAcc.revokeServiceExport(apiSubject, acc)
+
+	fcSubject := getStationSourceFlowControlSubject(grant.streamName)
+	acc.removeServiceImport(fcSubject)
+	sourceAcc.revokeServiceExport(fcSubject, acc)
+
+	if !sgc.hasTenantsGrant(grant.sourceTenant, grant.tenantName) {
+		deliverSubject := getStationSourceDeliverPrefix(grant.sourceTenant, grant.tenantName) + ".>"
+		acc.removeStreamImport(sourceAcc, deliverSubject)
+		sourceAcc.revokeStreamExport(deliverSubject, acc)
+	}
+}
+
+// grantsOfStream returns the grants of all the tenants which replicate the stream
+func (sgc *stationSourceGrantsCache) grantsOfStream(sourceTenant, streamName string) []stationSourceGrant {
+	sgc.Lock()
+	defer sgc.Unlock()
+	var grants []stationSourceGrant
+	for grant := range sgc.grants {
+		if grant.sourceTenant == sourceTenant && grant.streamName == streamName {
+			grants = append(grants, grant)
+		}
+	}
+	return grants
+}
+
+func (s *Server) lookupStationSourceAccounts(grant stationSourceGrant) (*Account, *Account, error) {
+	sourceAcc, err := s.lookupAccount(grant.sourceTenant)
+	if err != nil {
+		return nil, nil, err
+	}
+	acc, err := s.lookupAccount(grant.tenantName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sourceAcc, acc, nil
+}
+
+// grantStationSourceAccess grants a replicating station access to its source station on this broker,
+// grants live in memory only so they are broadcasted to all the brokers and restored on startup
+func (s *Server) grantStationSourceAccess(grant stationSourceGrant, stationName string) error {
+	sourceAcc, acc, err := s.lookupStationSourceAccounts(grant)
+	if err != nil {
+		return err
+	}
+	return stationSourcesGrants.grant(sourceAcc, acc, grant, stationName)
+}
+
+func (s *Server) revokeStationSourceAccess(grant stationSourceGrant, stationName string) error {
+	sourceAcc, acc, err := s.lookupStationSourceAccounts(grant)
+	if err != nil {
+		return err
+	}
+	stationSourcesGrants.revoke(sourceAcc, acc, grant, stationName)
+	return nil
+}
+
+// revokeServiceExport removes the account approval from the service export, the export is dropped once no account is approved
+func (a *Account) revokeServiceExport(subject string, importer *Account) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	se, ok := a.exports.services[subject]
+	if !ok || se == nil {
+		return
+	}
+	delete(se.approved, importer.Name)
+	if len(se.approved) == 0 {
+		delete(a.exports.services, subject)
+	}
+}
+
+// revokeStreamExport removes the account approval from the stream export, the export is dropped once no account is approved
+func (a *Account) revokeStreamExport(subject string, importer *Account) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	se, ok := a.exports.streams[subject]
+	if !ok || se == nil {
+		return
+	}
+	delete(se.approved, importer.Name)
+	if len(se.approved) == 0 {
+		delete(a.exports.streams, subject)
+	}
+}
+
+func (a *Account) removeStreamImport(account *Account, from string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i, si := range a.imports.streams {
+		if si.acc == account && si.from == from {
+			a.imports.streams = append(a.imports.streams[:i], a.imports.streams[i+1:]...)
+			return
+		}
+	}
+}
+
+// getStationSourcesGrants groups the streams a station replicates from other tenants by their tenant
+func getStationSourcesGrants(tenantName string, mirror *models.StationSource, sources []models.StationSource) (map[string][]string, error) {
+	grants := make(map[string][]string)
+	for _, src := range getStationSources(mirror, sources) {
+		sourceTenant := getStationSourceTenant(tenantName, src)
+		if sourceTenant == tenantName {
+			continue
+		}
+		sourceName, err := StationNameFromStr(src.StationName)
+		if err != nil {
+			return nil, err
+		}
+		grants[sourceTenant] = append(grants[sourceTenant], sourceName.Intern())
+	}
+	return grants, nil
+}
+
+// applyStationSourcesGrants grants the access on this broker before the stream is created and broadcasts it to the other brokers
+func (s *Server) applyStationSourcesGrants(tenantName string, sn StationName, mirror *models.StationSource, sources []models.StationSource) error {
+	grants, err := getStationSourcesGrants(tenantName, mirror, sources)
+	if err != nil {
+		return err
+	}
+	for sourceTenant, streams := range grants {
+		for _, streamName := range streams {
+			err = s.grantStationSourceAccess(stationSourceGrant{sourceTenant: sourceTenant, tenantName: tenantName, streamName: streamName}, sn.Intern())
+			if err != nil {
+				return err
+			}
+		}
+		err = s.sendStationSourcesCacheUpdate("grant", sourceTenant, streams, tenantName, sn.Intern())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackStationSourcesGrants revokes the grants the station uses to replicate other tenants on all the brokers
+func (s *Server) rollbackStationSourcesGrants(tenantName string, sn StationName, mirror *models.StationSource, sources []models.StationSource) error {
+	grants, err := getStationSourcesGrants(tenantName, mirror, sources)
+	if err != nil {
+		return err
+	}
+	for sourceTenant, streams := range grants {
+		for _, streamName := range streams {
+			err = s.revokeStationSourceAccess(stationSourceGrant{sourceTenant: sourceTenant, tenantName: tenantName, streamName: streamName}, sn.Intern())
+			if err != nil {
+				return err
+			}
+		}
+		err = s.sendStationSourcesCacheUpdate("revoke", sourceTenant, streams, tenantName, sn.Intern())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackStationCreation undoes a station creation which failed after its grants were applied,
+// the station record is deleted as well when it was already stored
+func (s *Server) rollbackStationCreation(tenantName string, sn StationName, mirror *models.StationSource, sources []models.StationSource, deleteRecord bool) {
+	err := s.rollbackStationSourcesGrants(tenantName, sn, mirror, sources)
+	if err != nil {
+		s.Errorf("[tenant: %v]rollbackStationCreation at rollbackStationSourcesGrants: Station %v: %v", tenantName, sn.Ext(), err.Error())
+	}
+	if !deleteRecord {
+		return
+	}
+	err = db.DeleteStation(sn.Ext(), tenantName)
+	if err != nil {
+		s.Errorf("[tenant: %v]rollbackStationCreation at DeleteStation: Station %v: %v", tenantName, sn.Ext(), err.Error())
+	}
+}
+
+// revokeStationSourcesGrants revokes the grants a removed station used to replicate other tenants and the grants of other tenants which replicated it
+func (s *Server) revokeStationSourcesGrants(station models.Station) error {
+	sn, err := StationNameFromStr(station.Name)
+	if err != nil {
+		return err
+	}
+	err = s.rollbackStationSourcesGrants(station.TenantName, sn, station.Mirror, station.Sources)
+	if err != nil {
+		return err
+	}
+
+	s.revokeStationSourceGrantsOfStream(station.TenantName, sn.Intern())
+	return s.sendStationSourcesCacheUpdate("revoke", station.TenantName, []string{sn.Intern()}, _EMPTY_, _EMPTY_)
+}
+
+// revokeStationSourceGrantsOfStream revokes the grants of all the tenants which replicate a removed source station
+func (s *Server) revokeStationSourceGrantsOfStream(sourceTenant, streamName string) {
+	for _, grant := range stationSourcesGrants.grantsOfStream(sourceTenant, streamName) {
+		err := s.revokeStationSourceAccess(grant, _EMPTY_)
+		if err != nil {
+			s.Errorf("[tenant: %v]revokeStationSourceGrantsOfStream at revokeStationSourceAccess: station %v of tenant %v: %v", grant.tenantName, streamName, sourceTenant, err.Error())
+		}
+	}
+}
+
+func (s *Server) sendStationSourcesCacheUpdate(operation, sourceTenant string, streams []string, tenantName, stationName string) error {
+	msg, err := json.Marshal(models.CacheUpdateRequest{
+		CacheType:    stationSourcesCacheType,
+		Operation:    operation,
+		TenantName:   sourceTenant,
+		Stations:     streams,
+		TargetTenant: tenantName,
+		StationName:  stationName,
+	})
+	if err != nil {
+		return err
+	}
+	return s.sendInternalAccountMsgWithReply(s.MemphisGlobalAccount(), CACHE_UDATES_SUBJ, _EMPTY_, nil, msg, true)
+}
+
+func (s *Server) handleStationSourcesGrant(req models.CacheUpdateRequest) {
+	for _, streamName := range req.Stations {
+		err := s.grantStationSourceAccess(stationSourceGrant{sourceTenant: req.TenantName, tenantName: req.TargetTenant, streamName: streamName}, req.StationName)
+		if err != nil {
+			s.Errorf("[tenant: %v]handleStationSourcesGrant at grantStationSourceAccess: station %v of tenant %v: %v", req.TargetTenant, streamName, req.TenantName, err.Error())
+		}
+	}
+}
+
+func (s *Server) handleStationSourcesRevoke(req models.CacheUpdateRequest) {
+	for _, streamName := range req.Stations {
+		// without a target tenant the source station itself was removed
+		if req.TargetTenant == _EMPTY_ {
+			s.revokeStationSourceGrantsOfStream(req.TenantName, streamName)
+			continue
+		}
+		err := s.revokeStationSourceAccess(stationSourceGrant{sourceTenant: req.TenantName, tenantName: req.TargetTenant, streamName: streamName}, req.StationName)
+		if err != nil {
+			s.Errorf("[tenant: %v]handleStationSourcesRevoke at revokeStationSourceAccess: station %v of tenant %v: %v", req.TargetTenant, streamName, req.TenantName, err.Error())
+		}
+	}
+}
+
+// RestoreStationSourcesGrants re-applies the cross tenant grants of replicated stations since they are not part of the accounts configuration
+func (s *Server) RestoreStationSourcesGrants() error {
+	stations, err := db.GetAllStations()
+	if err != nil {
+		return err
+	}
+	for _, station := range stations {
+		if station.IsDeleted || (station.Mirror == nil && len(station.Sources) == 0) {
+			continue
+		}
+		sn, err := StationNameFromStr(station.Name)
+		if err != nil {
+			return err
+		}
+		for _, src := range getStationSources(station.Mirror, station.Sources) {
+			sourceTenant := getStationSourceTenant(station.TenantName, src)
+			if sourceTenant == station.TenantName {
+				continue
+			}
+			sourceName, err := StationNameFromStr(src.StationName)
+			if err != nil {
+				return err
+			}
+			err = s.grantStationSourceAccess(stationSourceGrant{sourceTenant: sourceTenant, tenantName: station.TenantName, streamName: sourceName.Intern()}, sn.Intern())
+			if err != nil {
+				s.Errorf("[tenant: %v]RestoreStationSourcesGrants at grantStationSourceAccess: station %v: %v", station.TenantName, station.Name, err.Error())
+			}
+		}
+	}
+	return nil
+}
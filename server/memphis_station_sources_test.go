@@ -0,0 +1,107 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"memphis/models"
+	"testing"
+)
+
+func TestGetStreamSource(t *testing.T) {
+	ss, err := getStreamSource("tenant-a", models.StationSource{StationName: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss.Name != "orders" || ss.External != nil {
+		t.Fatalf("unexpected local source: %+v", ss)
+	}
+
+	ss, err = getStreamSource("tenant-a", models.StationSource{StationName: "orders", TenantName: "Tenant-B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ss.External == nil {
+		t.Fatal("expected an external source for a station of another tenant")
+	}
+	if ss.External.ApiPrefix != "$memphis_sources.tenant-b.API" || ss.External.DeliverPrefix != "$memphis_sources.tenant-b.deliver.tenant-a" {
+		t.Fatalf("unexpected external source: %+v", ss.External)
+	}
+}
+
+func TestGetStationConsumerFilterSubject(t *testing.T) {
+	sn, err := StationNameFromStr("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getStationConsumerFilterSubject(models.Station{Name: "orders"}, sn); got != "orders.final" {
+		t.Fatalf("unexpected filter subject: %v", got)
+	}
+	mirror := models.Station{Name: "orders", Mirror: &models.StationSource{StationName: "raw"}}
+	if got := getStationConsumerFilterSubject(mirror, sn); got != _EMPTY_ {
+		t.Fatalf("expected no filter subject for a mirror, got %v", got)
+	}
+	aggregate := models.Station{Name: "orders", Sources: []models.StationSource{{StationName: "eu"}, {StationName: "us"}}}
+	if got := getStationConsumerFilterSubject(aggregate, sn); got != _EMPTY_ {
+		t.Fatalf("expected no filter subject for an aggregate station, got %v", got)
+	}
+}
+
+func TestStationSourceGrantsRevoke(t *testing.T) {
+	srcAcc, acc := NewAccount("tenant-b"), NewAccount("tenant-a")
+	cache := stationSourceGrantsCache{grants: make(map[stationSourceGrant]map[string]bool)}
+	grant := stationSourceGrant{sourceTenant: srcAcc.Name, tenantName: acc.Name, streamName: "orders"}
+	fcSubject := getStationSourceFlowControlSubject("orders")
+	if fcSubject != "$JS.FC.orders.>" {
+		t.Fatalf("unexpected flow control subject: %v", fcSubject)
+	}
+
+	for _, station := range []string{"eu", "us"} {
+		if err := cache.grant(srcAcc, acc, grant, station); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := acc.imports.services[fcSubject]; !ok {
+		t.Fatal("expected the flow control subject of the stream to be imported")
+	}
+	if _, ok := srcAcc.exports.services["$JS.FC.>"]; ok {
+		t.Fatal("expected the flow control export to be scoped to the stream")
+	}
+	if len(acc.imports.streams) != 1 {
+		t.Fatalf("expected a single delivery import, got %v", len(acc.imports.streams))
+	}
+
+	cache.revoke(srcAcc, acc, grant, "eu")
+	if _, ok := acc.imports.services[fcSubject]; !ok {
+		t.Fatal("expected the grant to be kept while another station uses it")
+	}
+
+	cache.revoke(srcAcc, acc, grant, "us")
+	if len(acc.imports.services) != 0 || len(acc.imports.streams) != 0 {
+		t.Fatalf("expected all the imports to be revoked, got %v services and %v streams", len(acc.imports.services), len(acc.imports.streams))
+	}
+	if len(srcAcc.exports.services) != 0 || len(srcAcc.exports.streams) != 0 {
+		t.Fatalf("expected all the exports to be revoked, got %v services and %v streams", len(srcAcc.exports.services), len(srcAcc.exports.streams))
+	}
+
+	// a removed source station revokes the grant of every station which replicates it
+	if err := cache.grant(srcAcc, acc, grant, "eu"); err != nil {
+		t.Fatal(err)
+	}
+	grants := cache.grantsOfStream(srcAcc.Name, "orders")
+	if len(grants) != 1 || grants[0] != grant {
+		t.Fatalf("unexpected grants of the stream: %+v", grants)
+	}
+	cache.revoke(srcAcc, acc, grant, _EMPTY_)
+	if len(acc.imports.services) != 0 || len(cache.grants) != 0 {
+		t.Fatal("expected the grant to be revoked once the source station is removed")
+	}
+}
@@ -1034,7 +1034,7 @@ func (ms *memStore) removeMsg(seq uint64, secure bool) bool {
 	// send the message to tiered 2 storage if needed
 	tieredStorageEnabled := ms.cfg.TieredStorageEnabled
 	if !secure && !strings.HasPrefix(ms.cfg.Name, "$memphis") && tieredStorageEnabled && serv != nil {
-		serv.sendToTier2Storage(ms, copyBytes(sm.buf), sm.seq, "s3")
+		serv.sendToTier2Storage(ms, copyBytes(sm.buf), sm.seq, sm.ts)
 	}
 
 	ss = memStoreMsgSize(sm.subj, sm.hdr, sm.msg)
@@ -17,32 +17,54 @@ import (
 )
 
 func SendNotification(tenantName string, title string, message string, msgType string) error {
+	return sendNotificationToChannels(tenantName, title, message, msgType, false)
+}
+
+// sendNotificationToChannels with ignoreProperties set notifies every connected channel regardless of its alert toggles
+func sendNotificationToChannels(tenantName string, title string, message string, msgType string, ignoreProperties bool) error {
+	tenantInetgrations, ok := IntegrationsConcurrentCache.Load(tenantName)
+	if !ok {
+		return nil
+	}
+	var firstErr error
 	for k, f := range NotificationFunctionsMap {
+		var err error
 		switch k {
 		case "slack":
-			if tenantInetgrations, ok := IntegrationsConcurrentCache.Load(tenantName); ok {
-				if slackIntegration, ok := tenantInetgrations["slack"].(models.SlackIntegration); ok {
-					if slackIntegration.Properties[msgType] {
-						err := f.(func(models.SlackIntegration, string, string) error)(slackIntegration, title, message)
-						if err != nil {
-							return err
-						}
-					}
+			if slackIntegration, ok := tenantInetgrations["slack"].(models.SlackIntegration); ok {
+				if ignoreProperties || slackIntegration.Properties[msgType] {
+					err = f.(func(models.SlackIntegration, string, string) error)(slackIntegration, title, message)
+				}
+			}
+		case "webhook", "email":
+			if integration, ok := tenantInetgrations[k].(models.Integration); ok {
+				if ignoreProperties || integration.Properties[msgType] {
+					err = f.(func(models.Integration, string, string, string) error)(integration, title, message, msgType)
 				}
 			}
 		default:
-			return errors.New("failed sending notification: unsupported integration")
+			err = errors.New("failed sending notification: unsupported integration")
+		}
+		// a failing channel should not prevent the other channels from being notified
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
-	return nil
-
+	return firstErr
 }
 
 func shouldSendNotification(tenantName string, alertType string) bool {
 	if tenantInetgrations, ok := IntegrationsConcurrentCache.Load(tenantName); ok {
-		if slackIntegration, ok := tenantInetgrations["slack"].(models.SlackIntegration); ok {
-			if slackIntegration.Properties[alertType] {
-				return true
+		for k := range NotificationFunctionsMap {
+			switch integration := tenantInetgrations[k].(type) {
+			case models.SlackIntegration:
+				if integration.Properties[alertType] {
+					return true
+				}
+			case models.Integration:
+				if integration.Properties[alertType] {
+					return true
+				}
 			}
 		}
 	}
@@ -0,0 +1,447 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"memphis/db"
+	"memphis/models"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	emailDefaultPort            = "587"
+	emailDialTimeout            = 10 * time.Second
+	emailDefaultSubjectTemplate = "[Memphis] {{.Title}}"
+	emailDefaultPlainTemplate   = "{{.Title}}\n\n{{.Message}}\n\nTenant: {{.TenantName}}\nTime: {{.Timestamp}}\n"
+	emailDefaultHtmlTemplate    = `<html><body style="font-family: sans-serif;"><h2 style="color: #6557FF;">{{.Title}}</h2><p style="white-space: pre-wrap;">{{.Message}}</p><p style="color: #888888;">Tenant: {{.TenantName}}<br/>Time: {{.Timestamp}}</p></body></html>`
+)
+
+type emailTemplateData struct {
+	Type       string
+	Title      string
+	Message    string
+	TenantName string
+	Timestamp  string
+}
+
+func cacheDetailsEmail(keys map[string]string, properties map[string]bool, tenantName string) {
+	emailIntegration := models.Integration{}
+	emailIntegration.Keys = make(map[string]string)
+	emailIntegration.Properties = make(map[string]bool)
+	if keys == nil {
+		deleteIntegrationFromTenant(tenantName, "email", IntegrationsConcurrentCache)
+		return
+	}
+
+	for _, k := range []string{"host", "port", "username", "password", "from", "recipients", "subject_template", "plain_template", "html_template"} {
+		emailIntegration.Keys[k] = keys[k]
+	}
+	emailIntegration.Properties[PoisonMAlert] = properties[PoisonMAlert]
+	emailIntegration.Properties[SchemaVAlert] = properties[SchemaVAlert]
+	emailIntegration.Properties[DisconEAlert] = properties[DisconEAlert]
+	emailIntegration.Name = "email"
+	emailIntegration.TenantName = tenantName
+	if _, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
+		IntegrationsConcurrentCache.Add(tenantName, map[string]interface{}{"email": emailIntegration})
+	} else {
+		err := addIntegrationToTenant(tenantName, "email", IntegrationsConcurrentCache, emailIntegration)
+		if err != nil {
+			serv.Errorf("cacheDetailsEmail: %s ", err.Error())
+			return
+		}
+	}
+}
+
+func parseEmailRecipients(recipients string) ([]string, error) {
+	var addresses []string
+	for _, r := range strings.Split(recipients, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient address %v", r)
+		}
+		addresses = append(addresses, address.Address)
+	}
+	if len(addresses) == 0 {
+		return nil, errors.New("must provide at least one recipient for email integration")
+	}
+	return addresses, nil
+}
+
+func (it IntegrationsHandler) getEmailIntegrationDetails(body models.CreateIntegrationSchema) (map[string]string, map[string]bool, int, error) {
+	host := strings.TrimSpace(body.Keys["host"])
+	if host == "" {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("must provide SMTP host for email integration")
+	}
+	port := strings.TrimSpace(body.Keys["port"])
+	if port == "" {
+		port = emailDefaultPort
+	}
+	portNum, err := strconv.Atoi(port)
+	if err != nil || portNum <= 0 || portNum > 65535 {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("SMTP port has to be a valid port number")
+	}
+	from, err := mail.ParseAddress(body.Keys["from"])
+	if err != nil {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("must provide a valid sender address for email integration")
+	}
+	recipients, err := parseEmailRecipients(body.Keys["recipients"])
+	if err != nil {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+
+	if _, err := template.New("subject").Parse(body.Keys["subject_template"]); err != nil {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("invalid subject template: %v", err.Error())
+	}
+	if _, err := template.New("plain").Parse(body.Keys["plain_template"]); err != nil {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("invalid plain template: %v", err.Error())
+	}
+	if _, err := htmltemplate.New("html").Parse(body.Keys["html_template"]); err != nil {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("invalid html template: %v", err.Error())
+	}
+
+	extraKeys := map[string]string{
+		"host":             host,
+		"port":             port,
+		"username":         body.Keys["username"],
+		"password":         body.Keys["password"],
+		"from":             from.String(),
+		"recipients":       strings.Join(recipients, ","),
+		"subject_template": body.Keys["subject_template"],
+		"plain_template":   body.Keys["plain_template"],
+		"html_template":    body.Keys["html_template"],
+	}
+	keys, properties := createIntegrationsKeysAndProperties("email", integrationKeysOpts{
+		pmAlert:         body.Properties[PoisonMAlert],
+		svfAlert:        body.Properties[SchemaVAlert],
+		disconnectAlert: body.Properties[DisconEAlert],
+		extraKeys:       extraKeys,
+	})
+	return keys, properties, 0, nil
+}
+
+func (it IntegrationsHandler) handleCreateEmailIntegration(tenantName string, body models.CreateIntegrationSchema) (models.Integration, int, error) {
+	keys, properties, errorCode, err := it.getEmailIntegrationDetails(body)
+	if err != nil {
+		return models.Integration{}, errorCode, err
+	}
+	if it.S.opts.UiHost == "" && body.UIUrl != "" {
+		EditClusterCompHost("ui_host", body.UIUrl)
+	}
+	emailIntegration, err := createEmailIntegration(tenantName, keys, properties, body.UIUrl)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "email test") {
+			return models.Integration{}, SHOWABLE_ERROR_STATUS_CODE, err
+		} else {
+			return models.Integration{}, 500, err
+		}
+	}
+	return emailIntegration, 0, nil
+}
+
+func (it IntegrationsHandler) handleUpdateEmailIntegration(tenantName string, body models.CreateIntegrationSchema) (models.Integration, int, error) {
+	keys, properties, errorCode, err := it.getEmailIntegrationDetails(body)
+	if err != nil {
+		return models.Integration{}, errorCode, err
+	}
+	emailIntegration, err := updateEmailIntegration(tenantName, keys, properties, body.UIUrl)
+	if err != nil {
+		if strings.Contains(err.Error(), "email test") {
+			return models.Integration{}, SHOWABLE_ERROR_STATUS_CODE, err
+		} else {
+			return models.Integration{}, 500, err
+		}
+	}
+	return emailIntegration, 0, nil
+}
+
+func createEmailIntegration(tenantName string, keys map[string]string, properties map[string]bool, uiUrl string) (models.Integration, error) {
+	exist, _, err := db.GetIntegration("email", tenantName)
+	if err != nil {
+		return models.Integration{}, err
+	} else if exist {
+		return models.Integration{}, errors.New("email integration already exists")
+	}
+
+	err = testEmailIntegration(tenantName, keys, "Email integration with Memphis was added successfully")
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys := copyMaps(keys)
+	encryptedValue, err := EncryptAES([]byte(keys["password"]))
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys["password"] = encryptedValue
+	emailIntegration, err := db.InsertNewIntegration(tenantName, "email", cloneKeys, properties)
+	if err != nil {
+		return models.Integration{}, err
+	}
+
+	err = publishEmailIntegrationUpdate(tenantName, keys, properties, uiUrl)
+	if err != nil {
+		return models.Integration{}, err
+	}
+	emailIntegration.Keys["password"] = hideEmailPassword(keys["password"])
+	return emailIntegration, nil
+}
+
+func updateEmailIntegration(tenantName string, keys map[string]string, properties map[string]bool, uiUrl string) (models.Integration, error) {
+	if keys["password"] == "" {
+		exist, integrationFromDb, err := db.GetIntegration("email", tenantName)
+		if err != nil {
+			return models.Integration{}, err
+		}
+		if exist && integrationFromDb.Keys["password"] != "" {
+			password, err := DecryptAES(getAESKey(), integrationFromDb.Keys["password"])
+			if err != nil {
+				return models.Integration{}, err
+			}
+			keys["password"] = password
+		}
+	}
+
+	err := testEmailIntegration(tenantName, keys, "Email integration with Memphis was updated successfully")
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys := copyMaps(keys)
+	encryptedValue, err := EncryptAES([]byte(keys["password"]))
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys["password"] = encryptedValue
+	emailIntegration, err := db.UpdateIntegration(tenantName, "email", cloneKeys, properties)
+	if err != nil {
+		return models.Integration{}, err
+	}
+
+	err = publishEmailIntegrationUpdate(tenantName, keys, properties, uiUrl)
+	if err != nil {
+		return models.Integration{}, err
+	}
+	keys["password"] = hideEmailPassword(keys["password"])
+	emailIntegration.Keys = keys
+	emailIntegration.Properties = properties
+	return emailIntegration, nil
+}
+
+func publishEmailIntegrationUpdate(tenantName string, keys map[string]string, properties map[string]bool, uiUrl string) error {
+	integrationToUpdate := models.CreateIntegration{
+		Name:       "email",
+		Keys:       keys,
+		Properties: properties,
+		UIUrl:      uiUrl,
+		TenantName: tenantName,
+	}
+	msg, err := json.Marshal(integrationToUpdate)
+	if err != nil {
+		return err
+	}
+	err = serv.sendInternalAccountMsgWithReply(serv.MemphisGlobalAccount(), INTEGRATIONS_UPDATES_SUBJ, _EMPTY_, nil, msg, true)
+	if err != nil {
+		return err
+	}
+	if properties[SchemaVAlert] {
+		update := models.SdkClientsUpdates{
+			Type:   sendNotificationType,
+			Update: true,
+		}
+		serv.SendUpdateToClients(update)
+	}
+	return nil
+}
+
+func testEmailIntegration(tenantName string, keys map[string]string, message string) error {
+	integration := models.Integration{Name: "email", Keys: keys, TenantName: tenantName}
+	err := sendMessageToEmail(integration, "Memphis email integration", message, "test")
+	if err != nil {
+		return fmt.Errorf("email test failed: %v", err.Error())
+	}
+	return nil
+}
+
+func renderEmailTemplates(keys map[string]string, data emailTemplateData) (string, string, string, error) {
+	subjectTemplate, plainTemplate, htmlTemplate := keys["subject_template"], keys["plain_template"], keys["html_template"]
+	if subjectTemplate == "" {
+		subjectTemplate = emailDefaultSubjectTemplate
+	}
+	if plainTemplate == "" {
+		plainTemplate = emailDefaultPlainTemplate
+	}
+	if htmlTemplate == "" {
+		htmlTemplate = emailDefaultHtmlTemplate
+	}
+
+	var subject, plain, html bytes.Buffer
+	t, err := template.New("subject").Parse(subjectTemplate)
+	if err != nil {
+		return "", "", "", err
+	}
+	if err = t.Execute(&subject, data); err != nil {
+		return "", "", "", err
+	}
+	t, err = template.New("plain").Parse(plainTemplate)
+	if err != nil {
+		return "", "", "", err
+	}
+	if err = t.Execute(&plain, data); err != nil {
+		return "", "", "", err
+	}
+	ht, err := htmltemplate.New("html").Parse(htmlTemplate)
+	if err != nil {
+		return "", "", "", err
+	}
+	if err = ht.Execute(&html, data); err != nil {
+		return "", "", "", err
+	}
+	// header values can not span lines
+	subjectLine := strings.Join(strings.Fields(subject.String()), " ")
+	return subjectLine, plain.String(), html.String(), nil
+}
+
+func buildEmailMessage(from string, recipients []string, subject, plain, html string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{{"text/plain", plain}, {"text/html", html}} {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType+"; charset=UTF-8")
+		header.Set("Content-Transfer-Encoding", "8bit")
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write([]byte(strings.ReplaceAll(part.content, "\n", "\r\n"))); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	msg.WriteString("From: " + from + "\r\n")
+	msg.WriteString("To: " + strings.Join(recipients, ", ") + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: multipart/alternative; boundary=" + writer.Boundary() + "\r\n\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func sendSmtpMail(keys map[string]string, from string, recipients []string, msg []byte) error {
+	host := keys["host"]
+	addr := net.JoinHostPort(host, keys["port"])
+	tlsConfig := &tls.Config{ServerName: host}
+	var conn net.Conn
+	var err error
+	dialer := &net.Dialer{Timeout: emailDialTimeout}
+	// port 465 is implicit TLS, the rest upgrade with STARTTLS when the relay supports it
+	if keys["port"] == "465" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(2 * emailDialTimeout))
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && keys["port"] != "465" {
+		if err = client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if keys["username"] != "" {
+		if err = client.Auth(smtp.PlainAuth("", keys["username"], keys["password"], host)); err != nil {
+			return err
+		}
+	}
+	if err = client.Mail(from); err != nil {
+		return err
+	}
+	for _, r := range recipients {
+		if err = client.Rcpt(r); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func sendMessageToEmail(integration models.Integration, title string, message string, msgType string) error {
+	from, err := mail.ParseAddress(integration.Keys["from"])
+	if err != nil {
+		return err
+	}
+	recipients, err := parseEmailRecipients(integration.Keys["recipients"])
+	if err != nil {
+		return err
+	}
+	data := emailTemplateData{
+		Type:       msgType,
+		Title:      title,
+		Message:    message,
+		TenantName: integration.TenantName,
+		Timestamp:  time.Now().UTC().Format(time.RFC1123),
+	}
+	subject, plain, html, err := renderEmailTemplates(integration.Keys, data)
+	if err != nil {
+		return err
+	}
+	msg, err := buildEmailMessage(from.String(), recipients, subject, plain, html)
+	if err != nil {
+		return err
+	}
+	return sendSmtpMail(integration.Keys, from.Address, recipients, msg)
+}
+
+func hideEmailPassword(password string) string {
+	if password != "" {
+		return "****"
+	}
+	return password
+}
@@ -0,0 +1,50 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEmailRecipients(t *testing.T) {
+	recipients, err := parseEmailRecipients("ops@example.com, Team <team@example.com>,")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(recipients) != 2 || recipients[0] != "ops@example.com" || recipients[1] != "team@example.com" {
+		t.Fatalf("Unexpected recipients: %v", recipients)
+	}
+	if _, err := parseEmailRecipients(" , "); err == nil {
+		t.Fatalf("Expected an error for an empty recipients list")
+	}
+	if _, err := parseEmailRecipients("not-an-address"); err == nil {
+		t.Fatalf("Expected an error for an invalid address")
+	}
+}
+
+func TestRenderEmailTemplates(t *testing.T) {
+	data := emailTemplateData{Type: PoisonMAlert, Title: "Poison\nmessage", Message: "<b>msg</b>", TenantName: "tenant"}
+	subject, plain, html, err := renderEmailTemplates(map[string]string{"plain_template": "{{.Type}}: {{.Message}}"}, data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if subject != "[Memphis] Poison message" {
+		t.Fatalf("Unexpected subject: %q", subject)
+	}
+	if plain != PoisonMAlert+": <b>msg</b>" {
+		t.Fatalf("Unexpected plain body: %q", plain)
+	}
+	if !strings.Contains(html, "&lt;b&gt;msg&lt;/b&gt;") {
+		t.Fatalf("Expected the html body to be escaped: %q", html)
+	}
+}
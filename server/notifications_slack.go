@@ -143,7 +143,7 @@ func (it IntegrationsHandler) getSlackIntegrationDetails(body models.CreateInteg
 		disconnectAlert = false
 	}
 
-	keys, properties := createIntegrationsKeysAndProperties("slack", authToken, channelID, pmAlert, svfAlert, disconnectAlert, "", "", "", "", "", "")
+	keys, properties := createIntegrationsKeysAndProperties("slack", integrationKeysOpts{authToken: authToken, channelID: channelID, pmAlert: pmAlert, svfAlert: svfAlert, disconnectAlert: disconnectAlert})
 	return keys, properties, 0, nil
 }
 
@@ -251,7 +251,7 @@ func updateSlackIntegration(tenantName string, authToken string, channelID strin
 	if err != nil {
 		return slackIntegration, err
 	}
-	keys, properties := createIntegrationsKeysAndProperties("slack", authToken, channelID, pmAlert, svfAlert, disconnectAlert, "", "", "", "", "", "")
+	keys, properties := createIntegrationsKeysAndProperties("slack", integrationKeysOpts{authToken: authToken, channelID: channelID, pmAlert: pmAlert, svfAlert: svfAlert, disconnectAlert: disconnectAlert})
 	cloneKeys := copyMaps(keys)
 	encryptedValue, err := EncryptAES([]byte(authToken))
 	if err != nil {
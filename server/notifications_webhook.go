@@ -0,0 +1,354 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"memphis/db"
+	"memphis/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	webhookSignatureHeader      = "X-Memphis-Signature"
+	webhookTimestampHeader      = "X-Memphis-Timestamp"
+	webhookDefaultMaxRetries    = 3
+	webhookDefaultRetryInterval = 1000
+	webhookRequestTimeout       = 10 * time.Second
+)
+
+var webhookClient = &http.Client{Timeout: webhookRequestTimeout}
+
+func cacheDetailsWebhook(keys map[string]string, properties map[string]bool, tenantName string) {
+	webhookIntegration := models.Integration{}
+	webhookIntegration.Keys = make(map[string]string)
+	webhookIntegration.Properties = make(map[string]bool)
+	if keys == nil {
+		deleteIntegrationFromTenant(tenantName, "webhook", IntegrationsConcurrentCache)
+		return
+	}
+
+	webhookIntegration.Keys["url"] = keys["url"]
+	webhookIntegration.Keys["secret_key"] = keys["secret_key"]
+	webhookIntegration.Keys["headers"] = keys["headers"]
+	webhookIntegration.Keys["max_retries"] = keys["max_retries"]
+	webhookIntegration.Keys["retry_interval_ms"] = keys["retry_interval_ms"]
+	webhookIntegration.Properties[PoisonMAlert] = properties[PoisonMAlert]
+	webhookIntegration.Properties[SchemaVAlert] = properties[SchemaVAlert]
+	webhookIntegration.Properties[DisconEAlert] = properties[DisconEAlert]
+	webhookIntegration.Name = "webhook"
+	webhookIntegration.TenantName = tenantName
+	if _, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
+		IntegrationsConcurrentCache.Add(tenantName, map[string]interface{}{"webhook": webhookIntegration})
+	} else {
+		err := addIntegrationToTenant(tenantName, "webhook", IntegrationsConcurrentCache, webhookIntegration)
+		if err != nil {
+			serv.Errorf("cacheDetailsWebhook: %s ", err.Error())
+			return
+		}
+	}
+}
+
+func (it IntegrationsHandler) getWebhookIntegrationDetails(body models.CreateIntegrationSchema) (map[string]string, map[string]bool, int, error) {
+	webhookUrl := body.Keys["url"]
+	parsedUrl, err := url.ParseRequestURI(webhookUrl)
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Host == "" {
+		return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("must provide a valid http/https url for webhook integration")
+	}
+
+	if headers := body.Keys["headers"]; headers != "" {
+		var customHeaders map[string]string
+		err := json.Unmarshal([]byte(headers), &customHeaders)
+		if err != nil {
+			return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("webhook headers must be a json object of string values")
+		}
+	}
+
+	maxRetries := webhookDefaultMaxRetries
+	if value := body.Keys["max_retries"]; value != "" {
+		maxRetries, err = strconv.Atoi(value)
+		if err != nil || maxRetries < 0 || maxRetries > 10 {
+			return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("max_retries has to be a number between 0 and 10")
+		}
+	}
+	retryInterval := webhookDefaultRetryInterval
+	if value := body.Keys["retry_interval_ms"]; value != "" {
+		retryInterval, err = strconv.Atoi(value)
+		if err != nil || retryInterval < 100 || retryInterval > 60000 {
+			return map[string]string{}, map[string]bool{}, SHOWABLE_ERROR_STATUS_CODE, errors.New("retry_interval_ms has to be a number between 100 and 60000")
+		}
+	}
+
+	extraKeys := map[string]string{
+		"headers":           body.Keys["headers"],
+		"max_retries":       strconv.Itoa(maxRetries),
+		"retry_interval_ms": strconv.Itoa(retryInterval),
+	}
+	keys, properties := createIntegrationsKeysAndProperties("webhook", integrationKeysOpts{
+		pmAlert:         body.Properties[PoisonMAlert],
+		svfAlert:        body.Properties[SchemaVAlert],
+		disconnectAlert: body.Properties[DisconEAlert],
+		secretKey:       body.Keys["secret_key"],
+		url:             webhookUrl,
+		extraKeys:       extraKeys,
+	})
+	return keys, properties, 0, nil
+}
+
+func (it IntegrationsHandler) handleCreateWebhookIntegration(tenantName string, body models.CreateIntegrationSchema) (models.Integration, int, error) {
+	keys, properties, errorCode, err := it.getWebhookIntegrationDetails(body)
+	if err != nil {
+		return models.Integration{}, errorCode, err
+	}
+	if it.S.opts.UiHost == "" && body.UIUrl != "" {
+		EditClusterCompHost("ui_host", body.UIUrl)
+	}
+	webhookIntegration, err := createWebhookIntegration(tenantName, keys, properties, body.UIUrl)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "webhook test") {
+			return models.Integration{}, SHOWABLE_ERROR_STATUS_CODE, err
+		} else {
+			return models.Integration{}, 500, err
+		}
+	}
+	return webhookIntegration, 0, nil
+}
+
+func (it IntegrationsHandler) handleUpdateWebhookIntegration(tenantName string, body models.CreateIntegrationSchema) (models.Integration, int, error) {
+	keys, properties, errorCode, err := it.getWebhookIntegrationDetails(body)
+	if err != nil {
+		return models.Integration{}, errorCode, err
+	}
+	webhookIntegration, err := updateWebhookIntegration(tenantName, keys, properties, body.UIUrl)
+	if err != nil {
+		if strings.Contains(err.Error(), "webhook test") {
+			return models.Integration{}, SHOWABLE_ERROR_STATUS_CODE, err
+		} else {
+			return models.Integration{}, 500, err
+		}
+	}
+	return webhookIntegration, 0, nil
+}
+
+func createWebhookIntegration(tenantName string, keys map[string]string, properties map[string]bool, uiUrl string) (models.Integration, error) {
+	exist, _, err := db.GetIntegration("webhook", tenantName)
+	if err != nil {
+		return models.Integration{}, err
+	} else if exist {
+		return models.Integration{}, errors.New("webhook integration already exists")
+	}
+
+	err = testWebhookIntegration(tenantName, keys, "Webhook integration with Memphis was added successfully")
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys := copyMaps(keys)
+	encryptedValue, err := EncryptAES([]byte(keys["secret_key"]))
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys["secret_key"] = encryptedValue
+	webhookIntegration, err := db.InsertNewIntegration(tenantName, "webhook", cloneKeys, properties)
+	if err != nil {
+		return models.Integration{}, err
+	}
+
+	err = publishWebhookIntegrationUpdate(tenantName, keys, properties, uiUrl)
+	if err != nil {
+		return models.Integration{}, err
+	}
+	webhookIntegration.Keys["secret_key"] = hideWebhookSecret(keys["secret_key"])
+	return webhookIntegration, nil
+}
+
+func updateWebhookIntegration(tenantName string, keys map[string]string, properties map[string]bool, uiUrl string) (models.Integration, error) {
+	if keys["secret_key"] == "" {
+		exist, integrationFromDb, err := db.GetIntegration("webhook", tenantName)
+		if err != nil {
+			return models.Integration{}, err
+		}
+		if exist && integrationFromDb.Keys["secret_key"] != "" {
+			secret, err := DecryptAES(getAESKey(), integrationFromDb.Keys["secret_key"])
+			if err != nil {
+				return models.Integration{}, err
+			}
+			keys["secret_key"] = secret
+		}
+	}
+
+	err := testWebhookIntegration(tenantName, keys, "Webhook integration with Memphis was updated successfully")
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys := copyMaps(keys)
+	encryptedValue, err := EncryptAES([]byte(keys["secret_key"]))
+	if err != nil {
+		return models.Integration{}, err
+	}
+	cloneKeys["secret_key"] = encryptedValue
+	webhookIntegration, err := db.UpdateIntegration(tenantName, "webhook", cloneKeys, properties)
+	if err != nil {
+		return models.Integration{}, err
+	}
+
+	err = publishWebhookIntegrationUpdate(tenantName, keys, properties, uiUrl)
+	if err != nil {
+		return models.Integration{}, err
+	}
+	keys["secret_key"] = hideWebhookSecret(keys["secret_key"])
+	webhookIntegration.Keys = keys
+	webhookIntegration.Properties = properties
+	return webhookIntegration, nil
+}
+
+func publishWebhookIntegrationUpdate(tenantName string, keys map[string]string, properties map[string]bool, uiUrl string) error {
+	integrationToUpdate := models.CreateIntegration{
+		Name:       "webhook",
+		Keys:       keys,
+		Properties: properties,
+		UIUrl:      uiUrl,
+		TenantName: tenantName,
+	}
+	msg, err := json.Marshal(integrationToUpdate)
+	if err != nil {
+		return err
+	}
+	err = serv.sendInternalAccountMsgWithReply(serv.MemphisGlobalAccount(), INTEGRATIONS_UPDATES_SUBJ, _EMPTY_, nil, msg, true)
+	if err != nil {
+		return err
+	}
+	if properties[SchemaVAlert] {
+		update := models.SdkClientsUpdates{
+			Type:   sendNotificationType,
+			Update: true,
+		}
+		serv.SendUpdateToClients(update)
+	}
+	return nil
+}
+
+func testWebhookIntegration(tenantName string, keys map[string]string, message string) error {
+	integration := models.Integration{Name: "webhook", Keys: keys, TenantName: tenantName}
+	payload := models.WebhookPayload{
+		Type:       "test",
+		Title:      "Memphis webhook test",
+		Message:    message,
+		TenantName: tenantName,
+		Timestamp:  time.Now(),
+	}
+	err := sendWebhookRequest(integration, payload)
+	if err != nil {
+		return fmt.Errorf("webhook test request failed: %v", err.Error())
+	}
+	return nil
+}
+
+func signWebhookPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sendWebhookRequest(integration models.Integration, payload models.WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, integration.Keys["url"], bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if headers := integration.Keys["headers"]; headers != "" {
+		var customHeaders map[string]string
+		err = json.Unmarshal([]byte(headers), &customHeaders)
+		if err != nil {
+			return err
+		}
+		for k, v := range customHeaders {
+			req.Header.Set(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	timestamp := strconv.FormatInt(payload.Timestamp.Unix(), 10)
+	req.Header.Set(webhookTimestampHeader, timestamp)
+	if secret := integration.Keys["secret_key"]; secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, timestamp, body))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status code %v", resp.StatusCode)
+	}
+	return nil
+}
+
+func sendMessageToWebhook(integration models.Integration, title string, message string, msgType string) error {
+	payload := models.WebhookPayload{
+		Type:       msgType,
+		Title:      title,
+		Message:    message,
+		TenantName: integration.TenantName,
+		Timestamp:  time.Now(),
+	}
+	maxRetries, err := strconv.Atoi(integration.Keys["max_retries"])
+	if err != nil {
+		maxRetries = webhookDefaultMaxRetries
+	}
+	retryInterval, err := strconv.Atoi(integration.Keys["retry_interval_ms"])
+	if err != nil {
+		retryInterval = webhookDefaultRetryInterval
+	}
+
+	// retries are done in the background so alerts do not hold the flows which raised them
+	go func() {
+		interval := time.Duration(retryInterval) * time.Millisecond
+		for attempt := 0; ; attempt++ {
+			err := sendWebhookRequest(integration, payload)
+			if err == nil {
+				return
+			}
+			if attempt >= maxRetries {
+				serv.Warnf("[tenant: %v]sendMessageToWebhook: failed sending %v notification after %v attempts: %v", integration.TenantName, msgType, attempt+1, err.Error())
+				return
+			}
+			time.Sleep(interval)
+			interval *= 2
+		}
+	}()
+	return nil
+}
+
+func hideWebhookSecret(secret string) string {
+	if len(secret) > 4 {
+		return "****" + secret[len(secret)-4:]
+	}
+	if secret != "" {
+		return "****"
+	}
+	return secret
+}
@@ -0,0 +1,65 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"memphis/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSendWebhookRequestSignsPayload(t *testing.T) {
+	var gotSignature, gotTimestamp, gotCustom string
+	var gotBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(webhookSignatureHeader)
+		gotTimestamp = r.Header.Get(webhookTimestampHeader)
+		gotCustom = r.Header.Get("X-Custom")
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	integration := models.Integration{
+		Name: "webhook",
+		Keys: map[string]string{"url": srv.URL, "secret_key": "secret", "headers": `{"X-Custom":"value"}`},
+	}
+	payload := models.WebhookPayload{Type: PoisonMAlert, Title: "title", Message: "message", TenantName: "tenant", Timestamp: time.Now()}
+	if err := sendWebhookRequest(integration, payload); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotCustom != "value" {
+		t.Fatalf("Expected custom header to be sent, got %q", gotCustom)
+	}
+	if expected := signWebhookPayload("secret", gotTimestamp, gotBody); gotSignature != expected {
+		t.Fatalf("Expected signature %q, got %q", expected, gotSignature)
+	}
+	var received models.WebhookPayload
+	if err := json.Unmarshal(gotBody, &received); err != nil || received.Type != PoisonMAlert || received.TenantName != "tenant" {
+		t.Fatalf("Unexpected payload: %s", gotBody)
+	}
+}
+
+func TestSendWebhookRequestFailsOnErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	integration := models.Integration{Name: "webhook", Keys: map[string]string{"url": srv.URL}}
+	if err := sendWebhookRequest(integration, models.WebhookPayload{Timestamp: time.Now()}); err == nil {
+		t.Fatalf("Expected an error for a non 2xx response")
+	}
+}
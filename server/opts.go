@@ -291,6 +291,9 @@ type Options struct {
 	K8sNamespace                       string         `json:"-"`
 	LogsRetentionDays                  int            `json:"-"`
 	TieredStorageUploadIntervalSec     int            `json:"-"`
+	TieredStorageObjectFormat          string         `json:"-"`
+	TieredStorageLocalFsBaseDir        string         `json:"-"`
+	MetricsToken                       string         `json:"-"`
 	DlsRetentionHours                  map[string]int `json:"-"`
 	GCProducersConsumersRetentionHours int            `json:"-"`
 	UiHost                             string         `json:"-"`
@@ -1436,6 +1439,22 @@ func (o *Options) processConfigFileLine(k string, v interface{}, errors *[]error
 			return
 		}
 		o.TieredStorageUploadIntervalSec = value
+	case "tiered_storage_object_format":
+		value := strings.ToLower(v.(string))
+		if value != "json" && value != "compact" && value != "compact_s2" {
+			*errors = append(*errors, &configErr{tk, "error tiered_storage_object_format config: has to be one of json, compact (zstd) or compact_s2"})
+			return
+		}
+		o.TieredStorageObjectFormat = value
+	case "tiered_storage_local_fs_base_dir":
+		value := v.(string)
+		if !filepath.IsAbs(value) {
+			*errors = append(*errors, &configErr{tk, "error tiered_storage_local_fs_base_dir config: has to be an absolute path"})
+			return
+		}
+		o.TieredStorageLocalFsBaseDir = filepath.Clean(value)
+	case "metrics_token":
+		o.MetricsToken = v.(string)
 	case "dls_retention_hours":
 		value := int(v.(int64))
 		if value < 1 || value > 30 {
@@ -4760,6 +4779,12 @@ func setBaselineOptions(opts *Options) {
 	if opts.TieredStorageUploadIntervalSec == 0 {
 		opts.TieredStorageUploadIntervalSec = DEFAULT_TIERED_STORAGE_UPLOAD_INTERVAL_SEC
 	}
+	if opts.TieredStorageObjectFormat == _EMPTY_ {
+		opts.TieredStorageObjectFormat = DEFAULT_TIERED_STORAGE_OBJECT_FORMAT
+	}
+	if opts.TieredStorageLocalFsBaseDir == _EMPTY_ {
+		opts.TieredStorageLocalFsBaseDir = DEFAULT_TIERED_STORAGE_LOCAL_FS_BASE_DIR
+	}
 	for tenant, value := range opts.DlsRetentionHours {
 		if value == 0 {
 			opts.DlsRetentionHours[tenant] = DEFAULT_DLS_RETENTION_HOURS
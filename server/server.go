@@ -2355,6 +2355,7 @@ const (
 	JszPath          = "/jsz"
 	HealthzPath      = "/healthz"
 	IPQueuesPath     = "/ipqueuesz"
+	MetricsPath      = "/metrics"
 )
 
 func (s *Server) basePath(p string) string {
@@ -2462,6 +2463,8 @@ func (s *Server) startMonitoring(secure bool) error {
 	mux.HandleFunc(s.basePath(HealthzPath), s.HandleHealthz)
 	// IPQueuesz
 	mux.HandleFunc(s.basePath(IPQueuesPath), s.HandleIPQueuesz)
+	// Memphis metrics in the prometheus text format
+	mux.HandleFunc(s.basePath(MetricsPath), s.HandleMetrics)
 
 	// Do not set a WriteTimeout because it could cause cURL/browser
 	// to return empty response or unable to display page if the
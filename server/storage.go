@@ -17,36 +17,63 @@ import (
 	"fmt"
 	"memphis/models"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func flushMapToTier2Storage() error {
-	for t, tenant := range tieredStorageMsgsMap.m {
-		for k, f := range StorageFunctionsMap {
-			switch k {
-			case "s3":
-				if tenantIntegrations, ok := IntegrationsConcurrentCache.Load(t); !ok {
-					continue
-				} else {
-					if _, ok = tenantIntegrations["s3"].(models.Integration); ok {
-						err := f.(func(string, map[string][]StoredMsg) error)(t, tenant)
-						if err != nil {
-							return err
-						}
-					}
-				}
-			default:
-				return errors.New("failed uploading to tiered storage : unsupported integration")
-			}
+type Tier2Storage interface {
+	Upload(tenantName string, msgs map[string][]StoredMsg) error
+	ListObjects(tenantName, stationName string, from, to time.Time) ([]string, error)
+	ReadObject(tenantName, objectName string) ([]StoredMsg, error)
+}
+
+func getTier2StationPrefix(tenantName, stationName string) string {
+	if tenantName == serv.MemphisGlobalAccountString() {
+		tenantName = "global"
+	}
+	return "memphis/" + tenantName + "/" + stationName + "/"
+}
+
+func hasTier2StorageIntegration(tenantIntegrations map[string]interface{}) bool {
+	for k := range StorageFunctionsMap {
+		if _, ok := tenantIntegrations[k].(models.Integration); ok {
+			return true
 		}
 	}
-	return nil
+	return false
+}
+
+func isTier2StorageConnected(tenantName string) bool {
+	tenantIntegrations, ok := IntegrationsConcurrentCache.Load(tenantName)
+	if !ok {
+		return false
+	}
+	return hasTier2StorageIntegration(tenantIntegrations)
+}
 
+// getTier2Storage returns the storage the tenant reads tiered objects from,
+// when more than one storage is connected they are picked by name for stable results
+func getTier2Storage(tenantName string) (Tier2Storage, error) {
+	tenantIntegrations, ok := IntegrationsConcurrentCache.Load(tenantName)
+	if !ok {
+		return nil, errors.New("tiered storage integration does not exist")
+	}
+	storageTypes := make([]string, 0, len(StorageFunctionsMap))
+	for k := range StorageFunctionsMap {
+		storageTypes = append(storageTypes, k)
+	}
+	sort.Strings(storageTypes)
+	for _, k := range storageTypes {
+		if _, ok := tenantIntegrations[k].(models.Integration); ok {
+			return StorageFunctionsMap[k], nil
+		}
+	}
+	return nil, errors.New("tiered storage integration does not exist")
 }
 
-func (s *Server) sendToTier2Storage(storageType interface{}, buf []byte, seq uint64, tierStorageType string) error {
+func (s *Server) sendToTier2Storage(storageType interface{}, buf []byte, seq uint64, ts int64) error {
 	storedType := reflect.TypeOf(storageType).Elem().Name()
 	var streamName, tenantName string
 	switch storedType {
@@ -60,58 +87,34 @@ func (s *Server) sendToTier2Storage(storageType interface{}, buf []byte, seq uin
 		tenantName = memStore.account.Name
 	}
 
-	for k := range StorageFunctionsMap {
-		switch k {
-		case "s3":
-			if tenantIntegrations, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
-				continue
-			} else {
-				if _, ok = tenantIntegrations["s3"].(models.Integration); ok {
-					msgId := map[string]string{}
-					seqNumber := strconv.Itoa(int(seq))
-					msgId["msg-id"] = streamName + seqNumber
-					if tenantName == "" {
-						tenantName = serv.MemphisGlobalAccountString()
-					}
-					subject := fmt.Sprintf("%s.%s.%s", tieredStorageStream, streamName, tenantName)
-					// TODO: if the stream is not exists save the messages in buffer
-					if TIERED_STORAGE_STREAM_CREATED {
-						tierStorageMsg := TieredStorageMsg{
-							Buf:         buf,
-							StationName: streamName,
-							TenantName:  tenantName,
-						}
-
-						msg, err := json.Marshal(tierStorageMsg)
-						if err != nil {
-							return err
-						}
-						s.sendInternalAccountMsgWithHeadersWithEcho(s.MemphisGlobalAccount(), subject, msg, msgId)
-					}
-				}
-			}
-
-		default:
-			return errors.New("failed send to tiered storage : unsupported integration")
-		}
+	if !isTier2StorageConnected(tenantName) {
+		return nil
 	}
-	return nil
-}
 
-func (s *Server) storeInTieredStorageMap(msg StoredMsg) {
-	tieredStorageMapLock.Lock()
-	stationName := msg.Subject
-	tenantName := msg.TenantName
-	if strings.Contains(msg.Subject, "#") {
-		stationName = strings.Replace(msg.Subject, "#", ".", -1)
-	}
-	_, ok := tieredStorageMsgsMap.Load(tenantName)
-	if !ok {
-		tieredStorageMsgsMap.Add(tenantName, map[string][]StoredMsg{})
+	msgId := map[string]string{}
+	seqNumber := strconv.Itoa(int(seq))
+	msgId["msg-id"] = streamName + seqNumber
+	if tenantName == "" {
+		tenantName = serv.MemphisGlobalAccountString()
 	}
+	subject := fmt.Sprintf("%s.%s.%s", tieredStorageStream, streamName, tenantName)
+	// TODO: if the stream is not exists save the messages in buffer
+	if TIERED_STORAGE_STREAM_CREATED {
+		tierStorageMsg := TieredStorageMsg{
+			Buf:         buf,
+			StationName: streamName,
+			TenantName:  tenantName,
+			Seq:         seq,
+			Timestamp:   ts,
+		}
 
-	tieredStorageMsgsMap.m[tenantName][stationName] = append(tieredStorageMsgsMap.m[tenantName][stationName], msg)
-	tieredStorageMapLock.Unlock()
+		msg, err := json.Marshal(tierStorageMsg)
+		if err != nil {
+			return err
+		}
+		s.sendInternalAccountMsgWithHeadersWithEcho(s.MemphisGlobalAccount(), subject, msg, msgId)
+	}
+	return nil
 }
 
 func (s *Server) handleNewTieredStorageMsg(msg []byte, reply string) {
@@ -128,12 +131,20 @@ func (s *Server) handleNewTieredStorageMsg(msg []byte, reply string) {
 		return
 	}
 	payload := tieredStorageMsg.Buf
-	rawTs := tokenAt(reply, 8)
-	seq, _, _ := ackReplyInfo(reply)
-	intTs, err := strconv.Atoi(rawTs)
-	if err != nil {
-		s.Errorf("ListenForTieredStorageMessages: Failed convert rawTs from string to int")
-		return
+	streamSeq, _, _ := ackReplyInfo(reply)
+	// messages sent by older versions do not carry the original sequence and timestamp
+	seq, intTs := tieredStorageMsg.Seq, tieredStorageMsg.Timestamp
+	if seq == 0 {
+		seq = streamSeq
+	}
+	if intTs == 0 {
+		rawTs := tokenAt(reply, 8)
+		ts, err := strconv.Atoi(rawTs)
+		if err != nil {
+			s.Errorf("ListenForTieredStorageMessages: Failed convert rawTs from string to int")
+			return
+		}
+		intTs = int64(ts)
 	}
 
 	dataFirstIdx := 0
@@ -145,15 +156,164 @@ func (s *Server) handleNewTieredStorageMsg(msg []byte, reply string) {
 	dataLen := len(payload) - dataFirstIdx
 	header := payload[:dataFirstIdx]
 	data := payload[dataFirstIdx : dataFirstIdx+dataLen]
+	stationName := strings.Replace(tieredStorageMsg.StationName, "#", ".", -1)
 	message := StoredMsg{
-		Subject:      tieredStorageMsg.StationName,
-		Sequence:     uint64(seq),
+		Subject:      stationName,
+		Sequence:     seq,
 		Data:         data,
 		Header:       header,
-		Time:         time.Unix(0, int64(intTs)),
+		Time:         time.Unix(0, intTs),
 		ReplySubject: reply,
 		TenantName:   tieredStorageMsg.TenantName,
 	}
 
-	s.storeInTieredStorageMap(message)
+	added, alreadyBuffered := tieredStorageMsgsBuffer.add(message, streamSeq)
+	if !added {
+		s.nakTieredStorageMsg(reply, tieredStorageMsgsBuffer.retryDelay(message.TenantName))
+	} else if alreadyBuffered {
+		s.sendInternalAccountMsg(s.MemphisGlobalAccount(), reply, AckProgress)
+	}
+}
+
+const (
+	rehydrateMaxObjects   = 500
+	rehydrateMaxMessages  = 500000
+	rehydrateBatchSize    = 100
+	rehydrateAckTimeout   = 30 * time.Second
+	rehydrateProducerName = "$memphis_tiered_storage"
+)
+
+func validateRehydrateObjectNames(prefix, stationName string, objectNames []string) error {
+	if len(objectNames) > rehydrateMaxObjects {
+		return fmt.Errorf("at most %v objects can be rehydrated at a time", rehydrateMaxObjects)
+	}
+	for _, objectName := range objectNames {
+		if !strings.HasPrefix(objectName, prefix) || strings.Contains(objectName, "..") {
+			return fmt.Errorf("object %v does not belong to station %v", objectName, stationName)
+		}
+	}
+	return nil
+}
+
+func isRehydratedMsgInRange(msg StoredMsg, from, to time.Time) bool {
+	if !from.IsZero() && msg.Time.Before(from) {
+		return false
+	}
+	if !to.IsZero() && msg.Time.After(to) {
+		return false
+	}
+	return true
+}
+
+// getRehydratedMsgHeaders drops the nats and reserved memphis headers of a stored message so a rehydrated message
+// can not pass as one published by the broker itself, the original producer is kept unless it is a reserved one
+func getRehydratedMsgHeaders(msg StoredMsg, objectName string) (map[string]string, error) {
+	stored := map[string]string{}
+	if len(msg.Header) > 0 {
+		var err error
+		stored, err = DecodeHeader(msg.Header)
+		if err != nil {
+			return nil, fmt.Errorf("object %v contains invalid headers: %v", objectName, err.Error())
+		}
+	}
+	hdrs := make(map[string]string, len(stored)+2)
+	producer := _EMPTY_
+	for k, v := range stored {
+		lk := strings.ToLower(k)
+		// objects written in the json format keep lower cased headers only
+		if lk == "$memphis_producedby" {
+			producer = v
+		}
+		if strings.HasPrefix(lk, "nats") || strings.HasPrefix(lk, "$memphis") {
+			continue
+		}
+		hdrs[k] = v
+	}
+	if producer == _EMPTY_ || strings.HasPrefix(strings.ToLower(producer), "$memphis") {
+		producer = rehydrateProducerName
+	}
+	hdrs["$memphis_producedBy"] = producer
+	hdrs["$memphis_rehydrated_from"] = objectName
+	return hdrs, nil
+}
+
+// rehydrateFromTier2Storage republishes the stored messages within the time range into the target station,
+// messages are published in batches acked by the station and the work of a single request is bounded
+func (s *Server) rehydrateFromTier2Storage(tenantName string, stationName, targetStationName StationName, from, to time.Time, objectNames []string) (int, int, int, error) {
+	storage, err := getTier2Storage(tenantName)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	prefix := getTier2StationPrefix(tenantName, stationName.Ext())
+	if len(objectNames) == 0 {
+		// an object is uploaded after all of its messages were produced, so older objects can be skipped,
+		// newer ones may still hold messages of the range and are filtered by the messages time
+		objectNames, err = storage.ListObjects(tenantName, stationName.Ext(), from, time.Time{})
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if len(objectNames) > rehydrateMaxObjects {
+			return 0, 0, 0, fmt.Errorf("%v objects match the request while at most %v objects can be rehydrated at a time, use a later from_time or pass object_names", len(objectNames), rehydrateMaxObjects)
+		}
+	} else {
+		err = validateRehydrateObjectNames(prefix, stationName.Ext(), objectNames)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+	}
+
+	account, err := s.lookupAccount(tenantName)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	subject := targetStationName.Intern() + ".final"
+	msgsCount, failedCount := 0, 0
+	batch := make([]jsPublishMsg, 0, rehydrateBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		errs, err := s.publishWithAcks(account, subject, batch, rehydrateAckTimeout)
+		for _, pubErr := range errs {
+			if pubErr != nil {
+				failedCount++
+			} else {
+				msgsCount++
+			}
+		}
+		batch = batch[:0]
+		return err
+	}
+	for _, objectName := range objectNames {
+		msgs, err := storage.ReadObject(tenantName, objectName)
+		if err != nil {
+			return len(objectNames), msgsCount, failedCount, err
+		}
+		for _, msg := range msgs {
+			if !isRehydratedMsgInRange(msg, from, to) {
+				continue
+			}
+			if msgsCount+failedCount+len(batch) >= rehydrateMaxMessages {
+				err = flush()
+				if err != nil {
+					return len(objectNames), msgsCount, failedCount, err
+				}
+				return len(objectNames), msgsCount, failedCount, fmt.Errorf("at most %v messages can be rehydrated at a time, narrow down the time range", rehydrateMaxMessages)
+			}
+			hdrs, err := getRehydratedMsgHeaders(msg, objectName)
+			if err != nil {
+				return len(objectNames), msgsCount, failedCount, err
+			}
+			batch = append(batch, jsPublishMsg{hdr: hdrs, data: msg.Data})
+			if len(batch) >= rehydrateBatchSize {
+				err = flush()
+				if err != nil {
+					return len(objectNames), msgsCount, failedCount, err
+				}
+			}
+		}
+	}
+	err = flush()
+	return len(objectNames), msgsCount, failedCount, err
 }
@@ -0,0 +1,331 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	tieredStorageMaxBufferBytes       = 512 * 1024 * 1024
+	tieredStorageMaxTenantBufferBytes = 128 * 1024 * 1024
+	tieredStorageMaxBatchBytes        = 8 * 1024 * 1024
+	tieredStorageMaxBatchMsgs         = 10000
+	tieredStorageMinBackoff           = 5 * time.Second
+	tieredStorageMaxBackoff           = 5 * time.Minute
+)
+
+type tieredStorageBatch struct {
+	station    string
+	msgs       []StoredMsg
+	streamSeqs []uint64
+	size       int64
+	uploadedTo map[string]bool
+}
+
+type tieredStorageTenantBuffer struct {
+	stations    map[string]*tieredStorageBatch
+	failed      []*tieredStorageBatch
+	size        int64
+	uploading   bool
+	failures    map[string]int
+	nextAttempt map[string]time.Time
+}
+
+// tieredStorageBuffer holds messages pulled from the tiered storage work queue until they are uploaded,
+// messages are acked only once uploaded so the work queue stream keeps them durable across restarts
+type tieredStorageBuffer struct {
+	mu      sync.Mutex
+	tenants map[string]*tieredStorageTenantBuffer
+	seqs    map[uint64]struct{}
+	size    int64
+	flushCh chan string
+}
+
+func newTieredStorageBuffer() *tieredStorageBuffer {
+	return &tieredStorageBuffer{
+		tenants: map[string]*tieredStorageTenantBuffer{},
+		seqs:    map[uint64]struct{}{},
+		flushCh: make(chan string, 100),
+	}
+}
+
+func getTieredStorageBackoff(failures int) time.Duration {
+	backoff := tieredStorageMinBackoff
+	for i := 1; i < failures && backoff < tieredStorageMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > tieredStorageMaxBackoff {
+		backoff = tieredStorageMaxBackoff
+	}
+	return backoff
+}
+
+func (b *tieredStorageBuffer) isFull() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size >= tieredStorageMaxBufferBytes
+}
+
+// add returns whether the message has been buffered and whether it was already waiting for upload,
+// a message which is not buffered should be redelivered later
+func (b *tieredStorageBuffer) add(msg StoredMsg, streamSeq uint64) (bool, bool) {
+	size := int64(len(msg.Data) + len(msg.Header))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.seqs[streamSeq]; ok {
+		return true, true
+	}
+	tb, ok := b.tenants[msg.TenantName]
+	if !ok {
+		tb = &tieredStorageTenantBuffer{
+			stations:    map[string]*tieredStorageBatch{},
+			failures:    map[string]int{},
+			nextAttempt: map[string]time.Time{},
+		}
+		b.tenants[msg.TenantName] = tb
+	}
+	if b.size+size > tieredStorageMaxBufferBytes || tb.size+size > tieredStorageMaxTenantBufferBytes {
+		return false, false
+	}
+
+	batch, ok := tb.stations[msg.Subject]
+	if !ok {
+		batch = &tieredStorageBatch{station: msg.Subject, uploadedTo: map[string]bool{}}
+		tb.stations[msg.Subject] = batch
+	}
+	batch.msgs = append(batch.msgs, msg)
+	batch.streamSeqs = append(batch.streamSeqs, streamSeq)
+	batch.size += size
+	tb.size += size
+	b.size += size
+	b.seqs[streamSeq] = struct{}{}
+
+	if batch.size >= tieredStorageMaxBatchBytes || len(batch.msgs) >= tieredStorageMaxBatchMsgs {
+		select {
+		case b.flushCh <- msg.TenantName:
+		default:
+		}
+	}
+	return true, false
+}
+
+// retryDelay returns how long the tenant's messages should wait before being redelivered
+func (b *tieredStorageBuffer) retryDelay(tenantName string) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delay := time.Duration(0)
+	if tb, ok := b.tenants[tenantName]; ok {
+		for _, next := range tb.nextAttempt {
+			if d := time.Until(next); d > delay {
+				delay = d
+			}
+		}
+	}
+	if delay < tieredStorageMinBackoff {
+		delay = tieredStorageMinBackoff
+	}
+	return delay
+}
+
+func (b *tieredStorageBuffer) tenantsToFlush() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tenants := []string{}
+	for t, tb := range b.tenants {
+		if (len(tb.stations) > 0 || len(tb.failed) > 0) && !tb.uploading {
+			tenants = append(tenants, t)
+		}
+	}
+	return tenants
+}
+
+// startFlush returns the batches which failed on a previous flush first, followed by the newly buffered ones
+func (b *tieredStorageBuffer) startFlush(tenantName string) ([]*tieredStorageBatch, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tb, ok := b.tenants[tenantName]
+	if !ok || tb.uploading || (len(tb.stations) == 0 && len(tb.failed) == 0) {
+		return nil, false
+	}
+	tb.uploading = true
+	batches := tb.failed
+	for _, batch := range tb.stations {
+		batches = append(batches, batch)
+	}
+	tb.failed = nil
+	tb.stations = map[string]*tieredStorageBatch{}
+	return batches, true
+}
+
+func (b *tieredStorageBuffer) destinationReady(tenantName, storageType string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tb, ok := b.tenants[tenantName]
+	if !ok {
+		return true
+	}
+	return time.Now().After(tb.nextAttempt[storageType])
+}
+
+func (b *tieredStorageBuffer) reportDestination(tenantName, storageType string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tb, ok := b.tenants[tenantName]
+	if !ok {
+		return
+	}
+	if err == nil {
+		delete(tb.failures, storageType)
+		delete(tb.nextAttempt, storageType)
+		return
+	}
+	tb.failures[storageType]++
+	tb.nextAttempt[storageType] = time.Now().Add(getTieredStorageBackoff(tb.failures[storageType]))
+}
+
+// endFlush releases uploaded batches and returns the failed ones to the buffer so they are retried first,
+// a failed batch keeps its own destinations state so messages buffered meanwhile are uploaded to every destination
+func (b *tieredStorageBuffer) endFlush(tenantName string, uploaded, failed []*tieredStorageBatch) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tb, ok := b.tenants[tenantName]
+	if !ok {
+		return
+	}
+	for _, batch := range uploaded {
+		tb.size -= batch.size
+		b.size -= batch.size
+		for _, seq := range batch.streamSeqs {
+			delete(b.seqs, seq)
+		}
+	}
+	tb.failed = failed
+	tb.uploading = false
+	if len(tb.stations) == 0 && len(tb.failed) == 0 && len(tb.failures) == 0 {
+		delete(b.tenants, tenantName)
+	}
+}
+
+// upload sends every batch to each ready destination it has not reached yet and splits the batches
+// to the ones which reached all destinations and the ones which should be retried
+func (b *tieredStorageBuffer) upload(tenantName string, batches []*tieredStorageBatch, storageTypes []string, uploadFunc func(storageType, station string, msgs []StoredMsg) error) ([]*tieredStorageBatch, []*tieredStorageBatch) {
+	for _, k := range storageTypes {
+		if !b.destinationReady(tenantName, k) {
+			continue
+		}
+		for _, batch := range batches {
+			if batch.uploadedTo[k] {
+				continue
+			}
+			err := uploadFunc(k, batch.station, batch.msgs)
+			b.reportDestination(tenantName, k, err)
+			if err != nil {
+				// the destination is in backoff now, the rest of the batches will be retried with it
+				break
+			}
+			batch.uploadedTo[k] = true
+		}
+	}
+
+	uploaded := []*tieredStorageBatch{}
+	failed := []*tieredStorageBatch{}
+	for _, batch := range batches {
+		done := true
+		for _, k := range storageTypes {
+			if !batch.uploadedTo[k] {
+				done = false
+				break
+			}
+		}
+		if done {
+			uploaded = append(uploaded, batch)
+		} else {
+			failed = append(failed, batch)
+		}
+	}
+	return uploaded, failed
+}
+
+type tieredStorageTenantStats struct {
+	msgs     int
+	size     int64
+	oldest   time.Time
+	failures int
+}
+
+func (b *tieredStorageBuffer) stats() map[string]tieredStorageTenantStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := make(map[string]tieredStorageTenantStats, len(b.tenants))
+	for t, tb := range b.tenants {
+		ts := tieredStorageTenantStats{size: tb.size}
+		batches := tb.failed
+		for _, batch := range tb.stations {
+			batches = append(batches, batch)
+		}
+		for _, batch := range batches {
+			ts.msgs += len(batch.msgs)
+			for _, msg := range batch.msgs {
+				if ts.oldest.IsZero() || msg.Time.Before(ts.oldest) {
+					ts.oldest = msg.Time
+				}
+			}
+		}
+		for _, failures := range tb.failures {
+			ts.failures += failures
+		}
+		stats[t] = ts
+	}
+	return stats
+}
+
+func (s *Server) flushTenantToTier2Storage(tenantName string) {
+	batches, ok := tieredStorageMsgsBuffer.startFlush(tenantName)
+	if !ok {
+		return
+	}
+
+	tenantIntegrations, _ := IntegrationsConcurrentCache.Load(tenantName)
+	storageTypes := []string{}
+	for k := range StorageFunctionsMap {
+		if _, ok := tenantIntegrations[k]; ok {
+			storageTypes = append(storageTypes, k)
+		}
+	}
+
+	uploaded, failed := tieredStorageMsgsBuffer.upload(tenantName, batches, storageTypes, func(storageType, station string, msgs []StoredMsg) error {
+		err := StorageFunctionsMap[storageType].Upload(tenantName, map[string][]StoredMsg{station: msgs})
+		if err != nil {
+			s.Errorf("[tenant: %v]flushTenantToTier2Storage: failed uploading messages of station %v to %v: %v", tenantName, station, storageType, err.Error())
+		}
+		return err
+	})
+	// messages of tenants without a tiered storage integration are acked as well
+	for _, batch := range uploaded {
+		for _, msg := range batch.msgs {
+			s.sendInternalAccountMsg(s.MemphisGlobalAccount(), msg.ReplySubject, []byte(_EMPTY_))
+		}
+	}
+	tieredStorageMsgsBuffer.endFlush(tenantName, uploaded, failed)
+}
+
+func (s *Server) nakTieredStorageMsg(reply string, delay time.Duration) {
+	nak, err := json.Marshal(ConsumerNakOptions{Delay: delay})
+	if err != nil {
+		return
+	}
+	s.sendInternalAccountMsg(s.MemphisGlobalAccount(), reply, []byte(fmt.Sprintf("%s %s", AckNak, nak)))
+}
@@ -0,0 +1,115 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTestUpload = errors.New("upload failed")
+
+func TestTieredStorageBackoff(t *testing.T) {
+	if b := getTieredStorageBackoff(1); b != tieredStorageMinBackoff {
+		t.Fatalf("Expected %v, got %v", tieredStorageMinBackoff, b)
+	}
+	if b := getTieredStorageBackoff(2); b != 2*tieredStorageMinBackoff {
+		t.Fatalf("Expected %v, got %v", 2*tieredStorageMinBackoff, b)
+	}
+	if b := getTieredStorageBackoff(100); b != tieredStorageMaxBackoff {
+		t.Fatalf("Expected %v, got %v", tieredStorageMaxBackoff, b)
+	}
+}
+
+func TestTieredStorageBufferFlush(t *testing.T) {
+	b := newTieredStorageBuffer()
+	msg := StoredMsg{Subject: "station", TenantName: "tenant", Data: []byte("data"), Time: time.Now()}
+	if added, dup := b.add(msg, 1); !added || dup {
+		t.Fatalf("Expected the message to be added")
+	}
+	if added, dup := b.add(msg, 1); !added || !dup {
+		t.Fatalf("Expected a redelivered message to be detected")
+	}
+	b.add(msg, 2)
+
+	batches, ok := b.startFlush("tenant")
+	if !ok || len(batches) != 1 || len(batches[0].msgs) != 2 {
+		t.Fatalf("Unexpected batches: %+v", batches)
+	}
+	if _, ok := b.startFlush("tenant"); ok {
+		t.Fatalf("Expected a single flush per tenant at a time")
+	}
+	b.add(msg, 3)
+	b.reportDestination("tenant", "s3", errTestUpload)
+	if b.destinationReady("tenant", "s3") {
+		t.Fatalf("Expected the destination to be in backoff")
+	}
+	b.endFlush("tenant", []*tieredStorageBatch{}, batches)
+	if got := len(b.tenants["tenant"].failed[0].msgs) + len(b.tenants["tenant"].stations["station"].msgs); got != 3 {
+		t.Fatalf("Expected failed messages to be returned to the buffer, got %v", got)
+	}
+
+	batches, _ = b.startFlush("tenant")
+	if len(batches) != 2 || len(batches[0].msgs) != 2 {
+		t.Fatalf("Expected failed batches to be flushed first, got %+v", batches)
+	}
+	b.endFlush("tenant", batches, []*tieredStorageBatch{})
+	if b.size != int64(0) || len(b.seqs) != 0 {
+		t.Fatalf("Expected the buffer to be released, size: %v", b.size)
+	}
+}
+
+func TestTieredStorageBufferPartialUpload(t *testing.T) {
+	b := newTieredStorageBuffer()
+	msg := StoredMsg{Subject: "station", TenantName: "tenant", Data: []byte("data"), Time: time.Now()}
+	b.add(msg, 1)
+	b.add(msg, 2)
+
+	received := map[string]int{}
+	failS3 := true
+	uploadFunc := func(storageType, station string, msgs []StoredMsg) error {
+		if storageType == "s3" && failS3 {
+			return errTestUpload
+		}
+		received[storageType] += len(msgs)
+		return nil
+	}
+	storageTypes := []string{"s3", "local_fs"}
+
+	batches, _ := b.startFlush("tenant")
+	uploaded, failed := b.upload("tenant", batches, storageTypes, uploadFunc)
+	if len(uploaded) != 0 || len(failed) != 1 {
+		t.Fatalf("Expected the batch to be retried, uploaded: %v, failed: %v", len(uploaded), len(failed))
+	}
+	// messages buffered while the failed batch is retried must reach the destinations which already succeeded
+	b.add(msg, 3)
+	b.endFlush("tenant", uploaded, failed)
+
+	failS3 = false
+	b.tenants["tenant"].nextAttempt = map[string]time.Time{}
+	batches, _ = b.startFlush("tenant")
+	uploaded, failed = b.upload("tenant", batches, storageTypes, uploadFunc)
+	if len(failed) != 0 {
+		t.Fatalf("Expected all batches to be uploaded, failed: %v", len(failed))
+	}
+	b.endFlush("tenant", uploaded, failed)
+
+	for _, k := range storageTypes {
+		if received[k] != 3 {
+			t.Fatalf("Expected %v to receive 3 messages, got %v", k, received[k])
+		}
+	}
+	if b.size != int64(0) || len(b.seqs) != 0 {
+		t.Fatalf("Expected the buffer to be released, size: %v", b.size)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compact tier 2 object layout:
+// magic (4 bytes) | compression (1 byte) | blocks
+// every block is compressed on its own and prefixed with its compressed length (4 bytes, big endian),
+// so a reader holding the index manifest can fetch and decode a single block.
+// A decompressed block is a list of records:
+// uvarint seq | varint unix nano | uvarint len + producer | uvarint len + raw headers | uvarint len + data
+const (
+	tier2ObjectMagic          = "MTS1"
+	tier2ObjectHeaderLen      = 5
+	tier2BlockLenSize         = 4
+	tier2MaxMsgsPerBlock      = 500
+	tier2MaxBlockSize         = 1024 * 1024
+	tier2JsonObjectSuffix     = ".json"
+	tier2CompactObjectSuffix  = ".mts"
+	tier2IndexManifestSuffix  = ".index.json"
+	tier2ObjectFormatJson     = "json"
+	tier2ObjectFormatCompact  = "compact"
+	tier2ObjectFormatCompactS = "compact_s2"
+)
+
+const (
+	tier2CompressionNone byte = iota
+	tier2CompressionZstd
+	tier2CompressionS2
+)
+
+var (
+	tier2ZstdEncoder, _ = zstd.NewWriter(nil)
+	tier2ZstdDecoder, _ = zstd.NewReader(nil)
+)
+
+type Tier2IndexBlock struct {
+	Offset        int64     `json:"offset"`
+	Length        int64     `json:"length"`
+	MessagesCount int       `json:"messages_count"`
+	FirstSeq      uint64    `json:"first_seq"`
+	LastSeq       uint64    `json:"last_seq"`
+	FirstTime     time.Time `json:"first_time"`
+	LastTime      time.Time `json:"last_time"`
+}
+
+type Tier2ObjectIndex struct {
+	ObjectName    string            `json:"object_name"`
+	Format        string            `json:"format"`
+	Compression   string            `json:"compression"`
+	MessagesCount int               `json:"messages_count"`
+	FirstSeq      uint64            `json:"first_seq"`
+	LastSeq       uint64            `json:"last_seq"`
+	FirstTime     time.Time         `json:"first_time"`
+	LastTime      time.Time         `json:"last_time"`
+	Blocks        []Tier2IndexBlock `json:"blocks"`
+}
+
+func tier2CompressionName(compression byte) string {
+	switch compression {
+	case tier2CompressionZstd:
+		return "zstd"
+	case tier2CompressionS2:
+		return "s2"
+	default:
+		return "none"
+	}
+}
+
+func tier2CompressionByFormat(format string) byte {
+	if format == tier2ObjectFormatCompactS {
+		return tier2CompressionS2
+	}
+	return tier2CompressionZstd
+}
+
+func isCompactTier2Format(format string) bool {
+	return format == tier2ObjectFormatCompact || format == tier2ObjectFormatCompactS
+}
+
+func compressTier2Block(block []byte, compression byte) ([]byte, error) {
+	switch compression {
+	case tier2CompressionNone:
+		return block, nil
+	case tier2CompressionZstd:
+		return tier2ZstdEncoder.EncodeAll(block, nil), nil
+	case tier2CompressionS2:
+		return s2.Encode(nil, block), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %v", compression)
+	}
+}
+
+func decompressTier2Block(block []byte, compression byte) ([]byte, error) {
+	switch compression {
+	case tier2CompressionNone:
+		return block, nil
+	case tier2CompressionZstd:
+		return tier2ZstdDecoder.DecodeAll(block, nil)
+	case tier2CompressionS2:
+		return s2.Decode(nil, block)
+	default:
+		return nil, fmt.Errorf("unsupported compression %v", compression)
+	}
+}
+
+func getProducerFromHeader(header []byte) string {
+	if len(header) == 0 {
+		return ""
+	}
+	hdrs, err := DecodeHeader(header)
+	if err != nil {
+		return ""
+	}
+	if producer := hdrs["$memphis_producedBy"]; producer != "" {
+		return producer
+	}
+	return hdrs["producedBy"]
+}
+
+func appendTier2Record(buf []byte, msg StoredMsg) []byte {
+	producer := getProducerFromHeader(msg.Header)
+	buf = binary.AppendUvarint(buf, msg.Sequence)
+	buf = binary.AppendVarint(buf, msg.Time.UnixNano())
+	buf = binary.AppendUvarint(buf, uint64(len(producer)))
+	buf = append(buf, producer...)
+	buf = binary.AppendUvarint(buf, uint64(len(msg.Header)))
+	buf = append(buf, msg.Header...)
+	buf = binary.AppendUvarint(buf, uint64(len(msg.Data)))
+	buf = append(buf, msg.Data...)
+	return buf
+}
+
+func encodeTier2Object(msgs []StoredMsg, compression byte) ([]byte, Tier2ObjectIndex, error) {
+	index := Tier2ObjectIndex{
+		Format:        tier2ObjectFormatCompact,
+		Compression:   tier2CompressionName(compression),
+		MessagesCount: len(msgs),
+		Blocks:        []Tier2IndexBlock{},
+	}
+	var object bytes.Buffer
+	object.WriteString(tier2ObjectMagic)
+	object.WriteByte(compression)
+
+	var block []byte
+	var current Tier2IndexBlock
+	flush := func() error {
+		if current.MessagesCount == 0 {
+			return nil
+		}
+		compressed, err := compressTier2Block(block, compression)
+		if err != nil {
+			return err
+		}
+		var blockLen [tier2BlockLenSize]byte
+		binary.BigEndian.PutUint32(blockLen[:], uint32(len(compressed)))
+		object.Write(blockLen[:])
+		current.Offset = int64(object.Len())
+		current.Length = int64(len(compressed))
+		object.Write(compressed)
+		index.Blocks = append(index.Blocks, current)
+		block = block[:0]
+		current = Tier2IndexBlock{}
+		return nil
+	}
+
+	for i, msg := range msgs {
+		if i == 0 {
+			index.FirstSeq, index.FirstTime = msg.Sequence, msg.Time
+		}
+		index.LastSeq, index.LastTime = msg.Sequence, msg.Time
+		if current.MessagesCount == 0 {
+			current.FirstSeq, current.FirstTime = msg.Sequence, msg.Time
+		}
+		current.LastSeq, current.LastTime = msg.Sequence, msg.Time
+		current.MessagesCount++
+		block = appendTier2Record(block, msg)
+		if current.MessagesCount >= tier2MaxMsgsPerBlock || len(block) >= tier2MaxBlockSize {
+			if err := flush(); err != nil {
+				return nil, Tier2ObjectIndex{}, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, Tier2ObjectIndex{}, err
+	}
+	return object.Bytes(), index, nil
+}
+
+func readTier2Bytes(buf []byte, offset int) ([]byte, int, error) {
+	l, n := binary.Uvarint(buf[offset:])
+	if n <= 0 || uint64(len(buf)-offset-n) < l {
+		return nil, 0, errors.New("corrupted tier 2 record")
+	}
+	offset += n
+	return buf[offset : offset+int(l)], offset + int(l), nil
+}
+
+func decodeTier2Block(block []byte) ([]StoredMsg, error) {
+	msgs := []StoredMsg{}
+	offset := 0
+	for offset < len(block) {
+		seq, n := binary.Uvarint(block[offset:])
+		if n <= 0 {
+			return nil, errors.New("corrupted tier 2 record")
+		}
+		offset += n
+		ts, n := binary.Varint(block[offset:])
+		if n <= 0 {
+			return nil, errors.New("corrupted tier 2 record")
+		}
+		offset += n
+		var header, data []byte
+		var err error
+		// the producer is kept for index readers, it is also part of the headers
+		if _, offset, err = readTier2Bytes(block, offset); err != nil {
+			return nil, err
+		}
+		if header, offset, err = readTier2Bytes(block, offset); err != nil {
+			return nil, err
+		}
+		if data, offset, err = readTier2Bytes(block, offset); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, StoredMsg{
+			Sequence: seq,
+			Time:     time.Unix(0, ts),
+			Header:   copyBytes(header),
+			Data:     copyBytes(data),
+		})
+	}
+	return msgs, nil
+}
+
+func decodeTier2Object(object []byte) ([]StoredMsg, error) {
+	if len(object) < tier2ObjectHeaderLen || string(object[:len(tier2ObjectMagic)]) != tier2ObjectMagic {
+		return nil, errors.New("invalid tier 2 object")
+	}
+	compression := object[len(tier2ObjectMagic)]
+	msgs := []StoredMsg{}
+	offset := tier2ObjectHeaderLen
+	for offset < len(object) {
+		if len(object)-offset < tier2BlockLenSize {
+			return nil, errors.New("corrupted tier 2 object")
+		}
+		blockLen := int(binary.BigEndian.Uint32(object[offset:]))
+		offset += tier2BlockLenSize
+		if len(object)-offset < blockLen {
+			return nil, errors.New("corrupted tier 2 object")
+		}
+		block, err := decompressTier2Block(object[offset:offset+blockLen], compression)
+		if err != nil {
+			return nil, err
+		}
+		blockMsgs, err := decodeTier2Block(block)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, blockMsgs...)
+		offset += blockLen
+	}
+	return msgs, nil
+}
+
+func parseTier2Headers(header []byte) map[string]string {
+	hdrs := map[string]string{}
+	if len(header) == 0 {
+		return hdrs
+	}
+	headersSplit := strings.Split(strings.ToLower(string(header)), CR_LF)
+	for _, h := range headersSplit {
+		if h != "" && !strings.Contains(h, "nats") {
+			keyVal := strings.SplitN(h, ":", 2)
+			if len(keyVal) != 2 {
+				continue
+			}
+			hdrs[strings.TrimSpace(keyVal[0])] = strings.TrimSpace(keyVal[1])
+		}
+	}
+	return hdrs
+}
+
+func encodeTier2JsonObject(msgs []StoredMsg) ([]byte, error) {
+	messages := make([]Msg, 0, len(msgs))
+	for _, msg := range msgs {
+		messages = append(messages, Msg{Payload: hex.EncodeToString(msg.Data), Headers: parseTier2Headers(msg.Header)})
+	}
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(messages)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeTier2JsonObject(object []byte) ([]StoredMsg, error) {
+	var messages []Msg
+	err := json.Unmarshal(object, &messages)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]StoredMsg, 0, len(messages))
+	for _, message := range messages {
+		data, err := hex.DecodeString(message.Payload)
+		if err != nil {
+			return nil, err
+		}
+		var header []byte
+		for k, v := range message.Headers {
+			header = genHeader(header, k, v)
+		}
+		msgs = append(msgs, StoredMsg{Header: header, Data: data})
+	}
+	return msgs, nil
+}
+
+func getTier2ObjectSuffix(format string) string {
+	if isCompactTier2Format(format) {
+		return tier2CompactObjectSuffix
+	}
+	return tier2JsonObjectSuffix
+}
+
+// encodeTier2ObjectByFormat returns the object content and, for compact objects, its index manifest
+func encodeTier2ObjectByFormat(msgs []StoredMsg, format, objectName string) ([]byte, []byte, error) {
+	if !isCompactTier2Format(format) {
+		object, err := encodeTier2JsonObject(msgs)
+		return object, nil, err
+	}
+	object, index, err := encodeTier2Object(msgs, tier2CompressionByFormat(format))
+	if err != nil {
+		return nil, nil, err
+	}
+	index.ObjectName = objectName
+	manifest, err := json.Marshal(index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return object, manifest, nil
+}
+
+func decodeTier2ObjectByName(objectName string, object []byte) ([]StoredMsg, error) {
+	if strings.HasSuffix(objectName, tier2CompactObjectSuffix) {
+		return decodeTier2Object(object)
+	}
+	return decodeTier2JsonObject(object)
+}
+
+func isTier2DataObject(objectName string) bool {
+	if strings.HasSuffix(objectName, tier2IndexManifestSuffix) {
+		return false
+	}
+	return strings.HasSuffix(objectName, tier2JsonObjectSuffix) || strings.HasSuffix(objectName, tier2CompactObjectSuffix)
+}
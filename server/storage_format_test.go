@@ -0,0 +1,112 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTier2CompactObjectRoundTrip(t *testing.T) {
+	msgs := []StoredMsg{}
+	for i := 0; i < 2*tier2MaxMsgsPerBlock+3; i++ {
+		msgs = append(msgs, StoredMsg{
+			Sequence: uint64(i + 100),
+			Time:     time.Unix(0, int64(i)*int64(time.Millisecond)),
+			Header:   []byte("NATS/1.0\r\n$memphis_producedBy: Producer-1\r\nX-Custom-Key: Value\r\n\r\n"),
+			Data:     []byte(fmt.Sprintf("message-%d", i)),
+		})
+	}
+
+	for _, compression := range []byte{tier2CompressionNone, tier2CompressionZstd, tier2CompressionS2} {
+		t.Run(tier2CompressionName(compression), func(t *testing.T) {
+			object, index, err := encodeTier2Object(msgs, compression)
+			if err != nil {
+				t.Fatalf("Unexpected error encoding object: %v", err)
+			}
+			if index.MessagesCount != len(msgs) || len(index.Blocks) != 3 {
+				t.Fatalf("Unexpected index: %+v", index)
+			}
+			if index.FirstSeq != 100 || index.LastSeq != msgs[len(msgs)-1].Sequence {
+				t.Fatalf("Unexpected index sequences: %v-%v", index.FirstSeq, index.LastSeq)
+			}
+
+			decoded, err := decodeTier2Object(object)
+			if err != nil {
+				t.Fatalf("Unexpected error decoding object: %v", err)
+			}
+			if len(decoded) != len(msgs) {
+				t.Fatalf("Expected %v messages, got %v", len(msgs), len(decoded))
+			}
+			for i, msg := range decoded {
+				if msg.Sequence != msgs[i].Sequence || !msg.Time.Equal(msgs[i].Time) ||
+					!bytes.Equal(msg.Header, msgs[i].Header) || !bytes.Equal(msg.Data, msgs[i].Data) {
+					t.Fatalf("Message %v does not match the original", i)
+				}
+			}
+
+			// a single block can be decoded by its index entry
+			block := index.Blocks[1]
+			raw, err := decompressTier2Block(object[block.Offset:block.Offset+block.Length], compression)
+			if err != nil {
+				t.Fatalf("Unexpected error decompressing block: %v", err)
+			}
+			blockMsgs, err := decodeTier2Block(raw)
+			if err != nil {
+				t.Fatalf("Unexpected error decoding block: %v", err)
+			}
+			if len(blockMsgs) != block.MessagesCount || blockMsgs[0].Sequence != block.FirstSeq {
+				t.Fatalf("Unexpected block content")
+			}
+		})
+	}
+}
+
+func TestTier2CorruptedObject(t *testing.T) {
+	object, _, err := encodeTier2Object([]StoredMsg{{Sequence: 1, Data: []byte("data")}}, tier2CompressionNone)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding object: %v", err)
+	}
+	if _, err := decodeTier2Object(object[:len(object)-1]); err == nil {
+		t.Fatalf("Expected an error decoding a truncated object")
+	}
+	if _, err := decodeTier2Object([]byte("json")); err == nil {
+		t.Fatalf("Expected an error decoding an object without the magic")
+	}
+}
+
+func TestTier2JsonObjectRoundTrip(t *testing.T) {
+	msgs := []StoredMsg{{
+		Header: []byte("NATS/1.0\r\n$memphis_producedBy: Producer-1\r\n\r\n"),
+		Data:   []byte("message"),
+	}}
+	object, err := encodeTier2JsonObject(msgs)
+	if err != nil {
+		t.Fatalf("Unexpected error encoding object: %v", err)
+	}
+	decoded, err := decodeTier2ObjectByName("memphis/global/station/obj(1).json", object)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding object: %v", err)
+	}
+	if len(decoded) != 1 || string(decoded[0].Data) != "message" {
+		t.Fatalf("Unexpected decoded messages: %+v", decoded)
+	}
+	hdrs, err := DecodeHeader(decoded[0].Header)
+	if err != nil {
+		t.Fatalf("Unexpected error decoding headers: %v", err)
+	}
+	if hdrs["$memphis_producedby"] != "producer-1" {
+		t.Fatalf("Unexpected headers: %v", hdrs)
+	}
+}
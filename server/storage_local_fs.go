@@ -0,0 +1,407 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"memphis/db"
+	"memphis/models"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nuid"
+)
+
+type localFsStorage struct{}
+
+func (localFsStorage) Upload(tenantName string, msgs map[string][]StoredMsg) error {
+	return serv.uploadToLocalFsStorage(tenantName, msgs)
+}
+
+func (localFsStorage) ListObjects(tenantName, stationName string, from, to time.Time) ([]string, error) {
+	return serv.listLocalFsStorageObjects(tenantName, stationName, from, to)
+}
+
+func (localFsStorage) ReadObject(tenantName, objectName string) ([]StoredMsg, error) {
+	return serv.readFromLocalFsStorage(tenantName, objectName)
+}
+
+func cacheDetailsLocalFs(keys map[string]string, properties map[string]bool, tenantName string) {
+	localFsIntegration := models.Integration{}
+	localFsIntegration.Keys = make(map[string]string)
+	localFsIntegration.Properties = make(map[string]bool)
+	if keys == nil {
+		deleteIntegrationFromTenant(tenantName, "local_fs", IntegrationsConcurrentCache)
+		return
+	}
+
+	localFsIntegration.Keys["path"] = keys["path"]
+	localFsIntegration.Name = "local_fs"
+	if _, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
+		IntegrationsConcurrentCache.Add(tenantName, map[string]interface{}{"local_fs": localFsIntegration})
+	} else {
+		err := addIntegrationToTenant(tenantName, "local_fs", IntegrationsConcurrentCache, localFsIntegration)
+		if err != nil {
+			serv.Errorf("cacheDetailsLocalFs: %s ", err.Error())
+			return
+		}
+	}
+}
+
+func getLocalFsIntegration(tenantName string) (models.Integration, bool) {
+	tenantIntegrations, ok := IntegrationsConcurrentCache.Load(tenantName)
+	if !ok {
+		return models.Integration{}, false
+	}
+	integration, ok := tenantIntegrations["local_fs"].(models.Integration)
+	return integration, ok
+}
+
+func (it IntegrationsHandler) handleCreateLocalFsIntegration(tenantName string, keys map[string]string) (models.Integration, int, error) {
+	path, err := resolveLocalFsPath(it.S.opts.TieredStorageLocalFsBaseDir, keys["path"])
+	if err != nil {
+		return models.Integration{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	statusCode, err := testLocalFsIntegration(path)
+	if err != nil {
+		return models.Integration{}, statusCode, err
+	}
+
+	keys, properties := createIntegrationsKeysAndProperties("local_fs", integrationKeysOpts{extraKeys: map[string]string{"path": path}})
+	localFsIntegration, err := createLocalFsIntegration(tenantName, keys, properties)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return models.Integration{}, SHOWABLE_ERROR_STATUS_CODE, err
+		} else {
+			return models.Integration{}, 500, err
+		}
+	}
+	return localFsIntegration, statusCode, nil
+}
+
+func (it IntegrationsHandler) handleUpdateLocalFsIntegration(tenantName string, body models.CreateIntegrationSchema) (models.Integration, int, error) {
+	path, err := resolveLocalFsPath(it.S.opts.TieredStorageLocalFsBaseDir, body.Keys["path"])
+	if err != nil {
+		return models.Integration{}, SHOWABLE_ERROR_STATUS_CODE, err
+	}
+	statusCode, err := testLocalFsIntegration(path)
+	if err != nil {
+		return models.Integration{}, statusCode, err
+	}
+
+	keys, properties := createIntegrationsKeysAndProperties("local_fs", integrationKeysOpts{extraKeys: map[string]string{"path": path}})
+	localFsIntegration, err := updateLocalFsIntegration(tenantName, keys, properties)
+	if err != nil {
+		return localFsIntegration, 500, err
+	}
+	return localFsIntegration, statusCode, nil
+}
+
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// evalExistingSymlinks resolves the symlinks of the longest existing prefix of the path, the rest is created as plain directories
+func evalExistingSymlinks(path string) (string, error) {
+	rest := []string{}
+	for {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return _EMPTY_, err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return _EMPTY_, err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+// resolveLocalFsPath returns the cleaned integration path once it is verified to be within the configured base directory,
+// including after resolving symlinks, so the integration can not be used to write anywhere the broker can
+func resolveLocalFsPath(baseDir, path string) (string, error) {
+	if path == "" {
+		return _EMPTY_, errors.New("path can not be empty")
+	}
+	if !filepath.IsAbs(path) {
+		return _EMPTY_, errors.New("path must be absolute")
+	}
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".." {
+			return _EMPTY_, errors.New("path can not contain ..")
+		}
+	}
+	path = filepath.Clean(path)
+	if !isWithinDir(baseDir, path) {
+		return _EMPTY_, fmt.Errorf("path must be within %v", baseDir)
+	}
+
+	err := os.MkdirAll(baseDir, 0755)
+	if err != nil {
+		return _EMPTY_, fmt.Errorf("could not create directory %v: %v", baseDir, err.Error())
+	}
+	resolvedBaseDir, err := filepath.EvalSymlinks(baseDir)
+	if err != nil {
+		return _EMPTY_, err
+	}
+	resolvedPath, err := evalExistingSymlinks(path)
+	if err != nil {
+		return _EMPTY_, err
+	}
+	if !isWithinDir(resolvedBaseDir, resolvedPath) {
+		return _EMPTY_, fmt.Errorf("path must be within %v, symlinks leading outside of it are not allowed", baseDir)
+	}
+	return path, nil
+}
+
+// localFsObjectPath joins an object name to the integration path, names leading outside of it are rejected
+func localFsObjectPath(rootPath, objectName string) (string, error) {
+	objectPath := filepath.Join(rootPath, filepath.FromSlash(objectName))
+	if !isWithinDir(rootPath, objectPath) {
+		return _EMPTY_, fmt.Errorf("invalid object name %v", objectName)
+	}
+	return objectPath, nil
+}
+
+func (s *Server) getLocalFsRootPath(tenantName string) (string, bool, error) {
+	integration, ok := getLocalFsIntegration(tenantName)
+	if !ok {
+		return _EMPTY_, false, nil
+	}
+	// integrations are verified again since the base directory may have changed since they were created
+	rootPath, err := resolveLocalFsPath(s.opts.TieredStorageLocalFsBaseDir, integration.Keys["path"])
+	if err != nil {
+		return _EMPTY_, true, err
+	}
+	return rootPath, true, nil
+}
+
+func testLocalFsIntegration(path string) (int, error) {
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("could not create directory %v: %v", path, err.Error())
+	}
+
+	testFile := filepath.Join(path, ".memphis_test")
+	err = os.WriteFile(testFile, []byte("test"), 0644)
+	if err != nil {
+		return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("could not write files -  %s", err.Error())
+	}
+	err = os.Remove(testFile)
+	if err != nil {
+		return SHOWABLE_ERROR_STATUS_CODE, fmt.Errorf("could not delete files -  %s", err.Error())
+	}
+	return 0, nil
+}
+
+func createLocalFsIntegration(tenantName string, keys map[string]string, properties map[string]bool) (models.Integration, error) {
+	exist, localFsIntegration, err := db.GetIntegration("local_fs", tenantName)
+	if err != nil {
+		return models.Integration{}, err
+	} else if exist {
+		return models.Integration{}, errors.New("local_fs integration already exists")
+	}
+
+	localFsIntegration, err = db.InsertNewIntegration(tenantName, "local_fs", keys, properties)
+	if err != nil {
+		return models.Integration{}, err
+	}
+	integrationToUpdate := models.CreateIntegration{
+		Name:       "local_fs",
+		Keys:       keys,
+		Properties: properties,
+		TenantName: tenantName,
+	}
+	msg, err := json.Marshal(integrationToUpdate)
+	if err != nil {
+		return models.Integration{}, err
+	}
+	err = serv.sendInternalAccountMsgWithReply(serv.MemphisGlobalAccount(), INTEGRATIONS_UPDATES_SUBJ, _EMPTY_, nil, msg, true)
+	if err != nil {
+		return models.Integration{}, err
+	}
+	return localFsIntegration, nil
+}
+
+func updateLocalFsIntegration(tenantName string, keys map[string]string, properties map[string]bool) (models.Integration, error) {
+	localFsIntegration, err := db.UpdateIntegration(tenantName, "local_fs", keys, properties)
+	if err != nil {
+		return models.Integration{}, err
+	}
+
+	integrationToUpdate := models.CreateIntegration{
+		Name:       "local_fs",
+		Keys:       keys,
+		Properties: properties,
+		TenantName: tenantName,
+	}
+	msg, err := json.Marshal(integrationToUpdate)
+	if err != nil {
+		return localFsIntegration, err
+	}
+	err = serv.sendInternalAccountMsgWithReply(serv.MemphisGlobalAccount(), INTEGRATIONS_UPDATES_SUBJ, _EMPTY_, nil, msg, true)
+	if err != nil {
+		return localFsIntegration, err
+	}
+	return localFsIntegration, nil
+}
+
+func writeLocalFsObject(objectPath string, content []byte) error {
+	err := os.MkdirAll(filepath.Dir(objectPath), 0755)
+	if err != nil {
+		return err
+	}
+	// write to a temp file first so a partially written object is never visible, rename is atomic on local and NFS mounts
+	tmpPath := objectPath + ".tmp"
+	err = os.WriteFile(tmpPath, content, 0644)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	err = os.Rename(tmpPath, objectPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func (s *Server) uploadToLocalFsStorage(tenantName string, tenant map[string][]StoredMsg) error {
+	rootPath, ok, err := s.getLocalFsRootPath(tenantName)
+	if !ok {
+		return nil
+	}
+	if err != nil {
+		return errors.New("uploadToLocalFsStorage: " + err.Error())
+	}
+
+	for k, msgs := range tenant {
+		uid := nuid.Next()
+		objectName := getTier2StationPrefix(tenantName, k) + uid + "(" + strconv.Itoa(len(msgs)) + ")" + getTier2ObjectSuffix(s.opts.TieredStorageObjectFormat)
+		object, manifest, err := encodeTier2ObjectByFormat(msgs, s.opts.TieredStorageObjectFormat, objectName)
+		if err != nil {
+			return err
+		}
+		size := int64(0)
+		for _, msg := range msgs {
+			size += int64(len(msg.Data)) + int64(len(msg.Header))
+		}
+
+		objectPath, err := localFsObjectPath(rootPath, objectName)
+		if err != nil {
+			return errors.New("uploadToLocalFsStorage: " + err.Error())
+		}
+		err = writeLocalFsObject(objectPath, object)
+		if err != nil {
+			return errors.New("uploadToLocalFsStorage: failed to write object: " + err.Error())
+		}
+		if manifest != nil {
+			err = writeLocalFsObject(objectPath+tier2IndexManifestSuffix, manifest)
+			if err != nil {
+				return errors.New("uploadToLocalFsStorage: failed to write index manifest: " + err.Error())
+			}
+		}
+		IncrementEventCounter(tenantName, "tiered", size, int64(len(msgs)), "", []byte{}, []byte{})
+		serv.Noticef("new file has been written to local storage: %s", objectPath)
+	}
+
+	return nil
+}
+
+func (s *Server) listLocalFsStorageObjects(tenantName, stationName string, from, to time.Time) ([]string, error) {
+	rootPath, ok, err := s.getLocalFsRootPath(tenantName)
+	if !ok {
+		return []string{}, errors.New("local_fs integration does not exist")
+	}
+	if err != nil {
+		return []string{}, err
+	}
+	prefix := getTier2StationPrefix(tenantName, stationName)
+	prefixPath, err := localFsObjectPath(rootPath, prefix)
+	if err != nil {
+		return []string{}, err
+	}
+	dirEntries, err := os.ReadDir(prefixPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []string{}, nil
+		}
+		return []string{}, err
+	}
+
+	type localObject struct {
+		name    string
+		modTime time.Time
+	}
+	objects := []localObject{}
+	for _, entry := range dirEntries {
+		if entry.IsDir() || !isTier2DataObject(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if !from.IsZero() && info.ModTime().Before(from) {
+			continue
+		}
+		if !to.IsZero() && info.ModTime().After(to) {
+			continue
+		}
+		objects = append(objects, localObject{name: prefix + entry.Name(), modTime: info.ModTime()})
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].modTime.Before(objects[j].modTime)
+	})
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, object.name)
+	}
+	return keys, nil
+}
+
+func (s *Server) readFromLocalFsStorage(tenantName, objectName string) ([]StoredMsg, error) {
+	rootPath, ok, err := s.getLocalFsRootPath(tenantName)
+	if !ok {
+		return []StoredMsg{}, errors.New("local_fs integration does not exist")
+	}
+	if err != nil {
+		return []StoredMsg{}, err
+	}
+	objectPath, err := localFsObjectPath(rootPath, objectName)
+	if err != nil {
+		return []StoredMsg{}, err
+	}
+
+	content, err := os.ReadFile(objectPath)
+	if err != nil {
+		return []StoredMsg{}, fmt.Errorf("failed to read object %v: %v", objectName, err.Error())
+	}
+	msgs, err := decodeTier2ObjectByName(objectName, content)
+	if err != nil {
+		return []StoredMsg{}, fmt.Errorf("failed to decode object %v: %v", objectName, err.Error())
+	}
+	return msgs, nil
+}
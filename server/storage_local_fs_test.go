@@ -0,0 +1,68 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveLocalFsPath(t *testing.T) {
+	root := t.TempDir()
+	baseDir := filepath.Join(root, "base")
+	outside := filepath.Join(root, "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	path, err := resolveLocalFsPath(baseDir, filepath.Join(baseDir, "tenant", "tier2")+"/")
+	if err != nil || path != filepath.Join(baseDir, "tenant", "tier2") {
+		t.Fatalf("Expected a path within the base directory to be allowed, got %v: %v", path, err)
+	}
+	if _, err := resolveLocalFsPath(baseDir, baseDir); err != nil {
+		t.Fatalf("Expected the base directory itself to be allowed: %v", err)
+	}
+
+	if err := os.Symlink(outside, filepath.Join(baseDir, "link")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	invalid := []string{
+		"",
+		"relative/path",
+		outside,
+		baseDir + "-sibling",
+		filepath.Join(baseDir, "..", "outside"),
+		baseDir + "/tenant/../../outside",
+		filepath.Join(baseDir, "link"),
+		filepath.Join(baseDir, "link", "not", "created"),
+	}
+	for _, p := range invalid {
+		if _, err := resolveLocalFsPath(baseDir, p); err == nil {
+			t.Fatalf("Expected %q to be rejected", p)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "not")); !os.IsNotExist(err) {
+		t.Fatalf("Expected nothing to be created outside of the base directory")
+	}
+}
+
+func TestLocalFsObjectPath(t *testing.T) {
+	rootPath := filepath.Join(t.TempDir(), "tier2")
+	objectPath, err := localFsObjectPath(rootPath, "tenant/station/object(2).json")
+	if err != nil || objectPath != filepath.Join(rootPath, "tenant", "station", "object(2).json") {
+		t.Fatalf("Unexpected object path %v: %v", objectPath, err)
+	}
+	if _, err := localFsObjectPath(rootPath, "../../etc/passwd"); err == nil {
+		t.Fatalf("Expected an object name leading outside of the integration path to be rejected")
+	}
+}
@@ -14,15 +14,18 @@ package server
 import (
 	"bytes"
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 
 	"memphis/db"
 	"memphis/models"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
@@ -31,12 +34,15 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go"
+	"github.com/nats-io/nuid"
 )
 
 type TieredStorageMsg struct {
 	Buf         []byte `json:"buf"`
 	StationName string `json:"station_name"`
 	TenantName  string `json:"tenant_name"`
+	Seq         uint64 `json:"seq"`
+	Timestamp   int64  `json:"timestamp"`
 }
 
 func cacheDetailsS3(keys map[string]string, properties map[string]bool, tenantName string) {
@@ -72,7 +78,14 @@ func (it IntegrationsHandler) handleCreateS3Integration(tenantName string, keys
 		return models.Integration{}, statusCode, err
 	}
 
-	keys, properties := createIntegrationsKeysAndProperties("s3", "", "", false, false, false, keys["access_key"], keys["secret_key"], keys["bucket_name"], keys["region"], keys["url"], keys["s3_path_style"])
+	keys, properties := createIntegrationsKeysAndProperties("s3", integrationKeysOpts{
+		accessKey:        keys["access_key"],
+		secretKey:        keys["secret_key"],
+		bucketName:       keys["bucket_name"],
+		region:           keys["region"],
+		url:              keys["url"],
+		forceS3PathStyle: keys["s3_path_style"],
+	})
 	s3Integration, err := createS3Integration(tenantName, keys, properties)
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
@@ -90,7 +103,14 @@ func (it IntegrationsHandler) handleUpdateS3Integration(tenantName string, body
 		return models.Integration{}, statusCode, err
 	}
 	integrationType := strings.ToLower(body.Name)
-	keys, properties := createIntegrationsKeysAndProperties(integrationType, "", "", false, false, false, keys["access_key"], keys["secret_key"], keys["bucket_name"], keys["region"], keys["url"], keys["s3_path_style"])
+	keys, properties := createIntegrationsKeysAndProperties(integrationType, integrationKeysOpts{
+		accessKey:        keys["access_key"],
+		secretKey:        keys["secret_key"],
+		bucketName:       keys["bucket_name"],
+		region:           keys["region"],
+		url:              keys["url"],
+		forceS3PathStyle: keys["s3_path_style"],
+	})
 	s3Integration, err := updateS3Integration(tenantName, keys, properties)
 	if err != nil {
 		return s3Integration, 500, err
@@ -346,95 +366,231 @@ type Msg struct {
 	Headers map[string]string `json:"headers"`
 }
 
+const (
+	s3MaxUploadsInFlight = 8
+	s3UploadTimeout      = 2 * time.Minute
+)
+
+// s3UploadsInFlight is shared by all the tenants, a flush waits for a free slot until its upload times out
+// and then the tenant backs off like on any other upload failure
+var s3UploadsInFlight = make(chan struct{}, s3MaxUploadsInFlight)
+
 func (s *Server) uploadToS3Storage(tenantName string, tenant map[string][]StoredMsg) error {
-	for k, msgs := range tenant {
-		var credentialsMap models.Integration
-		if tenantIntegrations, ok := IntegrationsConcurrentCache.Load(tenantName); !ok {
-			continue
-		} else {
-			if credentialsMap, ok = tenantIntegrations["s3"].(models.Integration); !ok {
-				continue
-			}
+	credentialsMap, ok := getS3Integration(tenantName)
+	if !ok {
+		return nil
+	}
+	svc, err := getS3ClientFromIntegration(credentialsMap)
+	if err != nil {
+		return errors.New("uploadToS3Storage failure " + err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s3UploadTimeout)
+	defer cancel()
+	uploaded, err := uploadTier2ObjectsToS3(ctx, svc, credentialsMap.Keys["bucket_name"], tenantName, s.opts.TieredStorageObjectFormat, tenant)
+	for station, objectName := range uploaded {
+		size := int64(0)
+		for _, msg := range tenant[station] {
+			size += int64(len(msg.Data)) + int64(len(msg.Header))
 		}
-		provider := credentials.NewStaticCredentialsProvider(
-			credentialsMap.Keys["access_key"],
-			credentialsMap.Keys["secret_key"],
-			"",
-		)
+		IncrementEventCounter(tenantName, "tiered", size, int64(len(tenant[station])), "", []byte{}, []byte{})
+		s.Noticef("new file has been uploaded to S3: %s", objectName)
+	}
+	return err
+}
 
-		region := credentialsMap.Keys["region"]
-		url := credentialsMap.Keys["url"]
-		pathStyle, _ := strconv.ParseBool(credentialsMap.Keys["s3_path_style"])
+// uploadTier2ObjectsToS3 uploads an object per station, each upload holds an in flight slot until both the object
+// and its manifest are stored, it returns the object names of the stations which were uploaded
+func uploadTier2ObjectsToS3(ctx context.Context, svc manager.UploadAPIClient, bucketName, tenantName, format string, tenant map[string][]StoredMsg) (map[string]string, error) {
+	uploader := manager.NewUploader(svc, func(u *manager.Uploader) {
+		// the parts of a large object are sent one by one so the slots bound the requests in flight
+		u.Concurrency = 1
+	})
 
-		_, err := provider.Retrieve(context.Background())
-		if err != nil {
-			err = errors.New("uploadToS3Storage: Invalid credentials")
-			return err
-		}
-		cfg, err := awsconfig.LoadDefaultConfig(context.Background(),
-			awsconfig.WithCredentialsProvider(provider),
-			awsconfig.WithRegion(credentialsMap.Keys["region"]),
-			awsconfig.WithEndpointResolverWithOptions(getS3EndpointResolver(region, url)),
-		)
-		svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
-			o.UsePathStyle = pathStyle
-		})
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var uploadErr error
+	uploaded := make(map[string]string, len(tenant))
+	for k, msgs := range tenant {
+		uid := nuid.Next()
+		objectName := getTier2StationPrefix(tenantName, k) + uid + "(" + strconv.Itoa(len(msgs)) + ")" + getTier2ObjectSuffix(format)
+		object, manifest, err := encodeTier2ObjectByFormat(msgs, format, objectName)
 		if err != nil {
-			err = errors.New("uploadToS3Storage failure " + err.Error())
-			return err
+			wg.Wait()
+			return uploaded, err
 		}
-		uploader := manager.NewUploader(svc)
-		uid := serv.memphis.nuid.Next()
-		var objectName string
 
-		var messages []Msg
-		size := int64(0)
-		for _, msg := range msgs {
-			if tenantName == serv.MemphisGlobalAccountString() {
-				tenantName = "global"
-			}
-			objectName = "memphis/" + tenantName + "/" + k + "/" + uid + "(" + strconv.Itoa(len(msgs)) + ").json"
-			var headers string
-			hdrs := map[string]string{}
-			if len(msg.Header) > 0 {
-				headers = strings.ToLower(string(msg.Header))
-				headersSplit := strings.Split(headers, CR_LF)
-				for _, header := range headersSplit {
-					if header != "" && !strings.Contains(header, "nats") {
-						keyVal := strings.Split(header, ":")
-						key := strings.TrimSpace(keyVal[0])
-						value := strings.TrimSpace(keyVal[1])
-						hdrs[key] = value
-					}
+		select {
+		case s3UploadsInFlight <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return uploaded, errors.New("uploadToS3Storage: timed out waiting for an upload slot: " + ctx.Err().Error())
+		}
+		wg.Add(1)
+		go func(station, objectName string, object, manifest []byte) {
+			defer wg.Done()
+			defer func() { <-s3UploadsInFlight }()
+			err := uploadTier2ObjectToS3(ctx, uploader, bucketName, objectName, object, manifest)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if uploadErr == nil {
+					uploadErr = err
 				}
-			} else {
-				headers = ""
+				return
 			}
+			uploaded[station] = objectName
+		}(k, objectName, object, manifest)
+	}
+	wg.Wait()
+	return uploaded, uploadErr
+}
 
-			encodedMsg := hex.EncodeToString(msg.Data)
-			message := Msg{Payload: encodedMsg, Headers: hdrs}
-			messages = append(messages, message)
-			size += int64(len(msg.Data)) + int64(len(msg.Header))
-		}
-		// Upload the object to S3.
-		var buf bytes.Buffer
-		err = json.NewEncoder(&buf).Encode(messages)
+func uploadTier2ObjectToS3(ctx context.Context, uploader *manager.Uploader, bucketName, objectName string, object, manifest []byte) error {
+	// Upload the object to S3.
+	_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(objectName),
+		Body:   bytes.NewReader(object),
+	})
+	if err != nil {
+		return errors.New("uploadToS3Storage: failed to upload object to S3: " + err.Error())
+	}
+	if manifest != nil {
+		_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(objectName + tier2IndexManifestSuffix),
+			Body:   bytes.NewReader(manifest),
+		})
 		if err != nil {
-			return err
+			return errors.New("uploadToS3Storage: failed to upload index manifest to S3: " + err.Error())
 		}
-		_, err = uploader.Upload(context.Background(), &s3.PutObjectInput{
-			Bucket: aws.String(credentialsMap.Keys["bucket_name"]),
-			Key:    aws.String(objectName),
-			Body:   &buf,
-		})
+	}
+	return nil
+}
+
+func getS3ClientFromIntegration(integration models.Integration) (*s3.Client, error) {
+	provider := credentials.NewStaticCredentialsProvider(
+		integration.Keys["access_key"],
+		integration.Keys["secret_key"],
+		"",
+	)
+	_, err := provider.Retrieve(context.Background())
+	if err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	region := integration.Keys["region"]
+	url := integration.Keys["url"]
+	pathStyle, _ := strconv.ParseBool(integration.Keys["s3_path_style"])
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithCredentialsProvider(provider),
+		awsconfig.WithRegion(region),
+		awsconfig.WithEndpointResolverWithOptions(getS3EndpointResolver(region, url)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.UsePathStyle = pathStyle
+	})
+	return svc, nil
+}
+
+func getS3Integration(tenantName string) (models.Integration, bool) {
+	tenantIntegrations, ok := IntegrationsConcurrentCache.Load(tenantName)
+	if !ok {
+		return models.Integration{}, false
+	}
+	integration, ok := tenantIntegrations["s3"].(models.Integration)
+	return integration, ok
+}
+
+type s3Storage struct{}
+
+func (s3Storage) Upload(tenantName string, msgs map[string][]StoredMsg) error {
+	return serv.uploadToS3Storage(tenantName, msgs)
+}
+
+func (s3Storage) ListObjects(tenantName, stationName string, from, to time.Time) ([]string, error) {
+	return serv.listS3StorageObjects(tenantName, stationName, from, to)
+}
+
+func (s3Storage) ReadObject(tenantName, objectName string) ([]StoredMsg, error) {
+	return serv.readFromS3Storage(tenantName, objectName)
+}
+
+func (s *Server) listS3StorageObjects(tenantName, stationName string, from, to time.Time) ([]string, error) {
+	integration, ok := getS3Integration(tenantName)
+	if !ok {
+		return []string{}, errors.New("s3 integration does not exist")
+	}
+	svc, err := getS3ClientFromIntegration(integration)
+	if err != nil {
+		return []string{}, err
+	}
+
+	objects := []types.Object{}
+	paginator := s3.NewListObjectsV2Paginator(svc, &s3.ListObjectsV2Input{
+		Bucket: aws.String(integration.Keys["bucket_name"]),
+		Prefix: aws.String(getTier2StationPrefix(tenantName, stationName)),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(context.Background())
 		if err != nil {
-			err = errors.New("uploadToS3Storage: failed to upload object to S3: " + err.Error())
-			return err
+			return []string{}, err
+		}
+		for _, object := range page.Contents {
+			if object.LastModified == nil || !isTier2DataObject(*object.Key) {
+				continue
+			}
+			if !from.IsZero() && object.LastModified.Before(from) {
+				continue
+			}
+			if !to.IsZero() && object.LastModified.After(to) {
+				continue
+			}
+			objects = append(objects, object)
 		}
-		IncrementEventCounter(tenantName, "tiered", size, int64(len(messages)), "", []byte{}, []byte{})
-		serv.Noticef("new file has been uploaded to S3: %s", objectName)
 	}
 
-	return nil
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].LastModified.Before(*objects[j].LastModified)
+	})
+	keys := make([]string, 0, len(objects))
+	for _, object := range objects {
+		keys = append(keys, *object.Key)
+	}
+	return keys, nil
+}
 
+func (s *Server) readFromS3Storage(tenantName, objectName string) ([]StoredMsg, error) {
+	integration, ok := getS3Integration(tenantName)
+	if !ok {
+		return []StoredMsg{}, errors.New("s3 integration does not exist")
+	}
+	svc, err := getS3ClientFromIntegration(integration)
+	if err != nil {
+		return []StoredMsg{}, err
+	}
+
+	object, err := svc.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(integration.Keys["bucket_name"]),
+		Key:    aws.String(objectName),
+	})
+	if err != nil {
+		return []StoredMsg{}, fmt.Errorf("failed to download object %v: %v", objectName, err.Error())
+	}
+	defer object.Body.Close()
+
+	content, err := io.ReadAll(object.Body)
+	if err != nil {
+		return []StoredMsg{}, fmt.Errorf("failed to download object %v: %v", objectName, err.Error())
+	}
+	msgs, err := decodeTier2ObjectByName(objectName, content)
+	if err != nil {
+		return []StoredMsg{}, fmt.Errorf("failed to decode object %v: %v", objectName, err.Error())
+	}
+	return msgs, nil
 }
@@ -0,0 +1,182 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type mockS3UploadClient struct {
+	mu          sync.Mutex
+	objects     map[string][]byte
+	delay       time.Duration
+	failKey     string
+	inFlight    int32
+	maxInFlight int32
+}
+
+func newMockS3UploadClient() *mockS3UploadClient {
+	return &mockS3UploadClient{objects: map[string][]byte{}}
+}
+
+func (c *mockS3UploadClient) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	inFlight := atomic.AddInt32(&c.inFlight, 1)
+	defer atomic.AddInt32(&c.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&c.maxInFlight)
+		if inFlight <= max || atomic.CompareAndSwapInt32(&c.maxInFlight, max, inFlight) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+
+	key := *params.Key
+	if c.failKey != "" && strings.Contains(key, c.failKey) {
+		return nil, errors.New("access denied")
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.objects[key] = body
+	c.mu.Unlock()
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (c *mockS3UploadClient) UploadPart(context.Context, *s3.UploadPartInput, ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return nil, errors.New("unexpected multipart upload")
+}
+
+func (c *mockS3UploadClient) CreateMultipartUpload(context.Context, *s3.CreateMultipartUploadInput, ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return nil, errors.New("unexpected multipart upload")
+}
+
+func (c *mockS3UploadClient) CompleteMultipartUpload(context.Context, *s3.CompleteMultipartUploadInput, ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return nil, errors.New("unexpected multipart upload")
+}
+
+func (c *mockS3UploadClient) AbortMultipartUpload(context.Context, *s3.AbortMultipartUploadInput, ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return nil, errors.New("unexpected multipart upload")
+}
+
+func testTier2Msgs(count int) []StoredMsg {
+	msgs := make([]StoredMsg, 0, count)
+	for i := 1; i <= count; i++ {
+		msgs = append(msgs, StoredMsg{Sequence: uint64(i), Data: []byte("payload"), Time: time.Unix(int64(i), 0)})
+	}
+	return msgs
+}
+
+func TestUploadTier2ObjectsToS3(t *testing.T) {
+	for _, format := range []string{tier2ObjectFormatJson, tier2ObjectFormatCompact} {
+		svc := newMockS3UploadClient()
+		tenant := map[string][]StoredMsg{"orders": testTier2Msgs(3), "payments": testTier2Msgs(1)}
+		uploaded, err := uploadTier2ObjectsToS3(context.Background(), svc, "bucket", "tenant", format, tenant)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", format, err)
+		}
+		if len(uploaded) != len(tenant) {
+			t.Fatalf("%v: expected %v uploaded objects, got %v", format, len(tenant), len(uploaded))
+		}
+		for station, objectName := range uploaded {
+			if !strings.HasPrefix(objectName, "memphis/tenant/"+station+"/") {
+				t.Fatalf("%v: unexpected object name %v", format, objectName)
+			}
+			msgs, err := decodeTier2ObjectByName(objectName, svc.objects[objectName])
+			if err != nil || len(msgs) != len(tenant[station]) {
+				t.Fatalf("%v: expected %v messages in %v, got %v: %v", format, len(tenant[station]), objectName, len(msgs), err)
+			}
+			_, hasManifest := svc.objects[objectName+tier2IndexManifestSuffix]
+			if hasManifest != isCompactTier2Format(format) {
+				t.Fatalf("%v: unexpected manifest state of %v", format, objectName)
+			}
+		}
+	}
+}
+
+func TestUploadTier2ObjectsToS3Failure(t *testing.T) {
+	svc := newMockS3UploadClient()
+	svc.failKey = "/payments/"
+	tenant := map[string][]StoredMsg{"orders": testTier2Msgs(2), "payments": testTier2Msgs(2)}
+	uploaded, err := uploadTier2ObjectsToS3(context.Background(), svc, "bucket", "tenant", tier2ObjectFormatJson, tenant)
+	if err == nil {
+		t.Fatalf("Expected the failed upload to be reported")
+	}
+	if _, ok := uploaded["payments"]; ok {
+		t.Fatalf("Expected the failed station not to be reported as uploaded")
+	}
+	if _, ok := uploaded["orders"]; !ok {
+		t.Fatalf("Expected the rest of the stations to be uploaded")
+	}
+	if len(s3UploadsInFlight) != 0 {
+		t.Fatalf("Expected every upload slot to be released, %v are held", len(s3UploadsInFlight))
+	}
+}
+
+func TestUploadTier2ObjectsToS3InFlightLimit(t *testing.T) {
+	svc := newMockS3UploadClient()
+	svc.delay = 20 * time.Millisecond
+	tenant := map[string][]StoredMsg{}
+	for i := 0; i < s3MaxUploadsInFlight*3; i++ {
+		tenant["station"+strings.Repeat("x", i)] = testTier2Msgs(1)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := uploadTier2ObjectsToS3(context.Background(), svc, "bucket", "tenant", tier2ObjectFormatJson, tenant); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if max := atomic.LoadInt32(&svc.maxInFlight); max > s3MaxUploadsInFlight {
+		t.Fatalf("Expected at most %v uploads in flight, got %v", s3MaxUploadsInFlight, max)
+	}
+	if len(svc.objects) != len(tenant)*2 {
+		t.Fatalf("Expected %v objects, got %v", len(tenant)*2, len(svc.objects))
+	}
+}
+
+func TestUploadTier2ObjectsToS3SlotTimeout(t *testing.T) {
+	for i := 0; i < s3MaxUploadsInFlight; i++ {
+		s3UploadsInFlight <- struct{}{}
+	}
+	defer func() {
+		for i := 0; i < s3MaxUploadsInFlight; i++ {
+			<-s3UploadsInFlight
+		}
+	}()
+
+	svc := newMockS3UploadClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	uploaded, err := uploadTier2ObjectsToS3(ctx, svc, "bucket", "tenant", tier2ObjectFormatJson, map[string][]StoredMsg{"orders": testTier2Msgs(1)})
+	if err == nil || len(uploaded) != 0 {
+		t.Fatalf("Expected the upload to time out waiting for a slot, got %v: %v", uploaded, err)
+	}
+	if len(svc.objects) != 0 {
+		t.Fatalf("Expected nothing to be uploaded")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2022-2023 The Memphis.dev Authors
+// Licensed under the Memphis Business Source License 1.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// Changed License: [Apache License, Version 2.0 (https://www.apache.org/licenses/LICENSE-2.0), as published by the Apache Foundation.
+//
+// https://github.com/memphisdev/memphis/blob/master/LICENSE
+//
+// Additional Use Grant: You may make use of the Licensed Work (i) only as part of your own product or service, provided it is not a message broker or a message queue product or service; and (ii) provided that you do not use, provide, distribute, or make available the Licensed Work as a Service.
+// A "Service" is a commercial offering, product, hosted, or managed service, that allows third parties (other than your own employees and contractors acting on your behalf) to access and/or use the Licensed Work or a substantial set of the features or functionality of the Licensed Work to third parties as a software-as-a-service, platform-as-a-service, infrastructure-as-a-service or other similar services that compete with Licensor products or services.
+package server
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestValidateRehydrateObjectNames(t *testing.T) {
+	prefix := "memphis/tenant/orders/"
+	if err := validateRehydrateObjectNames(prefix, "orders", []string{prefix + "a(2).json", prefix + "b(1).mts"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	invalid := []string{
+		"memphis/tenant/payments/a(2).json",
+		"memphis/tenant/orders-archive/a(2).json",
+		prefix + "../payments/a(2).json",
+		"memphis/other/orders/a(2).json",
+	}
+	for _, objectName := range invalid {
+		if err := validateRehydrateObjectNames(prefix, "orders", []string{objectName}); err == nil {
+			t.Fatalf("Expected %v to be rejected", objectName)
+		}
+	}
+	tooMany := make([]string, rehydrateMaxObjects+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("%v%v(1).json", prefix, i)
+	}
+	if err := validateRehydrateObjectNames(prefix, "orders", tooMany); err == nil {
+		t.Fatalf("Expected the objects count to be bounded")
+	}
+}
+
+func TestGetRehydratedMsgHeaders(t *testing.T) {
+	hdr := genHeader(nil, "$memphis_producedBy", "$memphis_dls")
+	hdr = genHeader(hdr, "$memphis_connectionId", "conn")
+	hdr = genHeader(hdr, "Nats-Msg-Id", "id")
+	hdr = genHeader(hdr, "order-id", "17")
+	hdrs, err := getRehydratedMsgHeaders(StoredMsg{Header: hdr}, "object")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{"$memphis_producedBy": rehydrateProducerName, "$memphis_rehydrated_from": "object", "order-id": "17"}
+	if len(hdrs) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, hdrs)
+	}
+	for k, v := range expected {
+		if hdrs[k] != v {
+			t.Fatalf("Expected %v, got %v", expected, hdrs)
+		}
+	}
+
+	// objects written in the json format keep lower cased headers only
+	hdrs, err = getRehydratedMsgHeaders(StoredMsg{Header: genHeader(nil, "$memphis_producedby", "orders-producer")}, "object")
+	if err != nil || hdrs["$memphis_producedBy"] != "orders-producer" || len(hdrs) != 2 {
+		t.Fatalf("Expected the original producer to be kept, got %v: %v", hdrs, err)
+	}
+}
+
+func TestIsRehydratedMsgInRange(t *testing.T) {
+	now := time.Now()
+	msg := StoredMsg{Time: now}
+	tests := []struct {
+		from, to time.Time
+		inRange  bool
+	}{
+		{time.Time{}, time.Time{}, true},
+		{now.Add(-time.Minute), now.Add(time.Minute), true},
+		{now.Add(time.Second), time.Time{}, false},
+		{time.Time{}, now.Add(-time.Second), false},
+	}
+	for i, test := range tests {
+		if got := isRehydratedMsgInRange(msg, test.from, test.to); got != test.inRange {
+			t.Errorf("test %v: expected %v, got %v", i, test.inRange, got)
+		}
+	}
+}
@@ -3651,6 +3651,12 @@ func (mset *stream) getDirectRequest(req *JSApiMsgGetRequest, reply string) {
 func (mset *stream) processInboundJetStreamMsg(_ *subscription, c *client, _ *Account, subject, reply string, rmsg []byte) {
 	hdr, msg := c.msgParts(rmsg)
 
+	// ** added by memphis
+	if !mset.enforceStationSchema(c, reply, hdr, msg) {
+		return
+	}
+	// added by memphis **
+
 	// If we are not receiving directly from a client we should move this to another Go routine.
 	// Make sure to grab no stream or js locks.
 	if c.kind != CLIENT {
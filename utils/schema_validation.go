@@ -47,6 +47,14 @@ func descriptive(verr validator.ValidationErrors) []ValidationError {
 	return errs
 }
 
+// BindingErrorMessage describes a failed bind the same way Validate does, for handlers binding forms themselves
+func BindingErrorMessage(err error) interface{} {
+	if verr, ok := err.(validator.ValidationErrors); ok {
+		return descriptive(verr)
+	}
+	return err.Error()
+}
+
 func InitializeValidations() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterTagNameFunc(func(fld reflect.StructField) string {